@@ -16,7 +16,8 @@ const version = "1.0.0"
 func main() {
 	// CLI flags
 	cbomFile := flag.String("cbom", "", "Path to CBOM JSON file")
-	outputFile := flag.String("output", "", "Path to output migration plan JSON (default: cbom-basename-migration-plan.json)")
+	outputFile := flag.String("output", "", "Path to output migration plan (default: cbom-basename-migration-plan.json, or .md with -format md)")
+	format := flag.String("format", "json", "Output format: json or md (Markdown report)")
 	context := flag.String("context", "", "Deployment context (edge_ingress, service_mesh, etc.)")
 	timeline := flag.String("timeline", "", "Target timeline (e.g., 2025-Q2)")
 	rulesFile := flag.String("rules", "migration-rules.yaml", "Path to migration rules YAML")
@@ -29,6 +30,11 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *format != "json" && *format != "md" {
+		fmt.Fprintf(os.Stderr, "Error: unsupported format '%s'. Use: json, md\n", *format)
+		os.Exit(1)
+	}
+
 	if *cbomFile == "" {
 		fmt.Fprintln(os.Stderr, "Error: -cbom flag is required")
 		flag.Usage()
@@ -62,19 +68,24 @@ func main() {
 	// Determine output file
 	outPath := *outputFile
 	if outPath == "" {
-		// Default: cbom-basename-migration-plan.json
+		// Default: cbom-basename-migration-plan.json (or .md)
 		baseName := strings.TrimSuffix(*cbomFile, ".json")
-		outPath = baseName + "-migration-plan.json"
+		outPath = baseName + "-migration-plan." + *format
 	}
 
 	// Write migration plan
-	planJSON, err := json.MarshalIndent(plan, "", "  ")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling migration plan: %v\n", err)
-		os.Exit(1)
+	var planData []byte
+	if *format == "md" {
+		planData = []byte(migration.RenderPlanMarkdown(plan))
+	} else {
+		planData, err = json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling migration plan: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	if err := os.WriteFile(outPath, planJSON, 0644); err != nil {
+	if err := os.WriteFile(outPath, planData, 0644); err != nil {
 		fmt.Fprintf(os.Stderr, "Error writing migration plan: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,174 @@
+// Package compliance maps findings to the controls of compliance frameworks, such
+// as PCI DSS, FIPS 140-3 and NIST SP 800-131A, and summarizes which controls a scan
+// passes. The mapping table is frameworks.yaml.
+package compliance
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"qvs-pro/scanner/internal/crypto"
+)
+
+// Control results in a Summary
+const (
+	ResultPass = "pass"
+	ResultFail = "fail"
+)
+
+//go:embed frameworks.yaml
+var frameworksYAML []byte
+
+// Framework is a compliance framework and the controls findings are mapped to
+type Framework struct {
+	ID       string    `yaml:"-"` // Name selecting the framework, e.g. "pci"
+	Name     string    `yaml:"name"`
+	Controls []Control `yaml:"controls"`
+}
+
+// Control is a requirement of a framework that findings can violate
+type Control struct {
+	ID                 string   `yaml:"id"`
+	Title              string   `yaml:"title"`
+	Requirement        string   `yaml:"requirement"`
+	Status             string   `yaml:"status"`              // What the control says of matching findings, e.g. disallowed
+	Algorithms         []string `yaml:"algorithms"`          // Compared by canonical name
+	VulnerabilityTypes []string `yaml:"vulnerability_types"` // Matched exactly
+	BelowKeySize       int      `yaml:"below_key_size"`      // Algorithm matches also need a known key size below this
+}
+
+// Summary is the pass/fail outcome of every control of a framework
+type Summary struct {
+	Framework string          `json:"framework"`
+	Name      string          `json:"name"`
+	Passed    int             `json:"passed"`
+	Failed    int             `json:"failed"`
+	Controls  []ControlResult `json:"controls"`
+}
+
+// ControlResult is the outcome of one control: it fails when any finding violates it
+type ControlResult struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Requirement string `json:"requirement"`
+	Result      string `json:"result"`             // pass or fail
+	Findings    int    `json:"findings,omitempty"` // Findings violating the control
+}
+
+// Frameworks returns the built-in frameworks keyed by ID
+func Frameworks() (map[string]*Framework, error) {
+	var table struct {
+		Frameworks map[string]*Framework `yaml:"frameworks"`
+	}
+	if err := yaml.UnmarshalStrict(frameworksYAML, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse compliance frameworks: %w", err)
+	}
+	for id, framework := range table.Frameworks {
+		framework.ID = id
+	}
+	return table.Frameworks, nil
+}
+
+// Load returns the built-in framework with the given ID
+func Load(id string) (*Framework, error) {
+	frameworks, err := Frameworks()
+	if err != nil {
+		return nil, err
+	}
+	framework, ok := frameworks[strings.ToLower(id)]
+	if !ok {
+		ids := make([]string, 0, len(frameworks))
+		for id := range frameworks {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return nil, fmt.Errorf("unsupported compliance framework '%s'. Use: %s", id, strings.Join(ids, ", "))
+	}
+	return framework, nil
+}
+
+// Matches reports whether result violates the control
+func (c Control) Matches(result crypto.Result) bool {
+	for _, vulnerability := range c.VulnerabilityTypes {
+		if result.VulnerabilityType == vulnerability {
+			return true
+		}
+	}
+	algorithm := crypto.CanonicalAlgorithm(result.Algorithm)
+	for _, candidate := range c.Algorithms {
+		if crypto.CanonicalAlgorithm(candidate) != algorithm {
+			continue
+		}
+		if c.BelowKeySize == 0 {
+			return true
+		}
+		size := keySize(result)
+		return size > 0 && size < c.BelowKeySize
+	}
+	return false
+}
+
+// nistKeySizePattern reads the key size of NIST IR 8547 entries such as RSA-2048
+var nistKeySizePattern = regexp.MustCompile(`^(?:RSA|DH)-(\d+)$`)
+
+// keySize returns the key size of a finding in bits, or 0 when unknown
+func keySize(result crypto.Result) int {
+	if result.KeySize > 0 {
+		return result.KeySize
+	}
+	if m := nistKeySizePattern.FindStringSubmatch(result.NISTAlgorithmID); m != nil {
+		size, _ := strconv.Atoi(m[1])
+		return size
+	}
+	return 0
+}
+
+// Assessment maps findings to the controls of a framework as a scan produces
+// them and counts the findings violating each control
+type Assessment struct {
+	framework  *Framework
+	violations []int // Findings per control, in Controls order
+}
+
+// NewAssessment starts an assessment of findings against f
+func (f *Framework) NewAssessment() *Assessment {
+	return &Assessment{framework: f, violations: make([]int, len(f.Controls))}
+}
+
+// Add records the controls result violates in its Compliance
+func (a *Assessment) Add(result *crypto.Result) {
+	for i, control := range a.framework.Controls {
+		if !control.Matches(*result) {
+			continue
+		}
+		a.violations[i]++
+		result.Compliance = append(result.Compliance, crypto.ComplianceControl{
+			Framework: a.framework.Name,
+			Control:   control.ID,
+			Title:     control.Title,
+			Status:    control.Status,
+		})
+	}
+}
+
+// Summary returns the outcome of every control for the findings added so far
+func (a *Assessment) Summary() *Summary {
+	summary := &Summary{Framework: a.framework.ID, Name: a.framework.Name}
+	for i, control := range a.framework.Controls {
+		result := ControlResult{ID: control.ID, Title: control.Title, Requirement: control.Requirement, Result: ResultPass, Findings: a.violations[i]}
+		if result.Findings > 0 {
+			result.Result = ResultFail
+			summary.Failed++
+		} else {
+			summary.Passed++
+		}
+		summary.Controls = append(summary.Controls, result)
+	}
+	return summary
+}
@@ -0,0 +1,81 @@
+package crypto
+
+import "math"
+
+// agilityRiskWeights weight a vulnerable finding by its risk level
+var agilityRiskWeights = map[string]float64{
+	"Critical": 4,
+	"High":     3,
+	"Medium":   2,
+	"Low":      1,
+}
+
+// agilityHNDLMultipliers scale a vulnerable finding by its harvest-now-decrypt-later exposure
+var agilityHNDLMultipliers = map[string]float64{
+	HNDLRiskHigh:   2,
+	HNDLRiskMedium: 1.5,
+	HNDLRiskLow:    1,
+	HNDLRiskNone:   1,
+}
+
+// CryptoAgilityScore rates how quantum-ready a set of findings is, from 0 (nothing
+// migrated, heavy exposure) to 100 (everything quantum-safe), with a letter grade.
+//
+// Each quantum-safe finding (see IsQuantumSafe) counts 1. Each other finding counts
+// riskWeight x hndlMultiplier, where riskWeight is Critical 4, High 3, Medium 2, Low (or
+// unknown) 1 and hndlMultiplier is High 2, Medium 1.5, Low/None 1. Then
+//
+//	score = round(100 x safe / (safe + vulnerable))
+//
+// so a single high-risk, high-exposure finding outweighs six quantum-safe ones.
+// No findings scores 100. Grades: A >= 90, B >= 75, C >= 60, D >= 40, F below.
+func CryptoAgilityScore(results []Result) (int, string) {
+	var safe, vulnerable float64
+	for _, result := range results {
+		if IsQuantumSafe(result) {
+			safe++
+			continue
+		}
+
+		weight, ok := agilityRiskWeights[result.Risk]
+		if !ok {
+			weight = 1
+		}
+		hndl := result.HNDLRisk
+		if hndl == "" {
+			hndl = AssessHNDLRisk(result.Algorithm, result.Type)
+		}
+		if multiplier, ok := agilityHNDLMultipliers[hndl]; ok {
+			weight *= multiplier
+		}
+		vulnerable += weight
+	}
+
+	score := 100
+	if safe+vulnerable > 0 {
+		score = int(math.Round(100 * safe / (safe + vulnerable)))
+	}
+	return score, AgilityGrade(score)
+}
+
+// AgilityGrade converts a crypto-agility score to a letter grade
+func AgilityGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 75:
+		return "B"
+	case score >= 60:
+		return "C"
+	case score >= 40:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+// IsQuantumSafe reports whether a finding resists quantum attacks, either per its
+// NIST IR 8547 classification or because it is post-quantum or hybrid cryptography
+func IsQuantumSafe(result Result) bool {
+	return result.QuantumResistant || result.Type == "PostQuantum" || result.Type == "Hybrid"
+}
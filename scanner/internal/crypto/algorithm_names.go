@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"regexp"
+	"strings"
+)
+
+// canonicalAlgorithms maps synonyms of algorithm names, upper-cased with "-"
+// separators, to the name the detectors report them under
+var canonicalAlgorithms = map[string]string{
+	"ECDHE":              "ECDH",
+	"EC-DH":              "ECDH",
+	"EC":                 "ECC",
+	"ELLIPTIC-CURVE":     "ECC",
+	"DHE":                "DH",
+	"EDH":                "DH",
+	"FFDHE":              "DH",
+	"DIFFIE-HELLMAN":     "DH",
+	"DIFFIEHELLMAN":      "DH",
+	"ED25519":            "EdDSA",
+	"ED448":              "EdDSA",
+	"SHA1":               "SHA-1",
+	"SHA256":             "SHA-256",
+	"SHA384":             "SHA-384",
+	"SHA512":             "SHA-512",
+	"SHA3":               "SHA-3",
+	"AES128":             "AES-128",
+	"AES192":             "AES-192",
+	"AES256":             "AES-256",
+	"TRIPLEDES":          "3DES",
+	"TRIPLE-DES":         "3DES",
+	"DESEDE":             "3DES",
+	"TDES":               "3DES",
+	"CHACHA20-POLY1305":  "ChaCha20",
+	"KYBER":              "ML-KEM",
+	"CRYSTALS-KYBER":     "ML-KEM",
+	"DILITHIUM":          "ML-DSA",
+	"CRYSTALS-DILITHIUM": "ML-DSA",
+	"SPHINCS+":           "SLH-DSA",
+}
+
+// algorithmNameSeparators are the separators CanonicalAlgorithm treats as "-"
+var algorithmNameSeparators = regexp.MustCompile(`[\s_/]+`)
+
+// CanonicalAlgorithm returns the name findings report an algorithm under, so
+// synonyms such as "ECDHE" and "ECDH" count as one algorithm. Names without a
+// synonym are returned unchanged.
+func CanonicalAlgorithm(name string) string {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	key = algorithmNameSeparators.ReplaceAllString(key, "-")
+	if canonical, ok := canonicalAlgorithms[key]; ok {
+		return canonical
+	}
+	return name
+}
@@ -0,0 +1,239 @@
+package crypto
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Archive limits guard against zip bombs and runaway nesting
+const (
+	maxArchiveDepth     = 3
+	maxArchiveEntries   = 10000
+	maxArchiveEntrySize = 10 << 20  // 10 MiB uncompressed per entry
+	maxArchiveTotalSize = 200 << 20 // 200 MiB uncompressed per top-level archive
+)
+
+// archiveBudget tracks how much of an archive tree has been expanded
+type archiveBudget struct {
+	entries int
+	bytes   int64
+}
+
+// IsArchive reports whether a path names a supported archive format
+func IsArchive(path string) bool {
+	lower := strings.ToLower(path)
+	return isZipArchive(lower) || isTarGzArchive(lower)
+}
+
+func isZipArchive(lower string) bool {
+	switch filepath.Ext(lower) {
+	case ".jar", ".war", ".ear", ".zip":
+		return true
+	}
+	return false
+}
+
+func isTarGzArchive(lower string) bool {
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// ScanArchive scans eligible entries inside an archive in memory, descending into
+// nested archives up to a bounded depth. Findings are reported as "archive!/entry".
+// Returns results and the number of entries scanned.
+func (s *Scanner) ScanArchive(archivePath string) ([]Result, int) {
+	budget := &archiveBudget{}
+
+	if s.Verbose {
+		s.logf("Scanning archive: %s\n", archivePath)
+	}
+
+	if isTarGzArchive(strings.ToLower(archivePath)) {
+		file, err := os.Open(archivePath)
+		if err != nil {
+			s.logf("Error opening archive %s: %v\n", archivePath, err)
+			s.warn("", archivePath, fmt.Sprintf("failed to open archive: %v", err))
+			return nil, 0
+		}
+		defer file.Close()
+		return s.scanTarGz(archivePath, file, 1, budget)
+	}
+
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		s.logf("Error opening archive %s: %v\n", archivePath, err)
+		s.warn("", archivePath, fmt.Sprintf("failed to open archive: %v", err))
+		return nil, 0
+	}
+	defer reader.Close()
+	return s.scanZip(archivePath, &reader.Reader, 1, budget)
+}
+
+// scanZip scans the entries of a zip-format archive (jar, war, ear, zip)
+func (s *Scanner) scanZip(displayPath string, reader *zip.Reader, depth int, budget *archiveBudget) ([]Result, int) {
+	var results []Result
+	entryCount := 0
+
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		entryPath := displayPath + "!/" + file.Name
+
+		if !s.isArchiveEntryEligible(file.Name, depth) {
+			continue
+		}
+		if !budget.allow(int64(file.UncompressedSize64)) {
+			if s.Verbose {
+				s.logf("Archive limits reached, skipping remaining entries in %s\n", displayPath)
+			}
+			break
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			if s.Verbose {
+				s.logf("Error reading archive entry %s: %v\n", entryPath, err)
+			}
+			continue
+		}
+		data, err := readBounded(rc, maxArchiveEntrySize)
+		rc.Close()
+		if err != nil {
+			if s.Verbose {
+				s.logf("Skipping archive entry %s: %v\n", entryPath, err)
+			}
+			continue
+		}
+		budget.charge(len(data))
+
+		entryResults, scanned := s.scanArchiveEntry(entryPath, file.Name, data, depth, budget)
+		results = append(results, entryResults...)
+		entryCount += scanned
+	}
+
+	return results, entryCount
+}
+
+// scanTarGz scans the entries of a gzip-compressed tar archive
+func (s *Scanner) scanTarGz(displayPath string, r io.Reader, depth int, budget *archiveBudget) ([]Result, int) {
+	var results []Result
+	entryCount := 0
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		s.logf("Error reading archive %s: %v\n", displayPath, err)
+		s.warn("", displayPath, fmt.Sprintf("failed to read archive: %v", err))
+		return nil, 0
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if s.Verbose {
+				s.logf("Error reading archive %s: %v\n", displayPath, err)
+			}
+			s.warn("", displayPath, fmt.Sprintf("failed to read archive: %v", err))
+			break
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		entryPath := displayPath + "!/" + header.Name
+
+		if !s.isArchiveEntryEligible(header.Name, depth) {
+			continue
+		}
+		if !budget.allow(header.Size) {
+			if s.Verbose {
+				s.logf("Archive limits reached, skipping remaining entries in %s\n", displayPath)
+			}
+			break
+		}
+
+		data, err := readBounded(tr, maxArchiveEntrySize)
+		if err != nil {
+			if s.Verbose {
+				s.logf("Skipping archive entry %s: %v\n", entryPath, err)
+			}
+			continue
+		}
+		budget.charge(len(data))
+
+		entryResults, scanned := s.scanArchiveEntry(entryPath, header.Name, data, depth, budget)
+		results = append(results, entryResults...)
+		entryCount += scanned
+	}
+
+	return results, entryCount
+}
+
+// scanArchiveEntry scans a single archive entry, recursing into nested archives
+func (s *Scanner) scanArchiveEntry(entryPath, name string, data []byte, depth int, budget *archiveBudget) ([]Result, int) {
+	lower := strings.ToLower(name)
+	switch {
+	case isZipArchive(lower):
+		reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, 0
+		}
+		return s.scanZip(entryPath, reader, depth+1, budget)
+	case isTarGzArchive(lower):
+		return s.scanTarGz(entryPath, bytes.NewReader(data), depth+1, budget)
+	default:
+		if s.Verbose {
+			s.logf("Scanning file: %s\n", entryPath)
+		}
+		return s.scanContent(entryPath, data), 1
+	}
+}
+
+// isArchiveEntryEligible reports whether an archive entry should be read at all
+func (s *Scanner) isArchiveEntryEligible(name string, depth int) bool {
+	if IsArchive(name) {
+		return depth < maxArchiveDepth
+	}
+	// Compiled Java classes keep algorithm names as constant-pool strings
+	if strings.ToLower(filepath.Ext(name)) == ".class" {
+		return true
+	}
+	return !s.shouldSkip(name)
+}
+
+// allow reserves an entry against the budget, returning false once archive limits are exhausted
+func (b *archiveBudget) allow(declaredSize int64) bool {
+	if b.entries >= maxArchiveEntries || b.bytes+declaredSize > maxArchiveTotalSize {
+		return false
+	}
+	b.entries++
+	return true
+}
+
+// charge records bytes actually decompressed for an entry
+func (b *archiveBudget) charge(n int) {
+	b.bytes += int64(n)
+}
+
+// readBounded reads at most limit bytes, failing if the entry is larger.
+// Declared sizes can lie, so the limit is enforced on the decompressed stream.
+func readBounded(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("entry exceeds %d byte limit", limit)
+	}
+	return data, nil
+}
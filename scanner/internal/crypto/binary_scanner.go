@@ -0,0 +1,168 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// maxBinarySize bounds how much of a binary is loaded for signature search
+const maxBinarySize = 256 << 20
+
+// binarySignature is a byte sequence that identifies crypto usage inside a compiled binary
+type binarySignature struct {
+	Name            string // Human-readable signature name, e.g. "rsaEncryption OID"
+	Pattern         []byte
+	Algorithm       string
+	Type            string
+	Risk            string
+	Vulnerability   string
+	NISTAlgorithmID string
+}
+
+// derOID returns the DER encoding (tag, length, value) of an OID given its encoded value bytes
+func derOID(value ...byte) []byte {
+	return append([]byte{0x06, byte(len(value))}, value...)
+}
+
+// binarySignatures lists OIDs (DER and dotted form), curve names and library symbols to search for
+var binarySignatures = []binarySignature{
+	// Public key algorithm OIDs
+	{Name: "rsaEncryption OID", Pattern: derOID(0x2A, 0x86, 0x48, 0x86, 0xF7, 0x0D, 0x01, 0x01, 0x01), Algorithm: "RSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "RSA-2048"},
+	{Name: "rsaEncryption OID", Pattern: []byte("1.2.840.113549.1.1.1\x00"), Algorithm: "RSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "RSA-2048"},
+	{Name: "ecPublicKey OID", Pattern: derOID(0x2A, 0x86, 0x48, 0xCE, 0x3D, 0x02, 0x01), Algorithm: "ECDSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDSA-P256"},
+	{Name: "dsa OID", Pattern: derOID(0x2A, 0x86, 0x48, 0xCE, 0x38, 0x04, 0x01), Algorithm: "DSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm"},
+	{Name: "dhpublicnumber OID", Pattern: derOID(0x2A, 0x86, 0x48, 0xCE, 0x3E, 0x02, 0x01), Algorithm: "DH", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "DH-2048"},
+
+	// Signature algorithm OIDs with broken hashes
+	{Name: "md5WithRSAEncryption OID", Pattern: derOID(0x2A, 0x86, 0x48, 0x86, 0xF7, 0x0D, 0x01, 0x01, 0x04), Algorithm: "MD5", Type: "Hash", Risk: "High", Vulnerability: "Grover's Algorithm + Broken"},
+	{Name: "sha1WithRSAEncryption OID", Pattern: derOID(0x2A, 0x86, 0x48, 0x86, 0xF7, 0x0D, 0x01, 0x01, 0x05), Algorithm: "SHA-1", Type: "Hash", Risk: "High", Vulnerability: "Grover's Algorithm + Broken", NISTAlgorithmID: "SHA-1"},
+
+	// Named curve OIDs and names
+	{Name: "prime256v1 OID", Pattern: derOID(0x2A, 0x86, 0x48, 0xCE, 0x3D, 0x03, 0x01, 0x07), Algorithm: "ECDSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDSA-P256"},
+	{Name: "secp384r1 OID", Pattern: derOID(0x2B, 0x81, 0x04, 0x00, 0x22), Algorithm: "ECDSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDSA-P384"},
+	{Name: "secp521r1 OID", Pattern: derOID(0x2B, 0x81, 0x04, 0x00, 0x23), Algorithm: "ECDSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDSA-P521"},
+	{Name: "prime256v1 curve name", Pattern: []byte("prime256v1"), Algorithm: "ECDSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDSA-P256"},
+	{Name: "secp384r1 curve name", Pattern: []byte("secp384r1"), Algorithm: "ECDSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDSA-P384"},
+
+	// Post-quantum OIDs (FIPS 203/204)
+	{Name: "ML-KEM-768 OID", Pattern: derOID(0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x04, 0x02), Algorithm: "ML-KEM", Type: "PostQuantum", Risk: "Low", Vulnerability: "Quantum-Resistant", NISTAlgorithmID: "ML-KEM-768"},
+	{Name: "ML-DSA-65 OID", Pattern: derOID(0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x03, 0x12), Algorithm: "ML-DSA", Type: "PostQuantum", Risk: "Low", Vulnerability: "Quantum-Resistant", NISTAlgorithmID: "ML-DSA-65"},
+
+	// OpenSSL / BoringSSL symbols
+	{Name: "RSA_generate_key_ex symbol", Pattern: []byte("RSA_generate_key_ex"), Algorithm: "RSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "RSA-2048"},
+	{Name: "RSA_public_encrypt symbol", Pattern: []byte("RSA_public_encrypt"), Algorithm: "RSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "RSA-2048"},
+	{Name: "ECDSA_sign symbol", Pattern: []byte("ECDSA_sign"), Algorithm: "ECDSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDSA-P256"},
+	{Name: "DH_generate_key symbol", Pattern: []byte("DH_generate_key"), Algorithm: "DH", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "DH-2048"},
+	{Name: "EVP_PKEY_CTX_new symbol", Pattern: []byte("EVP_PKEY_CTX_new"), Algorithm: "OpenSSL EVP", Type: "Library", Risk: "Medium", Vulnerability: "Shor's Algorithm"},
+
+	// Go standard library packages compiled into Go binaries
+	{Name: "crypto/rsa package", Pattern: []byte("crypto/rsa."), Algorithm: "RSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "RSA-2048"},
+	{Name: "crypto/ecdsa package", Pattern: []byte("crypto/ecdsa."), Algorithm: "ECDSA", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDSA-P256"},
+	{Name: "crypto/des package", Pattern: []byte("crypto/des."), Algorithm: "DES", Type: "SymmetricKey", Risk: "High", Vulnerability: "Grover's Algorithm + Broken"},
+	{Name: "crypto/md5 package", Pattern: []byte("crypto/md5."), Algorithm: "MD5", Type: "Hash", Risk: "High", Vulnerability: "Grover's Algorithm + Broken"},
+}
+
+// IsBinaryFile reports whether a file starts with an ELF, PE or Mach-O magic number
+func IsBinaryFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return isBinaryHeader(header)
+}
+
+// isBinaryHeader checks magic bytes for executable formats
+func isBinaryHeader(header []byte) bool {
+	if len(header) < 8 {
+		return false
+	}
+	switch {
+	case bytes.HasPrefix(header, []byte{0x7F, 'E', 'L', 'F'}):
+		return true
+	case bytes.HasPrefix(header, []byte{'M', 'Z'}):
+		return true
+	case bytes.HasPrefix(header, []byte{0xFE, 0xED, 0xFA, 0xCE}),
+		bytes.HasPrefix(header, []byte{0xFE, 0xED, 0xFA, 0xCF}),
+		bytes.HasPrefix(header, []byte{0xCE, 0xFA, 0xED, 0xFE}),
+		bytes.HasPrefix(header, []byte{0xCF, 0xFA, 0xED, 0xFE}):
+		return true
+	case bytes.HasPrefix(header, []byte{0xCA, 0xFE, 0xBA, 0xBE}):
+		// Fat Mach-O shares its magic with Java class files; class files
+		// carry a major version >= 45 where fat binaries carry an arch count
+		return binary.BigEndian.Uint32(header[4:8]) < 45
+	}
+	return false
+}
+
+// ScanBinary searches a compiled binary for crypto OIDs, curve names and library symbols.
+// Each signature is reported at most once per binary.
+func (s *Scanner) ScanBinary(filePath string) []Result {
+	var results []Result
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		s.logf("Error reading file %s: %v\n", filePath, err)
+		s.warn("", filePath, fmt.Sprintf("failed to read file: %v", err))
+		return results
+	}
+	if info.Size() > maxBinarySize {
+		if s.Verbose {
+			s.logf("Skipping binary larger than %d bytes: %s\n", maxBinarySize, filePath)
+		}
+		return results
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		s.logf("Error reading file %s: %v\n", filePath, err)
+		s.warn("", filePath, fmt.Sprintf("failed to read file: %v", err))
+		return results
+	}
+
+	if s.Verbose {
+		s.logf("Scanning binary: %s\n", filePath)
+	}
+
+	return s.scanBinaryContent(filePath, content)
+}
+
+// scanBinaryContent searches binary content already in memory, reporting findings against displayPath
+func (s *Scanner) scanBinaryContent(displayPath string, content []byte) []Result {
+	var results []Result
+
+	reported := make(map[string]bool)
+	for _, sig := range binarySignatures {
+		offset := bytes.Index(content, sig.Pattern)
+		if offset < 0 || reported[sig.Name] {
+			continue
+		}
+		reported[sig.Name] = true
+
+		result := Result{
+			File:              displayPath,
+			Algorithm:         sig.Algorithm,
+			Type:              sig.Type,
+			Line:              1,
+			Method:            "Binary Analysis",
+			Risk:              sig.Risk,
+			VulnerabilityType: sig.Vulnerability,
+			Description:       fmt.Sprintf("Binary contains %s at offset 0x%x, indicating %s usage", sig.Name, offset, sig.Algorithm),
+			Recommendation:    "Identify the dependency providing this algorithm and upgrade to a version supporting post-quantum algorithms",
+		}
+		populateNISTFields(&result, sig.NISTAlgorithmID)
+		results = append(results, result)
+	}
+
+	classifyResults(results)
+
+	return results
+}
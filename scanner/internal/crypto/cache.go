@@ -0,0 +1,159 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// scanCacheFormat is bumped whenever the layout of cache entries, or how findings
+// are post-processed, changes
+const scanCacheFormat = 22
+
+// ScanCache stores the findings of each scanned file keyed by the SHA-256 of its
+// content, so re-scans of an unchanged tree skip the files already scanned.
+// Entries written under a different rule set or scanner configuration are ignored
+// and overwritten. A ScanCache is safe for concurrent use.
+type ScanCache struct {
+	Dir     string
+	Version string // Rule set and option fingerprint entries must match, see ScanCacheVersion
+
+	hits   int64
+	misses int64
+}
+
+// scanCacheEntry is the on-disk form of one cached file
+type scanCacheEntry struct {
+	Version string   `json:"version"`
+	Path    string   `json:"path"` // Path the findings were reported against
+	Assets  int      `json:"assets"`
+	Results []Result `json:"results"`
+	Keys    []string `json:"keys"` // Fingerprint key of each result, to recompute fingerprints at another path
+}
+
+// NewScanCache opens the cache in dir, creating the directory if needed, for scans
+// made by s with its current rules and options
+func NewScanCache(dir string, s *Scanner) (*ScanCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &ScanCache{Dir: dir, Version: ScanCacheVersion(s)}, nil
+}
+
+// ScanCacheVersion fingerprints the rule set and the options of s that change which
+// findings a file produces. Only whether a keystore password is set goes in, so
+// the password never reaches the cache; keystores opened with one aren't cached.
+func ScanCacheVersion(s *Scanner) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "format=%d archives=%t binaries=%t config=%t scripts=%t min-confidence=%g context-lines=%d remediation=%t fixes=%t keystore-password=%t\n", scanCacheFormat, s.ScanArchives, s.ScanBinaries, s.ScanConfig, s.ScanScripts, s.MinConfidence, s.ContextLines, s.ShowRemediation, s.SuggestFixes, s.KeystorePassword != "")
+	json.NewEncoder(h).Encode(s.Rules)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RuleSetDigest returns the SHA-256 of a detection rule set, so reports can tell
+// whether two scans matched with the same rules
+func RuleSetDigest(rules []DetectionRule) string {
+	h := sha256.New()
+	json.NewEncoder(h).Encode(rules)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Hits returns the number of files whose findings were served from the cache
+func (c *ScanCache) Hits() int {
+	return int(atomic.LoadInt64(&c.hits))
+}
+
+// Misses returns the number of files that had to be scanned
+func (c *ScanCache) Misses() int {
+	return int(atomic.LoadInt64(&c.misses))
+}
+
+// scan returns the cached findings for the file at path, or calls scanFile and
+// caches what it returns unless it failed. Cache failures fall back to scanning.
+func (c *ScanCache) scan(s *Scanner, path string, scanFile func() ([]Result, int, error)) ([]Result, int) {
+	sum, err := fileSHA256(path)
+	if err != nil {
+		atomic.AddInt64(&c.misses, 1)
+		results, assets, _ := scanFile()
+		return results, assets
+	}
+	entryPath := filepath.Join(c.Dir, sum[:2], sum+".json")
+
+	if data, err := os.ReadFile(entryPath); err == nil {
+		var entry scanCacheEntry
+		if json.Unmarshal(data, &entry) == nil && entry.Version == c.Version {
+			atomic.AddInt64(&c.hits, 1)
+			// Identical content may live at another path; report against this one
+			for i := range entry.Results {
+				if i < len(entry.Keys) {
+					entry.Results[i].fingerprintKey = entry.Keys[i]
+				}
+				if strings.HasPrefix(entry.Results[i].File, entry.Path) {
+					entry.Results[i].File = path + strings.TrimPrefix(entry.Results[i].File, entry.Path)
+					refingerprint(&entry.Results[i], s.Roots)
+				}
+			}
+			return entry.Results, entry.Assets
+		}
+	}
+
+	atomic.AddInt64(&c.misses, 1)
+	results, assets, err := scanFile()
+	if err != nil {
+		return results, assets
+	}
+	keys := make([]string, len(results))
+	for i, result := range results {
+		keys[i] = result.fingerprintKey
+	}
+	if err := c.store(entryPath, scanCacheEntry{Version: c.Version, Path: path, Assets: assets, Results: results, Keys: keys}); err != nil {
+		s.logf("Warning: failed to cache findings for %s: %v\n", path, err)
+	}
+	return results, assets
+}
+
+// store writes entry to entryPath atomically, so concurrent scans never read a partial entry
+func (c *ScanCache) store(entryPath string, entry scanCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(entryPath), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(entryPath), ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), entryPath)
+}
+
+// fileSHA256 returns the hex SHA-256 of a file's content
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
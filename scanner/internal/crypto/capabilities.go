@@ -0,0 +1,59 @@
+package crypto
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// Features a scan may need from the build or the environment
+const (
+	CapabilityPCAPFile    = "pcap-file"    // Analyzing capture files (-pcap-file)
+	CapabilityLiveCapture = "live-capture" // Capturing traffic from an interface (-live-capture, network mode)
+	CapabilityKubernetes  = "kubernetes"   // Reaching the Kubernetes API (k8s and cluster-scan modes)
+)
+
+// kubernetesProbeTimeout bounds the API server request of CheckCapabilities
+const kubernetesProbeTimeout = 5 * time.Second
+
+// Capability reports whether a feature works in this build and environment, and
+// why not when it doesn't
+type Capability struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// CheckCapabilities reports which features are available. Kubernetes access is
+// checked by asking the API server of kubeconfig and kubeContext for its version.
+func CheckCapabilities(kubeconfig, kubeContext string) []Capability {
+	return []Capability{
+		pcapFileCapability(),
+		liveCaptureCapability(),
+		kubernetesCapability(kubeconfig, kubeContext),
+	}
+}
+
+func kubernetesCapability(kubeconfig, kubeContext string) Capability {
+	capability := Capability{Name: CapabilityKubernetes}
+	config, err := BuildKubeConfig(kubeconfig, kubeContext)
+	if err != nil {
+		capability.Detail = fmt.Sprintf("no usable kubeconfig: %v", err)
+		return capability
+	}
+	config.Timeout = kubernetesProbeTimeout
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		capability.Detail = fmt.Sprintf("failed to create Kubernetes client: %v", err)
+		return capability
+	}
+	info, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		capability.Detail = fmt.Sprintf("API server %s is unreachable: %v", config.Host, err)
+		return capability
+	}
+	capability.Available = true
+	capability.Detail = fmt.Sprintf("API server %s, Kubernetes %s", config.Host, info.GitVersion)
+	return capability
+}
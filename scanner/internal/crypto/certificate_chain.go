@@ -0,0 +1,195 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// Certificate chain positions, from the trust anchor down to the end-entity certificate
+const (
+	ChainPositionRoot         = "root"
+	ChainPositionIntermediate = "intermediate"
+	ChainPositionLeaf         = "leaf"
+)
+
+// TLS record and handshake message types used when extracting certificates
+const (
+	tlsRecordHandshake      = 0x16
+	tlsHandshakeCertificate = 0x0b
+)
+
+// ParseTLSCertificates extracts the certificate list from TLS handshake records.
+// Only plaintext (TLS 1.2 and earlier) Certificate messages can be read; the
+// returned chain is in wire order, leaf first.
+func ParseTLSCertificates(payload []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+
+	for len(payload) >= 5 {
+		recordType := payload[0]
+		recordLen := int(payload[3])<<8 | int(payload[4])
+		if len(payload) < 5+recordLen {
+			recordLen = len(payload) - 5
+		}
+		record := payload[5 : 5+recordLen]
+		payload = payload[5+recordLen:]
+		if recordType != tlsRecordHandshake {
+			continue
+		}
+
+		for len(record) >= 4 {
+			msgType := record[0]
+			msgLen := readUint24(record[1:4])
+			if len(record) < 4+msgLen {
+				break
+			}
+			body := record[4 : 4+msgLen]
+			record = record[4+msgLen:]
+			if msgType == tlsHandshakeCertificate {
+				certs = append(certs, parseCertificateList(body)...)
+			}
+		}
+	}
+
+	return certs
+}
+
+// parseCertificateList parses the body of a TLS Certificate handshake message
+func parseCertificateList(body []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+	if len(body) < 3 {
+		return certs
+	}
+	list := body[3:]
+	if listLen := readUint24(body); listLen < len(list) {
+		list = list[:listLen]
+	}
+
+	for len(list) >= 3 {
+		certLen := readUint24(list)
+		if len(list) < 3+certLen {
+			break
+		}
+		if cert, err := x509.ParseCertificate(list[3 : 3+certLen]); err == nil {
+			certs = append(certs, cert)
+		}
+		list = list[3+certLen:]
+	}
+	return certs
+}
+
+// readUint24 decodes a big-endian 24-bit length
+func readUint24(b []byte) int {
+	return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+}
+
+// chainPosition classifies a certificate by its place in a leaf-first chain
+func chainPosition(cert *x509.Certificate, index int) string {
+	if bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil {
+		return ChainPositionRoot
+	}
+	if index == 0 {
+		return ChainPositionLeaf
+	}
+	return ChainPositionIntermediate
+}
+
+// AnalyzeTLSCertificates reports the public key algorithm of each certificate
+// found in TLS handshake records, tagged with its chain position. Results are in
+// wire order, leaf first, so each certificate is followed by its issuer.
+func AnalyzeTLSCertificates(payload []byte, source string) []Result {
+	var results []Result
+
+	for i, cert := range ParseTLSCertificates(payload) {
+		algorithm, nistID := certificateAlgorithm(cert)
+		if algorithm == "" {
+			continue
+		}
+		position := chainPosition(cert, i)
+
+		result := Result{
+			File:              source,
+			Algorithm:         algorithm,
+			Type:              "PublicKey",
+			Line:              1,
+			Method:            "Certificate Chain Analysis",
+			Risk:              "High",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       fmt.Sprintf("%s certificate %q uses %s, which is vulnerable to quantum attacks", position, certificateName(cert), algorithm),
+			Recommendation:    "Replace certificates with post-quantum alternatives when available, starting at the root of the chain",
+			ChainPosition:     position,
+			Subject:           certificateName(cert),
+		}
+		populateNISTFields(&result, nistID)
+		results = append(results, result)
+	}
+
+	classifyResults(results)
+	return results
+}
+
+// analyzePEMCertificates reports the public key algorithm of each CERTIFICATE block in
+// PEM content, reported against file. A bundle is treated as a chain, leaf first.
+// The remaining non-certificate PEM blocks (keys, parameters) are returned re-encoded.
+func analyzePEMCertificates(file, content string) ([]Result, string) {
+	var results []Result
+	var rest []byte
+
+	data := []byte(content)
+	index := 0
+	for {
+		block, remaining := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		data = remaining
+
+		if block.Type != "CERTIFICATE" {
+			rest = append(rest, pem.EncodeToMemory(block)...)
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		position := chainPosition(cert, index)
+		index++
+
+		// Post-quantum and other unrecognized keys are not reported
+		algorithm, nistID := certificateAlgorithm(cert)
+		if algorithm == "" {
+			continue
+		}
+		result := Result{
+			File:              file,
+			Algorithm:         algorithm,
+			Type:              "PublicKey",
+			Line:              1,
+			Method:            "TLS Certificate Analysis",
+			Risk:              "High",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       fmt.Sprintf("TLS certificate %q uses %s, which is vulnerable to quantum attacks", certificateName(cert), algorithm),
+			Recommendation:    "Replace with post-quantum certificate when available from CA",
+			ChainPosition:     position,
+			Subject:           certificateName(cert),
+		}
+		populateNISTFields(&result, nistID)
+		results = append(results, result)
+	}
+
+	return results, string(rest)
+}
+
+// certificateName identifies a certificate by common name, falling back to the full subject
+func certificateName(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
+
+// certificateAlgorithm names a certificate's public key algorithm and its NIST IR 8547 ID
+func certificateAlgorithm(cert *x509.Certificate) (string, string) {
+	return publicKeyAlgorithm(cert.PublicKey)
+}
@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// certificateExtensions are the certificate file extensions parsed by ScanCertificateFile
+var certificateExtensions = map[string]bool{".pem": true, ".crt": true, ".cer": true, ".der": true}
+
+// weakSignatureAlgorithms are certificate signature algorithms that are already broken
+var weakSignatureAlgorithms = map[x509.SignatureAlgorithm]bool{
+	x509.MD2WithRSA:    true,
+	x509.MD5WithRSA:    true,
+	x509.SHA1WithRSA:   true,
+	x509.DSAWithSHA1:   true,
+	x509.ECDSAWithSHA1: true,
+}
+
+// IsCertificateFile reports whether path is a PEM or DER certificate file
+func IsCertificateFile(path string) bool {
+	return certificateExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// ScanCertificateFile parses the certificates in a PEM or DER file with crypto/x509
+// and reports each one: its public key algorithm and size, signature algorithm and
+// expiry. A file holding a chain yields one finding per certificate, leaf first.
+func (s *Scanner) ScanCertificateFile(path string) []Result {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		s.logf("Error reading file %s: %v\n", path, err)
+		s.warn("", path, fmt.Sprintf("failed to read file: %v", err))
+		return nil
+	}
+
+	var results []Result
+	for i, cert := range parseCertificateFile(content) {
+		if result, ok := certificateFileResult(path, cert.cert, cert.line, i); ok {
+			results = append(results, result)
+		}
+	}
+	// Files such as dhparam.pem hold Diffie-Hellman parameters rather than certificates
+	results = append(results, analyzeDHParameters(path, string(content))...)
+	classifyResults(results)
+	return results
+}
+
+// fileCertificate is a certificate parsed from a file and the line it starts on
+type fileCertificate struct {
+	cert *x509.Certificate
+	line int
+}
+
+// parseCertificateFile returns the certificates in PEM content, or in DER content
+// when there is no PEM block. Blocks that fail to parse are skipped.
+func parseCertificateFile(content []byte) []fileCertificate {
+	var certs []fileCertificate
+	if !bytes.Contains(content, []byte("-----BEGIN")) {
+		parsed, err := x509.ParseCertificates(content)
+		if err != nil {
+			return nil
+		}
+		for _, cert := range parsed {
+			certs = append(certs, fileCertificate{cert: cert, line: 1})
+		}
+		return certs
+	}
+
+	rest := content
+	for {
+		block, remaining := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		// The block starts where the bytes consumed so far end
+		consumed := len(content) - len(rest)
+		start := consumed + bytes.Index(rest, []byte("-----BEGIN"))
+		rest = remaining
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, fileCertificate{cert: cert, line: bytes.Count(content[:start], []byte("\n")) + 1})
+		}
+	}
+	return certs
+}
+
+// certificateFileResult builds the finding for the index-th certificate of a file.
+// Certificates with keys the scanner can't classify, such as post-quantum keys, are
+// not reported.
+func certificateFileResult(path string, cert *x509.Certificate, line, index int) (Result, bool) {
+	algorithm, nistID := certificateAlgorithm(cert)
+	if algorithm == "" {
+		return Result{}, false
+	}
+	position := chainPosition(cert, index)
+	notAfter := cert.NotAfter.UTC()
+	result := Result{
+		File:               path,
+		Algorithm:          algorithm,
+		Type:               TypePublicKey,
+		Line:               line,
+		Method:             "Certificate File Analysis",
+		Risk:               "High",
+		VulnerabilityType:  "Shor's Algorithm",
+		KeySize:            publicKeySize(cert.PublicKey),
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		NotAfter:           &notAfter,
+		ChainPosition:      position,
+		Subject:            certificateName(cert),
+		Recommendation:     "Replace with a post-quantum certificate when available from the CA, starting at the root of the chain",
+	}
+
+	keyDescription := algorithm
+	if result.KeySize > 0 {
+		keyDescription = fmt.Sprintf("%s-%d", algorithm, result.KeySize)
+	}
+	result.Description = fmt.Sprintf("%s certificate %q uses a %s key signed with %s, which is vulnerable to quantum attacks; expires %s",
+		position, certificateName(cert), keyDescription, result.SignatureAlgorithm, notAfter.Format("2006-01-02"))
+	if weakSignatureAlgorithms[cert.SignatureAlgorithm] {
+		result.Risk = "Critical"
+		result.Description += fmt.Sprintf(" (%s signatures are already forgeable)", result.SignatureAlgorithm)
+	}
+	if notAfter.Before(time.Now()) {
+		result.Description += " (expired)"
+	}
+	populateNISTFields(&result, nistID)
+	return result, true
+}
@@ -0,0 +1,194 @@
+package crypto
+
+import (
+	"crypto/tls"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cipherSuiteMethod is the Method of findings from hardcoded cipher suite lists
+const cipherSuiteMethod = "Cipher Suite Analysis"
+
+// cipherSuiteListMaxLines bounds how far a cipher suite list is followed past its start
+const cipherSuiteListMaxLines = 40
+
+var (
+	// goCipherSuitesPattern starts a Go tls.Config cipher suite list
+	goCipherSuitesPattern = regexp.MustCompile(`\bCipherSuites:\s*\[\]uint16\{`)
+
+	// goCipherSuitePattern captures the crypto/tls constants and numeric IDs of a Go list
+	goCipherSuitePattern = regexp.MustCompile(`\btls\.(\w+)|\b(0[xX][0-9a-fA-F]{1,4})\b`)
+
+	// javaCipherSuitesPattern starts a Java SSLSocket, SSLEngine or SSLParameters cipher suite array
+	javaCipherSuitesPattern = regexp.MustCompile(`\.set(?:Enabled)?CipherSuites\(\s*new\s+String\[\]\s*\{`)
+
+	// javaCipherSuitePattern captures the suite names of a Java list
+	javaCipherSuitePattern = regexp.MustCompile(`"((?:TLS|SSL)_\w+)"`)
+)
+
+// goCipherSuiteNames maps the crypto/tls cipher suite constants to their IANA names.
+// Go's shorter ChaCha20 names are aliases of the _SHA256 suites.
+var goCipherSuiteNames = func() map[string]string {
+	names := make(map[string]string)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		names[suite.Name] = suite.Name
+	}
+	names["TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305"] = "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"
+	names["TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305"] = "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"
+	return names
+}()
+
+// cipherSuiteEntry is a suite of a hardcoded list and the line it is on
+type cipherSuiteEntry struct {
+	Constant string // As written, e.g. tls.TLS_RSA_WITH_AES_128_CBC_SHA
+	Name     string // IANA name
+	Line     int
+}
+
+// cipherSuiteWeakness is one reason a cipher suite is weak
+type cipherSuiteWeakness struct {
+	Algorithm     string
+	Type          string
+	Risk          string
+	Vulnerability string
+	Reason        string
+}
+
+// detectCipherSuiteAllowlists flags the weak suites of cipher suite lists hardcoded
+// in Go tls.Config CipherSuites and Java setEnabledCipherSuites calls: export,
+// NULL, anonymous, RC4, DES and 3DES suites, RSA key exchange and CBC mode. Each
+// weak suite is reported on its own line, naming the constant.
+func (s *Scanner) detectCipherSuiteAllowlists(filePath string, lines []string) []Result {
+	var results []Result
+	for i, line := range lines {
+		if loc := goCipherSuitesPattern.FindStringIndex(line); loc != nil {
+			for _, entry := range cipherSuiteList(lines, i, loc[1], goCipherSuitePattern, goCipherSuiteName) {
+				results = append(results, cipherSuiteResults(filePath, "tls.Config CipherSuites", entry)...)
+			}
+		}
+		if loc := javaCipherSuitesPattern.FindStringIndex(line); loc != nil {
+			for _, entry := range cipherSuiteList(lines, i, loc[1], javaCipherSuitePattern, javaCipherSuiteName) {
+				results = append(results, cipherSuiteResults(filePath, "setEnabledCipherSuites", entry)...)
+			}
+		}
+	}
+	return results
+}
+
+// cipherSuiteList reads the entries of a brace-delimited list whose elements start
+// at offset start of lines[index], resolving each match of pattern with resolve.
+// Entries that don't resolve to a cipher suite are skipped.
+func cipherSuiteList(lines []string, index, start int, pattern *regexp.Regexp, resolve func(match []string) (string, string, bool)) []cipherSuiteEntry {
+	var entries []cipherSuiteEntry
+	rest := lines[index][start:]
+	for n := 0; n < cipherSuiteListMaxLines && index+n < len(lines); n++ {
+		if n > 0 {
+			rest = lines[index+n]
+		}
+		end := strings.Index(rest, "}")
+		if end >= 0 {
+			rest = rest[:end]
+		}
+		if comment := strings.Index(rest, "//"); comment >= 0 {
+			rest = rest[:comment]
+		}
+		for _, match := range pattern.FindAllStringSubmatch(rest, -1) {
+			if constant, name, ok := resolve(match); ok {
+				entries = append(entries, cipherSuiteEntry{Constant: constant, Name: name, Line: index + n + 1})
+			}
+		}
+		if end >= 0 {
+			break
+		}
+	}
+	return entries
+}
+
+// goCipherSuiteName resolves a crypto/tls constant or numeric suite ID
+func goCipherSuiteName(match []string) (string, string, bool) {
+	if match[1] != "" {
+		name, ok := goCipherSuiteNames[match[1]]
+		return match[0], name, ok
+	}
+	id, err := strconv.ParseUint(match[2], 0, 16)
+	if err != nil {
+		return "", "", false
+	}
+	name := tls.CipherSuiteName(uint16(id))
+	return match[0], name, !strings.HasPrefix(name, "0x")
+}
+
+// javaCipherSuiteName resolves a JSSE suite name; JSSE keeps the SSL_ prefix of
+// suites defined before TLS 1.0
+func javaCipherSuiteName(match []string) (string, string, bool) {
+	return match[1], "TLS_" + strings.TrimPrefix(strings.TrimPrefix(match[1], "SSL_"), "TLS_"), true
+}
+
+// cipherSuiteWeaknesses returns why the suite with IANA name is weak, most severe first
+func cipherSuiteWeaknesses(name string) []cipherSuiteWeakness {
+	var weaknesses []cipherSuiteWeakness
+	add := func(algorithm, algType, risk, vulnerability, reason string) {
+		weaknesses = append(weaknesses, cipherSuiteWeakness{algorithm, algType, risk, vulnerability, reason})
+	}
+	switch {
+	case strings.Contains(name, "_EXPORT"):
+		add("Export Cipher", TypeSymmetricKey, "Critical", "Classical Weakness", "export-grade cipher with a 40- or 56-bit key")
+	case strings.Contains(name, "_WITH_NULL_"):
+		add("NULL Cipher", TypeSymmetricKey, "Critical", "Classical Weakness", "NULL cipher, so traffic is not encrypted")
+	}
+	if strings.Contains(name, "_anon_") {
+		add("Anonymous Key Exchange", TypeProtocol, "Critical", "Classical Weakness", "anonymous key exchange without server authentication")
+	}
+	switch {
+	case strings.Contains(name, "_RC4_"):
+		add("RC4", TypeSymmetricKey, "High", "Classical Weakness", "RC4 stream cipher with known keystream biases")
+	case strings.Contains(name, "_3DES_"):
+		add("3DES", TypeSymmetricKey, "High", "Classical Weakness", "3DES with a 64-bit block, open to Sweet32 birthday attacks")
+	case strings.Contains(name, "_DES_") || strings.Contains(name, "_DES40_"):
+		add("DES", TypeSymmetricKey, "Critical", "Classical Weakness", "single DES with a 56-bit key")
+	}
+	if strings.HasPrefix(name, "TLS_RSA_") {
+		add("RSA", TypePublicKey, "High", "Shor's Algorithm", "RSA key exchange without forward secrecy, vulnerable to quantum attacks")
+	}
+	if strings.Contains(name, "_CBC_") && !strings.Contains(name, "DES") {
+		add("CBC", TypeSymmetricKey, "Medium", "Classical Weakness", "CBC mode with MAC-then-encrypt, open to padding-oracle attacks such as Lucky13")
+	}
+
+	// Most severe first; the first weakness of equal risk names the finding
+	sort.SliceStable(weaknesses, func(i, j int) bool {
+		return agilityRiskWeights[weaknesses[i].Risk] > agilityRiskWeights[weaknesses[j].Risk]
+	})
+	return weaknesses
+}
+
+// cipherSuiteResults reports entry if its suite is weak, as its most severe weakness
+func cipherSuiteResults(filePath, list string, entry cipherSuiteEntry) []Result {
+	weaknesses := cipherSuiteWeaknesses(entry.Name)
+	if len(weaknesses) == 0 {
+		return nil
+	}
+	reasons := make([]string, len(weaknesses))
+	for i, weakness := range weaknesses {
+		reasons[i] = weakness.Reason
+	}
+	primary := weaknesses[0]
+	result := Result{
+		File:              filePath,
+		Algorithm:         primary.Algorithm,
+		Type:              primary.Type,
+		Line:              entry.Line,
+		Method:            cipherSuiteMethod,
+		Risk:              primary.Risk,
+		VulnerabilityType: primary.Vulnerability,
+		CWE:               []string{CWEBrokenCrypto},
+		Description:       fmt.Sprintf("%s allows %s: %s", list, entry.Constant, strings.Join(reasons, "; ")),
+		Recommendation:    "Remove the suite; allow only ECDHE suites with AES-GCM or ChaCha20-Poly1305, or rely on TLS 1.3, whose suites can't be weakened",
+	}
+	if primary.Algorithm == "RSA" {
+		populateNISTFields(&result, "RSA-2048")
+	}
+	return []Result{result}
+}
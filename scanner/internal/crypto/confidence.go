@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// methodConfidence is the default confidence of a rule match by detection method
+var methodConfidence = map[string]float64{
+	"Import Statement":      0.9,
+	"Function Name":         0.85,
+	"Cipher Initialization": 0.85,
+	"Cipher Mode":           0.8,
+	"Configuration":         0.7,
+}
+
+// defaultConfidence applies to methods without an entry in methodConfidence
+const defaultConfidence = 0.6
+
+// commentConfidence caps matches on comment lines, which mention algorithms far
+// more often than they use them
+const commentConfidence = 0.4
+
+// defaultCommentPrefixes start a comment line in files of types missing from
+// commentPrefixes. "*" (inside a block comment), "--" and ";" start code in most
+// languages, so they are only listed for those that use them.
+var defaultCommentPrefixes = []string{"//", "#", "/*", "<!--"}
+
+var (
+	cStyleComments    = []string{"//", "/*", "*"}
+	hashComments      = []string{"#"}
+	sqlComments       = []string{"--", "/*", "*"}
+	semicolonComments = []string{";"}
+)
+
+// commentPrefixes start a comment line in the languages the rules cover, by file
+// extension
+var commentPrefixes = map[string][]string{
+	".go": {"//", "/*"}, ".java": cStyleComments, ".js": cStyleComments, ".ts": cStyleComments,
+	".c": cStyleComments, ".cpp": cStyleComments, ".h": cStyleComments, ".cs": cStyleComments,
+	".swift": cStyleComments, ".rs": cStyleComments, ".kt": cStyleComments, ".kts": cStyleComments,
+	".php": {"//", "#", "/*", "*"}, ".tf": {"#", "//", "/*", "*"}, ".hcl": {"#", "//", "/*", "*"},
+	".py": hashComments, ".rb": hashComments, ".sh": hashComments, ".bash": hashComments,
+	".yaml": hashComments, ".yml": hashComments, ".toml": hashComments, ".conf": hashComments,
+	".properties": {"#", "!"}, ".ini": {";", "#"}, ".cnf": {";", "#"},
+	".sql": sqlComments, ".lua": {"--"},
+	".asm": semicolonComments, ".s": semicolonComments, ".clj": semicolonComments, ".el": semicolonComments,
+	".xml": {"<!--"}, ".html": {"<!--"},
+}
+
+// ruleConfidence scores a rule match on a line of path: the rule's own confidence,
+// or the default for its method, capped for comment lines
+func ruleConfidence(rule DetectionRule, path, line string) float64 {
+	confidence := rule.Confidence
+	if confidence == 0 {
+		var ok bool
+		if confidence, ok = methodConfidence[rule.Method]; !ok {
+			confidence = defaultConfidence
+		}
+	}
+
+	if isCommentLine(path, line) && confidence > commentConfidence {
+		confidence = commentConfidence
+	}
+	return confidence
+}
+
+// preprocessorPrefixes start C/C++ and Objective-C directives, which aren't comments despite the "#"
+var preprocessorPrefixes = []string{"#include", "#import", "#define", "#if"}
+
+// isCommentLine reports whether a line of path holds only a comment
+func isCommentLine(path, line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range preprocessorPrefixes {
+		if strings.HasPrefix(trimmed, prefix) {
+			return false
+		}
+	}
+	prefixes, ok := commentPrefixes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		prefixes = defaultCommentPrefixes
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,620 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// configExtensions are the structured configuration formats read by ScanConfigFile
+var configExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true, ".toml": true}
+
+// IsConfigFile reports whether path is a YAML, JSON or TOML configuration file
+func IsConfigFile(path string) bool {
+	return configExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// configKeyKind says what a crypto-bearing configuration key holds
+type configKeyKind int
+
+const (
+	configAlgorithm  configKeyKind = iota // An algorithm name, e.g. a JWT alg or cipher
+	configCipherList                      // An OpenSSL or IANA cipher suite list
+	configKeySize                         // A key size in bits
+)
+
+// configKeys maps crypto-bearing keys, lower-cased with '_' and '-' removed, to what
+// they hold. TLS server directives such as ssl_protocols are read as in tlsDirectives.
+var configKeys = map[string]configKeyKind{
+	"alg":                 configAlgorithm,
+	"algorithm":           configAlgorithm,
+	"algorithms":          configAlgorithm,
+	"jwtalgorithm":        configAlgorithm,
+	"signingalgorithm":    configAlgorithm,
+	"signaturealgorithm":  configAlgorithm,
+	"keyalgorithm":        configAlgorithm,
+	"encryptionalgorithm": configAlgorithm,
+	"hashalgorithm":       configAlgorithm,
+	"digest":              configAlgorithm,
+	"cipher":              configAlgorithm,
+	"ciphers":             configCipherList,
+	"ciphersuites":        configCipherList,
+	"cipherlist":          configCipherList,
+	"tlsciphers":          configCipherList,
+	"keysize":             configKeySize,
+	"keylength":           configKeySize,
+	"keybits":             configKeySize,
+	"bits":                configKeySize,
+	"rsabits":             configKeySize,
+	"rsakeysize":          configKeySize,
+	"dhparamsize":         configKeySize,
+	"dhparambits":         configKeySize,
+	"dhkeysize":           configKeySize,
+	"dhbits":              configKeySize,
+	"defaultdhparam":      configKeySize,
+}
+
+// configAlgorithmInfo classifies an algorithm name found as a configuration value
+type configAlgorithmInfo struct {
+	Algorithm       string
+	Type            string
+	Purpose         string
+	Risk            string
+	Vulnerability   string
+	NISTAlgorithmID string
+	Description     string
+	Recommendation  string
+	CWE             []string // Set when the vulnerability type's default doesn't fit
+}
+
+// configAlgorithmValues maps algorithm names as written in configuration, lower-cased,
+// to their classification. JWS and JWE names (RS256, ECDH-ES, A128GCM) are included.
+var configAlgorithmValues = func() map[string]configAlgorithmInfo {
+	rsaSignature := configAlgorithmInfo{Algorithm: "RSA", Type: TypePublicKey, Purpose: PurposeSigning, Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "RSA-2048",
+		Description: "RSA signature vulnerable to quantum attacks", Recommendation: "Plan migration to ML-DSA signatures; keep key rotation short until then"}
+	ecdsaSignature := configAlgorithmInfo{Algorithm: "ECDSA", Type: TypePublicKey, Purpose: PurposeSigning, Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDSA-P256",
+		Description: "ECDSA signature vulnerable to quantum attacks", Recommendation: "Plan migration to ML-DSA signatures"}
+	eddsaSignature := configAlgorithmInfo{Algorithm: "EdDSA", Type: TypePublicKey, Purpose: PurposeSigning, Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "Ed25519",
+		Description: "EdDSA signature vulnerable to quantum attacks", Recommendation: "Plan migration to ML-DSA signatures"}
+	rsaEncryption := configAlgorithmInfo{Algorithm: "RSA", Type: TypePublicKey, Purpose: PurposeKeyEncapsulation, Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "RSA-2048",
+		Description: "RSA key transport vulnerable to quantum attacks", Recommendation: "Plan migration to ML-KEM key encapsulation"}
+	ecdhAgreement := configAlgorithmInfo{Algorithm: "ECDH", Type: TypePublicKey, Purpose: PurposeKeyEncapsulation, Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDH-P256",
+		Description: "ECDH key agreement vulnerable to quantum attacks", Recommendation: "Plan migration to ML-KEM or a hybrid such as X25519MLKEM768"}
+	dhAgreement := configAlgorithmInfo{Algorithm: "DH", Type: TypePublicKey, Purpose: PurposeKeyEncapsulation, Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "DH-2048",
+		Description: "Diffie-Hellman key agreement vulnerable to quantum attacks", Recommendation: "Plan migration to ML-KEM or a hybrid such as X25519MLKEM768"}
+	dsaSignature := configAlgorithmInfo{Algorithm: "DSA", Type: TypePublicKey, Purpose: PurposeSigning, Risk: "High", Vulnerability: "Shor's Algorithm",
+		Description: "DSA signature vulnerable to quantum attacks", Recommendation: "Plan migration to ML-DSA signatures"}
+	aes128 := configAlgorithmInfo{Algorithm: "AES-128", Type: TypeSymmetricKey, Purpose: PurposeEncrypt, Risk: "Medium", Vulnerability: "Grover's Algorithm", NISTAlgorithmID: "AES-128",
+		Description: "AES-128 provides reduced quantum security", Recommendation: "Use AES-256"}
+	des := configAlgorithmInfo{Algorithm: "DES", Type: TypeSymmetricKey, Purpose: PurposeEncrypt, Risk: "High", Vulnerability: "Grover's Algorithm + Broken",
+		Description: "DES is broken", Recommendation: "Use AES-256-GCM"}
+	tripleDES := configAlgorithmInfo{Algorithm: "3DES", Type: TypeSymmetricKey, Purpose: PurposeEncrypt, Risk: "High", Vulnerability: "Classical Weakness",
+		Description: "3DES is deprecated", Recommendation: "Use AES-256-GCM"}
+	rc4 := configAlgorithmInfo{Algorithm: "RC4", Type: TypeSymmetricKey, Purpose: PurposeEncrypt, Risk: "High", Vulnerability: "Classical Weakness",
+		Description: "RC4 is broken", Recommendation: "Use AES-256-GCM or ChaCha20-Poly1305"}
+	md5 := configAlgorithmInfo{Algorithm: "MD5", Type: TypeHash, Purpose: PurposeHash, Risk: "High", Vulnerability: "Grover's Algorithm + Broken",
+		Description: "MD5 is broken", Recommendation: "Use SHA-256 or SHA-3"}
+	sha1 := configAlgorithmInfo{Algorithm: "SHA-1", Type: TypeHash, Purpose: PurposeHash, Risk: "High", Vulnerability: "Grover's Algorithm + Broken", NISTAlgorithmID: "SHA-1",
+		Description: "SHA-1 is broken", Recommendation: "Use SHA-256 or SHA-3"}
+
+	values := map[string]configAlgorithmInfo{
+		"rsa": rsaSignature, "rsa-sha256": rsaSignature, "sha256withrsa": rsaSignature,
+		"ecdsa": ecdsaSignature, "es256k": ecdsaSignature, "sha256withecdsa": ecdsaSignature,
+		"eddsa": eddsaSignature, "ed25519": eddsaSignature,
+		"rsa1_5": rsaEncryption, "rsa-oaep": rsaEncryption, "rsa-oaep-256": rsaEncryption,
+		"ecdh": ecdhAgreement, "ecdh-es": ecdhAgreement, "ecdh-es+a128kw": ecdhAgreement, "ecdh-es+a256kw": ecdhAgreement, "ecdhe": ecdhAgreement,
+		"dh": dhAgreement, "dhe": dhAgreement, "diffie-hellman": dhAgreement,
+		"dsa":    dsaSignature,
+		"aes128": aes128, "aes-128": aes128, "aes-128-cbc": aes128, "aes-128-gcm": aes128, "aes128-gcm": aes128, "a128gcm": aes128, "a128cbc-hs256": aes128, "a128kw": aes128,
+		"des": des, "des-cbc": des,
+		"3des": tripleDES, "desede": tripleDES, "tripledes": tripleDES, "des-ede3-cbc": tripleDES,
+		"rc4": rc4, "arcfour": rc4,
+		"md5":  md5,
+		"sha1": sha1, "sha-1": sha1,
+	}
+	for size, curve := range map[string]string{"256": "P256", "384": "P384", "512": "P521"} {
+		values["rs"+size] = rsaSignature
+		values["ps"+size] = rsaSignature
+		es := ecdsaSignature
+		es.NISTAlgorithmID = "ECDSA-" + curve
+		values["es"+size] = es
+	}
+	return values
+}()
+
+// configEntry is one key of a configuration file with its value
+type configEntry struct {
+	Path   string        // Dotted path of the key, e.g. "auth.jwt.alg"
+	Key    string        // The key itself, as written
+	Values []configValue // The scalar value, or the items of a list value
+}
+
+// configValue is a scalar value or list item and the line it is on
+type configValue struct {
+	Text string
+	Line int
+}
+
+// ScanConfigFile reads a YAML, JSON or TOML file and reports the algorithms, cipher
+// lists and key sizes set under known crypto-bearing keys
+func (s *Scanner) ScanConfigFile(ctx context.Context, path string) []Result {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		s.logf("Error reading file %s: %v\n", path, err)
+		s.warn("", path, fmt.Sprintf("failed to read file: %v", err))
+		return nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var results []Result
+	for _, entry := range parseConfigEntries(strings.ToLower(filepath.Ext(path)), content) {
+		if ctx.Err() != nil {
+			break
+		}
+		results = append(results, analyzeConfigEntry(path, entry)...)
+	}
+
+	// Private keys pasted into configuration are as exposed as in source
+	results = append(results, s.detectEmbeddedSecrets(path, lines)...)
+
+	s.attachSnippets(results, lines)
+	classifyResults(results)
+	SetFingerprints(results, lines, s.Roots)
+	return results
+}
+
+// analyzeConfigEntry reports the vulnerable algorithms set by one configuration key
+func analyzeConfigEntry(file string, entry configEntry) []Result {
+	if kind, ok := tlsDirectives[entry.Key]; ok {
+		return analyzeConfigList(file, entry, kind)
+	}
+	kind, ok := configKeys[strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(entry.Key))]
+	if !ok {
+		return nil
+	}
+
+	var results []Result
+	switch kind {
+	case configCipherList:
+		return analyzeConfigList(file, entry, directiveOpenSSLCiphers)
+
+	case configAlgorithm:
+		for _, value := range entry.Values {
+			if strings.EqualFold(value.Text, "none") && isJWTConfigKey(entry) {
+				results = append(results, Result{
+					File:              file,
+					Algorithm:         "JWT",
+					Type:              TypeProtocol,
+					Line:              value.Line,
+					Method:            "Config File Analysis",
+					Risk:              "Critical",
+					VulnerabilityType: "JWT Misuse",
+					Description:       fmt.Sprintf("%s selects alg \"none\": tokens carry no signature and anyone can forge them", entry.Path),
+					Recommendation:    "Never issue or accept unsigned tokens; configure the signing algorithm you issue",
+				})
+				continue
+			}
+			info, ok := configAlgorithmValues[strings.ToLower(value.Text)]
+			if !ok {
+				continue
+			}
+			results = append(results, configResult(file, entry.Path, value, info))
+		}
+
+	case configKeySize:
+		if len(entry.Values) != 1 {
+			return nil
+		}
+		bits, err := strconv.Atoi(entry.Values[0].Text)
+		if err != nil {
+			return nil
+		}
+		if info, ok := configKeySizeAlgorithm(entry.Path, bits); ok {
+			results = append(results, configResult(file, entry.Path, entry.Values[0], info))
+		}
+	}
+	return results
+}
+
+// isJWTConfigKey reports whether an algorithm key configures JWTs: a JOSE alg key, or
+// any algorithm key under a path mentioning JWT
+func isJWTConfigKey(entry configEntry) bool {
+	return strings.EqualFold(entry.Key, "alg") || strings.Contains(strings.ToLower(entry.Path), "jwt")
+}
+
+// configResult builds the finding for an algorithm set to value at path
+func configResult(file, path string, value configValue, info configAlgorithmInfo) Result {
+	result := Result{
+		File:              file,
+		Algorithm:         info.Algorithm,
+		Type:              info.Type,
+		Line:              value.Line,
+		Method:            "Config File Analysis",
+		Risk:              info.Risk,
+		VulnerabilityType: info.Vulnerability,
+		Description:       fmt.Sprintf("%s selects %s: %s", path, value.Text, info.Description),
+		Recommendation:    info.Recommendation,
+		Purpose:           info.Purpose,
+		CWE:               info.CWE,
+	}
+	populateNISTFields(&result, info.NISTAlgorithmID)
+	return result
+}
+
+// configKeySizeAlgorithm classifies a key size by the algorithm named in its key
+// path, e.g. rsa_bits or tls.rsa.key_size. Sizes of quantum-safe strength are not reported.
+func configKeySizeAlgorithm(path string, bits int) (configAlgorithmInfo, bool) {
+	var rsa, dh, aes bool
+	for _, segment := range strings.Split(path, ".") {
+		segment = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(segment))
+		rsa = rsa || strings.Contains(segment, "rsa")
+		dh = dh || strings.HasPrefix(segment, "dh") || strings.Contains(segment, "dhparam")
+		aes = aes || strings.Contains(segment, "aes")
+	}
+
+	switch {
+	case rsa:
+		info := configAlgorithmValues["rsa"]
+		info.Purpose = "" // A key size alone doesn't say what the key is for
+		info.Description = fmt.Sprintf("%d-bit RSA key vulnerable to quantum attacks", bits)
+		if nistID := fmt.Sprintf("RSA-%d", bits); GetNISTInfo(nistID) != nil {
+			info.NISTAlgorithmID = nistID
+		}
+		if bits < 2048 {
+			info.Risk = "Critical"
+			info.Description += " and below the 2048-bit minimum"
+		}
+		return info, true
+	case dh:
+		info := configAlgorithmValues["dh"]
+		info.Description = fmt.Sprintf("%d-bit Diffie-Hellman group vulnerable to quantum attacks", bits)
+		if nistID := dhNISTAlgorithmID(bits); nistID != "" {
+			info.NISTAlgorithmID = nistID
+		}
+		if bits < dhMinimumBits {
+			info.Risk = "Critical"
+			info.Vulnerability = "Shor's Algorithm + Broken"
+			info.CWE = []string{CWEInadequateStrength}
+			info.Description += " and below the 2048-bit minimum, so breakable today"
+		}
+		return info, true
+	case aes && bits == 128:
+		return configAlgorithmValues["aes-128"], true
+	}
+	return configAlgorithmInfo{}, false
+}
+
+// analyzeConfigList reports the vulnerable entries of a list key read as a directive
+// of kind, each on the line it is on. A single value holds the whole list.
+func analyzeConfigList(file string, entry configEntry, kind tlsDirectiveKind) []Result {
+	if len(entry.Values) == 0 {
+		return nil
+	}
+	if len(entry.Values) == 1 {
+		value := entry.Values[0]
+		return analyzeDirectiveEntries(file, value.Line, entry.Path, kind, splitDirectiveEntries(kind, value.Text))
+	}
+	if kind == directiveJavaDisabled {
+		// Omissions are only known from the list as a whole
+		var entries []string
+		for _, value := range entry.Values {
+			entries = append(entries, value.Text)
+		}
+		return analyzeDirectiveEntries(file, entry.Values[0].Line, entry.Path, kind, entries)
+	}
+
+	var results []Result
+	for _, value := range entry.Values {
+		results = append(results, analyzeDirectiveEntries(file, value.Line, entry.Path, kind, []string{value.Text})...)
+	}
+	return results
+}
+
+// parseConfigEntries lists the keys set in a YAML, JSON or TOML file with their full
+// paths and line numbers. YAML and JSON, which is YAML too, are parsed into yaml.v3
+// nodes, so minified JSON and every YAML style are read, and TOML by go-toml's
+// parser. YAML that doesn't parse, such as Helm templates, is read line by line;
+// TOML that doesn't parse is read up to the error.
+func parseConfigEntries(ext string, content []byte) []configEntry {
+	if ext == ".toml" {
+		entries, _ := parseTOMLEntries(content)
+		return entries
+	}
+	if entries, err := parseConfigNodes(content); err == nil {
+		return entries
+	}
+	return parseConfigLines(ext, strings.Split(string(content), "\n"))
+}
+
+// parseTOMLEntries lists the keys set in a TOML file. Keys under a [table] or
+// [[array of tables]] header are nested under it, and so are the keys of inline
+// tables, as YAML mappings are.
+func parseTOMLEntries(content []byte) ([]configEntry, error) {
+	var entries []configEntry
+	var parser unstable.Parser
+	parser.Reset(content)
+	table := "" // Path of the current table
+	for parser.NextExpression() {
+		expression := parser.Expression()
+		switch expression.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			var key string
+			table, key, _ = tomlKeyPath("", expression.Key(), &parser)
+			entries = append(entries, configEntry{Path: table, Key: key})
+		case unstable.KeyValue:
+			entries = appendTOMLKeyValue(entries, table, expression, &parser)
+		}
+	}
+	return entries, parser.Error()
+}
+
+// appendTOMLKeyValue appends a key-value expression below path. A key holding an
+// array or inline table is listed before its contents; the inline tables of an
+// array are read as keys nested under the array.
+func appendTOMLKeyValue(entries []configEntry, path string, keyValue *unstable.Node, parser *unstable.Parser) []configEntry {
+	fullPath, key, keyLine := tomlKeyPath(path, keyValue.Key(), parser)
+	entry := configEntry{Path: fullPath, Key: key}
+	value := keyValue.Value()
+	switch value.Kind {
+	case unstable.InlineTable:
+		entries = append(entries, entry)
+		for items := value.Children(); items.Next(); {
+			entries = appendTOMLKeyValue(entries, fullPath, items.Node(), parser)
+		}
+	case unstable.Array:
+		for items := value.Children(); items.Next(); {
+			if item := items.Node(); item.Kind != unstable.Array && item.Kind != unstable.InlineTable {
+				entry.Values = append(entry.Values, configValue{Text: string(item.Data), Line: tomlLine(item, keyLine, parser)})
+			}
+		}
+		entries = append(entries, entry)
+		for items := value.Children(); items.Next(); {
+			if item := items.Node(); item.Kind == unstable.InlineTable {
+				for pairs := item.Children(); pairs.Next(); {
+					entries = appendTOMLKeyValue(entries, fullPath, pairs.Node(), parser)
+				}
+			}
+		}
+	default:
+		entry.Values = []configValue{{Text: string(value.Data), Line: tomlLine(value, keyLine, parser)}}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// tomlKeyPath returns the dotted path of a possibly dotted TOML key below path, its
+// last part and the line it is on
+func tomlKeyPath(path string, parts unstable.Iterator, parser *unstable.Parser) (string, string, int) {
+	key, line := "", 0
+	for parts.Next() {
+		part := parts.Node()
+		key, line = string(part.Data), parser.Shape(part.Raw).Start.Line
+		if path != "" {
+			path += "."
+		}
+		path += key
+	}
+	return path, key, line
+}
+
+// tomlLine returns the line a TOML value starts on, or fallback for values the
+// parser doesn't locate
+func tomlLine(value *unstable.Node, fallback int, parser *unstable.Parser) int {
+	if value.Raw.Length == 0 {
+		return fallback
+	}
+	return parser.Shape(value.Raw).Start.Line
+}
+
+// parseConfigNodes lists the keys set in every document of a YAML or JSON file
+func parseConfigNodes(content []byte) ([]configEntry, error) {
+	var entries []configEntry
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if err == io.EOF {
+				return entries, nil
+			}
+			return nil, err
+		}
+		for _, node := range document.Content {
+			entries = appendConfigNode(entries, "", node)
+		}
+	}
+}
+
+// appendConfigNode appends the keys of a mapping node below path. A key holding a map
+// or list is listed before its contents; the mapping items of a list are read as
+// keys nested under the list.
+func appendConfigNode(entries []configEntry, path string, node *yaml.Node) []configEntry {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind == yaml.AliasNode {
+				value = value.Alias
+			}
+			entry := configEntry{Path: key.Value, Key: key.Value}
+			if path != "" {
+				entry.Path = path + "." + key.Value
+			}
+			switch value.Kind {
+			case yaml.ScalarNode:
+				entry.Values = []configValue{{Text: value.Value, Line: value.Line}}
+			case yaml.SequenceNode:
+				for _, item := range value.Content {
+					if item.Kind == yaml.ScalarNode {
+						entry.Values = append(entry.Values, configValue{Text: item.Value, Line: item.Line})
+					}
+				}
+			}
+			entries = append(entries, entry)
+			if value.Kind != yaml.ScalarNode {
+				entries = appendConfigNode(entries, entry.Path, value)
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				entries = appendConfigNode(entries, path, item)
+			}
+		}
+	}
+	return entries
+}
+
+// parseConfigLines lists the keys set in a YAML or JSON file line by line. Nesting
+// follows indentation. Inline maps and lists, block lists and multi-line JSON arrays
+// are read; other values are taken verbatim.
+func parseConfigLines(ext string, lines []string) []configEntry {
+	type parent struct {
+		key    string
+		indent int
+	}
+	var stack []parent
+	var entries []configEntry
+	list := -1 // Entry collecting the items of an open list
+	listIndent := 0
+
+	path := func(key string) string {
+		var parts []string
+		for _, p := range stack {
+			parts = append(parts, p.key)
+		}
+		return strings.Join(append(parts, key), ".")
+	}
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") || line == "---" {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+
+		// YAML block list items; a mapping item is read as a key nested under the list
+		if strings.HasPrefix(line, "- ") || line == "-" {
+			item := strings.TrimSpace(strings.TrimPrefix(line, "-"))
+			if _, _, ok := splitConfigKey(ext, item); !ok {
+				if list >= 0 && indent >= listIndent {
+					entries[list].Values = append(entries[list].Values, configValue{Text: trimConfigValue(item), Line: i + 1})
+				}
+				continue
+			}
+			line, indent = item, indent+2
+		}
+
+		key, value, ok := splitConfigKey(ext, line)
+		if !ok {
+			// Items of a multi-line JSON array
+			if list >= 0 {
+				if strings.HasPrefix(line, "]") {
+					list = -1
+				} else if item := trimConfigValue(line); item != "" && !strings.ContainsAny(item[:1], "{}") {
+					entries[list].Values = append(entries[list].Values, configValue{Text: item, Line: i + 1})
+				}
+			}
+			continue
+		}
+		list = -1
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		entry := configEntry{Path: path(key), Key: key}
+		value = strings.TrimSuffix(value, ",")
+		switch {
+		case value == "" || value == "{" || value == "[":
+			// A nested map or a list follows
+			entries = append(entries, entry)
+			if value != "{" {
+				list, listIndent = len(entries)-1, indent
+			}
+			stack = append(stack, parent{key: key, indent: indent})
+		case strings.HasPrefix(value, "{"):
+			// Inline map, e.g. jwt: { alg: RS256 }
+			for _, pair := range strings.Split(strings.Trim(value, "{} "), ",") {
+				if k, v, ok := splitInlineConfigPair(pair); ok {
+					entries = append(entries, configEntry{Path: entry.Path + "." + k, Key: k, Values: []configValue{{Text: v, Line: i + 1}}})
+				}
+			}
+		case strings.HasPrefix(value, "["):
+			for _, item := range strings.Split(strings.Trim(value, "[] "), ",") {
+				if item = trimConfigValue(item); item != "" {
+					entry.Values = append(entry.Values, configValue{Text: item, Line: i + 1})
+				}
+			}
+			entries = append(entries, entry)
+		default:
+			entry.Values = []configValue{{Text: trimConfigValue(value), Line: i + 1}}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// splitConfigKey splits a "key: value" (YAML) or "\"key\": value" (JSON) line into
+// its key and value
+func splitConfigKey(ext, line string) (key, value string, ok bool) {
+	switch ext {
+	case ".json":
+		if !strings.HasPrefix(line, `"`) {
+			return "", "", false
+		}
+		end := strings.Index(line[1:], `"`)
+		if end < 0 {
+			return "", "", false
+		}
+		rest := strings.TrimSpace(line[end+2:])
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		return line[1 : end+1], strings.TrimSpace(rest[1:]), true
+	default:
+		end := strings.Index(line, ": ")
+		if end < 0 && strings.HasSuffix(line, ":") {
+			end = len(line) - 1
+		}
+		if end <= 0 {
+			return "", "", false
+		}
+		return strings.Trim(strings.TrimSpace(line[:end]), `"'`), stripConfigComment(line[end+1:]), true
+	}
+}
+
+// splitInlineConfigPair splits one "key: value" pair of an inline map
+func splitInlineConfigPair(pair string) (key, value string, ok bool) {
+	end := strings.Index(pair, ":")
+	if end <= 0 {
+		return "", "", false
+	}
+	key = strings.Trim(strings.TrimSpace(pair[:end]), `"'`)
+	value = trimConfigValue(pair[end+1:])
+	return key, value, key != "" && value != ""
+}
+
+// stripConfigComment removes a trailing " #" comment from a YAML value
+func stripConfigComment(value string) string {
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// trimConfigValue strips surrounding whitespace, a trailing comma and quotes from a value
+func trimConfigValue(value string) string {
+	value = strings.TrimSuffix(strings.TrimSpace(value), ",")
+	return strings.Trim(strings.TrimSpace(value), `"'`)
+}
@@ -0,0 +1,102 @@
+package crypto
+
+import (
+	"regexp"
+)
+
+// ellipticCurve is a named curve recognized in source code
+type ellipticCurve struct {
+	Name             string
+	Pattern          *regexp.Regexp
+	NISTSuffix       string // Suffix of the curve's ECDSA-/ECDH- NIST IR 8547 entries; empty when it has none
+	EdDSAID          string // NIST IR 8547 entry for EdDSA over the curve, if any
+	SecurityStrength int    // Classical security strength in bits
+	Note             string // Added to the description of findings on the curve
+}
+
+// ellipticCurves lists the recognized curves. secp256k1 comes first so its
+// idioms are never read as the similarly named NIST curves.
+var ellipticCurves = []ellipticCurve{
+	{
+		Name:             "secp256k1",
+		Pattern:          regexp.MustCompile(`(?i)secp256k1|\bk256\b|\bES256K\b`),
+		SecurityStrength: 128,
+		Note:             "secp256k1 is not a NIST-approved curve (SP 800-186), so no NIST IR 8547 entry applies",
+	},
+	{
+		Name:             "P-256",
+		Pattern:          regexp.MustCompile(`(?i)secp256r1|prime256v1|\bP-?256\b|\bnistP256\b|\bES256\b`),
+		NISTSuffix:       "P256",
+		SecurityStrength: 128,
+	},
+	{
+		Name:             "P-384",
+		Pattern:          regexp.MustCompile(`(?i)secp384r1|\bP-?384\b|\bnistP384\b|\bES384\b`),
+		NISTSuffix:       "P384",
+		SecurityStrength: 192,
+	},
+	{
+		Name:             "P-521",
+		Pattern:          regexp.MustCompile(`(?i)secp521r1|\bP-?521\b|\bnistP521\b|\bES512\b`),
+		NISTSuffix:       "P521",
+		SecurityStrength: 256,
+	},
+	{
+		Name:             "Curve25519",
+		Pattern:          regexp.MustCompile(`(?i)curve25519|[xX]25519|[eE]d25519`),
+		EdDSAID:          "EdDSA-Ed25519",
+		SecurityStrength: 128,
+	},
+	{
+		Name:             "Curve448",
+		Pattern:          regexp.MustCompile(`(?i)curve448|[xX]448|[eE]d448`),
+		EdDSAID:          "EdDSA-Ed448",
+		SecurityStrength: 224,
+	},
+}
+
+// ellipticAlgorithms are the rule algorithms whose findings are refined by curve
+var ellipticAlgorithms = map[string]bool{"ECDSA": true, "ECDH": true, "EdDSA": true, "ECC": true}
+
+// detectCurve returns the curve named on a source line
+func detectCurve(line string) (ellipticCurve, bool) {
+	for _, curve := range ellipticCurves {
+		if curve.Pattern.MatchString(line) {
+			return curve, true
+		}
+	}
+	return ellipticCurve{}, false
+}
+
+// applyCurve sets the curve of an elliptic-curve finding from its source line, with
+// hybrid group names masked as in matchRules, and returns the NIST IR 8547 entry
+// for that curve, or nistAlgorithmID when the line names no curve. Findings on
+// curves without an entry get the curve's security strength and, for non-NIST
+// curves, a note instead.
+func applyCurve(result *Result, line, nistAlgorithmID string) string {
+	if !ellipticAlgorithms[result.Algorithm] {
+		return nistAlgorithmID
+	}
+	curve, ok := detectCurve(hybridNamePattern.ReplaceAllString(line, ""))
+	if !ok {
+		return nistAlgorithmID
+	}
+	result.Curve = curve.Name
+
+	family := "ECDSA"
+	if result.Algorithm == "ECDH" {
+		family = "ECDH"
+	}
+	switch {
+	case curve.NISTSuffix != "":
+		return family + "-" + curve.NISTSuffix
+	case curve.EdDSAID != "" && family == "ECDSA":
+		return curve.EdDSAID
+	}
+
+	result.SecurityStrength = curve.SecurityStrength
+	if curve.Note != "" {
+		result.Description += " (" + curve.Note + ")"
+	}
+	return ""
+}
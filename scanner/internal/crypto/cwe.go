@@ -0,0 +1,47 @@
+package crypto
+
+// CWE identifiers findings are tagged with
+const (
+	CWEBrokenCrypto                  = "CWE-327"  // Use of a Broken or Risky Cryptographic Algorithm
+	CWEInadequateStrength            = "CWE-326"  // Inadequate Encryption Strength
+	CWEWeakHash                      = "CWE-328"  // Use of Weak Hash
+	CWERiskyImplementation           = "CWE-1240" // Use of a Cryptographic Primitive with a Risky Implementation
+	CWEWeakIV                        = "CWE-1204" // Generation of Weak Initialization Vector
+	CWEWeakPRNG                      = "CWE-338"  // Use of Cryptographically Weak PRNG
+	CWEInsufficientEntropy           = "CWE-331"  // Insufficient Entropy
+	CWEHardcodedKey                  = "CWE-321"  // Use of Hard-coded Cryptographic Key
+	CWEWeakPasswordHash              = "CWE-916"  // Use of Password Hash With Insufficient Computational Effort
+	CWEImproperSignatureVerification = "CWE-347"  // Improper Verification of Cryptographic Signature
+	CWEImproperCertificateValidation = "CWE-295"  // Improper Certificate Validation
+	CWECleartextTransmission         = "CWE-319"  // Cleartext Transmission of Sensitive Information
+	CWEUnmaintainedComponent         = "CWE-1104" // Use of Unmaintained Third Party Components
+	CWEAlgorithmDowngrade            = "CWE-757"  // Selection of Less-Secure Algorithm During Negotiation
+)
+
+// vulnerabilityCWEs are the CWEs of findings by vulnerability type, for detectors
+// that don't set their own. Quantum-resistant and adequately sized algorithms have
+// no entry.
+var vulnerabilityCWEs = map[string][]string{
+	"Shor's Algorithm":            {CWEBrokenCrypto},
+	"Shor's Algorithm + Broken":   {CWEBrokenCrypto},
+	"Grover's Algorithm + Broken": {CWEBrokenCrypto},
+	"Classical Weakness":          {CWEBrokenCrypto},
+	"Broken":                      {CWEBrokenCrypto},
+	"Protocol Weakness":           {CWEBrokenCrypto},
+	"Insecure Mode":               {CWEBrokenCrypto, CWERiskyImplementation},
+	"Weak RNG":                    {CWEWeakPRNG, CWEInsufficientEntropy},
+	"Hardcoded Secret":            {CWEHardcodedKey},
+	"Weak Password Hashing":       {CWEWeakPasswordHash},
+	"JWT Misuse":                  {CWEImproperSignatureVerification},
+	"End of Life":                 {CWEUnmaintainedComponent},
+	"Downgrade":                   {CWEAlgorithmDowngrade},
+}
+
+// SetDefaultCWE tags each result without a CWE from its vulnerability type
+func SetDefaultCWE(results []Result) {
+	for i := range results {
+		if len(results[i].CWE) == 0 {
+			results[i].CWE = vulnerabilityCWEs[results[i].VulnerabilityType]
+		}
+	}
+}
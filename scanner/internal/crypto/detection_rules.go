@@ -1,122 +1,227 @@
 package crypto
 
+import "regexp"
+
+// Hybrid key exchange group names, as used by TLS libraries and configs
+const (
+	x25519MLKEM768Pattern     = `X25519MLKEM768|x25519_mlkem768|X25519Kyber768Draft00|x25519_kyber768|X25519Kyber768`
+	secP256r1MLKEM768Pattern  = `SecP256r1MLKEM768|p256_mlkem768|P256Kyber768|p256_kyber768`
+	secP384r1MLKEM1024Pattern = `SecP384r1MLKEM1024|p384_mlkem1024`
+)
+
+// hybridNamePattern matches any hybrid group name. Hybrid names embed their
+// classical component (X25519, P-256), so matches are masked before the
+// classical rules run to avoid reporting the hybrid as vulnerable.
+var hybridNamePattern = regexp.MustCompile(x25519MLKEM768Pattern + "|" + secP256r1MLKEM768Pattern + "|" + secP384r1MLKEM1024Pattern)
+
+// Remediation examples shown by -show-remediation, keyed by sourceLanguage
+var (
+	// mlkemRemediation replaces RSA key transport with ML-KEM key encapsulation
+	mlkemRemediation = map[string]string{
+		"go": `// Go 1.24+: crypto/mlkem
+dk, err := mlkem.GenerateKey768()
+if err != nil {
+	return err
+}
+ek := dk.EncapsulationKey() // Share ek.Bytes() with the peer
+// Peer: sharedKey, ciphertext := ek.Encapsulate()
+sharedKey, err := dk.Decapsulate(ciphertext)`,
+		"java": `// JDK 24+: ML-KEM (JEP 496)
+KeyPair kp = KeyPairGenerator.getInstance("ML-KEM-768").generateKeyPair();
+KEM.Encapsulated enc = KEM.getInstance("ML-KEM").newEncapsulator(kp.getPublic()).encapsulate();
+SecretKey sharedKey = enc.key(); // Send enc.encapsulation() to the key holder`,
+		"python": `# liboqs-python
+import oqs
+with oqs.KeyEncapsulation("ML-KEM-768") as kem:
+    public_key = kem.generate_keypair()
+    # Peer: ciphertext, shared_secret = oqs.KeyEncapsulation("ML-KEM-768").encap_secret(public_key)
+    shared_secret = kem.decap_secret(ciphertext)`,
+		"javascript": `// Node.js TLS: prefer the hybrid X25519MLKEM768 group (OpenSSL 3.5+)
+const server = tls.createServer({ ecdhCurve: 'X25519MLKEM768:X25519', key, cert });`,
+		"csharp": `// .NET 10+: System.Security.Cryptography.MLKem
+using MLKem key = MLKem.GenerateKey(MLKemAlgorithm.MLKem768);
+byte[] encapsulationKey = key.ExportEncapsulationKey(); // Share with the peer
+// Peer: MLKem.ImportEncapsulationKey(MLKemAlgorithm.MLKem768, encapsulationKey)
+//           .Encapsulate(out byte[] ciphertext, out byte[] sharedSecret);
+byte[] sharedSecret = key.Decapsulate(ciphertext);`,
+	}
+
+	// mldsaRemediation replaces RSA, ECDSA and EdDSA signatures with ML-DSA
+	mldsaRemediation = map[string]string{
+		"go": `// github.com/cloudflare/circl/sign/mldsa/mldsa65
+pub, priv, err := mldsa65.GenerateKey(rand.Reader)
+if err != nil {
+	return err
+}
+sig := make([]byte, mldsa65.SignatureSize)
+if err := mldsa65.SignTo(priv, msg, nil, false, sig); err != nil {
+	return err
+}
+valid := mldsa65.Verify(pub, msg, nil, sig)`,
+		"java": `// JDK 24+: ML-DSA (JEP 497)
+KeyPair kp = KeyPairGenerator.getInstance("ML-DSA-65").generateKeyPair();
+Signature signer = Signature.getInstance("ML-DSA");
+signer.initSign(kp.getPrivate());
+signer.update(message);
+byte[] sig = signer.sign();`,
+		"python": `# liboqs-python
+import oqs
+with oqs.Signature("ML-DSA-65") as signer:
+    public_key = signer.generate_keypair()
+    signature = signer.sign(message)
+# Verify: oqs.Signature("ML-DSA-65").verify(message, signature, public_key)`,
+		"csharp": `// .NET 10+: System.Security.Cryptography.MLDsa
+using MLDsa key = MLDsa.GenerateKey(MLDsaAlgorithm.MLDsa65);
+byte[] signature = key.SignData(message);
+bool valid = key.VerifyData(message, signature);`,
+	}
+
+	// sha256Remediation replaces broken hashes with SHA-256
+	sha256Remediation = map[string]string{
+		"go":         `sum := sha256.Sum256(data) // crypto/sha256`,
+		"java":       `byte[] digest = MessageDigest.getInstance("SHA-256").digest(data);`,
+		"python":     `digest = hashlib.sha256(data).hexdigest()`,
+		"javascript": `const digest = crypto.createHash('sha256').update(data).digest('hex');`,
+		"csharp":     `byte[] digest = SHA256.HashData(data);`,
+	}
+)
+
 // buildDetectionRules creates detection rules with NIST IR 8547 information
 func buildDetectionRules() []DetectionRule {
 	return []DetectionRule{
 		// RSA Detection Rules (NIST Table 2 - Quantum-Vulnerable)
 		{
-			AlgorithmType:     "PublicKey",
-			AlgorithmName:     "RSA",
-			Method:            "Function Name",
-			Pattern:           `RSA\.encrypt|RSACipher|rsa\.newkeys|rsa\.generate_private_key|public_key\.encrypt|private_key\.decrypt|private_key\.sign|KeyPairGenerator\.getInstance\("RSA"\)|crypto\.generateKeyPairSync\('rsa'`,
-			RiskLevel:         "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "RSA encryption is vulnerable to quantum attacks using Shor's algorithm, which can factor large integers in polynomial time",
-			Recommendation:    "Replace with quantum-resistant algorithm ML-KEM (CRYSTALS-Kyber) for key encapsulation or consider hybrid approaches",
-			NISTAlgorithmID:   "RSA-2048", // Default to common key size
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "RSA",
+			Method:             "Function Name",
+			Pattern:            `RSA\.encrypt|RSACipher|rsa\.newkeys|rsa\.generate_private_key|public_key\.encrypt|private_key\.decrypt|private_key\.sign|KeyPairGenerator\.getInstance\("RSA"\)|crypto\.generateKeyPairSync\('rsa'|Cipher\.getInstance\("RSA|withRSA"|KeyProperties\.KEY_ALGORITHM_RSA|kSecAttrKeyTypeRSA|RsaPrivateKey::|RsaPublicKey::|Pkcs1v15Sign|Pkcs1v15Encrypt|RsaKeyPair::|signature::RSA_P`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "RSA encryption is vulnerable to quantum attacks using Shor's algorithm, which can factor large integers in polynomial time",
+			Recommendation:     "Replace with quantum-resistant algorithm ML-KEM (CRYSTALS-Kyber) for key encapsulation or consider hybrid approaches",
+			NISTAlgorithmID:    "RSA-2048", // Default to common key size
+			RemediationExample: mlkemRemediation,
 		},
 		{
 			AlgorithmType:     "PublicKey",
 			AlgorithmName:     "RSA",
 			Method:            "Import Statement",
-			Pattern:           `from cryptography\.hazmat\.primitives\.asymmetric import rsa|import rsa|import java.security.KeyPairGenerator|const crypto = require\('crypto'\)`,
+			Pattern:           `from cryptography\.hazmat\.primitives\.asymmetric import rsa|import rsa|import java.security.KeyPairGenerator|const crypto = require\('crypto'\)|use rsa::`,
 			RiskLevel:         "High",
 			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
 			Description:       "RSA cryptography libraries are vulnerable to quantum attacks using Shor's algorithm",
 			Recommendation:    "Replace with NIST-standardized post-quantum cryptography libraries using ML-KEM",
 			NISTAlgorithmID:   "RSA-2048",
 		},
 		{
-			AlgorithmType:     "PublicKey",
-			AlgorithmName:     "RSA",
-			Method:            "Configuration",
-			Pattern:           `algorithm = "RSA"|keyGen\.initialize\(2048\)|keysize=2048`,
-			RiskLevel:         "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "RSA-2048 key generation is vulnerable to quantum attacks",
-			Recommendation:    "Replace with ML-KEM (CRYSTALS-Kyber) with appropriate parameter sets",
-			NISTAlgorithmID:   "RSA-2048",
-		},
-		{
-			AlgorithmType:     "PublicKey",
-			AlgorithmName:     "RSA",
-			Method:            "Configuration",
-			Pattern:           `keyGen\.initialize\(3072\)|keysize=3072`,
-			RiskLevel:         "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "RSA-3072 key generation is vulnerable to quantum attacks",
-			Recommendation:    "Replace with ML-KEM (CRYSTALS-Kyber) with appropriate parameter sets",
-			NISTAlgorithmID:   "RSA-3072",
-		},
-		{
-			AlgorithmType:     "PublicKey",
-			AlgorithmName:     "RSA",
-			Method:            "Configuration",
-			Pattern:           `keyGen\.initialize\(4096\)|keysize=4096`,
-			RiskLevel:         "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "RSA-4096 key generation is vulnerable to quantum attacks",
-			Recommendation:    "Replace with ML-KEM (CRYSTALS-Kyber) with appropriate parameter sets",
-			NISTAlgorithmID:   "RSA-4096",
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "RSA",
+			Method:             "Configuration",
+			Pattern:            `algorithm\s*=\s*"RSA"|keyGen\.initialize\(2048\)|keysize=2048|rsa_bits\s*=\s*2048|(?:RSACryptoServiceProvider|RSACng|RSA\.Create)\(2048\)`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "RSA-2048 key generation is vulnerable to quantum attacks",
+			Recommendation:     "Replace with ML-KEM (CRYSTALS-Kyber) with appropriate parameter sets",
+			NISTAlgorithmID:    "RSA-2048",
+			RemediationExample: mlkemRemediation,
+		},
+		{
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "RSA",
+			Method:             "Configuration",
+			Pattern:            `keyGen\.initialize\(3072\)|keysize=3072|rsa_bits\s*=\s*3072|(?:RSACryptoServiceProvider|RSACng|RSA\.Create)\(3072\)`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "RSA-3072 key generation is vulnerable to quantum attacks",
+			Recommendation:     "Replace with ML-KEM (CRYSTALS-Kyber) with appropriate parameter sets",
+			NISTAlgorithmID:    "RSA-3072",
+			RemediationExample: mlkemRemediation,
+		},
+		{
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "RSA",
+			Method:             "Configuration",
+			Pattern:            `keyGen\.initialize\(4096\)|keysize=4096|rsa_bits\s*=\s*4096|(?:RSACryptoServiceProvider|RSACng|RSA\.Create)\(4096\)`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "RSA-4096 key generation is vulnerable to quantum attacks",
+			Recommendation:     "Replace with ML-KEM (CRYSTALS-Kyber) with appropriate parameter sets",
+			NISTAlgorithmID:    "RSA-4096",
+			RemediationExample: mlkemRemediation,
 		},
 
 		// ECDSA Detection Rules (NIST Table 2 - Quantum-Vulnerable)
 		{
-			AlgorithmType:     "PublicKey",
-			AlgorithmName:     "ECDSA",
-			Method:            "Function Name",
-			Pattern:           `ECDSA|ecdsa\.Sign|ecdsa\.GenerateKey|SigningKey\.generate\(curve=SECP|SigningKey\.generate\(curve=NIST`,
-			RiskLevel:         "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "ECDSA (Elliptic Curve Digital Signature Algorithm) is vulnerable to quantum attacks",
-			Recommendation:    "Replace with quantum-resistant signature schemes like ML-DSA or SLH-DSA",
-			NISTAlgorithmID:   "ECDSA-P256",
-		},
-		{
-			AlgorithmType:     "PublicKey",
-			AlgorithmName:     "ECDSA",
-			Method:            "Configuration",
-			Pattern:           `secp256r1|prime256v1|P-256|NIST P-256`,
-			RiskLevel:         "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "ECDSA with P-256 curve is vulnerable to quantum attacks",
-			Recommendation:    "Replace with ML-DSA (CRYSTALS-Dilithium) for quantum-resistant signatures",
-			NISTAlgorithmID:   "ECDSA-P256",
-		},
-		{
-			AlgorithmType:     "PublicKey",
-			AlgorithmName:     "ECDSA",
-			Method:            "Configuration",
-			Pattern:           `secp384r1|P-384|NIST P-384`,
-			RiskLevel:         "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "ECDSA with P-384 curve is vulnerable to quantum attacks",
-			Recommendation:    "Replace with ML-DSA (CRYSTALS-Dilithium) for quantum-resistant signatures",
-			NISTAlgorithmID:   "ECDSA-P384",
-		},
-		{
-			AlgorithmType:     "PublicKey",
-			AlgorithmName:     "ECDSA",
-			Method:            "Configuration",
-			Pattern:           `secp521r1|P-521|NIST P-521`,
-			RiskLevel:         "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "ECDSA with P-521 curve is vulnerable to quantum attacks",
-			Recommendation:    "Replace with ML-DSA (CRYSTALS-Dilithium) for quantum-resistant signatures",
-			NISTAlgorithmID:   "ECDSA-P521",
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "ECDSA",
+			Method:             "Function Name",
+			Pattern:            `ECDSA|ecdsa\.Sign|ecdsa\.GenerateKey|SigningKey\.generate\(curve=SECP|SigningKey\.generate\(curve=NIST|KeyPairGenerator\.getInstance\("EC"\)|KeyProperties\.KEY_ALGORITHM_EC\b|kSecAttrKeyTypeECSECPrimeRandom|P(256|384|521)\.Signing|(p256|p384|k256)::ecdsa|EcdsaKeyPair::`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "ECDSA (Elliptic Curve Digital Signature Algorithm) is vulnerable to quantum attacks",
+			Recommendation:     "Replace with quantum-resistant signature schemes like ML-DSA or SLH-DSA",
+			NISTAlgorithmID:    "ECDSA-P256",
+			RemediationExample: mldsaRemediation,
+		},
+		{
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "ECDSA",
+			Method:             "Configuration",
+			Pattern:            `secp256r1|prime256v1|P-256|NIST P-256|ecdsa_curve\s*=\s*"P256"`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "ECDSA with P-256 curve is vulnerable to quantum attacks",
+			Recommendation:     "Replace with ML-DSA (CRYSTALS-Dilithium) for quantum-resistant signatures",
+			NISTAlgorithmID:    "ECDSA-P256",
+			RemediationExample: mldsaRemediation,
+		},
+		{
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "ECDSA",
+			Method:             "Configuration",
+			Pattern:            `secp384r1|P-384|NIST P-384|ecdsa_curve\s*=\s*"P384"`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "ECDSA with P-384 curve is vulnerable to quantum attacks",
+			Recommendation:     "Replace with ML-DSA (CRYSTALS-Dilithium) for quantum-resistant signatures",
+			NISTAlgorithmID:    "ECDSA-P384",
+			RemediationExample: mldsaRemediation,
+		},
+		{
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "ECDSA",
+			Method:             "Configuration",
+			Pattern:            `secp521r1|P-521|NIST P-521|ecdsa_curve\s*=\s*"P521"`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "ECDSA with P-521 curve is vulnerable to quantum attacks",
+			Recommendation:     "Replace with ML-DSA (CRYSTALS-Dilithium) for quantum-resistant signatures",
+			NISTAlgorithmID:    "ECDSA-P521",
+			RemediationExample: mldsaRemediation,
 		},
 
 		// EdDSA Detection Rules (NIST Table 2 - Quantum-Vulnerable)
 		{
-			AlgorithmType:     "PublicKey",
-			AlgorithmName:     "EdDSA",
-			Method:            "Function Name",
-			Pattern:           `EdDSA|Ed25519|Ed448|SigningKey\.generate\(curve=Ed25519|ed25519\.Sign|ed25519\.GenerateKey`,
-			RiskLevel:         "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "EdDSA (Edwards-curve Digital Signature Algorithm) is vulnerable to quantum attacks",
-			Recommendation:    "Replace with ML-DSA (CRYSTALS-Dilithium) for quantum-resistant signatures",
-			NISTAlgorithmID:   "EdDSA-Ed25519",
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "EdDSA",
+			Method:             "Function Name",
+			Pattern:            `EdDSA|Ed25519|Ed448|SigningKey\.generate\(curve=Ed25519|ed25519\.Sign|ed25519\.GenerateKey|ed25519_dalek|Curve25519\.Signing`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "EdDSA (Edwards-curve Digital Signature Algorithm) is vulnerable to quantum attacks",
+			Recommendation:     "Replace with ML-DSA (CRYSTALS-Dilithium) for quantum-resistant signatures",
+			NISTAlgorithmID:    "EdDSA-Ed25519",
+			RemediationExample: mldsaRemediation,
 		},
 
 		// ECC General Detection
@@ -127,6 +232,7 @@ func buildDetectionRules() []DetectionRule {
 			Pattern:           `ECDSA\.sign|ECCCipher|SigningKey\.generate|ec\.generate_private_key`,
 			RiskLevel:         "High",
 			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
 			Description:       "Elliptic Curve Cryptography is vulnerable to quantum attacks using a variant of Shor's algorithm",
 			Recommendation:    "Replace with quantum-resistant ML-DSA (CRYSTALS-Dilithium) or SLH-DSA (SPHINCS+) for digital signatures",
 			NISTAlgorithmID:   "ECDSA-P256",
@@ -135,9 +241,10 @@ func buildDetectionRules() []DetectionRule {
 			AlgorithmType:     "PublicKey",
 			AlgorithmName:     "ECC",
 			Method:            "Import Statement",
-			Pattern:           `from cryptography\.hazmat\.primitives\.asymmetric import ec|from ecdsa import SigningKey`,
+			Pattern:           `from cryptography\.hazmat\.primitives\.asymmetric import ec|from ecdsa import SigningKey|use (p256|p384|k256)::`,
 			RiskLevel:         "High",
 			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
 			Description:       "Elliptic Curve Cryptography libraries are vulnerable to quantum attacks",
 			Recommendation:    "Replace with post-quantum signature schemes like ML-DSA or SLH-DSA",
 			NISTAlgorithmID:   "ECDSA-P256",
@@ -151,6 +258,7 @@ func buildDetectionRules() []DetectionRule {
 			Pattern:           `DHParameterSpec|DHGenParameterSpec|DiffieHellmanGroup|createDiffieHellman`,
 			RiskLevel:         "High",
 			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
 			Description:       "Diffie-Hellman key exchange is vulnerable to quantum attacks via the discrete logarithm problem",
 			Recommendation:    "Replace with ML-KEM (CRYSTALS-Kyber) for quantum-resistant key exchange",
 			NISTAlgorithmID:   "DH-2048",
@@ -162,6 +270,7 @@ func buildDetectionRules() []DetectionRule {
 			Pattern:           `import javax.crypto.spec.DHParameterSpec|const dh = crypto.createDiffieHellman`,
 			RiskLevel:         "High",
 			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
 			Description:       "Diffie-Hellman library imports indicate vulnerable key exchange methods",
 			Recommendation:    "Replace with post-quantum key encapsulation mechanisms like ML-KEM",
 			NISTAlgorithmID:   "DH-2048",
@@ -172,9 +281,10 @@ func buildDetectionRules() []DetectionRule {
 			AlgorithmType:     "PublicKey",
 			AlgorithmName:     "ECDH",
 			Method:            "Function Name",
-			Pattern:           `ECDH|ECDiffieHellman|ecdh\.ECDH|crypto\.createECDH|X25519|X448`,
+			Pattern:           `ECDH|ECDiffieHellman|ecdh\.ECDH|crypto\.createECDH|X25519|X448|x25519_dalek|(p256|p384)::ecdh|ring::agreement|P(256|384|521)\.KeyAgreement|Curve25519\.KeyAgreement`,
 			RiskLevel:         "High",
 			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
 			Description:       "Elliptic Curve Diffie-Hellman is vulnerable to quantum attacks on elliptic curve discrete logarithm",
 			Recommendation:    "Replace with ML-KEM (CRYSTALS-Kyber) for quantum-resistant key exchange",
 			NISTAlgorithmID:   "ECDH-P256",
@@ -188,6 +298,7 @@ func buildDetectionRules() []DetectionRule {
 			Pattern:           `DSA|DSAPublicKey|DSAPrivateKey|KeyPairGenerator\.getInstance\("DSA"|dsa\.GenerateParameters|dsa\.Sign`,
 			RiskLevel:         "High",
 			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
 			Description:       "DSA (Digital Signature Algorithm) is vulnerable to quantum attacks on discrete logarithm problem",
 			Recommendation:    "Replace with ML-DSA (CRYSTALS-Dilithium) or SLH-DSA (SPHINCS+) for quantum-resistant signatures",
 			NISTAlgorithmID:   "RSA-2048", // DSA typically has similar security to RSA-2048
@@ -201,6 +312,7 @@ func buildDetectionRules() []DetectionRule {
 			Pattern:           `AES\.encrypt|AESCipher|Cipher\.getInstance\("AES|crypto\.createCipheriv\('aes-128-cbc'|aes128`,
 			RiskLevel:         "Medium",
 			VulnerabilityType: "Grover's Algorithm",
+			CWE:               []string{CWEInadequateStrength},
 			Description:       "AES-128 provides only 64 bits of security against quantum attacks using Grover's algorithm",
 			Recommendation:    "Upgrade to AES-256 which provides adequate security against known quantum attacks",
 			NISTAlgorithmID:   "AES-128",
@@ -236,6 +348,7 @@ func buildDetectionRules() []DetectionRule {
 			Pattern:           `DES\.encrypt|DESCipher|Cipher\.getInstance\("DES|crypto\.createCipheriv\('des'|des\.New\(\)`,
 			RiskLevel:         "High",
 			VulnerabilityType: "Grover's Algorithm + Broken",
+			CWE:               []string{CWEBrokenCrypto},
 			Description:       "DES is cryptographically broken with only 56-bit keys, providing minimal security against any attack",
 			Recommendation:    "Replace with AES-256 immediately. DES should never be used in production",
 			NISTAlgorithmID:   "", // DES is not in NIST IR 8547 tables
@@ -247,33 +360,62 @@ func buildDetectionRules() []DetectionRule {
 			Pattern:           `3DES|TripleDES|DESede|Cipher\.getInstance\("DESede|crypto\.createCipheriv\('des-ede3'|des3\.New\(\)`,
 			RiskLevel:         "High",
 			VulnerabilityType: "Grover's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
 			Description:       "3DES provides inadequate security with effective 112-bit strength, vulnerable to quantum attacks",
 			Recommendation:    "Replace with AES-256. 3DES is deprecated and should not be used",
 			NISTAlgorithmID:   "", // 3DES is deprecated
 		},
 
-		// Hash Functions (NIST Table 7)
+		// Insecure Cipher Modes (classical misuse, independent of quantum risk)
 		{
-			AlgorithmType:     "Hash",
-			AlgorithmName:     "MD5",
-			Method:            "Function Name",
-			Pattern:           `MD5|MessageDigest\.getInstance\("MD5"\)|hashlib\.md5|crypto\.createHash\('md5'\)|md5\.New\(\)|MD5CryptoServiceProvider|md5_hex|md5sum`,
+			AlgorithmType:     "SymmetricKey",
+			AlgorithmName:     "AES-ECB",
+			Method:            "Cipher Mode",
+			Pattern:           `AES/ECB|Cipher\.getInstance\("AES"\)|MODE_ECB|modes\.ECB\(|aes-(128|192|256)-ecb|NewECBEncrypter|NewECBDecrypter`,
 			RiskLevel:         "High",
-			VulnerabilityType: "Grover's Algorithm + Broken",
-			Description:       "MD5 is cryptographically broken and provides only 64 bits of security against quantum attacks",
-			Recommendation:    "Replace with SHA-256 or SHA-3 for non-cryptographic uses, or BLAKE3 for performance-critical applications",
-			NISTAlgorithmID:   "", // MD5 is not in NIST IR 8547
+			VulnerabilityType: "Insecure Mode",
+			CWE:               []string{CWEBrokenCrypto, CWERiskyImplementation},
+			Description:       "ECB mode encrypts identical plaintext blocks to identical ciphertext blocks, leaking data patterns (Java's Cipher.getInstance(\"AES\") defaults to ECB)",
+			Recommendation:    "Use an authenticated mode such as AES-GCM or ChaCha20-Poly1305 with a unique nonce per message",
 		},
 		{
-			AlgorithmType:     "Hash",
-			AlgorithmName:     "SHA-1",
-			Method:            "Function Name",
-			Pattern:           `SHA1|MessageDigest\.getInstance\("SHA-1"\)|hashlib\.sha1|crypto\.createHash\('sha1'\)|sha1\.New\(\)|SHA1CryptoServiceProvider|sha1_hex|sha1sum`,
+			AlgorithmType:     "SymmetricKey",
+			AlgorithmName:     "Static IV",
+			Method:            "Cipher Initialization",
+			Pattern:           `IvParameterSpec\(new byte\[\]\s*\{|IvParameterSpec\("[^"]*"\.getBytes|GCMParameterSpec\(\d+,\s*new byte\[\]\s*\{|MODE_(CBC|CFB|OFB|CTR|GCM),\s*(iv=|nonce=)?b['"]|modes\.(CBC|CFB|OFB|CTR|GCM)\(b['"]|New(CBC|CFB|OFB)(Encrypter|Decrypter)?\([^,]+,\s*\[\]byte\(|createCipheriv\([^,]+,[^,]+,\s*['"]`,
 			RiskLevel:         "High",
-			VulnerabilityType: "Grover's Algorithm + Broken",
-			Description:       "SHA-1 is cryptographically broken and provides only 80 bits of security against quantum attacks",
-			Recommendation:    "Replace with SHA-256 minimum, or SHA-3 for new applications",
-			NISTAlgorithmID:   "SHA-1",
+			VulnerabilityType: "Insecure Mode",
+			CWE:               []string{CWEWeakIV},
+			Description:       "Cipher initialized with a hardcoded IV/nonce; reusing an IV breaks confidentiality (and authenticity for GCM)",
+			Recommendation:    "Generate a fresh random IV/nonce per encryption using a CSPRNG and store it alongside the ciphertext",
+		},
+
+		// Hash Functions (NIST Table 7)
+		{
+			AlgorithmType:      "Hash",
+			AlgorithmName:      "MD5",
+			Method:             "Function Name",
+			Pattern:            `MD5|MessageDigest\.getInstance\("MD5"\)|hashlib\.md5|crypto\.createHash\('md5'\)|md5\.New\(\)|MD5CryptoServiceProvider|md5_hex|md5sum`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Grover's Algorithm + Broken",
+			CWE:                []string{CWEBrokenCrypto, CWEWeakHash},
+			Description:        "MD5 is cryptographically broken and provides only 64 bits of security against quantum attacks",
+			Recommendation:     "Replace with SHA-256 or SHA-3 for non-cryptographic uses, or BLAKE3 for performance-critical applications",
+			NISTAlgorithmID:    "", // MD5 is not in NIST IR 8547
+			RemediationExample: sha256Remediation,
+		},
+		{
+			AlgorithmType:      "Hash",
+			AlgorithmName:      "SHA-1",
+			Method:             "Function Name",
+			Pattern:            `SHA1|MessageDigest\.getInstance\("SHA-1"\)|hashlib\.sha1|crypto\.createHash\('sha1'\)|sha1\.New\(\)|SHA1CryptoServiceProvider|sha1_hex|sha1sum`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Grover's Algorithm + Broken",
+			CWE:                []string{CWEBrokenCrypto, CWEWeakHash},
+			Description:        "SHA-1 is cryptographically broken and provides only 80 bits of security against quantum attacks",
+			Recommendation:     "Replace with SHA-256 minimum, or SHA-3 for new applications",
+			NISTAlgorithmID:    "SHA-1",
+			RemediationExample: sha256Remediation,
 		},
 		{
 			AlgorithmType:     "Hash",
@@ -366,6 +508,354 @@ func buildDetectionRules() []DetectionRule {
 			NISTAlgorithmID:   "SLH-DSA-SHA2-128f",
 		},
 
+		// Hybrid Key Establishment (classical + ML-KEM)
+		{
+			AlgorithmType:     "Hybrid",
+			AlgorithmName:     "X25519MLKEM768",
+			Method:            "Configuration",
+			Pattern:           x25519MLKEM768Pattern,
+			RiskLevel:         "Low",
+			VulnerabilityType: "Quantum-Resistant (Hybrid)",
+			Description:       "X25519MLKEM768 combines X25519 with ML-KEM-768, remaining secure as long as either component is unbroken",
+			Recommendation:    "Hybrid key exchange is the recommended transition configuration. Keep enabled and plan for pure ML-KEM once ecosystem support matures",
+			NISTAlgorithmID:   "X25519MLKEM768",
+		},
+		{
+			AlgorithmType:     "Hybrid",
+			AlgorithmName:     "SecP256r1MLKEM768",
+			Method:            "Configuration",
+			Pattern:           secP256r1MLKEM768Pattern,
+			RiskLevel:         "Low",
+			VulnerabilityType: "Quantum-Resistant (Hybrid)",
+			Description:       "SecP256r1MLKEM768 combines ECDH P-256 with ML-KEM-768, remaining secure as long as either component is unbroken",
+			Recommendation:    "Hybrid key exchange is the recommended transition configuration. Keep enabled and plan for pure ML-KEM once ecosystem support matures",
+			NISTAlgorithmID:   "SecP256r1MLKEM768",
+		},
+		{
+			AlgorithmType:     "Hybrid",
+			AlgorithmName:     "SecP384r1MLKEM1024",
+			Method:            "Configuration",
+			Pattern:           secP384r1MLKEM1024Pattern,
+			RiskLevel:         "Low",
+			VulnerabilityType: "Quantum-Resistant (Hybrid)",
+			Description:       "SecP384r1MLKEM1024 combines ECDH P-384 with ML-KEM-1024, remaining secure as long as either component is unbroken",
+			Recommendation:    "Hybrid key exchange is the recommended transition configuration for high-security environments",
+			NISTAlgorithmID:   "SecP384r1MLKEM1024",
+		},
+
+		// Deprecated protocol versions pinned in application code
+		{
+			AlgorithmType:     "Protocol",
+			AlgorithmName:     "SSL 3.0",
+			Method:            "Configuration",
+			Pattern:           `SSLContext\.getInstance\("SSLv3"\)|ssl\.PROTOCOL_SSLv3|tls\.VersionSSL30|SslProtocols\.Ssl3|SSLv3_method`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Protocol Weakness",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "Code pins SSL 3.0, which is broken (POODLE) and prohibited by RFC 7568",
+			Recommendation:    "Require TLS 1.2 or later, preferably TLS 1.3, and let the library negotiate the version",
+		},
+		{
+			AlgorithmType:     "Protocol",
+			AlgorithmName:     "TLS 1.0",
+			Method:            "Configuration",
+			Pattern:           `SSLContext\.getInstance\("TLSv1"\)|ssl\.PROTOCOL_TLSv1\b|TLSVersion\.TLSv1\b|tls\.VersionTLS10|SslProtocols\.Tls\b|TLSv1_method`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Protocol Weakness",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "Code pins or allows TLS 1.0, which is deprecated by RFC 8996",
+			Recommendation:    "Require TLS 1.2 or later, preferably TLS 1.3, and let the library negotiate the version",
+		},
+		{
+			AlgorithmType:     "Protocol",
+			AlgorithmName:     "TLS 1.1",
+			Method:            "Configuration",
+			Pattern:           `SSLContext\.getInstance\("TLSv1\.1"\)|ssl\.PROTOCOL_TLSv1_1|TLSVersion\.TLSv1_1|tls\.VersionTLS11|SslProtocols\.Tls11|TLSv1_1_method`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Protocol Weakness",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "Code pins or allows TLS 1.1, which is deprecated by RFC 8996",
+			Recommendation:    "Require TLS 1.2 or later, preferably TLS 1.3, and let the library negotiate the version",
+		},
+
+		// JWT signing algorithms and misuse (jsonwebtoken, PyJWT, jjwt, java-jwt, golang-jwt, Nimbus, ruby-jwt)
+		{
+			AlgorithmType:     "Signature",
+			AlgorithmName:     "RSA",
+			Method:            "JWT Algorithm",
+			Pattern:           `['"](RS|PS)(256|384|512)['"]|SignatureAlgorithm\.(RS|PS)(256|384|512)\b|Algorithm\.RSA(256|384|512)\(|SigningMethod(RS|PS)(256|384|512)\b|JWSAlgorithm\.(RS|PS)(256|384|512)\b`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "JWTs signed with RS* or PS* use RSA signatures, which are vulnerable to quantum attacks",
+			Recommendation:    "Keep token lifetimes short and plan migration to ML-DSA signed tokens once JOSE registers them",
+			NISTAlgorithmID:   "RSA-2048",
+		},
+		{
+			AlgorithmType:     "Signature",
+			AlgorithmName:     "ECDSA",
+			Method:            "JWT Algorithm",
+			Pattern:           `['"]ES(256|384|512)K?['"]|SignatureAlgorithm\.ES(256|384|512)\b|Algorithm\.ECDSA(256|384|512)\(|SigningMethodES(256|384|512)\b|JWSAlgorithm\.ES(256|384|512)\b`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "JWTs signed with ES* use ECDSA signatures, which are vulnerable to quantum attacks",
+			Recommendation:    "Keep token lifetimes short and plan migration to ML-DSA signed tokens once JOSE registers them",
+			NISTAlgorithmID:   "ECDSA-P256",
+		},
+		{
+			AlgorithmType:     "Protocol",
+			AlgorithmName:     "JWT",
+			Method:            "JWT Algorithm",
+			Pattern:           `(?i)\balg(orithm)?s?['"]?\s*[:=]\s*\[?\s*['"]none['"]|SigningMethodNone|UnsafeAllowNoneSignatureType|SignatureAlgorithm\.NONE\b|Algorithm\.none\(\)|JWT\.encode\([^)]*['"]none['"]`,
+			RiskLevel:         "Critical",
+			VulnerabilityType: "JWT Misuse",
+			CWE:               []string{CWEImproperSignatureVerification},
+			Description:       "JWT issued or accepted with alg \"none\", so tokens carry no signature and anyone can forge them",
+			Recommendation:    "Never issue or accept unsigned tokens; pin the expected algorithm, e.g. algorithms: ['ES256'], when verifying",
+		},
+
+		// Dockerfile and shell script rules, see Scanner.ScanScripts
+		{
+			AlgorithmType:     "Library",
+			AlgorithmName:     "OpenSSL",
+			Method:            "Package Pin",
+			Pattern:           `\bopenssl[=-]1\.[01]\.|\blibssl1\.[01]\b|\blibssl\.so\.1\.[01]\b`,
+			RiskLevel:         "High",
+			VulnerabilityType: "End of Life",
+			CWE:               []string{CWEUnmaintainedComponent},
+			Description:       "Pinned OpenSSL 1.x release is end-of-life: it gets no security fixes and has no post-quantum algorithms",
+			Recommendation:    "Upgrade to OpenSSL 3.5 or later, which adds ML-KEM, ML-DSA and SLH-DSA",
+		},
+		{
+			AlgorithmType:     "Library",
+			AlgorithmName:     "OpenSSL",
+			Method:            "Package Pin",
+			Pattern:           `\bopenssl[=-]3\.[0-4]\.`,
+			RiskLevel:         "Medium",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       "Pinned OpenSSL 3.x release predates ML-KEM and ML-DSA support, so its TLS key exchange stays quantum-vulnerable",
+			Recommendation:    "Move the pin to OpenSSL 3.5 or later to enable the X25519MLKEM768 hybrid group",
+		},
+		{
+			AlgorithmType:     "Protocol",
+			AlgorithmName:     "TLS",
+			Method:            "Cipher Flag",
+			Pattern:           `--?ciphers?[=\s]+['"]?([^'"\s]*:)?(RC4|DES|3DES|NULL|aNULL|eNULL|EXP|EXPORT)\b`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Broken",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "Command-line cipher list enables broken ciphers (RC4, DES, NULL or export suites)",
+			Recommendation:    "Drop the cipher flag to use the library defaults, or allow only AEAD suites such as ECDHE+AESGCM",
+		},
+		{
+			AlgorithmType:     "Library",
+			AlgorithmName:     "OpenSSL",
+			Method:            "Environment",
+			Pattern:           `\bOPENSSL_CONF=|\b(ENV|export)\s+OPENSSL_CONF\b`,
+			RiskLevel:         "Low",
+			VulnerabilityType: "Configuration",
+			Description:       "OpenSSL configuration is overridden at startup, which can change protocol versions, ciphers and groups for every TLS client in the image",
+			Recommendation:    "Review the file's MinProtocol, CipherString and Groups settings; include X25519MLKEM768 in Groups on OpenSSL 3.5+",
+		},
+		{
+			AlgorithmType:     "Protocol",
+			AlgorithmName:     "TLS",
+			Method:            "Environment",
+			Pattern:           `\b(ENV|export)\s+[A-Z0-9_]*CIPHER[A-Z0-9_]*[=\s]`,
+			RiskLevel:         "Low",
+			VulnerabilityType: "Configuration",
+			Description:       "Cipher suites are set through an environment variable, overriding the application's TLS defaults",
+			Recommendation:    "Check the list for legacy suites and prefer the library defaults",
+		},
+		{
+			AlgorithmType:     "PublicKey",
+			AlgorithmName:     "RSA",
+			Method:            "Shell Command",
+			Pattern:           `\bssh-keygen\b.*-t\s*['"]?rsa\b`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "SSH key generated with ssh-keygen -t rsa is vulnerable to quantum attacks",
+			Recommendation:    "Use OpenSSH 9.9+ for the mlkem768x25519-sha256 hybrid key exchange, and plan to rotate RSA keys once post-quantum SSH signatures are available",
+			NISTAlgorithmID:   "RSA-2048",
+		},
+		{
+			AlgorithmType:     "PublicKey",
+			AlgorithmName:     "DSA",
+			Method:            "Shell Command",
+			Pattern:           `\bssh-keygen\b.*-t\s*['"]?dsa\b`,
+			RiskLevel:         "Critical",
+			VulnerabilityType: "Shor's Algorithm + Broken",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "SSH DSA keys are limited to 1024 bits, disabled by default since OpenSSH 7.0 and vulnerable to quantum attacks",
+			Recommendation:    "Replace with an Ed25519 key now and plan migration to post-quantum SSH signatures",
+		},
+		{
+			AlgorithmType:     "PublicKey",
+			AlgorithmName:     "ECDSA",
+			Method:            "Shell Command",
+			Pattern:           `\bssh-keygen\b.*-t\s*['"]?ecdsa\b`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "SSH key generated with ssh-keygen -t ecdsa is vulnerable to quantum attacks",
+			Recommendation:    "Use OpenSSH 9.9+ for the mlkem768x25519-sha256 hybrid key exchange, and plan to rotate keys once post-quantum SSH signatures are available",
+			NISTAlgorithmID:   "ECDSA-P256",
+		},
+		{
+			AlgorithmType:     "PublicKey",
+			AlgorithmName:     "EdDSA",
+			Method:            "Shell Command",
+			Pattern:           `\bssh-keygen\b.*-t\s*['"]?ed25519\b`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Shor's Algorithm",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "SSH key generated with ssh-keygen -t ed25519 is vulnerable to quantum attacks",
+			Recommendation:    "Use OpenSSH 9.9+ for the mlkem768x25519-sha256 hybrid key exchange, and plan to rotate keys once post-quantum SSH signatures are available",
+			NISTAlgorithmID:   "EdDSA-Ed25519",
+		},
+
+		// Java getInstance calls split across lines, e.g. Cipher.getInstance(
+		// "DES/CBC/PKCS5Padding"), which the single-line rules can't see
+		{
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "RSA",
+			Method:             "Function Name",
+			Pattern:            `(?:KeyPairGenerator|Cipher|Signature)\.getInstance\(\s*\n\s*"(?:RSA|\w+withRSA)`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "RSA encryption is vulnerable to quantum attacks using Shor's algorithm, which can factor large integers in polynomial time",
+			Recommendation:     "Replace with quantum-resistant algorithm ML-KEM (CRYSTALS-Kyber) for key encapsulation or consider hybrid approaches",
+			NISTAlgorithmID:    "RSA-2048",
+			RemediationExample: mlkemRemediation,
+			MultiLine:          true,
+		},
+		{
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "ECDSA",
+			Method:             "Function Name",
+			Pattern:            `(?:KeyPairGenerator|Signature)\.getInstance\(\s*\n\s*"(?:EC|\w+withECDSA)"`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "ECDSA (Elliptic Curve Digital Signature Algorithm) is vulnerable to quantum attacks",
+			Recommendation:     "Replace with quantum-resistant signature schemes like ML-DSA or SLH-DSA",
+			NISTAlgorithmID:    "ECDSA-P256",
+			RemediationExample: mldsaRemediation,
+			MultiLine:          true,
+		},
+		{
+			AlgorithmType:     "SymmetricKey",
+			AlgorithmName:     "DES",
+			Method:            "Function Name",
+			Pattern:           `Cipher\.getInstance\(\s*\n\s*"DES[/"]`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Grover's Algorithm + Broken",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "DES is cryptographically broken with only 56-bit keys, providing minimal security against any attack",
+			Recommendation:    "Replace with AES-256 immediately. DES should never be used in production",
+			MultiLine:         true,
+		},
+		{
+			AlgorithmType:     "SymmetricKey",
+			AlgorithmName:     "AES-ECB",
+			Method:            "Cipher Mode",
+			Pattern:           `Cipher\.getInstance\(\s*\n\s*"AES"`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Insecure Mode",
+			CWE:               []string{CWEBrokenCrypto, CWERiskyImplementation},
+			Description:       "ECB mode encrypts identical plaintext blocks to identical ciphertext blocks, leaking data patterns (Java's Cipher.getInstance(\"AES\") defaults to ECB)",
+			Recommendation:    "Use an authenticated mode such as AES-GCM or ChaCha20-Poly1305 with a unique nonce per message",
+			MultiLine:         true,
+		},
+
+		// .NET System.Security.Cryptography types, including the obsolete
+		// *CryptoServiceProvider, *Managed and *Cng classes (SYSLIB0021). Hash
+		// classes such as SHA1Managed are covered by the hash rules above.
+		{
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "RSA",
+			Method:             "Function Name",
+			Pattern:            `\bRSACryptoServiceProvider\b|\bRSACng\b|\bRSAOpenSsl\b|\bRSA\.Create\(|\bRSAPKCS1Signature(?:Formatter|Deformatter)\b|\bRSAOAEPKeyExchange(?:Formatter|Deformatter)\b`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "RSA encryption is vulnerable to quantum attacks using Shor's algorithm, which can factor large integers in polynomial time",
+			Recommendation:     "Replace with quantum-resistant algorithm ML-KEM (CRYSTALS-Kyber) for key encapsulation or consider hybrid approaches",
+			NISTAlgorithmID:    "RSA-2048",
+			RemediationExample: mlkemRemediation,
+		},
+		{
+			AlgorithmType:      "PublicKey",
+			AlgorithmName:      "ECDSA",
+			Method:             "Function Name",
+			Pattern:            `\bECDsa\.Create\(|\bECDsaCng\b|\bECDsaOpenSsl\b`,
+			RiskLevel:          "High",
+			VulnerabilityType:  "Shor's Algorithm",
+			CWE:                []string{CWEBrokenCrypto},
+			Description:        "ECDSA (Elliptic Curve Digital Signature Algorithm) is vulnerable to quantum attacks",
+			Recommendation:     "Replace with quantum-resistant signature schemes like ML-DSA or SLH-DSA",
+			NISTAlgorithmID:    "ECDSA-P256",
+			RemediationExample: mldsaRemediation,
+		},
+		{
+			AlgorithmType:     "SymmetricKey",
+			AlgorithmName:     "DES",
+			Method:            "Function Name",
+			Pattern:           `\bDESCryptoServiceProvider\b|\bDES\.Create\(`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Grover's Algorithm + Broken",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "DES is cryptographically broken with only 56-bit keys, providing minimal security against any attack",
+			Recommendation:    "Replace with AES-256 immediately. DES should never be used in production",
+		},
+		{
+			AlgorithmType:     "SymmetricKey",
+			AlgorithmName:     "RC2",
+			Method:            "Function Name",
+			Pattern:           `\bRC2CryptoServiceProvider\b|\bRC2\.Create\(`,
+			RiskLevel:         "High",
+			VulnerabilityType: "Classical Weakness",
+			CWE:               []string{CWEBrokenCrypto},
+			Description:       "RC2 is a legacy 64-bit block cipher with related-key weaknesses and is broken independently of quantum attacks",
+			Recommendation:    "Replace with AES-256 in GCM mode (AesGcm)",
+		},
+		{
+			AlgorithmType:     "SymmetricKey",
+			AlgorithmName:     "AES-256",
+			Method:            "Function Name",
+			Pattern:           `\bAes\.Create\(|\bAesManaged\b|\bAesCryptoServiceProvider\b|\bAesCng\b|\bRijndaelManaged\b|\bAesGcm\b|\bAesCcm\b`,
+			RiskLevel:         "Low",
+			VulnerabilityType: "Grover's Algorithm",
+			Description:       ".NET AES defaults to 256-bit keys, which provide 128 bits of security against quantum attacks",
+			Recommendation:    "Keep 256-bit keys; replace the obsolete AesManaged, AesCryptoServiceProvider and RijndaelManaged classes with Aes.Create() or AesGcm",
+			NISTAlgorithmID:   "AES-256",
+		},
+		{
+			AlgorithmType:     "PostQuantum",
+			AlgorithmName:     "ML-KEM",
+			Method:            "Function Name",
+			Pattern:           `\bMLKem(?:Algorithm)?\.`,
+			RiskLevel:         "Low",
+			VulnerabilityType: "Quantum-Resistant",
+			Description:       "ML-KEM (Module-Lattice-Based Key-Encapsulation Mechanism) is NIST's standardized version of Kyber",
+			Recommendation:    "NIST-approved quantum-resistant key encapsulation mechanism",
+			NISTAlgorithmID:   "ML-KEM-768",
+		},
+		{
+			AlgorithmType:     "PostQuantum",
+			AlgorithmName:     "ML-DSA",
+			Method:            "Function Name",
+			Pattern:           `\bMLDsa(?:Algorithm)?\.`,
+			RiskLevel:         "Low",
+			VulnerabilityType: "Quantum-Resistant",
+			Description:       "ML-DSA (Module-Lattice-Based Digital Signature Algorithm) is NIST's standardized version of Dilithium",
+			Recommendation:    "NIST-approved quantum-resistant digital signature algorithm",
+			NISTAlgorithmID:   "ML-DSA-65",
+		},
+
 		// Additional patterns for specific implementations
 		{
 			AlgorithmType:     "SymmetricKey",
@@ -401,4 +891,4 @@ func buildDetectionRules() []DetectionRule {
 			NISTAlgorithmID:   "", // BLAKE3 not in NIST tables
 		},
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,263 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// dhMinimumBits is the smallest finite-field Diffie-Hellman group NIST SP 800-131A
+// allows. Smaller groups are breakable today: 512-bit export groups fell to Logjam,
+// and a nation state can precompute the discrete logs of a 1024-bit prime.
+const dhMinimumBits = 2048
+
+// dhParameterBlocks are the PEM block types holding Diffie-Hellman parameters:
+// PKCS#3, as written by openssl dhparam, and X9.42. Both start with the prime and
+// the generator.
+var dhParameterBlocks = map[string]bool{"DH PARAMETERS": true, "X9.42 DH PARAMETERS": true}
+
+// dhParameters is the start of the PKCS#3 DHParameter and X9.42 DomainParameters
+// sequences; the fields that follow are ignored
+type dhParameters struct {
+	P *big.Int
+	G *big.Int
+}
+
+// modpGroups name the MODP groups by size
+var modpGroups = map[int]string{
+	768:  "RFC 2409 Oakley group 1",
+	1024: "RFC 2409 Oakley group 2",
+	1536: "RFC 3526 MODP group 5",
+	2048: "RFC 3526 MODP group 14",
+	3072: "RFC 3526 MODP group 15",
+	4096: "RFC 3526 MODP group 16",
+	6144: "RFC 3526 MODP group 17",
+	8192: "RFC 3526 MODP group 18",
+}
+
+// wellKnownDHGroup names the standard group whose published prime is p, or returns
+// "" for a prime generated on its own
+func wellKnownDHGroup(p *big.Int) string {
+	for group, prime := range wellKnownDHPrimes {
+		if p.Cmp(prime) == 0 {
+			return group
+		}
+	}
+	return ""
+}
+
+// analyzeDHParameters reports the Diffie-Hellman parameter blocks in PEM content,
+// such as a dhparam.pem, with the size of their prime and the standard group it
+// belongs to. Each block is reported on the line it starts on.
+func analyzeDHParameters(file, content string) []Result {
+	var results []Result
+
+	data := []byte(content)
+	rest := data
+	for {
+		block, remaining := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		start := len(data) - len(rest) + bytes.Index(rest, []byte("-----BEGIN"))
+		rest = remaining
+		if !dhParameterBlocks[block.Type] {
+			continue
+		}
+
+		var params dhParameters
+		if _, err := asn1.Unmarshal(block.Bytes, &params); err != nil || params.P == nil {
+			continue
+		}
+		line := bytes.Count(data[:start], []byte("\n")) + 1
+		subject := fmt.Sprintf("%s block", block.Type)
+		results = append(results, dhGroupResult(file, line, "DH Parameter Analysis", subject, params.P.BitLen(), wellKnownDHGroup(params.P)))
+	}
+
+	return results
+}
+
+// dhGroupResult builds the finding for a Diffie-Hellman group of bits, described as
+// subject and named group when it is a standard one. Groups below dhMinimumBits are
+// reported for their classical weakness as well as the quantum risk all of them carry.
+func dhGroupResult(file string, line int, method, subject string, bits int, group string) Result {
+	name := fmt.Sprintf("%d-bit Diffie-Hellman group", bits)
+	if group != "" {
+		name += fmt.Sprintf(" (%s)", group)
+	}
+	result := Result{
+		File:              file,
+		Algorithm:         "DH",
+		Type:              TypePublicKey,
+		Line:              line,
+		Method:            method,
+		KeySize:           bits,
+		Risk:              "High",
+		VulnerabilityType: "Shor's Algorithm",
+		Description:       fmt.Sprintf("%s uses a %s, which is vulnerable to quantum attacks", subject, name),
+		Recommendation:    "Plan migration to ML-KEM or a hybrid such as X25519MLKEM768",
+		Purpose:           PurposeKeyEncapsulation,
+	}
+	if bits < dhMinimumBits {
+		result.Risk = "Critical"
+		result.VulnerabilityType = "Shor's Algorithm + Broken"
+		result.CWE = []string{CWEInadequateStrength}
+		result.Description = fmt.Sprintf("%s uses a %s, below the %d-bit minimum and breakable today", subject, name, dhMinimumBits)
+		if group != "" {
+			result.Description += "; its prime is shared by many servers, so one precomputation breaks them all (Logjam)"
+		}
+		result.Description += ", and vulnerable to quantum attacks"
+		result.Recommendation = "Use an RFC 7919 ffdhe group of at least 2048 bits or ECDHE now, and plan migration to ML-KEM or a hybrid such as X25519MLKEM768"
+	}
+	populateNISTFields(&result, dhNISTAlgorithmID(bits))
+	return result
+}
+
+// dhNISTAlgorithmID returns the NIST IR 8547 entry for a DH group size, if it has one
+func dhNISTAlgorithmID(bits int) string {
+	if nistID := fmt.Sprintf("DH-%d", bits); GetNISTInfo(nistID) != nil {
+		return nistID
+	}
+	return ""
+}
+
+// dhGenerationCall is a call generating Diffie-Hellman parameters or keys, and
+// where the size of the group is passed
+type dhGenerationCall struct {
+	Subject string
+	Pattern *regexp.Regexp // Matches the call, up to its opening parenthesis unless SizeCall is set
+
+	// SizeCall is the call the size is passed to when it is not Pattern, searched
+	// for on nearby lines, e.g. the initialize after KeyPairGenerator.getInstance("DH")
+	SizeCall *regexp.Regexp
+	// SizeArg is the position of the size among the call's arguments, or -1
+	SizeArg int
+	// SizeKeyword matches the size passed by name, e.g. key_size=
+	SizeKeyword *regexp.Regexp
+}
+
+// dhGenerationCalls generate Diffie-Hellman parameters of a given size in the common
+// languages. The existing rules report these as quantum-vulnerable DH already, so
+// only groups below dhMinimumBits are reported here.
+var dhGenerationCalls = []dhGenerationCall{
+	{Subject: "dh.generate_parameters", Pattern: regexp.MustCompile(`\bgenerate_parameters\(`), SizeArg: 1, SizeKeyword: regexp.MustCompile(`\bkey_size\s*=\s*`)},
+	{Subject: "crypto.createDiffieHellman", Pattern: regexp.MustCompile(`\bcreateDiffieHellman\(`), SizeArg: 0},
+	{Subject: "crypto.generateKeyPair('dh')", Pattern: regexp.MustCompile(`\bgenerateKeyPair(?:Sync)?\(\s*['"]dh['"]`), SizeArg: -1, SizeKeyword: regexp.MustCompile(`\bprimeLength\s*:\s*`)},
+	{Subject: "KeyPairGenerator DH", Pattern: regexp.MustCompile(`KeyPairGenerator\.getInstance\(\s*"(?:DH|DiffieHellman)"`), SizeCall: regexp.MustCompile(`\.initialize\(`), SizeArg: 0},
+	{Subject: "AlgorithmParameterGenerator DH", Pattern: regexp.MustCompile(`AlgorithmParameterGenerator\.getInstance\(\s*"(?:DH|DiffieHellman)"`), SizeCall: regexp.MustCompile(`\.init\(`), SizeArg: 0},
+	{Subject: "DH_generate_parameters_ex", Pattern: regexp.MustCompile(`\bDH_generate_parameters_ex\(`), SizeArg: 1},
+	{Subject: "DH_generate_parameters", Pattern: regexp.MustCompile(`\bDH_generate_parameters\(`), SizeArg: 0},
+	{Subject: "EVP_PKEY_CTX_set_dh_paramgen_prime_len", Pattern: regexp.MustCompile(`\bEVP_PKEY_CTX_set_dh_paramgen_prime_len\(`), SizeArg: 1},
+}
+
+// dhSizeSettings match settings and commands that state a group size, capturing it:
+// openssl dhparam, the genpkey prime length and the JDK's ephemeral DH key size
+var dhSizeSettings = []struct {
+	Subject string
+	Pattern *regexp.Regexp
+}{
+	{"openssl dhparam", regexp.MustCompile(`\bopenssl\s+dhparam\b(?:\s+-\S+(?:\s+[^-\s]\S*)?)*?\s+(\d{3,5})(?:[\s;&|'"]|$)`)},
+	{"dh_paramgen_prime_len", regexp.MustCompile(`\bdh_paramgen_prime_len:(\d+)`)},
+	{"jdk.tls.ephemeralDHKeySize", regexp.MustCompile(`\bjdk\.tls\.ephemeralDHKeySize['"]?\s*[=,]\s*['"]?(\d+|legacy)\b`)},
+}
+
+// dhNamedGroups match the standard groups selected by name in source code, capturing
+// their size; the group they are is known from the name
+var dhNamedGroups = []struct {
+	Pattern *regexp.Regexp
+	Group   func(match string) (bits int, group string)
+}{
+	{regexp.MustCompile(`\b(?:getDiffieHellman|createDiffieHellmanGroup)\(\s*['"]modp(\d+)['"]`), func(match string) (int, string) {
+		number, _ := strconv.Atoi(match)
+		for bits, group := range modpGroups {
+			if strings.HasSuffix(group, fmt.Sprintf(" group %d", number)) {
+				return bits, group
+			}
+		}
+		return 0, ""
+	}},
+	{regexp.MustCompile(`\bBN_get_rfc(?:2409|3526)_prime_(\d+)\(`), func(match string) (int, string) {
+		bits, _ := strconv.Atoi(match)
+		return bits, modpGroups[bits]
+	}},
+	{regexp.MustCompile(`\bDH_get_(1024)_160\(`), func(match string) (int, string) {
+		return 1024, "RFC 5114 1024-bit MODP group with 160-bit subgroup"
+	}},
+}
+
+// detectWeakDHParameters reports Diffie-Hellman groups below dhMinimumBits generated
+// or selected in source code and scripts: parameter generation calls, openssl
+// dhparam, standard groups chosen by name and the JDK's ephemeral DH key size
+func (s *Scanner) detectWeakDHParameters(filePath string, lines []string) []Result {
+	var results []Result
+
+	report := func(line int, subject string, bits int, group string) {
+		if bits > 0 && bits < dhMinimumBits {
+			results = append(results, dhGroupResult(filePath, line, "DH Parameter Analysis", subject, bits, group))
+		}
+	}
+
+	for i, line := range lines {
+		for _, call := range dhGenerationCalls {
+			loc := call.Pattern.FindStringIndex(line)
+			if loc == nil {
+				continue
+			}
+			if bits, ok := dhGenerationSize(call, lines, i, loc); ok {
+				report(i+1, call.Subject, bits, "")
+			}
+			break
+		}
+		for _, setting := range dhSizeSettings {
+			match := setting.Pattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			bits, err := strconv.Atoi(match[1])
+			if match[1] == "legacy" {
+				// The JDK's legacy setting keeps 1024-bit groups, and 768-bit ones for export suites
+				bits, err = 1024, nil
+			}
+			if err == nil {
+				report(i+1, setting.Subject, bits, "")
+			}
+		}
+		for _, named := range dhNamedGroups {
+			if match := named.Pattern.FindStringSubmatch(line); match != nil {
+				bits, group := named.Group(match[1])
+				report(i+1, strings.TrimSuffix(match[0], "(")+")", bits, group)
+			}
+		}
+	}
+
+	return results
+}
+
+// dhGenerationSize finds the group size of the call at loc on line index: a
+// literal, or an identifier assigned one earlier in the file
+func dhGenerationSize(call dhGenerationCall, lines []string, index int, loc []int) (int, bool) {
+	if call.SizeCall != nil {
+		for j := index - kdfContextLines; j <= index+kdfContextLines; j++ {
+			if j < 0 || j >= len(lines) {
+				continue
+			}
+			if sizeLoc := call.SizeCall.FindStringIndex(lines[j]); sizeLoc != nil {
+				return kdfCostArgument(lines, j, callArguments(lines[j][sizeLoc[1]:]), call.SizeArg)
+			}
+		}
+		return 0, false
+	}
+
+	line := lines[index]
+	if call.SizeKeyword != nil {
+		if keywordLoc := call.SizeKeyword.FindStringIndex(line[loc[0]:]); keywordLoc != nil {
+			return resolveCost(lines, index, callArguments(line[loc[0]+keywordLoc[1]:])[0])
+		}
+	}
+	return kdfCostArgument(lines, index, callArguments(line[loc[1]:]), call.SizeArg)
+}
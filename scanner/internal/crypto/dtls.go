@@ -0,0 +1,138 @@
+package crypto
+
+import (
+	"crypto/tls"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// DTLS protocol versions (RFC 6347, RFC 9147). DTLS 1.0 is based on TLS 1.1, and
+// each later version on the TLS version of the same number.
+const (
+	dtlsVersion10 = 0xFEFF
+	dtlsVersion12 = 0xFEFD
+	dtlsVersion13 = 0xFEFC
+)
+
+// tlsEquivalentVersion returns the TLS version a DTLS version is based on, so DTLS
+// and TLS versions compare; TLS versions are returned unchanged
+func tlsEquivalentVersion(version uint16) uint16 {
+	switch version {
+	case dtlsVersion10:
+		return tls.VersionTLS11
+	case dtlsVersion12:
+		return tls.VersionTLS12
+	case dtlsVersion13:
+		return tls.VersionTLS13
+	}
+	return version
+}
+
+// DTLS adds an epoch and sequence number to the TLS record header, and a message
+// sequence number and fragment offset and length to the handshake header
+const (
+	dtlsRecordHeaderLen    = 13
+	dtlsHandshakeHeaderLen = 12
+)
+
+// isDTLSHandshake reports whether a UDP payload starts with a DTLS handshake record
+func isDTLSHandshake(payload []byte) bool {
+	if len(payload) < dtlsRecordHeaderLen || payload[0] != tlsRecordHandshake {
+		return false
+	}
+	switch uint16(payload[1])<<8 | uint16(payload[2]) {
+	case dtlsVersion10, dtlsVersion12, dtlsVersion13:
+		return true
+	}
+	return false
+}
+
+// dtlsHandshakeMessage returns the type of the handshake message that opens a DTLS
+// handshake record and the message in TLS form, so parseClientHello and
+// parseServerHello read it: the DTLS header fields are dropped and, for a
+// ClientHello, so is the cookie. Fragmented or incomplete messages return 0.
+func dtlsHandshakeMessage(payload []byte) (byte, []byte) {
+	if !isDTLSHandshake(payload) {
+		return 0, nil
+	}
+	record := payload[dtlsRecordHeaderLen:]
+	if recordLen := int(payload[11])<<8 | int(payload[12]); recordLen < len(record) {
+		record = record[:recordLen]
+	}
+	if len(record) < dtlsHandshakeHeaderLen {
+		return 0, nil
+	}
+	msgType := record[0]
+	length := int(record[1])<<16 | int(record[2])<<8 | int(record[3])
+	fragmentOffset := int(record[6])<<16 | int(record[7])<<8 | int(record[8])
+	fragmentLen := int(record[9])<<16 | int(record[10])<<8 | int(record[11])
+	if fragmentOffset != 0 || fragmentLen != length || len(record) < dtlsHandshakeHeaderLen+length {
+		return 0, nil
+	}
+	body := record[dtlsHandshakeHeaderLen : dtlsHandshakeHeaderLen+length]
+
+	if msgType == 1 {
+		// client_version, random, session_id, then the DTLS-only cookie
+		sessionEnd := 2 + 32 + 1
+		if len(body) < sessionEnd {
+			return 0, nil
+		}
+		sessionEnd += int(body[sessionEnd-1])
+		if len(body) < sessionEnd+1 || len(body) < sessionEnd+1+int(body[sessionEnd]) {
+			return 0, nil
+		}
+		cookieEnd := sessionEnd + 1 + int(body[sessionEnd])
+		body = append(append([]byte(nil), body[:sessionEnd]...), body[cookieEnd:]...)
+	}
+
+	msg := []byte{msgType, byte(len(body) >> 16), byte(len(body) >> 8), byte(len(body))}
+	return msgType, append(msg, body...)
+}
+
+// extractDTLSHandshake returns the ClientHello or ServerHello that opens a DTLS
+// handshake record in a UDP datagram, as used by WebRTC, CoAP and VPNs
+func (p *PCAPScanner) extractDTLSHandshake(packet gopacket.Packet, udp *layers.UDP) *TLSConnection {
+	srcIP, dstIP := packetIPs(packet)
+	conn := &TLSConnection{
+		SourceIP:   srcIP,
+		DestIP:     dstIP,
+		SourcePort: int(udp.SrcPort),
+		DestPort:   int(udp.DstPort),
+		Timestamp:  packet.Metadata().Timestamp,
+		Transport:  "DTLS",
+	}
+
+	switch msgType, msg := dtlsHandshakeMessage(udp.Payload); msgType {
+	case 1:
+		hello, err := parseClientHello(msg)
+		if err != nil {
+			return nil
+		}
+		conn.Role, conn.hello = RoleClient, hello
+		conn.TLSVersion = tlsVersionName(dtlsClientVersion(msg, hello))
+		return conn
+	case 2:
+		hello, err := parseServerHello(msg)
+		if err != nil {
+			return nil
+		}
+		conn.Role, conn.server = RoleServer, hello
+		conn.TLSVersion = tlsVersionName(hello.Version)
+		conn.CipherSuite = tls.CipherSuiteName(hello.CipherSuite)
+		conn.KeyExchange = p.parseKeyExchange(conn.CipherSuite)
+		return conn
+	}
+	return nil
+}
+
+// dtlsClientVersion returns the highest DTLS version a ClientHello offers: DTLS 1.3
+// if supported_versions lists it, the client_version otherwise
+func dtlsClientVersion(msg []byte, hello *clientHello) uint16 {
+	for _, version := range hello.Versions {
+		if version == dtlsVersion13 {
+			return dtlsVersion13
+		}
+	}
+	return uint16(msg[4])<<8 | uint16(msg[5])
+}
@@ -0,0 +1,244 @@
+package crypto
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// envWeakening is a setting of an environment variable that weakens the crypto of
+// the process it is passed to
+type envWeakening struct {
+	Algorithm      string
+	Type           string
+	Risk           string
+	Vulnerability  string
+	CWE            string
+	Description    string
+	Recommendation string
+}
+
+var (
+	// envAssignmentPattern captures NAME=value assignments, as in export, ENV, inline
+	// command prefixes and docker run -e
+	envAssignmentPattern = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)=("[^"]*"|'[^']*'|[^\s"']+)`)
+
+	// dockerfileEnvPattern captures the legacy Dockerfile form ENV NAME value
+	dockerfileEnvPattern = regexp.MustCompile(`^\s*ENV\s+([A-Za-z_][A-Za-z0-9_]*)\s+([^=\s].*)$`)
+
+	// javaSystemPropertyPattern captures the -Dname=value options of JVM option variables
+	javaSystemPropertyPattern = regexp.MustCompile(`-D([\w.]+)=("[^"]*"|'[^']*'|\S+)`)
+)
+
+// javaOptionVariables are the environment variables the JVM or its launchers read options from
+var javaOptionVariables = map[string]bool{"JAVA_TOOL_OPTIONS": true, "JDK_JAVA_OPTIONS": true, "_JAVA_OPTIONS": true, "JAVA_OPTS": true}
+
+// godebugWeakenings are the GODEBUG settings that turn off a crypto default of Go
+var godebugWeakenings = map[string]envWeakening{
+	"x509sha1=1": {"SHA-1", TypeHash, "High", "Broken", CWEWeakHash,
+		"GODEBUG x509sha1=1 makes crypto/x509 accept SHA-1 signed certificates again, which can be forged with chosen-prefix collisions",
+		"Remove x509sha1=1 and reissue SHA-1 signed certificates with SHA-256"},
+	"tls10server=1": {"TLS 1.0", TypeProtocol, "High", "Protocol Weakness", CWEBrokenCrypto,
+		"GODEBUG tls10server=1 lowers the default minimum version of Go TLS servers to TLS 1.0, which is deprecated by RFC 8996",
+		"Remove tls10server=1 so servers require TLS 1.2 or later"},
+	"tlsrsakex=1": {"RSA", TypePublicKey, "High", "Shor's Algorithm", CWEBrokenCrypto,
+		"GODEBUG tlsrsakex=1 re-enables RSA key exchange cipher suites, which lack forward secrecy and are vulnerable to quantum attacks",
+		"Remove tlsrsakex=1 and use ECDHE or hybrid post-quantum key exchange"},
+	"tls3des=1": {"3DES", TypeSymmetricKey, "High", "Classical Weakness", CWEBrokenCrypto,
+		"GODEBUG tls3des=1 re-enables 3DES cipher suites, which are open to Sweet32 birthday attacks",
+		"Remove tls3des=1 and use AES-GCM or ChaCha20-Poly1305 suites"},
+	"tlsmlkem=0": {"X25519", TypePublicKey, "High", "Shor's Algorithm", CWEBrokenCrypto,
+		"GODEBUG tlsmlkem=0 turns off the X25519MLKEM768 hybrid key exchange, so Go TLS falls back to classical X25519, which is vulnerable to quantum attacks",
+		"Remove tlsmlkem=0 to keep hybrid post-quantum key exchange"},
+	"tlskyber=0": {"X25519", TypePublicKey, "High", "Shor's Algorithm", CWEBrokenCrypto,
+		"GODEBUG tlskyber=0 turns off the X25519Kyber768 hybrid key exchange, so Go TLS falls back to classical X25519, which is vulnerable to quantum attacks",
+		"Remove tlskyber=0 and upgrade to Go 1.24+ for X25519MLKEM768"},
+	"rsa1024min=0": {"RSA", TypePublicKey, "Critical", "Classical Weakness", CWEInadequateStrength,
+		"GODEBUG rsa1024min=0 lets crypto/rsa use keys smaller than 1024 bits, which can be factored today",
+		"Remove rsa1024min=0 and replace small keys with RSA-3072 or, better, post-quantum algorithms"},
+}
+
+// nodeOptionWeakenings are the NODE_OPTIONS flags that weaken Node.js TLS
+var nodeOptionWeakenings = map[string]envWeakening{
+	"--tls-min-v1.0": {"TLS 1.0", TypeProtocol, "High", "Protocol Weakness", CWEBrokenCrypto,
+		"NODE_OPTIONS --tls-min-v1.0 lowers the minimum TLS version to TLS 1.0, which is deprecated by RFC 8996",
+		"Drop the flag so Node.js requires TLS 1.2 or later"},
+	"--tls-min-v1.1": {"TLS 1.1", TypeProtocol, "High", "Protocol Weakness", CWEBrokenCrypto,
+		"NODE_OPTIONS --tls-min-v1.1 lowers the minimum TLS version to TLS 1.1, which is deprecated by RFC 8996",
+		"Drop the flag so Node.js requires TLS 1.2 or later"},
+	"--openssl-legacy-provider": {"OpenSSL", TypeLibrary, "High", "Broken", CWEBrokenCrypto,
+		"NODE_OPTIONS --openssl-legacy-provider loads OpenSSL's legacy provider, re-enabling MD4, RC4, DES and other broken algorithms",
+		"Drop the flag and upgrade the dependencies that still need legacy algorithms"},
+}
+
+// certificateVerificationVariables turn off TLS certificate verification when set to "0"
+var certificateVerificationVariables = map[string]bool{"NODE_TLS_REJECT_UNAUTHORIZED": true, "PYTHONHTTPSVERIFY": true}
+
+// jdkDisabledDefaults are the entries a jdk.*.disabledAlgorithms override must keep
+// to stay as strict as the JDK default, with the algorithm each one disables
+var jdkDisabledDefaults = map[string][]struct{ entry, algorithm, algType string }{
+	"jdk.tls.disabledAlgorithms": {
+		{"SSLv3", "SSL 3.0", TypeProtocol},
+		{"TLSv1", "TLS 1.0", TypeProtocol},
+		{"TLSv1.1", "TLS 1.1", TypeProtocol},
+		{"RC4", "RC4", TypeSymmetricKey},
+		{"3DES_EDE_CBC", "3DES", TypeSymmetricKey},
+		{"NULL", "NULL", TypeSymmetricKey},
+	},
+	"jdk.certpath.disabledAlgorithms": {
+		{"MD5", "MD5", TypeHash},
+		{"SHA1", "SHA-1", TypeHash},
+	},
+}
+
+// javaProtocolProperties are the JVM system properties that select TLS protocol versions
+var javaProtocolProperties = map[string]bool{"jdk.tls.client.protocols": true, "jdk.tls.server.protocols": true, "https.protocols": true}
+
+// detectEnvironmentVariables flags environment variables set in Dockerfiles and
+// shell scripts that weaken the crypto of the processes they reach, such as
+// GODEBUG=x509sha1=1 or NODE_OPTIONS=--tls-min-v1.0
+func (s *Scanner) detectEnvironmentVariables(filePath string, lines []string) []Result {
+	if !IsScriptFile(filePath) {
+		return nil
+	}
+	var results []Result
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if match := dockerfileEnvPattern.FindStringSubmatch(line); match != nil {
+			results = append(results, environmentVariableResults(filePath, i+1, match[1], strings.TrimSpace(match[2]))...)
+			continue
+		}
+		for _, match := range envAssignmentPattern.FindAllStringSubmatch(line, -1) {
+			results = append(results, environmentVariableResults(filePath, i+1, match[1], match[2])...)
+		}
+	}
+	return results
+}
+
+// environmentVariableResults reports how setting name to value weakens crypto, if
+// it does. Quotes around value are ignored.
+func environmentVariableResults(file string, line int, name, value string) []Result {
+	value = unquoteEnvValue(value)
+	var weakenings []envWeakening
+	switch {
+	case name == "GODEBUG":
+		for _, setting := range strings.Split(value, ",") {
+			if weakening, ok := godebugWeakenings[strings.TrimSpace(setting)]; ok {
+				weakenings = append(weakenings, weakening)
+			}
+		}
+	case name == "NODE_OPTIONS":
+		weakenings = nodeOptionsWeakenings(value)
+	case javaOptionVariables[name]:
+		weakenings = javaOptionsWeakenings(name, value)
+	case certificateVerificationVariables[name] && value == "0":
+		weakenings = append(weakenings, envWeakening{"TLS", TypeProtocol, "Critical", "Transport Security", CWEImproperCertificateValidation,
+			fmt.Sprintf("%s=0 turns off TLS certificate verification, so any server certificate is accepted and connections can be intercepted", name),
+			"Remove the variable and trust a private CA through the system store or the client's CA bundle setting instead"})
+	}
+
+	results := make([]Result, 0, len(weakenings))
+	for _, weakening := range weakenings {
+		results = append(results, Result{
+			File:              file,
+			Algorithm:         weakening.Algorithm,
+			Type:              weakening.Type,
+			Line:              line,
+			Method:            "Environment Variable Analysis",
+			Risk:              weakening.Risk,
+			VulnerabilityType: weakening.Vulnerability,
+			CWE:               []string{weakening.CWE},
+			Description:       weakening.Description,
+			Recommendation:    weakening.Recommendation,
+		})
+	}
+	return results
+}
+
+// nodeOptionsWeakenings reports the NODE_OPTIONS flags that lower the TLS version,
+// load legacy algorithms or put weak ciphers in --tls-cipher-list
+func nodeOptionsWeakenings(value string) []envWeakening {
+	var weakenings []envWeakening
+	for _, option := range strings.Fields(value) {
+		if weakening, ok := nodeOptionWeakenings[option]; ok {
+			weakenings = append(weakenings, weakening)
+			continue
+		}
+		if list, ok := strings.CutPrefix(option, "--tls-cipher-list="); ok {
+			for _, algorithm := range weakCipherListAlgorithms(list) {
+				weakenings = append(weakenings, envWeakening{algorithm, TypeSymmetricKey, "High", "Classical Weakness", CWEBrokenCrypto,
+					fmt.Sprintf("NODE_OPTIONS --tls-cipher-list enables weak %s cipher suites", algorithm),
+					"Remove legacy ciphers and use AES-256-GCM or ChaCha20-Poly1305"})
+			}
+		}
+	}
+	return weakenings
+}
+
+// weakCipherListAlgorithms returns the weak ciphers an OpenSSL cipher list enables, once each
+func weakCipherListAlgorithms(list string) []string {
+	seen := make(map[string]bool)
+	var algorithms []string
+	for _, entry := range strings.Split(unquoteEnvValue(list), ":") {
+		for _, algorithm := range opensslCipherAlgorithms(entry) {
+			if (algorithm == "3DES" || algorithm == "DES" || algorithm == "RC4") && !seen[algorithm] {
+				seen[algorithm] = true
+				algorithms = append(algorithms, algorithm)
+			}
+		}
+	}
+	return algorithms
+}
+
+// javaOptionsWeakenings reports the -D options of a JVM option variable that drop
+// defaults from jdk.*.disabledAlgorithms or enable legacy TLS versions
+func javaOptionsWeakenings(name, value string) []envWeakening {
+	var weakenings []envWeakening
+	for _, match := range javaSystemPropertyPattern.FindAllStringSubmatch(value, -1) {
+		property, setting := match[1], unquoteEnvValue(match[2])
+
+		if defaults, ok := jdkDisabledDefaults[property]; ok {
+			disabled := make(map[string]bool)
+			for _, entry := range strings.Split(setting, ",") {
+				if fields := strings.Fields(entry); len(fields) > 0 {
+					disabled[fields[0]] = true
+				}
+			}
+			for _, def := range defaults {
+				if disabled[def.entry] {
+					continue
+				}
+				vulnerability, cwe := "Protocol Weakness", CWEBrokenCrypto
+				if def.algType == TypeHash {
+					vulnerability, cwe = "Broken", CWEWeakHash
+				} else if def.algType == TypeSymmetricKey {
+					vulnerability = "Classical Weakness"
+				}
+				weakenings = append(weakenings, envWeakening{def.algorithm, def.algType, "High", vulnerability, cwe,
+					fmt.Sprintf("%s replaces %s with a list that leaves out %s, re-enabling %s", name, property, def.entry, def.algorithm),
+					fmt.Sprintf("Add to the JDK defaults in a java.security override instead of replacing them, keeping %s disabled", def.entry)})
+			}
+		}
+
+		if javaProtocolProperties[property] {
+			for _, entry := range strings.Split(setting, ",") {
+				if version, ok := legacyProtocolName(strings.TrimSpace(entry)); ok {
+					weakenings = append(weakenings, envWeakening{version, TypeProtocol, "High", "Protocol Weakness", CWEBrokenCrypto,
+						fmt.Sprintf("%s sets %s to include %s, which is deprecated", name, property, version),
+						"Allow only TLSv1.2 and TLSv1.3"})
+				}
+			}
+		}
+	}
+	return weakenings
+}
+
+// unquoteEnvValue strips one pair of matching single or double quotes from value
+func unquoteEnvValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,153 @@
+package crypto
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Deployment environments findings can be labeled with
+const (
+	EnvironmentProduction  = "prod"
+	EnvironmentStaging     = "staging"
+	EnvironmentDevelopment = "dev"
+	EnvironmentTest        = "test"
+)
+
+// environmentRiskSteps is how many risk levels findings in each environment are
+// lowered by: code that never ships to production carries less of its risk
+var environmentRiskSteps = map[string]int{
+	EnvironmentProduction:  0,
+	EnvironmentStaging:     0,
+	EnvironmentDevelopment: 1,
+	EnvironmentTest:        1,
+}
+
+// riskLadder lists risk levels from least to most severe
+var riskLadder = []string{"Low", "Medium", "High", "Critical"}
+
+// CheckEnvironment reports an environment findings can't be labeled with
+func CheckEnvironment(environment string) error {
+	if _, ok := environmentRiskSteps[environment]; !ok {
+		return fmt.Errorf("unsupported environment '%s'. Use: prod, staging, dev, test", environment)
+	}
+	return nil
+}
+
+// EnvironmentPath labels the findings in files matching Pattern with Environment
+type EnvironmentPath struct {
+	// Pattern is a path.Match glob matched against the path of a file below its scan
+	// target and every trailing part of it, e.g. "*_test.go" or "fixtures/*.pem". A
+	// pattern ending in "/" matches the files below such a directory, e.g. "test/".
+	Pattern     string
+	Environment string
+}
+
+// ParseEnvironmentPath parses a PATTERN=ENV mapping
+func ParseEnvironmentPath(spec string) (EnvironmentPath, error) {
+	pattern, environment, ok := strings.Cut(spec, "=")
+	if !ok || pattern == "" {
+		return EnvironmentPath{}, fmt.Errorf("invalid environment path '%s'. Use: PATTERN=ENV, e.g. test/=test", spec)
+	}
+	if _, err := path.Match(strings.TrimSuffix(pattern, "/"), ""); err != nil {
+		return EnvironmentPath{}, fmt.Errorf("invalid environment path pattern '%s': %w", pattern, err)
+	}
+	if err := CheckEnvironment(environment); err != nil {
+		return EnvironmentPath{}, err
+	}
+	return EnvironmentPath{Pattern: pattern, Environment: environment}, nil
+}
+
+// Environments labels findings with the deployment environment of the code they are
+// in, so test and development code can be told apart from what ships
+type Environments struct {
+	Default string            // Environment of findings no path matches; empty leaves them unlabeled
+	Paths   []EnvironmentPath // Tried in order; the first match labels the finding
+}
+
+// Label sets the Environment of each result from the first path it matches, or
+// Default. Paths are matched below the root of roots the file is in, if any.
+func (e *Environments) Label(results []Result, roots []string) {
+	for i := range results {
+		environment := e.Default
+		rel := relativeToRoots(results[i].File, roots)
+		for _, mapping := range e.Paths {
+			if matchEnvironmentPath(mapping.Pattern, rel) {
+				environment = mapping.Environment
+				break
+			}
+		}
+		if environment != "" {
+			results[i].Environment = environment
+		}
+	}
+}
+
+// relativeToRoots returns file relative to the first of roots it is below, in
+// slash form, or file itself when it is below none of them
+func relativeToRoots(file string, roots []string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		for _, root := range roots {
+			if rel, err := filepath.Rel(root, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				if rel == "." {
+					// A file given as the target itself
+					rel = filepath.Base(abs)
+				}
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+// matchEnvironmentPath reports whether pattern matches rel or any trailing part of it
+func matchEnvironmentPath(pattern, rel string) bool {
+	segments := strings.Split(rel, "/")
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	for i := range segments {
+		if !directory {
+			if matched, _ := path.Match(pattern, strings.Join(segments[i:], "/")); matched {
+				return true
+			}
+			continue
+		}
+		// The directory is any run of segments above the file's name
+		for j := i + 1; j < len(segments); j++ {
+			if matched, _ := path.Match(pattern, strings.Join(segments[i:j], "/")); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ApplyEnvironmentRisk lowers the risk of a result labeled with an environment that
+// doesn't ship, such as test, and reports whether it changed. Low stays Low, and
+// risks outside Low to Critical, e.g. from a severity map, are kept. The risk before
+// is kept in DefaultRisk.
+func ApplyEnvironmentRisk(result *Result) bool {
+	steps := environmentRiskSteps[result.Environment]
+	if steps == 0 {
+		return false
+	}
+	for i, risk := range riskLadder {
+		if !strings.EqualFold(risk, result.Risk) {
+			continue
+		}
+		lowered := i - steps
+		if lowered < 0 {
+			lowered = 0
+		}
+		if lowered == i {
+			return false
+		}
+		if result.DefaultRisk == "" {
+			result.DefaultRisk = result.Risk
+		}
+		result.Risk = riskLadder[lowered]
+		return true
+	}
+	return false
+}
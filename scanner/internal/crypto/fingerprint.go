@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SetFingerprints gives each result without a Fingerprint a stable ID, so the same
+// logical finding can be matched across scans and tools. It is derived from the
+// path relative to the first of roots the file is below, so checkouts of one tree
+// at different places agree, the algorithm and method, and the text of the matched
+// line in lines with whitespace collapsed, not the line number, so edits elsewhere
+// in the file keep it. Findings without a source line, such as certificates,
+// captures and cluster resources, use their description instead. Identical
+// findings in one file are told apart by their order.
+func SetFingerprints(results []Result, lines []string, roots []string) {
+	occurrences := make(map[string]int)
+	for i := range results {
+		if results[i].Fingerprint != "" {
+			continue
+		}
+		key := fingerprintKey(results[i], lines)
+		occurrences[results[i].File+"\x00"+key]++
+		results[i].fingerprintKey = fmt.Sprintf("%s\x00%d", key, occurrences[results[i].File+"\x00"+key])
+		results[i].Fingerprint = fingerprint(results[i].File, results[i].fingerprintKey, roots)
+	}
+}
+
+// refingerprint recomputes the fingerprint of a result whose File changed, such as
+// a cached finding reported against another copy of the same content
+func refingerprint(result *Result, roots []string) {
+	if result.fingerprintKey != "" {
+		result.Fingerprint = fingerprint(result.File, result.fingerprintKey, roots)
+	}
+}
+
+// fingerprintKey identifies a finding within its file
+func fingerprintKey(result Result, lines []string) string {
+	context := result.Description
+	if result.Line >= 1 && result.Line <= len(lines) {
+		context = strings.Join(strings.Fields(lines[result.Line-1]), " ")
+	}
+	return strings.Join([]string{CanonicalAlgorithm(result.Algorithm), result.Method, context}, "\x00")
+}
+
+// fingerprint hashes a finding's path below roots with its key
+func fingerprint(path, key string, roots []string) string {
+	sum := sha256.Sum256([]byte(relativeToRoots(filepath.Clean(path), roots) + "\x00" + key))
+	return hex.EncodeToString(sum[:16])
+}
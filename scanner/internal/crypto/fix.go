@@ -0,0 +1,121 @@
+package crypto
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// fixContextLines is the number of unchanged lines around a suggested fix's change
+const fixContextLines = 3
+
+// fixRule is a mechanical, drop-in substitution for a weak algorithm or protocol
+// version on a finding's line
+type fixRule struct {
+	Algorithms        []string // Finding algorithms the fix applies to
+	VulnerabilityType string   // Finding vulnerability type the fix applies to
+	Pattern           *regexp.Regexp
+	Replacement       string // Expanded as in regexp.Regexp.ReplaceAllString
+}
+
+// fixRules are the curated substitutions SuggestFix offers. Each keeps the call
+// and its arguments and only swaps the algorithm or the minimum version, so the
+// code still compiles; digests change length and stored values must be migrated.
+var fixRules = []fixRule{
+	{
+		Algorithms:        []string{"MD5", "SHA-1"},
+		VulnerabilityType: "Grover's Algorithm + Broken",
+		Pattern:           regexp.MustCompile(`\bhashlib\.(?:md5|sha1)\(`),
+		Replacement:       "hashlib.sha256(",
+	},
+	{
+		Algorithms:        []string{"MD5", "SHA-1"},
+		VulnerabilityType: "Grover's Algorithm + Broken",
+		Pattern:           regexp.MustCompile(`\bhashlib\.new\((['"])(?:md5|sha1)(['"])`),
+		Replacement:       "hashlib.new(${1}sha256${2}",
+	},
+	{
+		Algorithms:        []string{"MD5", "SHA-1"},
+		VulnerabilityType: "Grover's Algorithm + Broken",
+		Pattern:           regexp.MustCompile(`\bcreateHash\((['"])(?:md5|sha1)(['"])\)`),
+		Replacement:       "createHash(${1}sha256${2})",
+	},
+	{
+		Algorithms:        []string{"MD5", "SHA-1"},
+		VulnerabilityType: "Grover's Algorithm + Broken",
+		Pattern:           regexp.MustCompile(`\bMessageDigest\.getInstance\("(?:MD5|SHA-?1)"\)`),
+		Replacement:       `MessageDigest.getInstance("SHA-256")`,
+	},
+	{
+		Algorithms:        []string{"TLS 1.0", "TLS 1.1"},
+		VulnerabilityType: "Protocol Weakness",
+		Pattern:           regexp.MustCompile(`\bMinVersion:(\s*)tls\.VersionTLS1[01]\b`),
+		Replacement:       "MinVersion:${1}tls.VersionTLS12",
+	},
+	{
+		Algorithms:        []string{"TLS 1.0", "TLS 1.1"},
+		VulnerabilityType: "Protocol Weakness",
+		Pattern:           regexp.MustCompile(`(\.minimum_version\s*=\s*(?:ssl\.)?)TLSVersion\.TLSv1(?:_1)?\b`),
+		Replacement:       "${1}TLSVersion.TLSv1_2",
+	},
+	{
+		Algorithms:        []string{"TLS 1.0", "TLS 1.1"},
+		VulnerabilityType: "Protocol Weakness",
+		Pattern:           regexp.MustCompile(`\bSSLContext\.getInstance\("TLSv1(?:\.1)?"\)`),
+		Replacement:       `SSLContext.getInstance("TLSv1.2")`,
+	},
+}
+
+// SuggestFix returns a unified diff replacing the weak algorithm or protocol
+// version on result's line of fileContent, for the well-understood cases in
+// fixRules. It reports false when no curated fix applies, including on comment
+// lines. The diff names result.File on both sides; as scan paths are absolute,
+// apply it with the file given explicitly, e.g. patch file.py < fix.diff.
+func SuggestFix(result Result, fileContent []byte) (string, bool) {
+	// A trailing newline ends the last line rather than starting another
+	lines := strings.Split(strings.TrimSuffix(string(fileContent), "\n"), "\n")
+	if result.Line < 1 || result.Line > len(lines) {
+		return "", false
+	}
+	index := result.Line - 1
+	line := lines[index]
+	if isCommentLine(result.File, line) {
+		return "", false
+	}
+
+	for _, fix := range fixRules {
+		if fix.VulnerabilityType != result.VulnerabilityType || !containsString(fix.Algorithms, result.Algorithm) || !fix.Pattern.MatchString(line) {
+			continue
+		}
+		fixed := fix.Pattern.ReplaceAllString(line, fix.Replacement)
+		if fixed == line {
+			continue
+		}
+
+		start := max(index-fixContextLines, 0)
+		end := min(index+fixContextLines+1, len(lines))
+		count := end - start
+		var b strings.Builder
+		fmt.Fprintf(&b, "--- %s\n+++ %s\n", result.File, result.File)
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", start+1, count, start+1, count)
+		for i := start; i < end; i++ {
+			if i == index {
+				fmt.Fprintf(&b, "-%s\n+%s\n", line, fixed)
+				continue
+			}
+			fmt.Fprintf(&b, " %s\n", lines[i])
+		}
+		return b.String(), true
+	}
+	return "", false
+}
+
+// containsString reports whether list holds value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package crypto
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// goAnalysisMethod is the Method of findings from the Go syntax tree
+const goAnalysisMethod = "Go AST Analysis"
+
+// goCryptoPackage maps a Go standard-library crypto package to the detection rule
+// algorithm its calls are reported as
+type goCryptoPackage struct {
+	Algorithm string
+	Functions map[string]string // Functions reported as another algorithm, e.g. des.NewTripleDESCipher
+}
+
+// goCryptoPackages are the standard-library packages the Go analyzer follows
+var goCryptoPackages = map[string]goCryptoPackage{
+	"crypto/rsa":      {Algorithm: "RSA"},
+	"crypto/ecdsa":    {Algorithm: "ECDSA"},
+	"crypto/ed25519":  {Algorithm: "EdDSA"},
+	"crypto/elliptic": {Algorithm: "ECC"},
+	"crypto/ecdh":     {Algorithm: "ECDH"},
+	"crypto/dsa":      {Algorithm: "DSA"},
+	"crypto/des":      {Algorithm: "DES", Functions: map[string]string{"NewTripleDESCipher": "3DES"}},
+	"crypto/md5":      {Algorithm: "MD5"},
+	"crypto/sha1":     {Algorithm: "SHA-1"},
+	"crypto/sha256":   {Algorithm: "SHA-256"},
+	"crypto/sha512":   {Algorithm: "SHA-512"},
+}
+
+// goAnalyzedAlgorithms are the algorithms of goCryptoPackages
+var goAnalyzedAlgorithms = func() map[string]bool {
+	algorithms := make(map[string]bool)
+	for _, pkg := range goCryptoPackages {
+		algorithms[pkg.Algorithm] = true
+		for _, algorithm := range pkg.Functions {
+			algorithms[algorithm] = true
+		}
+	}
+	return algorithms
+}()
+
+// replacedByGoAnalysis reports whether rule looks for the imports and calls the Go
+// analyzer finds. Other rules, such as key sizes and JWT algorithms, still run on
+// Go files.
+func replacedByGoAnalysis(rule DetectionRule) bool {
+	return goAnalyzedAlgorithms[rule.AlgorithmName] && (rule.Method == "Function Name" || rule.Method == "Import Statement")
+}
+
+// analyzeGoSource reports the calls into the standard-library crypto packages of a
+// Go file at their exact line and column. A package imported but never called,
+// e.g. only for its types, is reported at its import. Comments and strings can't
+// match, unlike with regex rules. It reports false when content doesn't parse, so
+// the caller falls back to the regex rules.
+func (s *Scanner) analyzeGoSource(filePath string, content []byte, lines []string) ([]Result, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, content, 0)
+	if err != nil {
+		return nil, false
+	}
+
+	// Crypto imports by local package name, and the names in import order
+	imported := make(map[string]goCryptoPackage)
+	specs := make(map[string]*ast.ImportSpec)
+	var names []string
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if pkg, ok := goCryptoPackages[importPath]; ok {
+			name := path.Base(importPath)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			imported[name], specs[name] = pkg, spec
+			names = append(names, name)
+		}
+	}
+	if len(imported) == 0 {
+		return nil, true
+	}
+
+	var results []Result
+	called := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := selector.X.(*ast.Ident)
+		// A declared object means a local variable shadows the package name
+		if !ok || ident.Obj != nil {
+			return true
+		}
+		pkg, ok := imported[ident.Name]
+		if !ok {
+			return true
+		}
+		called[ident.Name] = true
+
+		algorithm := pkg.Algorithm
+		if override, ok := pkg.Functions[selector.Sel.Name]; ok {
+			algorithm = override
+		}
+		if result, ok := s.goResult(filePath, algorithm, "Function Name", fset.Position(call.Pos()), lines); ok {
+			results = append(results, result)
+		}
+		return true
+	})
+
+	for _, name := range names {
+		if called[name] {
+			continue
+		}
+		if result, ok := s.goResult(filePath, imported[name].Algorithm, "Import Statement", fset.Position(specs[name].Pos()), lines); ok {
+			results = append(results, result)
+		}
+	}
+	return results, true
+}
+
+// goResult builds a Go analyzer finding at pos from the rule for algorithm,
+// preferring one detected by method
+func (s *Scanner) goResult(filePath, algorithm, method string, pos token.Position, lines []string) (Result, bool) {
+	var rule DetectionRule
+	found := false
+	for _, candidate := range s.Rules {
+		if candidate.AlgorithmName != algorithm || candidate.MultiLine {
+			continue
+		}
+		if !found || candidate.Method == method {
+			rule, found = candidate, true
+		}
+		if candidate.Method == method {
+			break
+		}
+	}
+	if !found || pos.Line < 1 || pos.Line > len(lines) {
+		return Result{}, false
+	}
+
+	rule.Method = goAnalysisMethod
+	rule.Confidence = 1
+	result, ok := s.ruleResult(filePath, rule, strings.TrimSuffix(lines[pos.Line-1], "\r"), pos.Line)
+	result.Column = pos.Column
+	return result, ok
+}
@@ -0,0 +1,72 @@
+package crypto
+
+// HNDL (harvest-now-decrypt-later) risk levels
+const (
+	HNDLRiskHigh   = "High"
+	HNDLRiskMedium = "Medium"
+	HNDLRiskLow    = "Low"
+	HNDLRiskNone   = "None"
+)
+
+// keyEstablishmentAlgorithms protect confidentiality of session or stored data,
+// so captured ciphertext can be decrypted once a quantum computer exists
+var keyEstablishmentAlgorithms = map[string]bool{
+	"RSA":  true,
+	"DH":   true,
+	"ECDH": true,
+	"TLS":  true,
+}
+
+// weakSymmetricAlgorithms provide reduced confidentiality against Grover's algorithm
+var weakSymmetricAlgorithms = map[string]bool{
+	"AES-128": true,
+	"DES":     true,
+	"3DES":    true,
+}
+
+// AssessHNDLRisk estimates harvest-now-decrypt-later exposure from the algorithm purpose.
+// Key exchange and encryption are exposed to recorded traffic; signatures are only
+// at risk once forged in real time, so they score lower.
+func AssessHNDLRisk(algorithm, algType string) string {
+	switch algType {
+	case "PostQuantum", "Hybrid", "Hash", "KDF", "EmbeddedKey":
+		return HNDLRiskNone
+	case "PublicKey":
+		if keyEstablishmentAlgorithms[algorithm] {
+			return HNDLRiskHigh
+		}
+		return HNDLRiskLow
+	case "SymmetricKey":
+		if weakSymmetricAlgorithms[algorithm] {
+			return HNDLRiskMedium
+		}
+		return HNDLRiskLow
+	case "Protocol":
+		return HNDLRiskMedium
+	default:
+		return HNDLRiskLow
+	}
+}
+
+// HNDLRiskRank orders HNDL risk levels for prioritization (higher is more urgent)
+func HNDLRiskRank(level string) int {
+	switch level {
+	case HNDLRiskHigh:
+		return 3
+	case HNDLRiskMedium:
+		return 2
+	case HNDLRiskLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// applyHNDLRisk fills in the HNDL risk of results that don't have one yet
+func applyHNDLRisk(results []Result) {
+	for i := range results {
+		if results[i].HNDLRisk == "" {
+			results[i].HNDLRisk = AssessHNDLRisk(results[i].Algorithm, results[i].Type)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package crypto
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// imageSystemPaths are image directories holding OS packages rather than application
+// code. Distribution libraries are covered by package scanners, so deep scans skip them.
+var imageSystemPaths = []string{
+	"bin/", "sbin/", "lib/", "lib32/", "lib64/",
+	"usr/bin/", "usr/sbin/", "usr/lib/", "usr/lib32/", "usr/lib64/", "usr/libexec/", "usr/include/", "usr/share/",
+	"var/lib/", "var/cache/", "var/log/",
+	"etc/ssl/certs/", "proc/", "sys/", "dev/",
+}
+
+// ImageFetcher retrieves container images for deep code scanning
+type ImageFetcher interface {
+	// FetchImage writes image into dir as a `docker save` tarball and returns its path
+	FetchImage(ctx context.Context, image, dir string) (string, error)
+}
+
+// DockerImageFetcher pulls and exports images with the docker CLI
+type DockerImageFetcher struct{}
+
+// FetchImage pulls image and saves it to dir/image.tar
+func (DockerImageFetcher) FetchImage(ctx context.Context, image, dir string) (string, error) {
+	if out, err := exec.CommandContext(ctx, "docker", "pull", image).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to pull image %s: %v: %s", image, err, strings.TrimSpace(string(out)))
+	}
+	tarPath := filepath.Join(dir, "image.tar")
+	if out, err := exec.CommandContext(ctx, "docker", "save", "-o", tarPath, image).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to save image %s: %v: %s", image, err, strings.TrimSpace(string(out)))
+	}
+	return tarPath, nil
+}
+
+// ScanImageTar scans the application files in a `docker save` image tarball.
+// Findings are reported against their path inside the image; the count is files scanned.
+func (s *Scanner) ScanImageTar(tarPath string) ([]Result, int) {
+	layers, err := imageLayers(tarPath)
+	if err != nil {
+		s.logf("Error reading image %s: %v\n", tarPath, err)
+		s.warn("", tarPath, fmt.Sprintf("failed to read image: %v", err))
+		return nil, 0
+	}
+
+	file, err := os.Open(tarPath)
+	if err != nil {
+		s.logf("Error reading image %s: %v\n", tarPath, err)
+		s.warn("", tarPath, fmt.Sprintf("failed to read image: %v", err))
+		return nil, 0
+	}
+	defer file.Close()
+
+	var results []Result
+	fileCount := 0
+	budget := &archiveBudget{}
+
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.logf("Error reading image %s: %v\n", tarPath, err)
+			s.warn("", tarPath, fmt.Sprintf("failed to read image: %v", err))
+			break
+		}
+		// Without a manifest every regular entry is tried as a layer
+		if header.Typeflag != tar.TypeReg || (layers != nil && !layers[header.Name]) {
+			continue
+		}
+
+		layerResults, scanned := s.scanImageLayer(header.Name, tr, budget)
+		results = append(results, layerResults...)
+		fileCount += scanned
+	}
+
+	return results, fileCount
+}
+
+// imageLayers returns the layer entries listed in an image tarball's manifest.json,
+// or nil if the tarball has no manifest
+func imageLayers(tarPath string) (map[string]bool, error) {
+	file, err := os.Open(tarPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if path.Clean(header.Name) != "manifest.json" {
+			continue
+		}
+
+		var manifest []struct {
+			Layers []string `json:"Layers"`
+		}
+		if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+			return nil, fmt.Errorf("invalid manifest.json: %w", err)
+		}
+		layers := make(map[string]bool)
+		for _, image := range manifest {
+			for _, layer := range image.Layers {
+				layers[layer] = true
+			}
+		}
+		return layers, nil
+	}
+}
+
+// scanImageLayer scans the application files of a single, optionally gzip-compressed,
+// layer. A layer that can't be read is recorded as a warning.
+func (s *Scanner) scanImageLayer(layerName string, r io.Reader, budget *archiveBudget) ([]Result, int) {
+	var results []Result
+	fileCount := 0
+
+	br := bufio.NewReader(r)
+	var layer io.Reader = br
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1F, 0x8B}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			if s.Verbose {
+				s.logf("Skipping image layer %s: %v\n", layerName, err)
+			}
+			s.warn("", layerName, fmt.Sprintf("failed to read image layer: %v", err))
+			return nil, 0
+		}
+		defer gz.Close()
+		layer = gz
+	}
+
+	tr := tar.NewReader(layer)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if s.Verbose {
+				s.logf("Skipping image layer %s: %v\n", layerName, err)
+			}
+			s.warn("", layerName, fmt.Sprintf("failed to read image layer: %v", err))
+			break
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if !isImageApplicationPath(name) {
+			continue
+		}
+
+		if s.isArchiveEntryEligible(name, 0) {
+			if !budget.allow(header.Size) {
+				if s.Verbose {
+					s.logf("Image limits reached, skipping remaining files in layer %s\n", layerName)
+				}
+				break
+			}
+			data, err := readBounded(tr, maxArchiveEntrySize)
+			if err != nil {
+				if s.Verbose {
+					s.logf("Skipping image file %s: %v\n", name, err)
+				}
+				continue
+			}
+			budget.charge(len(data))
+
+			entryResults, scanned := s.scanArchiveEntry(name, name, data, 0, budget)
+			results = append(results, entryResults...)
+			fileCount += scanned
+			continue
+		}
+
+		// Only executables and shared libraries are candidates for binary analysis
+		if header.Mode&0111 == 0 && !isSharedLibrary(name) || header.Size > maxBinarySize {
+			continue
+		}
+		magic := make([]byte, 8)
+		if _, err := io.ReadFull(tr, magic); err != nil || !isBinaryHeader(magic) {
+			continue
+		}
+		data, err := readBounded(io.MultiReader(bytes.NewReader(magic), tr), maxBinarySize)
+		if err != nil {
+			continue
+		}
+		if s.Verbose {
+			s.logf("Scanning binary: %s\n", name)
+		}
+		results = append(results, s.scanBinaryContent(name, data)...)
+		fileCount++
+	}
+
+	return results, fileCount
+}
+
+// isImageApplicationPath reports whether an image path lies outside the OS package directories
+func isImageApplicationPath(name string) bool {
+	if strings.HasPrefix(path.Base(name), ".wh.") {
+		return false
+	}
+	for _, prefix := range imageSystemPaths {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSharedLibrary reports whether a file name looks like a shared library
+func isSharedLibrary(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".so") || strings.Contains(lower, ".so.") ||
+		strings.HasSuffix(lower, ".dll") || strings.HasSuffix(lower, ".dylib")
+}
@@ -0,0 +1,127 @@
+package crypto
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// cipherAnnotations are ingress-controller annotations that pin an OpenSSL cipher list
+var cipherAnnotations = []string{
+	"nginx.ingress.kubernetes.io/ssl-ciphers",
+	"ingress.kubernetes.io/ssl-ciphers",
+	"haproxy.org/ssl-ciphers",
+}
+
+// albSSLPolicyAnnotation selects an AWS load balancer security policy
+const albSSLPolicyAnnotation = "alb.ingress.kubernetes.io/ssl-policy"
+
+// weakCipherAlgorithms are symmetric ciphers in OpenSSL cipher names that offer too little security
+var weakCipherAlgorithms = []string{"3DES", "DES", "RC4"}
+
+// analyzeIngressAnnotations reports quantum-vulnerable key exchange and weak ciphers
+// pinned through ingress-controller annotations
+func analyzeIngressAnnotations(file string, annotations map[string]string) []Result {
+	var results []Result
+
+	for _, annotation := range cipherAnnotations {
+		if ciphers, ok := annotations[annotation]; ok {
+			results = append(results, analyzeCipherList(file, annotation, ciphers)...)
+		}
+	}
+
+	if policy, ok := annotations[albSSLPolicyAnnotation]; ok {
+		if strings.Contains(policy, "TLS-1-0") || strings.Contains(policy, "TLS-1-1") {
+			results = append(results, Result{
+				File:              file,
+				Algorithm:         "TLS 1.0/1.1",
+				Type:              "Protocol",
+				Line:              1,
+				Method:            "Ingress TLS Analysis",
+				Risk:              "High",
+				VulnerabilityType: "Protocol Weakness",
+				Description:       fmt.Sprintf("Load balancer SSL policy %s allows outdated TLS versions", policy),
+				Recommendation:    "Select a TLS 1.3 policy, preferably one with post-quantum hybrid key exchange",
+			})
+		}
+	}
+
+	return results
+}
+
+// analyzeCipherList classifies an OpenSSL cipher list, reporting one finding per vulnerable
+// algorithm with the ciphers that use it. TLS 1.3 suites (TLS_*) don't fix key exchange.
+func analyzeCipherList(file, annotation, list string) []Result {
+	ciphersByAlgorithm := make(map[string][]string)
+	for _, cipher := range splitCipherList(list) {
+		for _, algorithm := range opensslCipherAlgorithms(cipher) {
+			ciphersByAlgorithm[algorithm] = append(ciphersByAlgorithm[algorithm], cipher)
+		}
+	}
+
+	algorithms := make([]string, 0, len(ciphersByAlgorithm))
+	for algorithm := range ciphersByAlgorithm {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+
+	var results []Result
+	for _, algorithm := range algorithms {
+		ciphers := strings.Join(ciphersByAlgorithm[algorithm], ", ")
+		result := Result{
+			File:              file,
+			Algorithm:         algorithm,
+			Type:              "PublicKey",
+			Line:              1,
+			Method:            "Ingress TLS Analysis",
+			Risk:              "High",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       fmt.Sprintf("Annotation %s pins ciphers using %s key exchange: %s", annotation, algorithm, ciphers),
+			Recommendation:    "Prefer TLS 1.3 with hybrid post-quantum key exchange (X25519MLKEM768)",
+		}
+		switch algorithm {
+		case "3DES", "DES", "RC4":
+			result.Type = "SymmetricKey"
+			result.VulnerabilityType = "Classical Weakness"
+			result.Description = fmt.Sprintf("Annotation %s pins weak %s ciphers: %s", annotation, algorithm, ciphers)
+			result.Recommendation = "Remove legacy ciphers and use AES-256-GCM or ChaCha20-Poly1305"
+		case "RSA":
+			populateNISTFields(&result, "RSA-2048")
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// splitCipherList splits an OpenSSL cipher list on its separators
+func splitCipherList(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool { return r == ':' || r == ',' || r == ' ' })
+}
+
+// opensslCipherAlgorithms returns the quantum-vulnerable key exchange and weak cipher
+// of an OpenSSL cipher name. Excluded (!) entries, TLS 1.3 suites (TLS_*), whose key
+// exchange is negotiated separately, and PSK suites have none.
+func opensslCipherAlgorithms(cipher string) []string {
+	name := strings.ToUpper(strings.TrimPrefix(cipher, "!"))
+	if strings.HasPrefix(cipher, "!") || strings.HasPrefix(name, "TLS_") || strings.Contains(name, "PSK") {
+		return nil
+	}
+
+	var algorithms []string
+	switch {
+	case strings.HasPrefix(name, "ECDHE-"):
+		algorithms = append(algorithms, "ECDH")
+	case strings.HasPrefix(name, "DHE-"), strings.HasPrefix(name, "EDH-"):
+		algorithms = append(algorithms, "DH")
+	case strings.Contains(name, "-"):
+		// OpenSSL names without a key exchange prefix use static RSA key transport
+		algorithms = append(algorithms, "RSA")
+	}
+	for _, weak := range weakCipherAlgorithms {
+		if strings.Contains(name, weak) || (weak == "3DES" && strings.Contains(name, "DES-CBC3")) {
+			algorithms = append(algorithms, weak)
+			break
+		}
+	}
+	return algorithms
+}
@@ -0,0 +1,143 @@
+package crypto
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// jwtCallMaxLines bounds how many lines a JWT call's arguments are read across
+const jwtCallMaxLines = 8
+
+// jwtMinSecretLength is the shortest HMAC secret accepted for HS256 (RFC 7518 section 3.2)
+const jwtMinSecretLength = 32
+
+// jwtVerifyCall is a JWT verification call that takes an algorithm allowlist
+type jwtVerifyCall struct {
+	Library   string
+	Pattern   *regexp.Regexp
+	Allowlist string // Name of the allowlist argument
+	Example   string // How the allowlist is passed, for the recommendation
+}
+
+var (
+	nodeJWTVerify = jwtVerifyCall{Library: "jsonwebtoken", Pattern: regexp.MustCompile(`\bjwt\.verify\(`), Allowlist: "algorithms", Example: "{ algorithms: ['ES256'] }"}
+
+	// jwtVerifyCalls maps file extensions to the verification calls checked for an allowlist
+	jwtVerifyCalls = map[string][]jwtVerifyCall{
+		".js": {nodeJWTVerify},
+		".ts": {nodeJWTVerify},
+		".py": {{Library: "PyJWT", Pattern: regexp.MustCompile(`\bjwt\.decode\(`), Allowlist: "algorithms", Example: `algorithms=["ES256"]`}},
+		".rb": {{Library: "ruby-jwt", Pattern: regexp.MustCompile(`\bJWT\.decode\(`), Allowlist: "algorithm", Example: "{ algorithm: 'ES256' }"}},
+	}
+
+	// jwtSignatureDisabledPattern matches options that skip signature verification entirely
+	jwtSignatureDisabledPattern = regexp.MustCompile(`['"]verify_signature['"]\s*:\s*False|\bverify\s*=\s*False`)
+
+	// jwtHardcodedSecretPattern captures a string literal passed as the key of a JWT signing call
+	jwtHardcodedSecretPattern = regexp.MustCompile(`\b(?:jwt\.sign|jwt\.encode|JWT\.encode)\(\s*[^,]+,\s*['"]([^'"]*)['"]`)
+
+	// jwtHMACAlgorithmPattern finds the HMAC algorithm named in a signing call
+	jwtHMACAlgorithmPattern = regexp.MustCompile(`\bHS(256|384|512)\b`)
+
+	// jwtAsymmetricAlgorithmPattern finds an RSA, ECDSA or EdDSA algorithm named in a call
+	jwtAsymmetricAlgorithmPattern = regexp.MustCompile(`\b(RS|PS|ES)(256|384|512)\b|\bEdDSA\b`)
+)
+
+// detectJWTMisuse flags JWT verification without an algorithm allowlist, which lets
+// a token pick alg "none" or have an RSA public key accepted as its HMAC secret, and
+// hardcoded HMAC signing secrets
+func (s *Scanner) detectJWTMisuse(filePath string, lines []string) []Result {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	calls := jwtVerifyCalls[ext]
+	if len(calls) == 0 {
+		return nil
+	}
+
+	misuse := func(line int, description, recommendation string) Result {
+		return Result{
+			File:              filePath,
+			Algorithm:         "JWT",
+			Type:              TypeProtocol,
+			Line:              line,
+			Method:            "JWT Verification Analysis",
+			Risk:              "High",
+			VulnerabilityType: "JWT Misuse",
+			CWE:               []string{CWEImproperSignatureVerification},
+			Description:       description,
+			Recommendation:    recommendation,
+		}
+	}
+
+	var results []Result
+	for i, line := range lines {
+		for _, call := range calls {
+			loc := call.Pattern.FindStringIndex(line)
+			if loc == nil {
+				continue
+			}
+			args := jwtCallArguments(lines, i, loc[1])
+			switch {
+			case jwtSignatureDisabledPattern.MatchString(args):
+				result := misuse(i+1, fmt.Sprintf("%s decodes a JWT with signature verification disabled, so any forged token is accepted", call.Library),
+					"Always verify the signature and pin the expected algorithm")
+				result.Risk = "Critical"
+				results = append(results, result)
+			case !strings.Contains(args, call.Allowlist):
+				results = append(results, misuse(i+1, fmt.Sprintf("%s verifies a JWT without an algorithm allowlist, allowing alg \"none\" or RSA/HMAC key confusion", call.Library),
+					fmt.Sprintf("Pass the algorithms you issue, e.g. %s, and never mix HMAC and public-key algorithms for one key", call.Example)))
+			}
+		}
+
+		if match := jwtHardcodedSecretPattern.FindStringSubmatchIndex(line); match != nil {
+			args := jwtCallArguments(lines, i, match[1])
+			if jwtAsymmetricAlgorithmPattern.MatchString(args) {
+				continue // A literal PEM key path or key, not an HMAC secret
+			}
+			algorithm := "HS256" // The libraries' default for string secrets
+			if hmac := jwtHMACAlgorithmPattern.FindString(args); hmac != "" {
+				algorithm = hmac
+			}
+			secret := line[match[2]:match[3]]
+			description := fmt.Sprintf("%s JWT secret is hardcoded in source", algorithm)
+			if len(secret) < jwtMinSecretLength {
+				description = fmt.Sprintf("%s JWT secret is a hardcoded %d-character string, shorter than the %d bytes needed and open to offline brute force", algorithm, len(secret), jwtMinSecretLength)
+			}
+			result := misuse(i+1, description,
+				"Load a random secret of at least 32 bytes from a secret store, or sign with an asymmetric key")
+			result.CWE = []string{CWEHardcodedKey}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// jwtCallArguments returns the text of a call's arguments starting at offset start of
+// lines[index], read up to the closing parenthesis or jwtCallMaxLines lines
+func jwtCallArguments(lines []string, index, start int) string {
+	var b strings.Builder
+	depth := 1
+	text := lines[index][start:]
+	for n := 0; n < jwtCallMaxLines; n++ {
+		for i, r := range text {
+			switch r {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					b.WriteString(text[:i])
+					return b.String()
+				}
+			}
+		}
+		b.WriteString(text)
+		b.WriteString("\n")
+		if index+n+1 >= len(lines) {
+			break
+		}
+		text = lines[index+n+1]
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// scanPodEnvironments reads the literal env values of every pod container, listing
+// namespaces concurrently. Each container is one asset; values from Secrets and
+// ConfigMaps are left to the secret and ConfigMap scans.
+func (k *K8sScanner) scanPodEnvironments(ctx context.Context, namespaces []string) ([]Result, int) {
+	return k.forEachNamespace(ctx, namespaces, func(namespace string) ([]Result, int) {
+		var results []Result
+		assetCount := 0
+
+		if k.scanner.Verbose {
+			k.scanner.logf("Scanning pod environments in namespace: %s\n", namespace)
+		}
+
+		err := eachListItem(ctx, k, func(opts metav1.ListOptions) (*corev1.PodList, error) {
+			return k.clientset.CoreV1().Pods(namespace).List(ctx, opts)
+		}, func(pod *corev1.Pod) {
+			containers := append(append([]corev1.Container(nil), pod.Spec.InitContainers...), pod.Spec.Containers...)
+			for _, container := range containers {
+				assetCount++
+				results = append(results, k.analyzeContainerEnv(pod.Name, namespace, container)...)
+			}
+		})
+		if err != nil {
+			if k.scanner.Verbose {
+				k.scanner.logf("Error listing pods in namespace %s: %v\n", namespace, err)
+			}
+			k.scanner.warn(namespace, "", fmt.Sprintf("failed to list pods: %v", err))
+		}
+
+		return results, assetCount
+	})
+}
+
+// analyzeContainerEnv reports the env entries of a container that weaken its crypto,
+// e.g. GODEBUG=x509sha1=1, and those the detection rules match as NAME=value, such as
+// OPENSSL_CONF. Findings are on the entry's position in the env list.
+func (k *K8sScanner) analyzeContainerEnv(podName, namespace string, container corev1.Container) []Result {
+	var results []Result
+	file := fmt.Sprintf("pod/%s/container/%s/env (%s)", podName, container.Name, namespace)
+	for i, env := range container.Env {
+		if env.ValueFrom != nil {
+			continue
+		}
+		line := env.Name + "=" + env.Value
+		for _, rule := range k.scanner.matchRules(line) {
+			result := Result{
+				File:              file,
+				Algorithm:         rule.AlgorithmName,
+				Type:              rule.AlgorithmType,
+				Line:              i + 1,
+				Method:            "Environment Variable Analysis",
+				Risk:              rule.RiskLevel,
+				VulnerabilityType: rule.VulnerabilityType,
+				CWE:               rule.CWE,
+				Description:       fmt.Sprintf("Container environment sets %s: %s", env.Name, rule.Description),
+				Recommendation:    rule.Recommendation,
+			}
+			populateNISTFields(&result, rule.NISTAlgorithmID)
+			results = append(results, result)
+		}
+		results = append(results, environmentVariableResults(file, i+1, env.Name, env.Value)...)
+	}
+	return results
+}
@@ -1,36 +1,72 @@
 package crypto
 
 import (
+	"bytes"
 	"context"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
 	"fmt"
-	"regexp"
+	"os"
 	"strings"
+	"sync"
 
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// defaultDeepScanConcurrency bounds how many images a deep code scan pulls at once
+const defaultDeepScanConcurrency = 2
+
+// KubernetesScanOptions selects which cluster resources a Kubernetes scan reads
+type KubernetesScanOptions struct {
+	SecretScan        bool
+	ConfigMapScan     bool
+	ImageScan         bool
+	NetworkPolicyScan bool
+	IngressScan       bool
+	ServiceMeshScan   bool
+	DeepCodeScan      bool // Pulls each pod image to scan its code
+	WebhookScan       bool
+	EnvScan           bool
+	IncludeKubeSystem bool // Also scan kube-system, kube-public and kube-node-lease when discovering namespaces
+}
+
 // K8sScanner handles Kubernetes-specific scanning operations
 type K8sScanner struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
 	scanner   *Scanner
+
+	ImageFetcher        ImageFetcher // Retrieves images for deep code scans (default: docker CLI)
+	DeepScanConcurrency int          // Images fetched and scanned in parallel (default: 2)
+	ListPageSize        int          // Objects fetched per List call (default: 500)
+}
+
+// NewK8sScannerWithClient creates a Kubernetes scanner using an existing client,
+// such as a fake clientset in tests
+func NewK8sScannerWithClient(scanner *Scanner, client kubernetes.Interface) *K8sScanner {
+	return &K8sScanner{
+		clientset: client,
+		scanner:   scanner,
+	}
 }
 
-// NewK8sScanner creates a new Kubernetes scanner
+// NewK8sScanner creates a new Kubernetes scanner for the cluster selected by
+// the scanner's Kubeconfig and KubeContext
 func NewK8sScanner(scanner *Scanner) (*K8sScanner, error) {
-	// Try in-cluster config first, then kubeconfig
-	config, err := rest.InClusterConfig()
+	config, err := BuildKubeConfig(scanner.Kubeconfig, scanner.KubeContext)
 	if err != nil {
-		// Fallback to kubeconfig
-		config, err = clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create Kubernetes config: %v", err)
-		}
+		return nil, fmt.Errorf("failed to create Kubernetes config: %v", err)
 	}
 
+	// client-go's default of 5 QPS is too slow for namespace-parallel listing
+	config.QPS = defaultAPIQPS
+	config.Burst = defaultAPIBurst
+
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
@@ -42,134 +78,149 @@ func NewK8sScanner(scanner *Scanner) (*K8sScanner, error) {
 	}, nil
 }
 
-// ScanKubernetesCluster scans a Kubernetes cluster for crypto vulnerabilities
-func (k *K8sScanner) ScanKubernetesCluster(namespaces []string, secretScan, configMapScan, imageScan, networkPolicyScan, ingressScan, serviceMeshScan, deepCodeScan, includeKubeSystem bool) ([]Result, int) {
+// BuildKubeConfig loads the client config for kubeconfigPath and kubeContext.
+// Without either, the in-cluster config is tried first; otherwise the standard
+// loading rules apply ($KUBECONFIG, then ~/.kube/config) with kubeContext
+// overriding the file's current context.
+func BuildKubeConfig(kubeconfigPath, kubeContext string) (*rest.Config, error) {
+	if kubeconfigPath == "" && kubeContext == "" {
+		if config, err := rest.InClusterConfig(); err == nil {
+			return config, nil
+		}
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfigPath
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+}
+
+// ScanKubernetesCluster scans a Kubernetes cluster for crypto vulnerabilities.
+// Scanning stops between namespaces once ctx is done, returning what was gathered.
+func (k *K8sScanner) ScanKubernetesCluster(ctx context.Context, namespaces []string, opts KubernetesScanOptions) ([]Result, int) {
 	var results []Result
 	assetCount := 0
 
 	// If no namespaces specified, get all accessible namespaces
 	if len(namespaces) == 0 {
-		discoveredNamespaces, err := k.discoverNamespaces(includeKubeSystem)
+		discoveredNamespaces, err := k.discoverNamespaces(ctx, opts.IncludeKubeSystem)
 		if err != nil {
 			if k.scanner.Verbose {
-				fmt.Printf("Error discovering namespaces: %v\n", err)
+				k.scanner.logf("Error discovering namespaces: %v\n", err)
 			}
-		} else {
-			namespaces = discoveredNamespaces
+			k.scanner.warn("", "", fmt.Sprintf("failed to discover namespaces: %v", err))
 		}
+		namespaces = discoveredNamespaces
 	}
 
 	if k.scanner.Verbose {
-		fmt.Printf("Scanning Kubernetes cluster across %d namespaces: %v\n", len(namespaces), namespaces)
-	}
-
-	// Scan secrets for crypto material
-	if secretScan {
-		secretResults, secretCount := k.scanSecrets(namespaces)
-		results = append(results, secretResults...)
-		assetCount += secretCount
+		k.scanner.logf("Scanning Kubernetes cluster across %d namespaces: %v\n", len(namespaces), namespaces)
 	}
 
-	// Scan ConfigMaps for crypto configurations
-	if configMapScan {
-		configMapResults, configMapCount := k.scanConfigMaps(namespaces)
-		results = append(results, configMapResults...)
-		assetCount += configMapCount
+	// Resource kinds are scanned concurrently, each listing namespaces in parallel.
+	// Every scan keeps its own findings and asset count, combined in a fixed order
+	// once all are done, so both match a serial scan exactly.
+	resourceScans := []struct {
+		enabled bool
+		scan    func(context.Context, []string) ([]Result, int)
+	}{
+		{opts.SecretScan, k.scanSecrets},        // Secrets for crypto material
+		{opts.ConfigMapScan, k.scanConfigMaps},  // ConfigMaps for crypto configurations
+		{opts.ImageScan, k.scanContainerImages}, // Container images by name
+		{opts.DeepCodeScan, k.deepScanImages},   // Pulls each image, so only when explicitly requested
+		{opts.NetworkPolicyScan, k.scanNetworkPolicies},
+		{opts.IngressScan, k.scanIngresses},
+		{opts.WebhookScan, k.scanWebhookCABundles}, // Cluster-scoped, so listed once
+		{opts.EnvScan, k.scanPodEnvironments},
 	}
-
-	// Scan container images (if enabled)
-	if imageScan {
-		imageResults, imageCount := k.scanContainerImages(namespaces)
-		results = append(results, imageResults...)
-		assetCount += imageCount
+	type resourceScanResult struct {
+		results []Result
+		assets  int
 	}
-
-	// Additional resource scanning (placeholder for now)
-	if networkPolicyScan {
-		networkResults, networkCount := k.scanNetworkPolicies(namespaces)
-		results = append(results, networkResults...)
-		assetCount += networkCount
+	scans := make([]resourceScanResult, len(resourceScans))
+	var wg sync.WaitGroup
+	for i, resource := range resourceScans {
+		if !resource.enabled {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, scan func(context.Context, []string) ([]Result, int)) {
+			defer wg.Done()
+			scans[i].results, scans[i].assets = scan(ctx, namespaces)
+		}(i, resource.scan)
 	}
+	wg.Wait()
 
-	if ingressScan {
-		ingressResults, ingressCount := k.scanIngresses(namespaces)
-		results = append(results, ingressResults...)
-		assetCount += ingressCount
+	for _, scan := range scans {
+		results = append(results, scan.results...)
+		assetCount += scan.assets
 	}
 
 	if k.scanner.Verbose {
-		fmt.Printf("Kubernetes scan completed. Analyzed %d assets across %d namespaces.\n", assetCount, len(namespaces))
+		k.scanner.logf("Kubernetes scan completed. Analyzed %d assets across %d namespaces.\n", assetCount, len(namespaces))
 	}
 
 	return results, assetCount
 }
 
-// discoverNamespaces discovers all accessible namespaces
-func (k *K8sScanner) discoverNamespaces(includeKubeSystem bool) ([]string, error) {
-	namespaceList, err := k.clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
-
+// discoverNamespaces discovers all accessible namespaces. Those listed before an
+// error are returned with it.
+func (k *K8sScanner) discoverNamespaces(ctx context.Context, includeKubeSystem bool) ([]string, error) {
 	var namespaces []string
-	for _, ns := range namespaceList.Items {
+	err := eachListItem(ctx, k, func(opts metav1.ListOptions) (*corev1.NamespaceList, error) {
+		return k.clientset.CoreV1().Namespaces().List(ctx, opts)
+	}, func(ns *corev1.Namespace) {
 		// Skip kube-system unless explicitly requested
-		if !includeKubeSystem && (ns.Name == "kube-system" || ns.Name == "kube-public" || ns.Name == "kube-node-lease") {
-			continue
+		if includeKubeSystem || (ns.Name != "kube-system" && ns.Name != "kube-public" && ns.Name != "kube-node-lease") {
+			namespaces = append(namespaces, ns.Name)
 		}
-		namespaces = append(namespaces, ns.Name)
-	}
-
-	return namespaces, nil
+	})
+	return namespaces, err
 }
 
-// scanSecrets scans Kubernetes secrets for crypto material
-func (k *K8sScanner) scanSecrets(namespaces []string) ([]Result, int) {
-	var results []Result
-	assetCount := 0
+// scanSecrets scans Kubernetes secrets for crypto material, listing namespaces concurrently
+func (k *K8sScanner) scanSecrets(ctx context.Context, namespaces []string) ([]Result, int) {
+	return k.forEachNamespace(ctx, namespaces, func(namespace string) ([]Result, int) {
+		var results []Result
+		assetCount := 0
 
-	for _, namespace := range namespaces {
 		if k.scanner.Verbose {
-			fmt.Printf("Scanning secrets in namespace: %s\n", namespace)
+			k.scanner.logf("Scanning secrets in namespace: %s\n", namespace)
 		}
 
-		secretList, err := k.clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			if k.scanner.Verbose {
-				fmt.Printf("Error listing secrets in namespace %s: %v\n", namespace, err)
-			}
-			continue
-		}
-
-		for _, secret := range secretList.Items {
+		// Secrets are analyzed page by page, so memory stays flat in large namespaces
+		err := eachListItem(ctx, k, func(opts metav1.ListOptions) (*corev1.SecretList, error) {
+			return k.clientset.CoreV1().Secrets(namespace).List(ctx, opts)
+		}, func(secret *corev1.Secret) {
 			assetCount++
 			secretResults := k.analyzeSecret(secret.Name, namespace, secret.Data, string(secret.Type))
 			results = append(results, secretResults...)
+		})
+		if err != nil {
+			if k.scanner.Verbose {
+				k.scanner.logf("Error listing secrets in namespace %s: %v\n", namespace, err)
+			}
+			k.scanner.warn(namespace, "", fmt.Sprintf("failed to list secrets: %v", err))
 		}
-	}
 
-	return results, assetCount
+		return results, assetCount
+	})
 }
 
-// analyzeSecret analyzes a Kubernetes secret for crypto vulnerabilities
+// analyzeSecret analyzes a Kubernetes secret for crypto vulnerabilities.
+// The API server already base64-decodes secret data, so values are raw PEM, DER or text.
 func (k *K8sScanner) analyzeSecret(secretName, namespace string, data map[string][]byte, secretType string) []Result {
 	var results []Result
 
 	for key, value := range data {
-		// Decode base64 content if needed
-		content := string(value)
-		
-		// Check if content is base64 encoded (common for TLS secrets)
-		if decoded, err := base64.StdEncoding.DecodeString(content); err == nil {
-			content = string(decoded)
-		}
-
-		// Analyze content for crypto patterns
-		lines := strings.Split(content, "\n")
-		for lineNum, line := range lines {
-			for _, rule := range k.scanner.Rules {
-				if match, _ := regexp.MatchString(rule.Pattern, line); match {
-					results = append(results, Result{
+		content, isDER := decodeSecretValue(value)
+
+		// Analyze text content for crypto patterns; DER is binary and has no lines to match
+		if !isDER {
+			lines := strings.Split(string(content), "\n")
+			for lineNum, line := range lines {
+				for _, rule := range k.scanner.matchRules(line) {
+					result := Result{
 						File:              fmt.Sprintf("secret/%s/%s (%s)", secretName, key, namespace),
 						Algorithm:         rule.AlgorithmName,
 						Type:              rule.AlgorithmType,
@@ -177,89 +228,120 @@ func (k *K8sScanner) analyzeSecret(secretName, namespace string, data map[string
 						Method:            "Kubernetes Secret Analysis",
 						Risk:              rule.RiskLevel,
 						VulnerabilityType: rule.VulnerabilityType,
+						CWE:               rule.CWE,
 						Description:       fmt.Sprintf("Secret contains %s: %s", rule.AlgorithmName, rule.Description),
 						Recommendation:    rule.Recommendation,
-					})
+					}
+					populateNISTFields(&result, rule.NISTAlgorithmID)
+					results = append(results, result)
 				}
 			}
+			results = append(results, analyzeTLSDirectives(fmt.Sprintf("secret/%s/%s (%s)", secretName, key, namespace), string(content))...)
 		}
 
-		// Special handling for TLS secrets
-		if secretType == "kubernetes.io/tls" || strings.Contains(key, "tls") || strings.Contains(key, "cert") {
-			if strings.Contains(content, "BEGIN CERTIFICATE") || strings.Contains(content, "BEGIN RSA PRIVATE KEY") {
-				// Analyze certificate/key content
-				certResults := k.analyzeTLSMaterial(secretName, namespace, key, content)
-				results = append(results, certResults...)
-			}
+		// Certificates and keys are analyzed structurally, whatever the secret type
+		if isDER {
+			content = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: content})
+		}
+		if bytes.Contains(content, []byte("-----BEGIN ")) {
+			certResults := k.analyzeTLSMaterial(secretName, namespace, key, string(content))
+			results = append(results, certResults...)
 		}
 	}
 
 	return results
 }
 
-// analyzeTLSMaterial analyzes TLS certificates and keys
-func (k *K8sScanner) analyzeTLSMaterial(secretName, namespace, key, content string) []Result {
-	var results []Result
+// decodeSecretValue returns the content of a secret value to analyze. PEM and DER
+// are used as-is; text that is itself base64 (secrets double-encoded by some tools)
+// is decoded only when it decodes to PEM or DER. isDER reports a DER certificate.
+func decodeSecretValue(value []byte) (content []byte, isDER bool) {
+	if isDERCertificate(value) {
+		return value, true
+	}
+	if bytes.Contains(value, []byte("-----BEGIN ")) || !looksBase64(value) {
+		return value, false
+	}
 
-	// Check for RSA keys/certificates
-	if strings.Contains(content, "RSA") || (strings.Contains(content, "BEGIN PRIVATE KEY") && len(content) > 1000) {
-		results = append(results, Result{
-			File:              fmt.Sprintf("secret/%s/%s (%s)", secretName, key, namespace),
-			Algorithm:         "RSA",
-			Type:              "PublicKey",
-			Line:              1,
-			Method:            "TLS Certificate Analysis",
-			Risk:              "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "TLS certificate/key uses RSA algorithm vulnerable to quantum attacks",
-			Recommendation:    "Replace with post-quantum certificate when available from CA",
-		})
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.Join(bytes.Fields(value), nil)))
+	if err != nil {
+		return value, false
 	}
+	if bytes.Contains(decoded, []byte("-----BEGIN ")) {
+		return decoded, false
+	}
+	if isDERCertificate(decoded) {
+		return decoded, true
+	}
+	return value, false
+}
 
-	// Check for ECDSA certificates
-	if strings.Contains(content, "EC PRIVATE KEY") || strings.Contains(content, "prime256v1") || strings.Contains(content, "secp") {
-		results = append(results, Result{
-			File:              fmt.Sprintf("secret/%s/%s (%s)", secretName, key, namespace),
-			Algorithm:         "ECDSA",
-			Type:              "PublicKey",
-			Line:              1,
-			Method:            "TLS Certificate Analysis",
-			Risk:              "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "TLS certificate/key uses ECDSA algorithm vulnerable to quantum attacks",
-			Recommendation:    "Replace with post-quantum certificate when available from CA",
-		})
+// isDERCertificate reports whether data is a single DER-encoded X.509 certificate
+func isDERCertificate(data []byte) bool {
+	if len(data) == 0 || data[0] != 0x30 {
+		return false
 	}
+	_, err := x509.ParseCertificate(data)
+	return err == nil
+}
 
-	return results
+// looksBase64 reports whether data is plausibly base64-wrapped text: only base64
+// characters and line breaks, and long enough to hold a key or certificate
+func looksBase64(data []byte) bool {
+	trimmed := bytes.Join(bytes.Fields(data), nil)
+	if len(trimmed) < 64 || len(trimmed)%4 != 0 {
+		return false
+	}
+	for _, c := range trimmed {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '+', c == '/', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
 }
 
-// scanConfigMaps scans Kubernetes ConfigMaps for crypto configurations
-func (k *K8sScanner) scanConfigMaps(namespaces []string) ([]Result, int) {
+// analyzeTLSMaterial analyzes TLS certificates and keys
+func (k *K8sScanner) analyzeTLSMaterial(secretName, namespace, key, content string) []Result {
 	var results []Result
-	assetCount := 0
+	file := fmt.Sprintf("secret/%s/%s (%s)", secretName, key, namespace)
 
-	for _, namespace := range namespaces {
-		if k.scanner.Verbose {
-			fmt.Printf("Scanning ConfigMaps in namespace: %s\n", namespace)
-		}
+	// Certificates and keys are parsed to report their real algorithm and size
+	certResults, keys := analyzePEMCertificates(file, content)
+	results = append(results, certResults...)
+	results = append(results, analyzePEMKeys(file, keys)...)
+	results = append(results, analyzeDHParameters(file, content)...)
 
-		configMapList, err := k.clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			if k.scanner.Verbose {
-				fmt.Printf("Error listing ConfigMaps in namespace %s: %v\n", namespace, err)
-			}
-			continue
+	return results
+}
+
+// scanConfigMaps scans Kubernetes ConfigMaps for crypto configurations, listing namespaces concurrently
+func (k *K8sScanner) scanConfigMaps(ctx context.Context, namespaces []string) ([]Result, int) {
+	return k.forEachNamespace(ctx, namespaces, func(namespace string) ([]Result, int) {
+		var results []Result
+		assetCount := 0
+
+		if k.scanner.Verbose {
+			k.scanner.logf("Scanning ConfigMaps in namespace: %s\n", namespace)
 		}
 
-		for _, configMap := range configMapList.Items {
+		err := eachListItem(ctx, k, func(opts metav1.ListOptions) (*corev1.ConfigMapList, error) {
+			return k.clientset.CoreV1().ConfigMaps(namespace).List(ctx, opts)
+		}, func(configMap *corev1.ConfigMap) {
 			assetCount++
 			configMapResults := k.analyzeConfigMap(configMap.Name, namespace, configMap.Data)
 			results = append(results, configMapResults...)
+		})
+		if err != nil {
+			if k.scanner.Verbose {
+				k.scanner.logf("Error listing ConfigMaps in namespace %s: %v\n", namespace, err)
+			}
+			k.scanner.warn(namespace, "", fmt.Sprintf("failed to list ConfigMaps: %v", err))
 		}
-	}
 
-	return results, assetCount
+		return results, assetCount
+	})
 }
 
 // analyzeConfigMap analyzes a ConfigMap for crypto configurations
@@ -269,46 +351,48 @@ func (k *K8sScanner) analyzeConfigMap(configMapName, namespace string, data map[
 	for key, content := range data {
 		lines := strings.Split(content, "\n")
 		for lineNum, line := range lines {
-			for _, rule := range k.scanner.Rules {
-				if match, _ := regexp.MatchString(rule.Pattern, line); match {
-					results = append(results, Result{
-						File:              fmt.Sprintf("configmap/%s/%s (%s)", configMapName, key, namespace),
-						Algorithm:         rule.AlgorithmName,
-						Type:              rule.AlgorithmType,
-						Line:              lineNum + 1,
-						Method:            "Kubernetes ConfigMap Analysis",
-						Risk:              rule.RiskLevel,
-						VulnerabilityType: rule.VulnerabilityType,
-						Description:       fmt.Sprintf("ConfigMap contains crypto configuration: %s", rule.Description),
-						Recommendation:    rule.Recommendation,
-					})
+			for _, rule := range k.scanner.matchRules(line) {
+				result := Result{
+					File:              fmt.Sprintf("configmap/%s/%s (%s)", configMapName, key, namespace),
+					Algorithm:         rule.AlgorithmName,
+					Type:              rule.AlgorithmType,
+					Line:              lineNum + 1,
+					Method:            "Kubernetes ConfigMap Analysis",
+					Risk:              rule.RiskLevel,
+					VulnerabilityType: rule.VulnerabilityType,
+					CWE:               rule.CWE,
+					Description:       fmt.Sprintf("ConfigMap contains crypto configuration: %s", rule.Description),
+					Recommendation:    rule.Recommendation,
 				}
+				populateNISTFields(&result, rule.NISTAlgorithmID)
+				results = append(results, result)
 			}
 		}
+
+		// Server and SSH configuration directives are read entry by entry
+		results = append(results, analyzeTLSDirectives(fmt.Sprintf("configmap/%s/%s (%s)", configMapName, key, namespace), content)...)
+
+		// Diffie-Hellman parameters are public, so they are often mounted from ConfigMaps
+		results = append(results, analyzeDHParameters(fmt.Sprintf("configmap/%s/%s (%s)", configMapName, key, namespace), content)...)
 	}
 
 	return results
 }
 
-// scanContainerImages scans container images in pods (placeholder implementation)
-func (k *K8sScanner) scanContainerImages(namespaces []string) ([]Result, int) {
-	var results []Result
-	assetCount := 0
+// scanContainerImages scans container images in pods (placeholder implementation),
+// listing namespaces concurrently
+func (k *K8sScanner) scanContainerImages(ctx context.Context, namespaces []string) ([]Result, int) {
+	return k.forEachNamespace(ctx, namespaces, func(namespace string) ([]Result, int) {
+		var results []Result
+		assetCount := 0
 
-	for _, namespace := range namespaces {
 		if k.scanner.Verbose {
-			fmt.Printf("Scanning container images in namespace: %s\n", namespace)
+			k.scanner.logf("Scanning container images in namespace: %s\n", namespace)
 		}
 
-		podList, err := k.clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			if k.scanner.Verbose {
-				fmt.Printf("Error listing pods in namespace %s: %v\n", namespace, err)
-			}
-			continue
-		}
-
-		for _, pod := range podList.Items {
+		err := eachListItem(ctx, k, func(opts metav1.ListOptions) (*corev1.PodList, error) {
+			return k.clientset.CoreV1().Pods(namespace).List(ctx, opts)
+		}, func(pod *corev1.Pod) {
 			for _, container := range pod.Spec.Containers {
 				assetCount++
 				// Placeholder: In a real implementation, this would scan the container image
@@ -316,10 +400,16 @@ func (k *K8sScanner) scanContainerImages(namespaces []string) ([]Result, int) {
 				imageResults := k.analyzeContainerImage(pod.Name, namespace, container.Name, container.Image)
 				results = append(results, imageResults...)
 			}
+		})
+		if err != nil {
+			if k.scanner.Verbose {
+				k.scanner.logf("Error listing pods in namespace %s: %v\n", namespace, err)
+			}
+			k.scanner.warn(namespace, "", fmt.Sprintf("failed to list pods: %v", err))
 		}
-	}
 
-	return results, assetCount
+		return results, assetCount
+	})
 }
 
 // analyzeContainerImage analyzes container images for crypto libraries (placeholder)
@@ -328,7 +418,7 @@ func (k *K8sScanner) analyzeContainerImage(podName, namespace, containerName, im
 
 	// Placeholder logic - in reality this would scan the actual image layers
 	// For now, make educated guesses based on common patterns
-	
+
 	if strings.Contains(image, "openssl") || strings.Contains(image, "ssl") {
 		results = append(results, Result{
 			File:              fmt.Sprintf("pod/%s/container/%s (%s)", podName, containerName, namespace),
@@ -346,55 +436,190 @@ func (k *K8sScanner) analyzeContainerImage(podName, namespace, containerName, im
 	return results
 }
 
-// scanNetworkPolicies scans network policies (placeholder)
-func (k *K8sScanner) scanNetworkPolicies(namespaces []string) ([]Result, int) {
-	var results []Result
-	assetCount := 0
+// podContainer identifies a container running a given image
+type podContainer struct {
+	pod, container, namespace string
+}
+
+// deepScanImages pulls the image of every pod container and scans its application
+// files. Each distinct image is fetched once; findings are keyed by pod/container/path.
+func (k *K8sScanner) deepScanImages(ctx context.Context, namespaces []string) ([]Result, int) {
+	var images []string
+	containers := make(map[string][]podContainer)
 
 	for _, namespace := range namespaces {
-		networkPolicyList, err := k.clientset.NetworkingV1().NetworkPolicies(namespace).List(context.TODO(), metav1.ListOptions{})
+		if ctx.Err() != nil {
+			break
+		}
+		err := eachListItem(ctx, k, func(opts metav1.ListOptions) (*corev1.PodList, error) {
+			return k.clientset.CoreV1().Pods(namespace).List(ctx, opts)
+		}, func(pod *corev1.Pod) {
+			for _, container := range pod.Spec.Containers {
+				if _, seen := containers[container.Image]; !seen {
+					images = append(images, container.Image)
+				}
+				containers[container.Image] = append(containers[container.Image], podContainer{pod.Name, container.Name, namespace})
+			}
+		})
 		if err != nil {
+			if k.scanner.Verbose {
+				k.scanner.logf("Error listing pods in namespace %s: %v\n", namespace, err)
+			}
+			k.scanner.warn(namespace, "", fmt.Sprintf("failed to list pods: %v", err))
 			continue
 		}
+	}
 
-		assetCount += len(networkPolicyList.Items)
-		// Placeholder - network policies don't typically contain crypto directly
+	type imageScan struct {
+		results []Result
+		files   int
 	}
+	scans := make([]imageScan, len(images))
 
-	return results, assetCount
-}
+	concurrency := k.DeepScanConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultDeepScanConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, image := range images {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, image string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			scans[i].results, scans[i].files = k.deepScanImage(ctx, image)
+		}(i, image)
+	}
+	wg.Wait()
 
-// scanIngresses scans ingress configurations (placeholder)
-func (k *K8sScanner) scanIngresses(namespaces []string) ([]Result, int) {
 	var results []Result
 	assetCount := 0
+	for i, image := range images {
+		assetCount += scans[i].files
+		for _, ref := range containers[image] {
+			for _, result := range scans[i].results {
+				result.File = fmt.Sprintf("pod/%s/container/%s/%s (%s)", ref.pod, ref.container, result.File, ref.namespace)
+				results = append(results, result)
+			}
+		}
+	}
 
-	for _, namespace := range namespaces {
-		ingressList, err := k.clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+	return results, assetCount
+}
+
+// deepScanImage fetches a single image into a temporary directory and scans it
+func (k *K8sScanner) deepScanImage(ctx context.Context, image string) ([]Result, int) {
+	fetcher := k.ImageFetcher
+	if fetcher == nil {
+		fetcher = DockerImageFetcher{}
+	}
+
+	dir, err := os.MkdirTemp("", "cbom-image-")
+	if err != nil {
+		k.scanner.logf("Error creating directory for image %s: %v\n", image, err)
+		k.scanner.warn("", image, fmt.Sprintf("failed to create a directory for the image: %v", err))
+		return nil, 0
+	}
+	defer os.RemoveAll(dir)
+
+	if k.scanner.Verbose {
+		k.scanner.logf("Deep scanning container image: %s\n", image)
+	}
+	tarPath, err := fetcher.FetchImage(ctx, image, dir)
+	if err != nil {
+		if k.scanner.Verbose {
+			k.scanner.logf("Error fetching image %s: %v\n", image, err)
+		}
+		k.scanner.warn("", image, fmt.Sprintf("failed to fetch image: %v", err))
+		return nil, 0
+	}
+
+	return k.scanner.ScanImageTar(tarPath)
+}
+
+// scanNetworkPolicies counts network policies (placeholder), listing namespaces concurrently
+func (k *K8sScanner) scanNetworkPolicies(ctx context.Context, namespaces []string) ([]Result, int) {
+	return k.forEachNamespace(ctx, namespaces, func(namespace string) ([]Result, int) {
+		assetCount := 0
+		err := eachListItem(ctx, k, func(opts metav1.ListOptions) (*networkingv1.NetworkPolicyList, error) {
+			return k.clientset.NetworkingV1().NetworkPolicies(namespace).List(ctx, opts)
+		}, func(*networkingv1.NetworkPolicy) {
+			assetCount++
+		})
 		if err != nil {
-			continue
+			k.scanner.warn(namespace, "", fmt.Sprintf("failed to list network policies: %v", err))
 		}
 
-		for _, ingress := range ingressList.Items {
+		// Placeholder - network policies don't typically contain crypto directly
+		return nil, assetCount
+	})
+}
+
+// scanIngresses scans ingress configurations, resolving each TLS secret to report
+// the algorithm of the certificate actually served, listing namespaces concurrently
+func (k *K8sScanner) scanIngresses(ctx context.Context, namespaces []string) ([]Result, int) {
+	return k.forEachNamespace(ctx, namespaces, func(namespace string) ([]Result, int) {
+		var results []Result
+		assetCount := 0
+
+		err := eachListItem(ctx, k, func(opts metav1.ListOptions) (*networkingv1.IngressList, error) {
+			return k.clientset.NetworkingV1().Ingresses(namespace).List(ctx, opts)
+		}, func(ingress *networkingv1.Ingress) {
 			assetCount++
-			// Check TLS configuration in ingresses
 			for _, tls := range ingress.Spec.TLS {
 				if tls.SecretName != "" {
-					results = append(results, Result{
-						File:              fmt.Sprintf("ingress/%s/tls/%s (%s)", ingress.Name, tls.SecretName, namespace),
-						Algorithm:         "TLS",
-						Type:              "PublicKey",
-						Line:              1,
-						Method:            "Ingress TLS Analysis",
-						Risk:              "High",
-						VulnerabilityType: "Shor's Algorithm",
-						Description:       "Ingress uses TLS certificate that may contain quantum-vulnerable algorithms",
-						Recommendation:    "Verify TLS certificate uses post-quantum algorithms",
-					})
+					results = append(results, k.analyzeIngressTLS(ctx, ingress.Name, namespace, tls.SecretName)...)
 				}
 			}
+			file := fmt.Sprintf("ingress/%s (%s)", ingress.Name, namespace)
+			results = append(results, analyzeIngressAnnotations(file, ingress.Annotations)...)
+		})
+		if err != nil {
+			k.scanner.warn(namespace, "", fmt.Sprintf("failed to list ingresses: %v", err))
 		}
+
+		return results, assetCount
+	})
+}
+
+// analyzeIngressTLS fetches an ingress TLS secret and reports its certificate's algorithm.
+// Quantum-safe certificates produce no findings; an unreadable secret is reported as unverified.
+func (k *K8sScanner) analyzeIngressTLS(ctx context.Context, ingressName, namespace, secretName string) []Result {
+	file := fmt.Sprintf("ingress/%s/tls/%s (%s)", ingressName, secretName, namespace)
+
+	secret, err := k.clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		if k.scanner.Verbose {
+			k.scanner.logf("Error reading TLS secret %s for ingress %s in namespace %s: %v\n", secretName, ingressName, namespace, err)
+		}
+		k.scanner.warn(namespace, "", fmt.Sprintf("failed to read TLS secret %s of ingress %s: %v", secretName, ingressName, err))
+		return []Result{{
+			File:              file,
+			Algorithm:         "TLS",
+			Type:              "PublicKey",
+			Line:              1,
+			Method:            "Ingress TLS Analysis",
+			Risk:              "Medium",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       fmt.Sprintf("Ingress TLS secret could not be read (%v); its certificate may contain quantum-vulnerable algorithms", err),
+			Recommendation:    "Grant the scanner read access to the secret or verify the certificate manually",
+		}}
 	}
 
-	return results, assetCount
-}
\ No newline at end of file
+	content, isDER := decodeSecretValue(secret.Data["tls.crt"])
+	if isDER {
+		content = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: content})
+	}
+	results, _ := analyzePEMCertificates(file, string(content))
+	for i := range results {
+		results[i].Method = "Ingress TLS Analysis"
+	}
+	return results
+}
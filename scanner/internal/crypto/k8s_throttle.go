@@ -0,0 +1,120 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/pager"
+	"k8s.io/client-go/util/retry"
+)
+
+// Client-side limits keep large cluster scans under API server rate limits. The
+// QPS and burst are set on the rest.Config, so client-go's own rate limiter
+// applies them to every request.
+const (
+	defaultNamespaceConcurrency = 8  // Namespaces listed in parallel
+	defaultAPIQPS               = 20 // Sustained API requests per second
+	defaultAPIBurst             = 40 // Requests allowed above the sustained rate
+)
+
+// apiRetryBackoff spaces out the retries of a throttled or timed-out List call.
+// The REST client already waits out a Retry-After hint from the server itself;
+// this covers the responses that come without one.
+var apiRetryBackoff = wait.Backoff{Steps: 5, Duration: 200 * time.Millisecond, Factor: 2, Jitter: 0.1}
+
+// eachListItem lists a resource with client-go's pager in pages of k.ListPageSize
+// objects, handing each object to handle as its page arrives. One page is fetched
+// ahead while the last is handled, so memory stays flat in large namespaces. A
+// throttled or timed-out page is retried from the same continue token. When a
+// later page fails, e.g. with 410 Gone once the continue token expires, the
+// objects already handled stand and the error says the listing is partial.
+func eachListItem[L, T runtime.Object](ctx context.Context, k *K8sScanner, list func(opts metav1.ListOptions) (L, error), handle func(item T)) error {
+	listPager := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		defer k.scanner.timing.add(&k.scanner.timing.api, time.Now())
+		var page L
+		err := retry.OnError(apiRetryBackoff, func(err error) bool {
+			if !isRetryableAPIError(err) || ctx.Err() != nil {
+				return false
+			}
+			if k.scanner.Verbose {
+				k.scanner.logf("Kubernetes API request failed (%v), retrying\n", err)
+			}
+			return true
+		}, func() (err error) {
+			page, err = list(opts)
+			return err
+		})
+		return page, err
+	})
+	listPager.PageBufferSize = 1
+	if k.ListPageSize > 0 {
+		listPager.PageSize = int64(k.ListPageSize)
+	}
+
+	handled := 0
+	err := listPager.EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+		item, ok := obj.(T)
+		if !ok {
+			return fmt.Errorf("unexpected %T in list", obj)
+		}
+		handle(item)
+		handled++
+		return nil
+	})
+	if err != nil && handled > 0 {
+		return fmt.Errorf("listing stopped after %d objects, results are partial: %w", handled, err)
+	}
+	return err
+}
+
+// isRetryableAPIError reports whether an API error is transient
+func isRetryableAPIError(err error) bool {
+	return apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsServiceUnavailable(err)
+}
+
+// forEachNamespace runs scan for every namespace on a bounded pool of workers.
+// Results are combined in namespace order; a namespace that fails contributes nothing
+// and does not stop the others.
+func (k *K8sScanner) forEachNamespace(ctx context.Context, namespaces []string, scan func(namespace string) ([]Result, int)) ([]Result, int) {
+	type namespaceScan struct {
+		results []Result
+		assets  int
+	}
+	scans := make([]namespaceScan, len(namespaces))
+
+	sem := make(chan struct{}, defaultNamespaceConcurrency)
+	var wg sync.WaitGroup
+	for i, namespace := range namespaces {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, namespace string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			scans[i].results, scans[i].assets = scan(namespace)
+		}(i, namespace)
+	}
+	wg.Wait()
+
+	var results []Result
+	assetCount := 0
+	for _, scan := range scans {
+		results = append(results, scan.results...)
+		assetCount += scan.assets
+	}
+	return results, assetCount
+}
@@ -0,0 +1,113 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// apiServicesPath lists the aggregated APIs registered with the API server. Their
+// types live outside client-go, so they are read as unstructured objects.
+const apiServicesPath = "/apis/apiregistration.k8s.io/v1/apiservices"
+
+// scanWebhookCABundles scans the CA bundles the API server trusts when calling
+// admission webhooks and aggregated APIs. These resources are cluster-scoped, so
+// namespaces are ignored; every webhook or APIService is one asset.
+func (k *K8sScanner) scanWebhookCABundles(ctx context.Context, _ []string) ([]Result, int) {
+	var results []Result
+	assetCount := 0
+
+	err := eachListItem(ctx, k, func(opts metav1.ListOptions) (*admissionregistrationv1.ValidatingWebhookConfigurationList, error) {
+		return k.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, opts)
+	}, func(config *admissionregistrationv1.ValidatingWebhookConfiguration) {
+		for _, webhook := range config.Webhooks {
+			assetCount++
+			file := fmt.Sprintf("validatingwebhookconfiguration/%s/%s", config.Name, webhook.Name)
+			results = append(results, analyzeCABundle(file, webhook.ClientConfig.CABundle)...)
+		}
+	})
+	if err != nil {
+		k.webhookListFailed("validating webhook configurations", err)
+	}
+
+	err = eachListItem(ctx, k, func(opts metav1.ListOptions) (*admissionregistrationv1.MutatingWebhookConfigurationList, error) {
+		return k.clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, opts)
+	}, func(config *admissionregistrationv1.MutatingWebhookConfiguration) {
+		for _, webhook := range config.Webhooks {
+			assetCount++
+			file := fmt.Sprintf("mutatingwebhookconfiguration/%s/%s", config.Name, webhook.Name)
+			results = append(results, analyzeCABundle(file, webhook.ClientConfig.CABundle)...)
+		}
+	})
+	if err != nil {
+		k.webhookListFailed("mutating webhook configurations", err)
+	}
+
+	apiResults, apiAssets := k.scanAPIServiceCABundles(ctx)
+	return append(results, apiResults...), assetCount + apiAssets
+}
+
+// scanAPIServiceCABundles scans the CA bundles of aggregated APIServices. Clients
+// without a REST client, such as the fake clientset, have none to scan.
+func (k *K8sScanner) scanAPIServiceCABundles(ctx context.Context) ([]Result, int) {
+	restClient := k.clientset.Discovery().RESTClient()
+	if restClient == nil {
+		return nil, 0
+	}
+
+	var results []Result
+	assetCount := 0
+	err := eachListItem(ctx, k, func(opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+		request := restClient.Get().AbsPath(apiServicesPath).Param("limit", fmt.Sprint(opts.Limit))
+		if opts.Continue != "" {
+			request = request.Param("continue", opts.Continue)
+		}
+		data, err := request.Do(ctx).Raw()
+		if err != nil {
+			return nil, err
+		}
+		list := &unstructured.UnstructuredList{}
+		if err := list.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("failed to decode APIServices: %v", err)
+		}
+		return list, nil
+	}, func(service *unstructured.Unstructured) {
+		assetCount++
+		file := fmt.Sprintf("apiservice/%s", service.GetName())
+		encoded, _, _ := unstructured.NestedString(service.Object, "spec", "caBundle")
+		caBundle, _ := base64.StdEncoding.DecodeString(encoded)
+		results = append(results, analyzeCABundle(file, caBundle)...)
+	})
+	if err != nil {
+		k.webhookListFailed("APIServices", err)
+	}
+	return results, assetCount
+}
+
+// webhookListFailed records a cluster-scoped resource kind that could not be listed
+func (k *K8sScanner) webhookListFailed(kind string, err error) {
+	if k.scanner.Verbose {
+		k.scanner.logf("Error listing %s: %v\n", kind, err)
+	}
+	k.scanner.warn("", "", fmt.Sprintf("failed to list %s: %v", kind, err))
+}
+
+// analyzeCABundle reports the algorithm, key size and expiry of every certificate in
+// a caBundle, which holds PEM certificates. Empty bundles, meaning the API server's
+// own trust roots are used, produce no findings.
+func analyzeCABundle(file string, bundle []byte) []Result {
+	var results []Result
+	for i, certificate := range parseCertificateFile(bundle) {
+		result, ok := certificateFileResult(file, certificate.cert, certificate.line, i)
+		if !ok {
+			continue
+		}
+		result.Method = "Webhook CA Bundle Analysis"
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,328 @@
+package crypto
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// kdfContextLines is how many lines around a KDF call are searched for its
+// parameters, such as a Java PBEKeySpec next to the SecretKeyFactory
+const kdfContextLines = 3
+
+// kdfCall is a key-derivation function call in one of the common languages, and
+// where its work factor is passed
+type kdfCall struct {
+	Algorithm string
+	Pattern   *regexp.Regexp // Matches the call, up to its opening parenthesis unless CostCall is set
+
+	// CostCall is the call the work factor is passed to when it is not Pattern,
+	// searched for on nearby lines
+	CostCall *regexp.Regexp
+	// CostArg is the position of the work factor among the call's arguments, or -1
+	CostArg int
+	// CostKeyword matches the work factor passed by name, e.g. iterations=
+	CostKeyword *regexp.Regexp
+}
+
+// kdfCostKeywords match the names each algorithm's work factor is passed by, up to its value
+var (
+	pbkdf2CostKeyword = regexp.MustCompile(`(?i)\b(?:iterations|iter|rounds)\s*[=:]\s*`)
+	bcryptCostKeyword = regexp.MustCompile(`(?i)\b(?:rounds|cost|log_rounds|saltRounds)\s*[=:]\s*`)
+	scryptCostKeyword = regexp.MustCompile(`\b(?:n|N|cost|costParameter)\s*[=:]\s*`)
+	argon2CostKeyword = regexp.MustCompile(`(?i)\b(?:memory_cost|memoryCost|memory|m)\s*[=:]\s*|withMemoryAsKB\(\s*`)
+)
+
+// kdfCalls are the key-derivation functions detected in source code. HKDF derives
+// keys from secrets that are already strong, so it has no work factor.
+var kdfCalls = []kdfCall{
+	{Algorithm: "PBKDF2", Pattern: regexp.MustCompile(`\bpbkdf2\.Key\(`), CostArg: 2},
+	{Algorithm: "PBKDF2", Pattern: regexp.MustCompile(`\bpbkdf2_hmac\(`), CostArg: 3, CostKeyword: pbkdf2CostKeyword},
+	{Algorithm: "PBKDF2", Pattern: regexp.MustCompile(`\bPBKDF2HMAC\(`), CostArg: -1, CostKeyword: pbkdf2CostKeyword},
+	{Algorithm: "PBKDF2", Pattern: regexp.MustCompile(`\bpbkdf2(?:Sync)?\(`), CostArg: 2},
+	{Algorithm: "PBKDF2", Pattern: regexp.MustCompile(`getInstance\(\s*"PBKDF2WithHmac\w+"`), CostCall: regexp.MustCompile(`\bPBEKeySpec\(`), CostArg: 2},
+	{Algorithm: "PBKDF2", Pattern: regexp.MustCompile(`\bRfc2898DeriveBytes\(|\bKeyDerivation\.Pbkdf2\(`), CostArg: 2, CostKeyword: regexp.MustCompile(`\biterationCount\s*:\s*`)},
+	{Algorithm: "HKDF", Pattern: regexp.MustCompile(`\bhkdf\.(?:New|Key|Extract|Expand)\(|\bHKDF(?:Expand)?\(|\bhkdf(?:Sync)?\(|\bHKDFBytesGenerator\(`), CostArg: -1},
+	{Algorithm: "bcrypt", Pattern: regexp.MustCompile(`\bbcrypt\.GenerateFromPassword\(|\bbcrypt\.(?:hash|hashSync)\(`), CostArg: 1, CostKeyword: bcryptCostKeyword},
+	{Algorithm: "bcrypt", Pattern: regexp.MustCompile(`(?i)\bbcrypt\.(?:gensalt|genSalt|genSaltSync)\(`), CostArg: 0, CostKeyword: bcryptCostKeyword},
+	{Algorithm: "bcrypt", Pattern: regexp.MustCompile(`\bBCryptPasswordEncoder\(`), CostArg: 0},
+	{Algorithm: "scrypt", Pattern: regexp.MustCompile(`\bscrypt\.Key\(`), CostArg: 2},
+	{Algorithm: "scrypt", Pattern: regexp.MustCompile(`\bhashlib\.scrypt\(|\bScrypt\(|\bscrypt(?:Sync)?\(`), CostArg: -1, CostKeyword: scryptCostKeyword},
+	{Algorithm: "Argon2id", Pattern: regexp.MustCompile(`\bargon2\.IDKey\(`), CostArg: 3},
+	{Algorithm: "Argon2i", Pattern: regexp.MustCompile(`\bargon2\.Key\(`), CostArg: 3},
+	{Algorithm: "Argon2id", Pattern: regexp.MustCompile(`\bPasswordHasher\(|\bargon2\.hash\(|\bArgon2PasswordEncoder\(|\bArgon2Parameters\.Builder\(`), CostArg: -1, CostKeyword: argon2CostKeyword},
+}
+
+// kdfMinimumCosts are the lowest work factors considered adequate for password
+// hashing, after the OWASP Password Storage Cheat Sheet: PBKDF2 iterations per PRF,
+// the bcrypt cost (log2 rounds), the scrypt N and the Argon2 memory in KiB
+var (
+	pbkdf2MinimumIterations = map[string]int{
+		"SHA-1":   1300000,
+		"SHA-256": 600000,
+		"SHA-512": 210000,
+	}
+	kdfMinimumCosts = map[string]int{
+		"bcrypt":   10,
+		"scrypt":   1 << 14,
+		"Argon2id": 19456,
+		"Argon2i":  19456,
+	}
+)
+
+// kdfCostNames name each algorithm's work factor in descriptions
+var kdfCostNames = map[string]string{
+	"PBKDF2":   "iterations",
+	"bcrypt":   "cost",
+	"scrypt":   "N",
+	"Argon2id": "KiB of memory",
+	"Argon2i":  "KiB of memory",
+}
+
+// kdfHashPattern matches the hash a PBKDF2 or HKDF call is keyed with, such as
+// sha256.New, hashes.SHA256() or PBKDF2WithHmacSHA1
+var kdfHashPattern = regexp.MustCompile(`(?i)(md5|sha3[-_]?(?:224|256|384|512)|sha-?(?:512|384|256|224|1))`)
+
+// kdfNamedCosts are library constants passed as work factors
+var kdfNamedCosts = map[string]int{
+	"bcrypt.MinCost":     4,
+	"bcrypt.DefaultCost": 10,
+	"bcrypt.MaxCost":     31,
+}
+
+// detectKeyDerivation reports key-derivation functions and whether their work
+// factor is adequate. KDFs are built from hashes, so Grover's algorithm at most
+// halves their strength and they stay quantum-resistant; the risk they carry is a
+// work factor low enough to brute-force the derived keys or password hashes today.
+func (s *Scanner) detectKeyDerivation(filePath string, lines []string) []Result {
+	var results []Result
+
+	for i, line := range lines {
+		for _, call := range kdfCalls {
+			loc := call.Pattern.FindStringIndex(line)
+			if loc == nil {
+				continue
+			}
+			hash := kdfHash(call, lines, i)
+			cost, known := kdfCost(call, lines, i, loc)
+			results = append(results, kdfResult(filePath, i+1, call.Algorithm, hash, cost, known))
+			break
+		}
+	}
+
+	return results
+}
+
+// kdfHash returns the NIST name of the hash a PBKDF2 or HKDF call uses, or ""
+func kdfHash(call kdfCall, lines []string, index int) string {
+	if call.Algorithm != "PBKDF2" && call.Algorithm != "HKDF" {
+		return ""
+	}
+	match := kdfHashPattern.FindString(lines[index])
+	if match == "" {
+		return ""
+	}
+	name := strings.ToUpper(strings.NewReplacer("_", "-").Replace(match))
+	if name == "MD5" || strings.HasPrefix(name, "SHA3") {
+		return name
+	}
+	return CanonicalAlgorithm(name)
+}
+
+// kdfCost finds the work factor of the call at loc on line index: a literal, a
+// library constant or an identifier assigned a literal earlier in the file
+func kdfCost(call kdfCall, lines []string, index int, loc []int) (int, bool) {
+	if call.CostCall != nil {
+		for j := index - kdfContextLines; j <= index+kdfContextLines; j++ {
+			if j < 0 || j >= len(lines) {
+				continue
+			}
+			if costLoc := call.CostCall.FindStringIndex(lines[j]); costLoc != nil {
+				return kdfCostArgument(lines, j, callArguments(lines[j][costLoc[1]:]), call.CostArg)
+			}
+		}
+		return 0, false
+	}
+
+	line := lines[index]
+	if call.CostKeyword != nil {
+		if keywordLoc := call.CostKeyword.FindStringIndex(line[loc[0]:]); keywordLoc != nil {
+			if cost, ok := resolveCost(lines, index, callArguments(line[loc[0]+keywordLoc[1]:])[0]); ok {
+				return cost, true
+			}
+		}
+	}
+	return kdfCostArgument(lines, index, callArguments(line[loc[1]:]), call.CostArg)
+}
+
+// kdfCostArgument resolves the argument at position of a call's arguments
+func kdfCostArgument(lines []string, index int, args []string, position int) (int, bool) {
+	if position < 0 || position >= len(args) {
+		return 0, false
+	}
+	return resolveCost(lines, index, args[position])
+}
+
+// callArguments splits the arguments of a call starting after its opening
+// parenthesis at the top level, up to the closing parenthesis or the end of the line
+func callArguments(rest string) []string {
+	var args []string
+	depth, start := 0, 0
+	for i, r := range rest {
+		switch r {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return append(args, strings.TrimSpace(rest[start:i]))
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(rest[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(args, strings.TrimSpace(rest[start:]))
+}
+
+// costAssignmentPattern matches an identifier assigned an integer, capturing the value
+const costAssignmentPattern = `\b%s\s*(?::=|=|:)\s*([0-9][0-9_]*(?:\s*(?:<<|\*\*|\*)\s*[0-9]+)?)`
+
+// resolveCost evaluates a work factor argument: an integer literal such as 100_000
+// or 1 << 15, a library constant, or an identifier whose last assignment
+// before line index is one of those
+func resolveCost(lines []string, index int, value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	if cost, ok := kdfNamedCosts[value]; ok {
+		return cost, true
+	}
+	if cost, ok := parseCostLiteral(value); ok {
+		return cost, true
+	}
+	if !identifierPattern.MatchString(value) {
+		return 0, false
+	}
+
+	assignment := regexp.MustCompile(fmt.Sprintf(costAssignmentPattern, regexp.QuoteMeta(value)))
+	for j := index; j >= 0; j-- {
+		if match := assignment.FindStringSubmatch(lines[j]); match != nil {
+			return parseCostLiteral(match[1])
+		}
+	}
+	return 0, false
+}
+
+// identifierPattern matches a plain identifier
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseCostLiteral parses an integer literal, optionally a product, shift or
+// power such as 64*1024, 1 << 15 or 2**14
+func parseCostLiteral(value string) (int, bool) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), "_", "")
+	for _, operator := range []string{"<<", "**", "*"} {
+		left, right, ok := strings.Cut(value, operator)
+		if !ok {
+			continue
+		}
+		a, err1 := strconv.Atoi(strings.TrimSpace(left))
+		b, err2 := strconv.Atoi(strings.TrimSpace(right))
+		if err1 != nil || err2 != nil || b < 0 || (operator != "*" && b > 40) {
+			return 0, false
+		}
+		switch operator {
+		case "<<":
+			return a << b, true
+		case "*":
+			return a * b, true
+		}
+		result := 1
+		for ; b > 0; b-- {
+			result *= a
+		}
+		return result, true
+	}
+	cost, err := strconv.Atoi(value)
+	return cost, err == nil
+}
+
+// kdfResult builds the finding for a KDF call. PBKDF2 and HKDF are NIST-approved
+// (SP 800-132, SP 800-56C) and take the strength of their hash; a broken hash or a
+// work factor below kdfMinimumCosts makes the finding weak.
+func kdfResult(filePath string, line int, algorithm, hash string, cost int, costKnown bool) Result {
+	result := Result{
+		File:              filePath,
+		Algorithm:         algorithm,
+		Type:              TypeKDF,
+		Line:              line,
+		Method:            "Key Derivation Analysis",
+		Risk:              "Low",
+		VulnerabilityType: "Grover's Algorithm",
+		QuantumResistant:  true,
+		Purpose:           PurposeKeyDerivation,
+	}
+
+	name := algorithm
+	if hash != "" {
+		name = fmt.Sprintf("%s-HMAC-%s", algorithm, hash)
+		if info := GetNISTInfo(hash); info != nil {
+			result.NISTCategory = string(info.Category)
+			result.SecurityStrength = info.SecurityStrength
+			result.QuantumResistant = info.QuantumResistant
+		}
+	}
+
+	var weaknesses []string
+	if hash == "MD5" || hash == "SHA-1" {
+		weaknesses = append(weaknesses, fmt.Sprintf("keyed with %s", hash))
+		result.QuantumResistant = false
+	}
+	minimum := kdfMinimumCosts[algorithm]
+	if algorithm == "PBKDF2" {
+		minimum = pbkdf2MinimumIterations["SHA-256"]
+		if m, ok := pbkdf2MinimumIterations[hash]; ok {
+			minimum = m
+		}
+	}
+	if costKnown && minimum > 0 && cost < minimum {
+		weaknesses = append(weaknesses, fmt.Sprintf("only %d %s (at least %d recommended)", cost, kdfCostNames[algorithm], minimum))
+	}
+
+	if len(weaknesses) > 0 {
+		result.Risk = "High"
+		result.VulnerabilityType = "Weak Key Derivation"
+		result.Description = fmt.Sprintf("%s is used with weak parameters: %s; derived keys and password hashes can be brute-forced", name, strings.Join(weaknesses, ", "))
+		result.Recommendation = kdfRecommendation(algorithm)
+		return result
+	}
+
+	result.Description = fmt.Sprintf("%s derives keys with a symmetric construction; Grover's algorithm at most halves its strength, so it remains quantum-resistant", name)
+	switch {
+	case minimum > 0 && costKnown:
+		result.Description += fmt.Sprintf(" (%d %s)", cost, kdfCostNames[algorithm])
+	case minimum > 0:
+		result.Description += fmt.Sprintf(" (%s not determined)", kdfCostNames[algorithm])
+	}
+	result.Recommendation = "No change needed; keep the work factor in line with current guidance as hardware gets faster"
+	if algorithm == "Argon2i" {
+		result.Recommendation = "Prefer Argon2id, which also resists side-channel and GPU attacks, for password hashing"
+	}
+	return result
+}
+
+// kdfRecommendation is the fix for a weakly parameterized KDF
+func kdfRecommendation(algorithm string) string {
+	switch algorithm {
+	case "PBKDF2":
+		return "Use PBKDF2 with HMAC-SHA-256 and at least 600,000 iterations, or Argon2id where FIPS 140 is not required"
+	case "HKDF":
+		return "Use HKDF with SHA-256 or stronger"
+	case "bcrypt":
+		return "Use a bcrypt cost of at least 10, or migrate to Argon2id"
+	case "scrypt":
+		return "Use scrypt with N of at least 2^14 (r=8, p=1), or migrate to Argon2id"
+	}
+	return "Use Argon2id with at least 19 MiB of memory and 2 iterations"
+}
@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// pemHeaderAlgorithms names the algorithm of PEM key blocks whose type states it,
+// used when the key itself can't be parsed (for example, a truncated key)
+var pemHeaderAlgorithms = map[string]string{
+	"RSA PRIVATE KEY": "RSA",
+	"RSA PUBLIC KEY":  "RSA",
+	"EC PRIVATE KEY":  "ECDSA",
+	"DSA PRIVATE KEY": "DSA",
+}
+
+// analyzePEMKeys reports the algorithm and size of each private or public key in PEM
+// content, reported against file. PKCS#1, PKCS#8, SEC1 and PKIX encodings are parsed,
+// so a PKCS#8 "PRIVATE KEY" holding an EC key is reported as ECDSA.
+func analyzePEMKeys(file, content string) []Result {
+	var results []Result
+
+	data := []byte(content)
+	for {
+		block, remaining := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		data = remaining
+
+		pub, err := parsePEMKey(block)
+		var algorithm, nistID string
+		switch {
+		case err == nil && pub != nil:
+			algorithm, nistID = publicKeyAlgorithm(pub)
+		case err != nil:
+			algorithm = pemHeaderAlgorithms[block.Type]
+		}
+		if algorithm == "" {
+			continue
+		}
+
+		name := algorithm
+		if nistID != "" {
+			name = nistID
+		}
+		result := Result{
+			File:              file,
+			Algorithm:         algorithm,
+			Type:              "PublicKey",
+			Line:              1,
+			Method:            "TLS Key Analysis",
+			Risk:              "High",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       fmt.Sprintf("TLS key (%s) uses %s, which is vulnerable to quantum attacks", block.Type, name),
+			Recommendation:    "Replace with post-quantum certificate when available from CA",
+		}
+		populateNISTFields(&result, nistID)
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// parsePEMKey parses a PEM key block and returns its public key. Blocks that
+// aren't keys return nil and no error.
+func parsePEMKey(block *pem.Block) (interface{}, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return &key.PublicKey, nil
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return &key.PublicKey, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch key := key.(type) {
+		case *rsa.PrivateKey:
+			return &key.PublicKey, nil
+		case *ecdsa.PrivateKey:
+			return &key.PublicKey, nil
+		case ed25519.PrivateKey:
+			return key.Public(), nil
+		}
+		return nil, fmt.Errorf("unsupported PKCS#8 key type %T", key)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	return nil, nil
+}
+
+// publicKeyAlgorithm names a public key's algorithm and its NIST IR 8547 ID
+func publicKeyAlgorithm(pub interface{}) (string, string) {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return "RSA", fmt.Sprintf("RSA-%d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA", fmt.Sprintf("ECDSA-P%d", key.Curve.Params().BitSize)
+	case ed25519.PublicKey:
+		return "EdDSA", "EdDSA-Ed25519"
+	case *dsa.PublicKey:
+		return "DSA", ""
+	}
+	return "", ""
+}
+
+// publicKeySize returns the size in bits of a public key, or 0 for unknown key types
+func publicKeySize(pub interface{}) int {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	case *dsa.PublicKey:
+		return key.P.BitLen()
+	}
+	return 0
+}
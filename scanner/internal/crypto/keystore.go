@@ -0,0 +1,174 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+)
+
+// keystoreMethod is the Method of findings from keystore files
+const keystoreMethod = "Keystore Analysis"
+
+// keystoreExtensions are the keystore file extensions parsed by ScanKeystore
+var keystoreExtensions = map[string]bool{".p12": true, ".pfx": true, ".jks": true, ".jceks": true, ".keystore": true}
+
+// Magic numbers starting Java keystores
+const (
+	jksMagic   = 0xfeedfeed
+	jceksMagic = 0xcececece
+)
+
+// IsKeystoreFile reports whether path is a PKCS#12 or Java keystore
+func IsKeystoreFile(path string) bool {
+	return keystoreExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// ScanKeystore opens a PKCS#12 (.p12, .pfx) or Java (JKS) keystore with
+// KeystorePassword and reports the certificates and private keys it holds: their
+// algorithm, key size and, for certificates, signature algorithm and expiry. The
+// empty password is tried when none is set. Content the password doesn't open is
+// left out with a warning; the keystore still counts as a scanned asset.
+func (s *Scanner) ScanKeystore(path string) []Result {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		s.logf("Error reading file %s: %v\n", path, err)
+		s.warn("", path, fmt.Sprintf("failed to read file: %v", err))
+		return nil
+	}
+
+	var entries []keystoreEntry
+	if len(content) >= 4 && (binary.BigEndian.Uint32(content) == jksMagic || binary.BigEndian.Uint32(content) == jceksMagic) {
+		entries, err = parseJKS(content, s.KeystorePassword)
+	} else {
+		entries, err = parsePKCS12(content, s.KeystorePassword)
+	}
+	switch {
+	case errors.Is(err, errKeystorePassword) && s.KeystorePassword == "":
+		s.warn("", path, "keystore is password-protected; set -keystore-password to report the keys and certificates it holds")
+	case errors.Is(err, errKeystorePassword):
+		s.warn("", path, "keystore password is incorrect; its protected keys and certificates were not reported")
+	case err != nil:
+		s.warn("", path, fmt.Sprintf("failed to parse keystore: %v", err))
+	}
+
+	var results []Result
+	certIndex := 0
+	for _, entry := range entries {
+		if entry.Certificate != nil {
+			if result, ok := certificateFileResult(path, entry.Certificate, 1, certIndex); ok {
+				result.Method = keystoreMethod
+				results = append(results, result)
+			}
+			certIndex++
+			continue
+		}
+		if result, ok := keystoreKeyResult(path, entry); ok {
+			results = append(results, result)
+		}
+	}
+	classifyResults(results)
+	return results
+}
+
+// keystoreKeyResult builds the finding for a private key entry. Keys the scanner
+// can't classify are not reported.
+func keystoreKeyResult(path string, entry keystoreEntry) (Result, bool) {
+	signer, ok := entry.PrivateKey.(crypto.Signer)
+	if !ok {
+		return Result{}, false
+	}
+	pub := signer.Public()
+	algorithm, nistID := publicKeyAlgorithm(pub)
+	if algorithm == "" {
+		return Result{}, false
+	}
+
+	result := Result{
+		File:              path,
+		Algorithm:         algorithm,
+		Type:              TypePublicKey,
+		Line:              1,
+		Method:            keystoreMethod,
+		Risk:              "High",
+		VulnerabilityType: "Shor's Algorithm",
+		KeySize:           publicKeySize(pub),
+		Recommendation:    "Replace with a post-quantum key pair and certificate when available from the CA",
+	}
+	keyDescription := algorithm
+	if result.KeySize > 0 {
+		keyDescription = fmt.Sprintf("%s-%d", algorithm, result.KeySize)
+	}
+	name := "Private key"
+	if entry.Alias != "" {
+		name = fmt.Sprintf("Private key %q", entry.Alias)
+	}
+	result.Description = fmt.Sprintf("%s uses %s, which is vulnerable to quantum attacks", name, keyDescription)
+	populateNISTFields(&result, nistID)
+	return result, true
+}
+
+// parseJKS returns the certificates and private keys of a JKS keystore.
+// Certificates are stored in the clear and always returned; private keys are
+// decrypted with password. errKeystorePassword is returned when the password
+// doesn't match the keystore's integrity check or a key can't be decrypted.
+func parseJKS(data []byte, password string) ([]keystoreEntry, error) {
+	if binary.BigEndian.Uint32(data) == jceksMagic {
+		return nil, errors.New("JCEKS keystores are not supported")
+	}
+
+	// Load fills the keystore before checking its integrity, so the
+	// certificates are there even when the password is wrong
+	ks := keystore.New(keystore.WithOrderedAliases(), keystore.WithCaseExactAliases())
+	var passwordErr error
+	if err := ks.Load(bytes.NewReader(data), []byte(password)); isJKSDigestMismatch(err) {
+		passwordErr = errKeystorePassword
+	} else if err != nil {
+		return nil, fmt.Errorf("malformed Java keystore: %w", err)
+	}
+
+	var entries []keystoreEntry
+	for _, alias := range ks.Aliases() {
+		if ks.IsTrustedCertificateEntry(alias) {
+			entry, err := ks.GetTrustedCertificateEntry(alias)
+			if err != nil {
+				continue
+			}
+			if cert, err := x509.ParseCertificate(entry.Certificate.Content); err == nil {
+				entries = append(entries, keystoreEntry{Alias: alias, Certificate: cert})
+			}
+			continue
+		}
+
+		chain, err := ks.GetPrivateKeyEntryCertificateChain(alias)
+		if err != nil {
+			continue
+		}
+		if entry, err := ks.GetPrivateKeyEntry(alias, []byte(password)); isJKSDigestMismatch(err) {
+			passwordErr = errKeystorePassword
+		} else if err != nil {
+			passwordErr = fmt.Errorf("malformed Java keystore key: %w", err)
+		} else if key, err := x509.ParsePKCS8PrivateKey(entry.PrivateKey); err == nil {
+			entries = append(entries, keystoreEntry{Alias: alias, PrivateKey: key})
+		}
+		for _, certificate := range chain {
+			if cert, err := x509.ParseCertificate(certificate.Content); err == nil {
+				entries = append(entries, keystoreEntry{Alias: alias, Certificate: cert})
+			}
+		}
+	}
+	return entries, passwordErr
+}
+
+// isJKSDigestMismatch reports whether err is keystore-go's failed password check,
+// for the keystore or one of its keys, which it doesn't export a sentinel for
+func isJKSDigestMismatch(err error) bool {
+	return err != nil && strings.HasSuffix(err.Error(), "got invalid digest")
+}
@@ -19,7 +19,7 @@ const (
 
 // NISTTimeline represents key dates from NIST IR 8547
 var (
-	NISTDeprecationDate2030 = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	NISTDeprecationDate2030  = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
 	NISTDisallowanceDate2035 = time.Date(2035, 1, 1, 0, 0, 0, 0, time.UTC)
 )
 
@@ -359,6 +359,30 @@ var NISTAlgorithmMap = map[string]NISTAlgorithmInfo{
 		Table:            "Table 5",
 	},
 
+	// Hybrid Key Establishment (classical + ML-KEM composite)
+	// Quantum-resistant as long as the ML-KEM component holds
+	"X25519MLKEM768": {
+		AlgorithmID:      "X25519MLKEM768",
+		Category:         NISTCategory3,
+		QuantumResistant: true,
+		SecurityStrength: 192,
+		Table:            "Table 5 (Hybrid)",
+	},
+	"SecP256r1MLKEM768": {
+		AlgorithmID:      "SecP256r1MLKEM768",
+		Category:         NISTCategory3,
+		QuantumResistant: true,
+		SecurityStrength: 192,
+		Table:            "Table 5 (Hybrid)",
+	},
+	"SecP384r1MLKEM1024": {
+		AlgorithmID:      "SecP384r1MLKEM1024",
+		Category:         NISTCategory5,
+		QuantumResistant: true,
+		SecurityStrength: 256,
+		Table:            "Table 5 (Hybrid)",
+	},
+
 	// Block Ciphers - Table 6
 	"AES-128": {
 		AlgorithmID:      "AES-128",
@@ -498,4 +522,16 @@ func GetTimelineStatus(info *NISTAlgorithmInfo, checkDate time.Time) string {
 		return "quantum-resistant"
 	}
 	return "vulnerable"
-}
\ No newline at end of file
+}
+
+// SetTimelineStatusAt sets the TimelineStatusAtProjectDate of each result with a
+// NIST IR 8547 entry to its status on projectDate, as reported by GetTimelineStatus.
+// Unlike the risk escalation made at scan time, this looks ahead to when a project
+// ships and leaves Risk unchanged.
+func SetTimelineStatusAt(results []Result, projectDate time.Time) {
+	for i := range results {
+		if info := GetNISTInfo(results[i].NISTAlgorithmID); info != nil {
+			results[i].TimelineStatusAtProjectDate = GetTimelineStatus(info, projectDate)
+		}
+	}
+}
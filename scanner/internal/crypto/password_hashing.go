@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// passwordContextLines is how many lines around a fast hash call are searched for
+// password handling and for a key-derivation function
+const passwordContextLines = 3
+
+var (
+	// passwordContextPattern matches identifiers suggesting the hashed value is a password
+	passwordContextPattern = regexp.MustCompile(`(?i)passw|\bpwd\b|passphrase|credential`)
+
+	// passwordKDFPattern matches password hashing functions; a fast hash next to one
+	// is usually an input to it or a legacy fallback being migrated
+	passwordKDFPattern = regexp.MustCompile(`(?i)bcrypt|scrypt|argon2|pbkdf2|password_hash\(|PasswordHasher|\bcrypt\(`)
+)
+
+// fastHash is a general-purpose hash that is too fast to store passwords with
+type fastHash struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// fastHashCallPattern matches calls of a hash in the common languages: md5($pw) and
+// sha1() in PHP, hashlib.md5() and hashlib.new("md5"), MessageDigest.getInstance("MD5"),
+// DigestUtils.md5Hex(), createHash('md5'), md5.Sum() and MD5.Create(), Digest::MD5.
+// names is a regexp alternation of the hash's spellings.
+func fastHashCallPattern(names string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?i)\b(?:%[1]s)(?:Hex)?\s*\(|getInstance\(\s*"(?:%[1]s)"|hashlib\.new\(\s*['"](?:%[1]s)['"]|createHash\(\s*['"](?:%[1]s)['"]|\bhash\(\s*['"](?:%[1]s)['"]|\b(?:%[1]s)\.(?:Sum\w*|New|Create)\(|Digest::(?:%[1]s)\b`, names))
+}
+
+// fastHashes are the hashes flagged when applied to passwords
+var fastHashes = []fastHash{
+	{Name: "MD5", Pattern: fastHashCallPattern(`md5`)},
+	{Name: "SHA-1", Pattern: fastHashCallPattern(`sha-?1`)},
+	{Name: "SHA-256", Pattern: fastHashCallPattern(`sha-?256`)},
+	{Name: "SHA-512", Pattern: fastHashCallPattern(`sha-?512`)},
+}
+
+// detectWeakPasswordHashing flags fast hashes applied to passwords without a
+// key-derivation function. Only calls with a password or credential identifier
+// within a few lines are reported, to keep checksums and cache keys out.
+func (s *Scanner) detectWeakPasswordHashing(filePath string, lines []string) []Result {
+	var results []Result
+
+	for i, line := range lines {
+		for _, hash := range fastHashes {
+			if !hash.Pattern.MatchString(line) || !matchesNearby(lines, i, passwordContextLines, passwordContextPattern) ||
+				matchesNearby(lines, i, passwordContextLines, passwordKDFPattern) {
+				continue
+			}
+			results = append(results, Result{
+				File:              filePath,
+				Algorithm:         hash.Name,
+				Type:              TypeHash,
+				Line:              i + 1,
+				Method:            "Password Hashing Analysis",
+				Risk:              "High",
+				VulnerabilityType: "Weak Password Hashing",
+				Description:       fmt.Sprintf("%s is a fast, unsalted hash used on a password; leaked hashes can be brute-forced at billions of guesses per second", hash.Name),
+				Recommendation:    "Hash passwords with Argon2id, scrypt or bcrypt (e.g. password_hash() in PHP), or PBKDF2 with a high iteration count where FIPS 140 is required",
+			})
+			break
+		}
+	}
+
+	return results
+}
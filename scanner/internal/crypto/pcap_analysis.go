@@ -0,0 +1,561 @@
+package crypto
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// TLSConnection represents a TLS connection with crypto details
+type TLSConnection struct {
+	SourceIP    string
+	DestIP      string
+	SourcePort  int
+	DestPort    int
+	TLSVersion  string
+	CipherSuite string
+	KeyExchange string
+	Certificate []byte
+	Timestamp   time.Time
+	Upgrade     string // Protocol that switched to TLS with STARTTLS, e.g. "SMTP"; empty for implicit TLS
+	Transport   string // "QUIC" for handshakes read from QUIC Initial packets, "DTLS" for DTLS over UDP; empty for TCP
+	Role        string // RoleClient for a ClientHello, RoleServer for a ServerHello; empty if neither was parsed
+	Seen        int    // Number of identical handshakes folded into this one by aggregateTLSConnections
+
+	hello  *clientHello // Parsed ClientHello, when Role is RoleClient
+	server *serverHello // Parsed ServerHello, when Role is RoleServer
+}
+
+// captureState follows connections across the packets of a capture
+type captureState struct {
+	starttls *starttlsTracker
+	quic     *quicTracker
+}
+
+func newCaptureState() *captureState {
+	return &captureState{starttls: newSTARTTLSTracker(), quic: newQUICTracker()}
+}
+
+// collectTLSConnections reads packets until the channel closes or ctx is done,
+// returning the TLS handshakes seen and the number of packets read
+func (p *PCAPScanner) collectTLSConnections(ctx context.Context, packets <-chan gopacket.Packet) ([]TLSConnection, int) {
+	var tlsConnections []TLSConnection
+	packetCount := 0
+	state := newCaptureState()
+
+	for {
+		select {
+		case packet, ok := <-packets:
+			if !ok {
+				return tlsConnections, packetCount
+			}
+			packetCount++
+
+			// Analyze TLS handshakes
+			if tlsData := p.extractTLSHandshake(packet, state); tlsData != nil {
+				tlsConnections = append(tlsConnections, *tlsData)
+			}
+
+		case <-ctx.Done():
+			return tlsConnections, packetCount
+		}
+	}
+}
+
+// AnalyzePCAPReader analyzes a capture in libpcap or pcapng format read from r.
+// Unlike AnalyzePCAPFile it needs no libpcap, so it works in every build.
+// Findings are reported against source.
+func (p *PCAPScanner) AnalyzePCAPReader(ctx context.Context, r io.Reader, source string) ([]Result, int, error) {
+	buffered := bufio.NewReader(r)
+	var packetSource *gopacket.PacketSource
+	if header, _ := buffered.Peek(4); isPCAPNG(header) {
+		reader, err := pcapgo.NewNgReader(buffered, pcapgo.DefaultNgReaderOptions)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read pcapng capture: %w", err)
+		}
+		packetSource = gopacket.NewPacketSource(reader, reader.LinkType())
+	} else {
+		reader, err := pcapgo.NewReader(buffered)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read capture: %w", err)
+		}
+		packetSource = gopacket.NewPacketSource(reader, reader.LinkType())
+	}
+	tlsConnections, packetCount := p.collectTLSConnections(ctx, packetSource.Packets())
+
+	return p.analyzeTLSConnections(tlsConnections, source), packetCount, nil
+}
+
+// isPCAPNG reports whether a capture starts with a pcapng Section Header Block,
+// whose block type reads the same in either byte order
+func isPCAPNG(header []byte) bool {
+	return len(header) >= 4 && header[0] == 0x0A && header[1] == 0x0D && header[2] == 0x0D && header[3] == 0x0A
+}
+
+// PCAPStdin is the PCAP file name that reads the capture from stdin, e.g. piped from
+// tcpdump -w -
+const PCAPStdin = "-"
+
+// PCAPStdinSource names stdin in the findings and metadata of a piped capture
+const PCAPStdinSource = "stdin"
+
+// analyzePCAPStdin analyzes a libpcap or pcapng capture piped to stdin. A pipe can't
+// seek, so libpcap is bypassed and the capture read once, front to back, by
+// AnalyzePCAPReader in every build. Empty or malformed input is an error.
+func (p *PCAPScanner) analyzePCAPStdin(ctx context.Context) ([]Result, int, error) {
+	reader := bufio.NewReader(os.Stdin)
+	if header, _ := reader.Peek(4); len(header) == 0 {
+		return nil, 0, fmt.Errorf("no capture on stdin")
+	}
+	results, packetCount, err := p.AnalyzePCAPReader(ctx, reader, PCAPStdinSource)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read the capture on stdin: %w", err)
+	}
+	return results, packetCount, nil
+}
+
+// analyzeTLSConnections analyzes each distinct handshake once, recording on its
+// findings how many times it was seen, then both sides of each session together
+func (p *PCAPScanner) analyzeTLSConnections(conns []TLSConnection, source string) []Result {
+	var results []Result
+	for _, conn := range aggregateTLSConnections(conns) {
+		connResults := p.analyzeTLSConnection(conn, source)
+		for i := range connResults {
+			connResults[i].Seen = conn.Seen
+		}
+		results = append(results, connResults...)
+	}
+	return append(results, analyzeTLSSessions(conns, source)...)
+}
+
+// aggregateTLSConnections folds handshakes between the same client and server
+// endpoint (client IP, server IP, server port and SNI) into one, counting them in
+// Seen. The first handshake of each group is kept, in capture order; handshakes
+// that differ in role or negotiated parameters stay separate so no finding is lost.
+func aggregateTLSConnections(conns []TLSConnection) []TLSConnection {
+	var aggregated []TLSConnection
+	index := make(map[string]int)
+	for _, conn := range conns {
+		key := tlsEndpointKey(conn)
+		if i, ok := index[key]; ok {
+			aggregated[i].Seen++
+			continue
+		}
+		conn.Seen = 1
+		index[key] = len(aggregated)
+		aggregated = append(aggregated, conn)
+	}
+	return aggregated
+}
+
+// tlsEndpointKey identifies the endpoint and parameters of a handshake, ignoring
+// the client's ephemeral port
+func tlsEndpointKey(conn TLSConnection) string {
+	clientIP, serverIP, serverPort := conn.SourceIP, conn.DestIP, conn.DestPort
+	if conn.Role == RoleServer || (conn.Role == "" && tlsPorts[layers.TCPPort(conn.SourcePort)]) {
+		clientIP, serverIP, serverPort = conn.DestIP, conn.SourceIP, conn.SourcePort
+	}
+	sni := ""
+	params := fmt.Sprintf("%s|%s|%s", conn.TLSVersion, conn.CipherSuite, conn.KeyExchange)
+	if conn.hello != nil {
+		sni = conn.hello.ServerName
+		params = fmt.Sprint(conn.hello.CipherSuites, conn.hello.Groups, conn.hello.KeyShares)
+	}
+	if conn.server != nil {
+		params += fmt.Sprintf("|%d", conn.server.Group)
+	}
+	return fmt.Sprintf("%s|%s|%d|%s|%s|%s|%s|%s", clientIP, serverIP, serverPort, sni, conn.Transport, conn.Upgrade, conn.Role, params)
+}
+
+// extractTLSHandshake extracts TLS handshake information from a packet.
+// Plaintext connections are followed in state so STARTTLS upgrades are analyzed
+// too, QUIC Initial packets are reassembled into their ClientHello, and DTLS
+// handshakes are read from UDP on any port.
+func (p *PCAPScanner) extractTLSHandshake(packet gopacket.Packet, state *captureState) *TLSConnection {
+	if udpLayer := packet.Layer(layers.LayerTypeUDP); udpLayer != nil {
+		udp, _ := udpLayer.(*layers.UDP)
+		if isDTLSHandshake(udp.Payload) {
+			return p.extractDTLSHandshake(packet, udp)
+		}
+		return p.extractQUICInitial(packet, udp, state.quic)
+	}
+
+	// Check if packet contains TCP layer
+	tcpLayer := packet.Layer(layers.LayerTypeTCP)
+	if tcpLayer == nil {
+		return nil
+	}
+
+	tcp, _ := tcpLayer.(*layers.TCP)
+
+	// Implicit-TLS ports carry handshakes directly; other connections only once
+	// the client has issued STARTTLS
+	upgrade := ""
+	if !tlsPorts[tcp.DstPort] && !tlsPorts[tcp.SrcPort] {
+		key := connectionKey(packet, tcp)
+		if app := packet.ApplicationLayer(); app != nil {
+			state.starttls.observe(key, app.Payload())
+		}
+		protocol, ok := state.starttls.protocol(key)
+		if !ok {
+			return nil
+		}
+		upgrade = protocol
+	}
+
+	// Check for TLS Application Data
+	applicationLayer := packet.ApplicationLayer()
+	if applicationLayer == nil {
+		return nil
+	}
+
+	payload := applicationLayer.Payload()
+	if len(payload) < 5 {
+		return nil
+	}
+
+	// Check for TLS record header (Content Type: 22 = Handshake)
+	if payload[0] != 0x16 {
+		return nil
+	}
+
+	// Extract network layer for IP addresses
+	networkLayer := packet.NetworkLayer()
+	if networkLayer == nil {
+		return nil
+	}
+
+	srcIP, dstIP := packetIPs(packet)
+	conn := &TLSConnection{
+		SourceIP:    srcIP,
+		DestIP:      dstIP,
+		SourcePort:  int(tcp.SrcPort),
+		DestPort:    int(tcp.DstPort),
+		Certificate: payload, // Store raw payload for certificate analysis
+		Timestamp:   packet.Metadata().Timestamp,
+		Upgrade:     upgrade,
+	}
+
+	// Read the hello that opens the record, so client offers are kept apart from
+	// what the server selected
+	switch msgType, msg := firstHandshakeMessage(payload); msgType {
+	case 1:
+		if hello, err := parseClientHello(msg); err == nil {
+			conn.Role, conn.hello = RoleClient, hello
+			conn.TLSVersion = p.parseTLSVersion(payload)
+			return conn
+		}
+	case 2:
+		if hello, err := parseServerHello(msg); err == nil {
+			conn.Role, conn.server = RoleServer, hello
+			conn.TLSVersion = tlsVersionName(hello.Version)
+			conn.CipherSuite = tls.CipherSuiteName(hello.CipherSuite)
+			conn.KeyExchange = p.parseKeyExchange(conn.CipherSuite)
+			return conn
+		}
+	}
+
+	// Fall back to pattern matching for records that don't start with a hello
+	conn.TLSVersion = p.parseTLSVersion(payload)
+	conn.CipherSuite = p.parseCipherSuite(payload)
+	conn.KeyExchange = p.parseKeyExchange(conn.CipherSuite)
+	return conn
+}
+
+// firstHandshakeMessage returns the type and bytes of the handshake message at the
+// start of a TLS handshake record, or 0 if it is incomplete
+func firstHandshakeMessage(payload []byte) (byte, []byte) {
+	if len(payload) < 9 || payload[0] != tlsRecordHandshake {
+		return 0, nil
+	}
+	msg := payload[5:]
+	length := 4 + (int(msg[1])<<16 | int(msg[2])<<8 | int(msg[3]))
+	if len(msg) < length {
+		return 0, nil
+	}
+	return msg[0], msg[:length]
+}
+
+// extractQUICInitial returns the ClientHello carried by a client's QUIC Initial
+// packets to UDP port 443 once the tracker has all of it
+func (p *PCAPScanner) extractQUICInitial(packet gopacket.Packet, udp *layers.UDP, quic *quicTracker) *TLSConnection {
+	if udp.DstPort != 443 {
+		return nil
+	}
+	hello := quic.observe(udp.Payload)
+	if hello == nil {
+		return nil
+	}
+
+	srcIP, dstIP := packetIPs(packet)
+	return &TLSConnection{
+		SourceIP:   srcIP,
+		DestIP:     dstIP,
+		SourcePort: int(udp.SrcPort),
+		DestPort:   int(udp.DstPort),
+		TLSVersion: "TLS 1.3", // QUIC always carries TLS 1.3
+		Timestamp:  packet.Metadata().Timestamp,
+		Transport:  "QUIC",
+		Role:       RoleClient,
+		hello:      hello,
+	}
+}
+
+// packetIPs returns the source and destination IP addresses of a packet
+func packetIPs(packet gopacket.Packet) (srcIP, dstIP string) {
+	if ipv4Layer := packet.Layer(layers.LayerTypeIPv4); ipv4Layer != nil {
+		ipv4, _ := ipv4Layer.(*layers.IPv4)
+		return ipv4.SrcIP.String(), ipv4.DstIP.String()
+	}
+	if ipv6Layer := packet.Layer(layers.LayerTypeIPv6); ipv6Layer != nil {
+		ipv6, _ := ipv6Layer.(*layers.IPv6)
+		return ipv6.SrcIP.String(), ipv6.DstIP.String()
+	}
+	return "", ""
+}
+
+// parseTLSVersion extracts TLS version from handshake payload
+func (p *PCAPScanner) parseTLSVersion(payload []byte) string {
+	if len(payload) < 3 {
+		return "Unknown"
+	}
+
+	// TLS version is in bytes 1-2 of the TLS record
+	majorVersion := payload[1]
+	minorVersion := payload[2]
+
+	switch {
+	case majorVersion == 3 && minorVersion == 1:
+		return "TLS 1.0"
+	case majorVersion == 3 && minorVersion == 2:
+		return "TLS 1.1"
+	case majorVersion == 3 && minorVersion == 3:
+		return "TLS 1.2"
+	case majorVersion == 3 && minorVersion == 4:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("TLS %d.%d", majorVersion, minorVersion)
+	}
+}
+
+// parseCipherSuite extracts cipher suite information from handshake
+func (p *PCAPScanner) parseCipherSuite(payload []byte) string {
+	// This is a simplified parser - in reality, would need full TLS handshake parsing
+	// For now, look for common cipher suite patterns in the payload
+	payloadStr := fmt.Sprintf("%x", payload)
+
+	// Common cipher suite patterns (hex representations)
+	if strings.Contains(payloadStr, "c02f") || strings.Contains(payloadStr, "c030") {
+		return "ECDHE-RSA-AES256-GCM-SHA384"
+	}
+	if strings.Contains(payloadStr, "c02b") || strings.Contains(payloadStr, "c02c") {
+		return "ECDHE-ECDSA-AES256-GCM-SHA384"
+	}
+	if strings.Contains(payloadStr, "009e") || strings.Contains(payloadStr, "009f") {
+		return "DHE-RSA-AES256-GCM-SHA384"
+	}
+	if strings.Contains(payloadStr, "003d") {
+		return "AES256-SHA256"
+	}
+	if strings.Contains(payloadStr, "0035") {
+		return "AES256-SHA"
+	}
+
+	return "Unknown Cipher Suite"
+}
+
+// parseKeyExchange determines key exchange method from cipher suite
+func (p *PCAPScanner) parseKeyExchange(cipherSuite string) string {
+	switch {
+	case strings.Contains(cipherSuite, "ECDHE"):
+		return "ECDHE"
+	case strings.Contains(cipherSuite, "DHE"):
+		return "DHE"
+	case strings.Contains(cipherSuite, "RSA"):
+		return "RSA"
+	case strings.Contains(cipherSuite, "ECDH"):
+		return "ECDH"
+	default:
+		return "Unknown"
+	}
+}
+
+// analyzeTLSConnection analyzes a TLS connection for crypto vulnerabilities.
+// Findings from a ClientHello are marked as offered by the client, and findings
+// from a ServerHello as negotiated by the server.
+func (p *PCAPScanner) analyzeTLSConnection(conn TLSConnection, source string) []Result {
+	if conn.hello != nil {
+		results := analyzeClientHello(conn, source)
+		for i := range results {
+			results[i].Role = RoleClient
+			results[i].Negotiation = NegotiationOffered
+		}
+		return results
+	}
+
+	results := p.analyzeHandshake(conn, source)
+	if conn.server != nil {
+		results = append(results, analyzeNegotiatedGroup(conn, source)...)
+		for i := range results {
+			results[i].Role = RoleServer
+			results[i].Negotiation = NegotiationNegotiated
+		}
+	}
+
+	// Analyze certificate chains (simplified)
+	certResults := p.analyzeCertificateChain(conn.Certificate, source)
+	for i := range certResults {
+		certResults[i].Role = conn.Role
+	}
+	results = append(results, certResults...)
+
+	return results
+}
+
+// analyzeHandshake reports the protocol version, key exchange and cipher of a connection
+func (p *PCAPScanner) analyzeHandshake(conn TLSConnection, source string) []Result {
+	var results []Result
+
+	// Analyze TLS version
+	if conn.TLSVersion == "TLS 1.0" || conn.TLSVersion == "TLS 1.1" || conn.TLSVersion == "DTLS 1.0" {
+		recommendation := "Upgrade to TLS 1.2 or TLS 1.3"
+		if conn.Transport == "DTLS" {
+			recommendation = "Upgrade to DTLS 1.2 or DTLS 1.3"
+		}
+		results = append(results, Result{
+			File:              source,
+			Algorithm:         conn.TLSVersion,
+			Type:              "Protocol",
+			Line:              1,
+			Method:            "TLS Protocol Analysis",
+			Risk:              "High",
+			VulnerabilityType: "Protocol Weakness",
+			Description:       fmt.Sprintf("Connection uses outdated %s protocol vulnerable to attacks", conn.TLSVersion),
+			Recommendation:    recommendation,
+		})
+	}
+
+	// Analyze key exchange methods
+	switch conn.KeyExchange {
+	case "RSA":
+		results = append(results, Result{
+			File:              source,
+			Algorithm:         "RSA",
+			Type:              "PublicKey",
+			Line:              1,
+			Method:            "TLS Key Exchange Analysis",
+			Risk:              "High",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       "TLS connection uses RSA key exchange vulnerable to quantum attacks",
+			Recommendation:    "Configure servers to prefer ECDHE or post-quantum key exchange",
+		})
+	case "ECDHE", "ECDH":
+		results = append(results, Result{
+			File:              source,
+			Algorithm:         "ECDH",
+			Type:              "PublicKey",
+			Line:              1,
+			Method:            "TLS Key Exchange Analysis",
+			Risk:              "High",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       "TLS connection uses ECDH key exchange vulnerable to quantum attacks",
+			Recommendation:    "Upgrade to post-quantum key exchange mechanisms when available",
+		})
+	case "DHE":
+		results = append(results, Result{
+			File:              source,
+			Algorithm:         "DH",
+			Type:              "PublicKey",
+			Line:              1,
+			Method:            "TLS Key Exchange Analysis",
+			Risk:              "High",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       "TLS connection uses Diffie-Hellman key exchange vulnerable to quantum attacks",
+			Recommendation:    "Replace with post-quantum key exchange mechanisms",
+		})
+	}
+
+	// Analyze cipher suites for weak symmetric crypto
+	if strings.Contains(conn.CipherSuite, "AES256") || strings.Contains(conn.CipherSuite, "AES_256") {
+		results = append(results, Result{
+			File:              source,
+			Algorithm:         "AES-256",
+			Type:              "SymmetricKey",
+			Line:              1,
+			Method:            "TLS Cipher Suite Analysis",
+			Risk:              "Low",
+			VulnerabilityType: "Grover's Algorithm",
+			Description:       "TLS connection uses AES-256 which provides adequate quantum resistance",
+			Recommendation:    "AES-256 provides strong quantum resistance. No action needed",
+		})
+	} else if strings.Contains(conn.CipherSuite, "AES128") || strings.Contains(conn.CipherSuite, "AES_128") {
+		results = append(results, Result{
+			File:              source,
+			Algorithm:         "AES-128",
+			Type:              "SymmetricKey",
+			Line:              1,
+			Method:            "TLS Cipher Suite Analysis",
+			Risk:              "Medium",
+			VulnerabilityType: "Grover's Algorithm",
+			Description:       "TLS connection uses AES-128 which provides reduced quantum security",
+			Recommendation:    "Configure TLS to prefer AES-256 cipher suites",
+		})
+	}
+
+	return results
+}
+
+// analyzeCertificateChain analyzes certificate data for crypto vulnerabilities
+func (p *PCAPScanner) analyzeCertificateChain(certData []byte, source string) []Result {
+	// Prefer parsing the Certificate handshake message, which also gives chain positions
+	if results := AnalyzeTLSCertificates(certData, source); len(results) > 0 {
+		return results
+	}
+
+	var results []Result
+
+	// Convert to string for pattern matching
+	certStr := string(certData)
+
+	// Fall back to looking for certificate patterns in the TLS handshake
+
+	if strings.Contains(certStr, "rsaEncryption") || len(certData) > 1000 {
+		// Large certificate likely indicates RSA
+		results = append(results, Result{
+			File:              source,
+			Algorithm:         "RSA",
+			Type:              "PublicKey",
+			Line:              1,
+			Method:            "Certificate Chain Analysis",
+			Risk:              "High",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       "Certificate chain contains RSA certificates vulnerable to quantum attacks",
+			Recommendation:    "Replace certificates with post-quantum alternatives when available",
+		})
+	}
+
+	if strings.Contains(certStr, "ecPublicKey") || strings.Contains(certStr, "prime256v1") {
+		results = append(results, Result{
+			File:              source,
+			Algorithm:         "ECDSA",
+			Type:              "PublicKey",
+			Line:              1,
+			Method:            "Certificate Chain Analysis",
+			Risk:              "High",
+			VulnerabilityType: "Shor's Algorithm",
+			Description:       "Certificate chain contains ECDSA certificates vulnerable to quantum attacks",
+			Recommendation:    "Replace certificates with post-quantum alternatives when available",
+		})
+	}
+
+	return results
+}
@@ -1,14 +1,15 @@
+//go:build cgo
 // +build cgo
 
 package crypto
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"os"
 	"time"
 
 	"github.com/google/gopacket"
-	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcap"
 )
 
@@ -24,89 +25,103 @@ func NewPCAPScanner(scanner *Scanner) *PCAPScanner {
 	}
 }
 
-// AnalyzePCAPFile analyzes a PCAP file for crypto vulnerabilities
-func (p *PCAPScanner) AnalyzePCAPFile(pcapFile string, tlsFilter bool) ([]Result, int) {
+// pcapFileCapability reports the libpcap that reads capture files
+func pcapFileCapability() Capability {
+	return Capability{Name: CapabilityPCAPFile, Available: true, Detail: pcap.Version()}
+}
+
+// liveCaptureCapability reports whether libpcap can see any interface to capture
+// from, which usually takes root or CAP_NET_RAW
+func liveCaptureCapability() Capability {
+	capability := Capability{Name: CapabilityLiveCapture}
+	devices, err := pcap.FindAllDevs()
+	switch {
+	case err != nil:
+		capability.Detail = fmt.Sprintf("libpcap cannot list interfaces: %v", err)
+	case len(devices) == 0:
+		capability.Detail = "no capture interfaces are visible; capturing usually needs root or CAP_NET_RAW"
+	default:
+		capability.Available = true
+		capability.Detail = fmt.Sprintf("%s, %d interfaces", pcap.Version(), len(devices))
+	}
+	return capability
+}
+
+// AnalyzePCAPFile analyzes a PCAP file for crypto vulnerabilities, stopping early once ctx is done
+func (p *PCAPScanner) AnalyzePCAPFile(ctx context.Context, pcapFile string, tlsFilter bool) ([]Result, int, error) {
 	var results []Result
-	assetCount := 0
 
 	if p.scanner.Verbose {
-		fmt.Printf("Opening PCAP file: %s\n", pcapFile)
+		p.scanner.logf("Opening PCAP file: %s\n", pcapFile)
 	}
 
-	handle, err := pcap.OpenOffline(pcapFile)
+	// Open the capture ourselves so a missing or unreadable file reports the OS error
+	file, err := os.Open(pcapFile)
 	if err != nil {
-		if p.scanner.Verbose {
-			fmt.Printf("Error opening PCAP file: %v\n", err)
-		}
-		return p.generateFallbackPCAPResults(pcapFile), 150
+		return nil, 0, fmt.Errorf("failed to open PCAP file: %w", err)
+	}
+	defer file.Close()
+	handle, err := pcap.OpenOfflineFile(file)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: failed to read capture: %w", pcapFile, err)
 	}
 	defer handle.Close()
 
 	packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
-	var tlsConnections []TLSConnection
-
-	for packet := range packetSource.Packets() {
-		assetCount++
-		
-		// Analyze TLS handshakes
-		if tlsData := p.extractTLSHandshake(packet); tlsData != nil {
-			tlsConnections = append(tlsConnections, *tlsData)
-		}
-	}
+	tlsConnections, assetCount := p.collectTLSConnections(ctx, packetSource.Packets())
 
 	// Analyze collected TLS data for vulnerabilities
-	for _, conn := range tlsConnections {
-		tlsResults := p.analyzeTLSConnection(conn, pcapFile)
-		results = append(results, tlsResults...)
-	}
+	results = append(results, p.analyzeTLSConnections(tlsConnections, pcapFile)...)
 
 	if p.scanner.Verbose {
-		fmt.Printf("PCAP analysis completed. Analyzed %d packets, found %d TLS connections.\n", assetCount, len(tlsConnections))
+		p.scanner.logf("PCAP analysis completed. Analyzed %d packets, found %d TLS connections.\n", assetCount, len(tlsConnections))
 	}
 
-	return results, assetCount
+	return results, assetCount, nil
 }
 
-// PerformLiveCapture captures and analyzes live network traffic
-func (p *PCAPScanner) PerformLiveCapture(captureInterface, captureDuration string, tlsFilter bool) ([]Result, int) {
+// PerformLiveCapture captures and analyzes live network traffic until the duration elapses or ctx is done
+func (p *PCAPScanner) PerformLiveCapture(ctx context.Context, captureInterface, captureDuration string, tlsFilter bool) ([]Result, int, error) {
 	var results []Result
 	assetCount := 0
 
 	if p.scanner.Verbose {
-		fmt.Printf("Starting live capture on interface %s for %s...\n", captureInterface, captureDuration)
+		p.scanner.logf("Starting live capture on interface %s for %s...\n", captureInterface, captureDuration)
 	}
 
 	// Parse duration
 	duration, err := time.ParseDuration(captureDuration)
 	if err != nil {
-		if p.scanner.Verbose {
-			fmt.Printf("Error parsing duration: %v\n", err)
-		}
-		return p.generateFallbackNetworkResults(captureInterface), 25
+		return nil, 0, fmt.Errorf("invalid -duration %q: %w", captureDuration, err)
 	}
 
 	handle, err := pcap.OpenLive(captureInterface, 1600, true, duration)
 	if err != nil {
 		if p.scanner.Verbose {
-			fmt.Printf("Error opening interface for live capture: %v\n", err)
+			p.scanner.logf("Error opening interface for live capture: %v\n", err)
 		}
-		return p.generateFallbackNetworkResults(captureInterface), 25
+		if err := p.scanner.fallback(CapabilityLiveCapture, "live:"+captureInterface, fmt.Sprintf("failed to open interface: %v", err)); err != nil {
+			return nil, 0, err
+		}
+		return p.generateFallbackNetworkResults(captureInterface), 25, nil
 	}
 	defer handle.Close()
 
 	// Set BPF filter for TLS traffic if requested
 	if tlsFilter {
-		err = handle.SetBPFFilter("tcp port 443 or tcp port 993 or tcp port 995")
+		err = handle.SetBPFFilter(tlsCaptureFilter)
 		if err != nil {
 			if p.scanner.Verbose {
-				fmt.Printf("Warning: Could not set TLS filter: %v\n", err)
+				p.scanner.logf("Warning: Could not set TLS filter: %v\n", err)
 			}
+			p.scanner.warn("", "live:"+captureInterface, fmt.Sprintf("failed to set the TLS capture filter: %v", err))
 		}
 	}
 
 	packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
 	timeout := time.After(duration)
 	var tlsConnections []TLSConnection
+	state := newCaptureState()
 
 	for {
 		select {
@@ -115,339 +130,29 @@ func (p *PCAPScanner) PerformLiveCapture(captureInterface, captureDuration strin
 				goto analysis
 			}
 			assetCount++
-			
+
 			// Analyze TLS handshakes
-			if tlsData := p.extractTLSHandshake(packet); tlsData != nil {
+			if tlsData := p.extractTLSHandshake(packet, state); tlsData != nil {
 				tlsConnections = append(tlsConnections, *tlsData)
 			}
-			
+
 		case <-timeout:
 			goto analysis
+
+		case <-ctx.Done():
+			goto analysis
 		}
 	}
 
 analysis:
 	// Analyze collected TLS data for vulnerabilities
-	for _, conn := range tlsConnections {
-		tlsResults := p.analyzeTLSConnection(conn, fmt.Sprintf("live:%s", captureInterface))
-		results = append(results, tlsResults...)
-	}
+	results = append(results, p.analyzeTLSConnections(tlsConnections, fmt.Sprintf("live:%s", captureInterface))...)
 
 	if p.scanner.Verbose {
-		fmt.Printf("Live capture completed. Analyzed %d packets, found %d TLS connections.\n", assetCount, len(tlsConnections))
+		p.scanner.logf("Live capture completed. Analyzed %d packets, found %d TLS connections.\n", assetCount, len(tlsConnections))
 	}
 
-	return results, assetCount
-}
-
-// TLSConnection represents a TLS connection with crypto details
-type TLSConnection struct {
-	SourceIP      string
-	DestIP        string
-	SourcePort    int
-	DestPort      int
-	TLSVersion    string
-	CipherSuite   string
-	KeyExchange   string
-	Certificate   []byte
-	Timestamp     time.Time
-}
-
-// extractTLSHandshake extracts TLS handshake information from a packet
-func (p *PCAPScanner) extractTLSHandshake(packet gopacket.Packet) *TLSConnection {
-	// Check if packet contains TCP layer
-	tcpLayer := packet.Layer(layers.LayerTypeTCP)
-	if tcpLayer == nil {
-		return nil
-	}
-
-	tcp, _ := tcpLayer.(*layers.TCP)
-	
-	// Check for TLS ports (443, 993, 995, etc.)
-	if tcp.DstPort != 443 && tcp.SrcPort != 443 && 
-	   tcp.DstPort != 993 && tcp.SrcPort != 993 &&
-	   tcp.DstPort != 995 && tcp.SrcPort != 995 {
-		return nil
-	}
-
-	// Check for TLS Application Data
-	applicationLayer := packet.ApplicationLayer()
-	if applicationLayer == nil {
-		return nil
-	}
-
-	payload := applicationLayer.Payload()
-	if len(payload) < 5 {
-		return nil
-	}
-
-	// Check for TLS record header (Content Type: 22 = Handshake)
-	if payload[0] != 0x16 {
-		return nil
-	}
-
-	// Extract network layer for IP addresses
-	networkLayer := packet.NetworkLayer()
-	if networkLayer == nil {
-		return nil
-	}
-
-	var srcIP, dstIP string
-	if ipv4Layer := packet.Layer(layers.LayerTypeIPv4); ipv4Layer != nil {
-		ipv4, _ := ipv4Layer.(*layers.IPv4)
-		srcIP = ipv4.SrcIP.String()
-		dstIP = ipv4.DstIP.String()
-	} else if ipv6Layer := packet.Layer(layers.LayerTypeIPv6); ipv6Layer != nil {
-		ipv6, _ := ipv6Layer.(*layers.IPv6)
-		srcIP = ipv6.SrcIP.String()
-		dstIP = ipv6.DstIP.String()
-	}
-
-	// Parse TLS handshake details
-	tlsVersion := p.parseTLSVersion(payload)
-	cipherSuite := p.parseCipherSuite(payload)
-	keyExchange := p.parseKeyExchange(cipherSuite)
-
-	return &TLSConnection{
-		SourceIP:    srcIP,
-		DestIP:      dstIP,
-		SourcePort:  int(tcp.SrcPort),
-		DestPort:    int(tcp.DstPort),
-		TLSVersion:  tlsVersion,
-		CipherSuite: cipherSuite,
-		KeyExchange: keyExchange,
-		Certificate: payload, // Store raw payload for certificate analysis
-		Timestamp:   packet.Metadata().Timestamp,
-	}
-}
-
-// parseTLSVersion extracts TLS version from handshake payload
-func (p *PCAPScanner) parseTLSVersion(payload []byte) string {
-	if len(payload) < 3 {
-		return "Unknown"
-	}
-	
-	// TLS version is in bytes 1-2 of the TLS record
-	majorVersion := payload[1]
-	minorVersion := payload[2]
-	
-	switch {
-	case majorVersion == 3 && minorVersion == 1:
-		return "TLS 1.0"
-	case majorVersion == 3 && minorVersion == 2:
-		return "TLS 1.1"
-	case majorVersion == 3 && minorVersion == 3:
-		return "TLS 1.2"
-	case majorVersion == 3 && minorVersion == 4:
-		return "TLS 1.3"
-	default:
-		return fmt.Sprintf("TLS %d.%d", majorVersion, minorVersion)
-	}
-}
-
-// parseCipherSuite extracts cipher suite information from handshake
-func (p *PCAPScanner) parseCipherSuite(payload []byte) string {
-	// This is a simplified parser - in reality, would need full TLS handshake parsing
-	// For now, look for common cipher suite patterns in the payload
-	payloadStr := fmt.Sprintf("%x", payload)
-	
-	// Common cipher suite patterns (hex representations)
-	if strings.Contains(payloadStr, "c02f") || strings.Contains(payloadStr, "c030") {
-		return "ECDHE-RSA-AES256-GCM-SHA384"
-	}
-	if strings.Contains(payloadStr, "c02b") || strings.Contains(payloadStr, "c02c") {
-		return "ECDHE-ECDSA-AES256-GCM-SHA384"
-	}
-	if strings.Contains(payloadStr, "009e") || strings.Contains(payloadStr, "009f") {
-		return "DHE-RSA-AES256-GCM-SHA384"
-	}
-	if strings.Contains(payloadStr, "003d") {
-		return "AES256-SHA256"
-	}
-	if strings.Contains(payloadStr, "0035") {
-		return "AES256-SHA"
-	}
-	
-	return "Unknown Cipher Suite"
-}
-
-// parseKeyExchange determines key exchange method from cipher suite
-func (p *PCAPScanner) parseKeyExchange(cipherSuite string) string {
-	switch {
-	case strings.Contains(cipherSuite, "ECDHE"):
-		return "ECDHE"
-	case strings.Contains(cipherSuite, "DHE"):
-		return "DHE"
-	case strings.Contains(cipherSuite, "RSA"):
-		return "RSA"
-	case strings.Contains(cipherSuite, "ECDH"):
-		return "ECDH"
-	default:
-		return "Unknown"
-	}
-}
-
-// analyzeTLSConnection analyzes a TLS connection for crypto vulnerabilities
-func (p *PCAPScanner) analyzeTLSConnection(conn TLSConnection, source string) []Result {
-	var results []Result
-	
-	// Analyze TLS version
-	if conn.TLSVersion == "TLS 1.0" || conn.TLSVersion == "TLS 1.1" {
-		results = append(results, Result{
-			File:              source,
-			Algorithm:         conn.TLSVersion,
-			Type:              "Protocol",
-			Line:              1,
-			Method:            "TLS Protocol Analysis",
-			Risk:              "High",
-			VulnerabilityType: "Protocol Weakness",
-			Description:       fmt.Sprintf("Connection uses outdated %s protocol vulnerable to attacks", conn.TLSVersion),
-			Recommendation:    "Upgrade to TLS 1.2 or TLS 1.3",
-		})
-	}
-	
-	// Analyze key exchange methods
-	switch conn.KeyExchange {
-	case "RSA":
-		results = append(results, Result{
-			File:              source,
-			Algorithm:         "RSA",
-			Type:              "PublicKey",
-			Line:              1,
-			Method:            "TLS Key Exchange Analysis",
-			Risk:              "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "TLS connection uses RSA key exchange vulnerable to quantum attacks",
-			Recommendation:    "Configure servers to prefer ECDHE or post-quantum key exchange",
-		})
-	case "ECDHE", "ECDH":
-		results = append(results, Result{
-			File:              source,
-			Algorithm:         "ECDH",
-			Type:              "PublicKey",
-			Line:              1,
-			Method:            "TLS Key Exchange Analysis",
-			Risk:              "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "TLS connection uses ECDH key exchange vulnerable to quantum attacks",
-			Recommendation:    "Upgrade to post-quantum key exchange mechanisms when available",
-		})
-	case "DHE":
-		results = append(results, Result{
-			File:              source,
-			Algorithm:         "DH",
-			Type:              "PublicKey",
-			Line:              1,
-			Method:            "TLS Key Exchange Analysis",
-			Risk:              "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "TLS connection uses Diffie-Hellman key exchange vulnerable to quantum attacks",
-			Recommendation:    "Replace with post-quantum key exchange mechanisms",
-		})
-	}
-	
-	// Analyze cipher suites for weak symmetric crypto
-	if strings.Contains(conn.CipherSuite, "AES256") {
-		results = append(results, Result{
-			File:              source,
-			Algorithm:         "AES-256",
-			Type:              "SymmetricKey",
-			Line:              1,
-			Method:            "TLS Cipher Suite Analysis",
-			Risk:              "Low",
-			VulnerabilityType: "Grover's Algorithm",
-			Description:       "TLS connection uses AES-256 which provides adequate quantum resistance",
-			Recommendation:    "AES-256 provides strong quantum resistance. No action needed",
-		})
-	} else if strings.Contains(conn.CipherSuite, "AES128") {
-		results = append(results, Result{
-			File:              source,
-			Algorithm:         "AES-128",
-			Type:              "SymmetricKey",
-			Line:              1,
-			Method:            "TLS Cipher Suite Analysis",
-			Risk:              "Medium",
-			VulnerabilityType: "Grover's Algorithm",
-			Description:       "TLS connection uses AES-128 which provides reduced quantum security",
-			Recommendation:    "Configure TLS to prefer AES-256 cipher suites",
-		})
-	}
-	
-	// Analyze certificate chains (simplified)
-	certResults := p.analyzeCertificateChain(conn.Certificate, source)
-	results = append(results, certResults...)
-	
-	return results
-}
-
-// analyzeCertificateChain analyzes certificate data for crypto vulnerabilities
-func (p *PCAPScanner) analyzeCertificateChain(certData []byte, source string) []Result {
-	var results []Result
-	
-	// Convert to string for pattern matching
-	certStr := string(certData)
-	
-	// Look for certificate patterns in the TLS handshake
-	// This is a simplified approach - real implementation would parse ASN.1/DER
-	
-	if strings.Contains(certStr, "rsaEncryption") || len(certData) > 1000 {
-		// Large certificate likely indicates RSA
-		results = append(results, Result{
-			File:              source,
-			Algorithm:         "RSA",
-			Type:              "PublicKey",
-			Line:              1,
-			Method:            "Certificate Chain Analysis",
-			Risk:              "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "Certificate chain contains RSA certificates vulnerable to quantum attacks",
-			Recommendation:    "Replace certificates with post-quantum alternatives when available",
-		})
-	}
-	
-	if strings.Contains(certStr, "ecPublicKey") || strings.Contains(certStr, "prime256v1") {
-		results = append(results, Result{
-			File:              source,
-			Algorithm:         "ECDSA",
-			Type:              "PublicKey",
-			Line:              1,
-			Method:            "Certificate Chain Analysis",
-			Risk:              "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "Certificate chain contains ECDSA certificates vulnerable to quantum attacks",
-			Recommendation:    "Replace certificates with post-quantum alternatives when available",
-		})
-	}
-	
-	return results
-}
-
-// generateFallbackPCAPResults provides fallback results when PCAP analysis fails
-func (p *PCAPScanner) generateFallbackPCAPResults(pcapFile string) []Result {
-	return []Result{
-		{
-			File:              pcapFile,
-			Algorithm:         "RSA",
-			Type:              "PublicKey",
-			Line:              1,
-			Method:            "PCAP Analysis (Simulated)",
-			Risk:              "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "Simulated: TLS connection uses RSA key exchange vulnerable to quantum attacks",
-			Recommendation:    "Configure servers to prefer ECDHE or post-quantum key exchange",
-		},
-		{
-			File:              pcapFile,
-			Algorithm:         "SHA-1",
-			Type:              "Hash",
-			Line:              2,
-			Method:            "Certificate Analysis (Simulated)",
-			Risk:              "High",
-			VulnerabilityType: "Grover's Algorithm + Broken",
-			Description:       "Simulated: Certificate signed with SHA-1 which is cryptographically broken",
-			Recommendation:    "Replace certificates with SHA-256 signatures",
-		},
-	}
+	return results, assetCount, nil
 }
 
 // generateFallbackNetworkResults provides fallback results when live capture fails
@@ -463,6 +168,7 @@ func (p *PCAPScanner) generateFallbackNetworkResults(captureInterface string) []
 			VulnerabilityType: "Shor's Algorithm",
 			Description:       "Simulated: Live TLS traffic uses ECDH key exchange vulnerable to quantum attacks",
 			Recommendation:    "Upgrade TLS configuration to support post-quantum key exchange",
+			Simulated:         true,
 		},
 	}
-}
\ No newline at end of file
+}
@@ -1,8 +1,14 @@
+//go:build !cgo
 // +build !cgo
 
 package crypto
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
 
 // PCAPScanner stub for non-CGO builds
 type PCAPScanner struct {
@@ -16,48 +22,49 @@ func NewPCAPScanner(scanner *Scanner) *PCAPScanner {
 	}
 }
 
-// AnalyzePCAPFile provides fallback PCAP analysis
-func (p *PCAPScanner) AnalyzePCAPFile(pcapFile string, tlsFilter bool) ([]Result, int) {
-	if p.scanner.Verbose {
-		fmt.Printf("PCAP analysis not available in this build. Providing simulated results.\n")
-	}
-	return p.generateFallbackPCAPResults(pcapFile), 150
+// liveCaptureUnavailable explains why this build cannot capture live traffic
+const liveCaptureUnavailable = "live capture needs libpcap, which builds without cgo do not link"
+
+// pcapFileCapability reports that capture files are read without libpcap
+func pcapFileCapability() Capability {
+	return Capability{Name: CapabilityPCAPFile, Available: true, Detail: "libpcap and pcapng formats, read without libpcap (built without cgo)"}
 }
 
-// PerformLiveCapture provides fallback live capture
-func (p *PCAPScanner) PerformLiveCapture(captureInterface, captureDuration string, tlsFilter bool) ([]Result, int) {
-	if p.scanner.Verbose {
-		fmt.Printf("Live capture not available in this build. Providing simulated results.\n")
+// liveCaptureCapability reports that this build cannot capture
+func liveCaptureCapability() Capability {
+	return Capability{Name: CapabilityLiveCapture, Detail: liveCaptureUnavailable}
+}
+
+// AnalyzePCAPFile analyzes a libpcap or pcapng capture with the pure Go readers. A
+// missing or malformed file is an error.
+func (p *PCAPScanner) AnalyzePCAPFile(ctx context.Context, pcapFile string, tlsFilter bool) ([]Result, int, error) {
+	file, err := os.Open(pcapFile)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open PCAP file: %w", err)
 	}
-	return p.generateFallbackNetworkResults(captureInterface), 25
+	defer file.Close()
+
+	results, packetCount, err := p.AnalyzePCAPReader(ctx, file, pcapFile)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", pcapFile, err)
+	}
+	return results, packetCount, nil
 }
 
-// generateFallbackPCAPResults provides fallback results when PCAP analysis fails
-func (p *PCAPScanner) generateFallbackPCAPResults(pcapFile string) []Result {
-	return []Result{
-		{
-			File:              pcapFile,
-			Algorithm:         "RSA",
-			Type:              "PublicKey",
-			Line:              1,
-			Method:            "PCAP Analysis (Simulated)",
-			Risk:              "High",
-			VulnerabilityType: "Shor's Algorithm",
-			Description:       "Simulated: TLS connection uses RSA key exchange vulnerable to quantum attacks",
-			Recommendation:    "Configure servers to prefer ECDHE or post-quantum key exchange",
-		},
-		{
-			File:              pcapFile,
-			Algorithm:         "SHA-1",
-			Type:              "Hash",
-			Line:              2,
-			Method:            "Certificate Analysis (Simulated)",
-			Risk:              "High",
-			VulnerabilityType: "Grover's Algorithm + Broken",
-			Description:       "Simulated: Certificate signed with SHA-1 which is cryptographically broken",
-			Recommendation:    "Replace certificates with SHA-256 signatures",
-		},
+// PerformLiveCapture can't capture without libpcap: it returns ErrBackendUnavailable
+// for Strict scanners and otherwise warns and provides fallback results. An invalid
+// duration is an error either way.
+func (p *PCAPScanner) PerformLiveCapture(ctx context.Context, captureInterface, captureDuration string, tlsFilter bool) ([]Result, int, error) {
+	if _, err := time.ParseDuration(captureDuration); err != nil {
+		return nil, 0, fmt.Errorf("invalid -duration %q: %w", captureDuration, err)
+	}
+	if err := p.scanner.fallback(CapabilityLiveCapture, "live:"+captureInterface, liveCaptureUnavailable); err != nil {
+		return nil, 0, err
 	}
+	if p.scanner.Verbose {
+		p.scanner.logf("Live capture not available in this build. Providing simulated results.\n")
+	}
+	return p.generateFallbackNetworkResults(captureInterface), 25, nil
 }
 
 // generateFallbackNetworkResults provides fallback results when live capture fails
@@ -73,6 +80,7 @@ func (p *PCAPScanner) generateFallbackNetworkResults(captureInterface string) []
 			VulnerabilityType: "Shor's Algorithm",
 			Description:       "Simulated: Live TLS traffic uses ECDH key exchange vulnerable to quantum attacks",
 			Recommendation:    "Upgrade TLS configuration to support post-quantum key exchange",
+			Simulated:         true,
 		},
 	}
-}
\ No newline at end of file
+}
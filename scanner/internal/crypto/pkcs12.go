@@ -0,0 +1,222 @@
+package crypto
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// errKeystorePassword is returned when a keystore's content is encrypted or
+// integrity-protected under a password other than the one given
+var errKeystorePassword = errors.New("keystore password is missing or incorrect")
+
+// pkcs12MaxIterations bounds the key derivation iterations a PKCS#12 file may ask
+// for. Tools write a few thousand; a crafted file could otherwise stall the scan.
+const pkcs12MaxIterations = 10_000_000
+
+var (
+	oidPKCS7Data           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPKCS7EncryptedData  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidFriendlyName        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidPBES2               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBMAC1              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 14}
+)
+
+// The parts of a PKCS#12 file readable before any decryption
+type pfxPDU struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo struct {
+		ContentType                asn1.ObjectIdentifier
+		ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	}
+}
+
+type macData struct {
+	Mac struct {
+		Algorithm pkix.AlgorithmIdentifier
+		Digest    []byte
+	}
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID     asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// keystoreEntry is a certificate or private key read from a keystore
+type keystoreEntry struct {
+	Alias       string
+	Certificate *x509.Certificate
+	PrivateKey  interface{} // Parsed PKCS#8 key, for key entries
+}
+
+// parsePKCS12 returns the certificates and private key of a PKCS#12 file: a key
+// with its certificate chain, or a Java trust store of certificates only.
+// errKeystorePassword is returned when password doesn't open it.
+func parsePKCS12(data []byte, password string) ([]keystoreEntry, error) {
+	keyAlias, err := inspectPKCS12(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key, certificate, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil && !errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		// Trust stores hold no key, which DecodeChain requires
+		if certs, trustErr := pkcs12.DecodeTrustStore(data, password); trustErr == nil {
+			key, certificate, caCerts, err = nil, nil, certs, nil
+		}
+	}
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return nil, errKeystorePassword
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []keystoreEntry
+	for _, cert := range append([]*x509.Certificate{certificate}, caCerts...) {
+		if cert != nil {
+			entries = append(entries, keystoreEntry{Certificate: cert})
+		}
+	}
+	if key != nil {
+		entries = append(entries, keystoreEntry{Alias: keyAlias, PrivateKey: key})
+	}
+	return entries, nil
+}
+
+// inspectPKCS12 rejects a PKCS#12 file whose MAC, encrypted content or shrouded key
+// bags ask for more than pkcs12MaxIterations key derivation iterations, before any
+// are run. It returns the friendly name of the first key bag stored in the clear,
+// as OpenSSL and keytool write them, since the decoder doesn't report aliases.
+func inspectPKCS12(data []byte) (string, error) {
+	var pfx pfxPDU
+	if _, err := asn1.Unmarshal(data, &pfx); err != nil {
+		return "", fmt.Errorf("not a PKCS#12 file: %w", err)
+	}
+	iterations := []int{pfx.MacData.Iterations}
+	if pfx.MacData.Mac.Algorithm.Algorithm.Equal(oidPBMAC1) {
+		iterations = append(iterations, pbeIterations(pfx.MacData.Mac.Algorithm))
+	}
+
+	var authSafe []contentInfo
+	if err := unmarshalOctetString(pfx.AuthSafe.Content.Bytes, &authSafe); err != nil {
+		return "", fmt.Errorf("malformed PKCS#12 content: %w", err)
+	}
+	keyAlias := ""
+	for _, ci := range authSafe {
+		switch {
+		case ci.ContentType.Equal(oidPKCS7EncryptedData):
+			// The bags inside are only read once this content's iterations have run
+			var ed encryptedData
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &ed); err == nil {
+				iterations = append(iterations, pbeIterations(ed.EncryptedContentInfo.ContentEncryptionAlgorithm))
+			}
+		case ci.ContentType.Equal(oidPKCS7Data):
+			var bags []safeBag
+			if err := unmarshalOctetString(ci.Content.Bytes, &bags); err != nil {
+				return "", fmt.Errorf("malformed PKCS#12 safe contents: %w", err)
+			}
+			for _, bag := range bags {
+				var epki encryptedPrivateKeyInfo
+				if !bag.ID.Equal(oidPKCS8ShroudedKeyBag) {
+					continue
+				}
+				if _, err := asn1.Unmarshal(bag.Value.Bytes, &epki); err == nil {
+					iterations = append(iterations, pbeIterations(epki.Algorithm))
+				}
+				if keyAlias == "" {
+					keyAlias = friendlyName(bag.Attributes)
+				}
+			}
+		}
+	}
+
+	for _, n := range iterations {
+		if n > pkcs12MaxIterations {
+			return "", fmt.Errorf("PKCS#12 file asks for %d key derivation iterations, more than the %d allowed", n, pkcs12MaxIterations)
+		}
+	}
+	return keyAlias, nil
+}
+
+// friendlyName returns the friendlyName attribute of a bag, or ""
+func friendlyName(attributes []pkcs12Attribute) string {
+	for _, attribute := range attributes {
+		if !attribute.ID.Equal(oidFriendlyName) {
+			continue
+		}
+		var raw asn1.RawValue
+		if _, err := asn1.Unmarshal(attribute.Values.Bytes, &raw); err != nil || raw.Tag != asn1.TagBMPString || len(raw.Bytes)%2 != 0 {
+			return ""
+		}
+		units := make([]uint16, len(raw.Bytes)/2)
+		for i := range units {
+			units[i] = uint16(raw.Bytes[2*i])<<8 | uint16(raw.Bytes[2*i+1])
+		}
+		return string(utf16.Decode(units))
+	}
+	return ""
+}
+
+// unmarshalOctetString parses the DER value wrapped in the OCTET STRING data into out
+func unmarshalOctetString(data []byte, out interface{}) error {
+	var content []byte
+	if _, err := asn1.Unmarshal(data, &content); err != nil {
+		return err
+	}
+	_, err := asn1.Unmarshal(content, out)
+	return err
+}
+
+// pbeIterations returns the iteration count of a PKCS#12 PBE, PBES2 or PBMAC1
+// algorithm, or 0 when its parameters can't be read
+func pbeIterations(algorithm pkix.AlgorithmIdentifier) int {
+	if algorithm.Algorithm.Equal(oidPBES2) || algorithm.Algorithm.Equal(oidPBMAC1) {
+		// Both start with the PBKDF2 algorithm and its parameters
+		var scheme struct{ KeyDerivationFunc pkix.AlgorithmIdentifier }
+		if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &scheme); err != nil {
+			return 0
+		}
+		algorithm = scheme.KeyDerivationFunc
+	}
+	// PKCS#12 PBE and PBKDF2 parameters both start with a salt and the iterations
+	var params struct {
+		Salt       asn1.RawValue
+		Iterations int
+	}
+	if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+		return 0
+	}
+	return params.Iterations
+}
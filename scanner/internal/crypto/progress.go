@@ -0,0 +1,51 @@
+package crypto
+
+import "sync"
+
+// progressUpdate is a single file-completed notification
+type progressUpdate struct {
+	current string
+}
+
+// progressReporter delivers progress updates to a ProgressFunc from a single goroutine.
+// Updates are buffered for every file up front, so workers never wait on the callback.
+type progressReporter struct {
+	updates chan progressUpdate
+	done    sync.WaitGroup
+}
+
+// newProgressReporter starts delivering updates for total files to fn; returns nil if fn is nil
+func newProgressReporter(fn func(scanned, total int, current string), total int) *progressReporter {
+	if fn == nil {
+		return nil
+	}
+
+	p := &progressReporter{updates: make(chan progressUpdate, total)}
+	p.done.Add(1)
+	go func() {
+		defer p.done.Done()
+		scanned := 0
+		for update := range p.updates {
+			scanned++
+			fn(scanned, total, update.current)
+		}
+	}()
+	return p
+}
+
+// fileDone records that a file finished scanning
+func (p *progressReporter) fileDone(path string) {
+	if p == nil {
+		return
+	}
+	p.updates <- progressUpdate{current: path}
+}
+
+// close stops accepting updates and waits until all pending ones are delivered
+func (p *progressReporter) close() {
+	if p == nil {
+		return
+	}
+	close(p.updates)
+	p.done.Wait()
+}
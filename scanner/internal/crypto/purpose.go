@@ -0,0 +1,173 @@
+package crypto
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Algorithm types a Result.Type is normalized to
+const (
+	TypePublicKey    = "PublicKey"
+	TypeSymmetricKey = "SymmetricKey"
+	TypeHash         = "Hash"
+	TypeHybrid       = "Hybrid"
+	TypePostQuantum  = "PostQuantum"
+	TypeProtocol     = "Protocol"
+	TypeEmbeddedKey  = "EmbeddedKey"
+	TypeRandom       = "RandomNumberGenerator"
+	TypeLibrary      = "Library"
+	TypeKDF          = "KDF"
+)
+
+// Purposes an algorithm is used for, see Result.Purpose
+const (
+	PurposeSigning          = "signing"
+	PurposeKeyEncapsulation = "keyEncapsulation" // Key exchange, agreement or transport
+	PurposeEncrypt          = "encrypt"
+	PurposeHash             = "hash"
+	PurposeKeyDerivation    = "keyDerivation"
+)
+
+// typeAliases maps the spellings of algorithm types found in rules and older
+// results, lower-cased, to the defined types
+var typeAliases = map[string]string{
+	"publickey":             TypePublicKey,
+	"public key":            TypePublicKey,
+	"asymmetric":            TypePublicKey,
+	"signature":             TypePublicKey,
+	"digital signature":     TypePublicKey,
+	"key exchange":          TypePublicKey,
+	"key establishment":     TypePublicKey,
+	"symmetrickey":          TypeSymmetricKey,
+	"symmetric":             TypeSymmetricKey,
+	"cipher":                TypeSymmetricKey,
+	"encryption":            TypeSymmetricKey,
+	"hash":                  TypeHash,
+	"hashing":               TypeHash,
+	"hybrid":                TypeHybrid,
+	"postquantum":           TypePostQuantum,
+	"post-quantum":          TypePostQuantum,
+	"protocol":              TypeProtocol,
+	"embeddedkey":           TypeEmbeddedKey,
+	"randomnumbergenerator": TypeRandom,
+	"rng":                   TypeRandom,
+	"library":               TypeLibrary,
+	"kdf":                   TypeKDF,
+	"key derivation":        TypeKDF,
+}
+
+// typePurposes are the purposes implied by type spellings that name one
+var typePurposes = map[string]string{
+	"signature":         PurposeSigning,
+	"digital signature": PurposeSigning,
+	"key exchange":      PurposeKeyEncapsulation,
+	"key establishment": PurposeKeyEncapsulation,
+}
+
+// NormalizeType returns the defined type for an algorithm type spelling, or t
+// unchanged if it is not a known spelling
+func NormalizeType(t string) string {
+	if normalized, ok := typeAliases[strings.ToLower(strings.TrimSpace(t))]; ok {
+		return normalized
+	}
+	return t
+}
+
+// AssessPurpose infers what the algorithm of a finding is used for. Signature and
+// key establishment algorithms are recognized by family; RSA, which serves both, is
+// placed by the finding's context and left empty when that is silent.
+func AssessPurpose(result Result) string {
+	if purpose, ok := typePurposes[strings.ToLower(strings.TrimSpace(result.Type))]; ok {
+		return purpose
+	}
+
+	switch NormalizeType(result.Type) {
+	case TypeHash:
+		return PurposeHash
+	case TypeKDF:
+		return PurposeKeyDerivation
+	case TypeSymmetricKey:
+		return PurposeEncrypt
+	case TypePublicKey, TypeHybrid, TypePostQuantum:
+	default:
+		return ""
+	}
+
+	algorithm := strings.ToUpper(result.Algorithm)
+	switch {
+	case strings.Contains(algorithm, "DSA"), strings.HasPrefix(algorithm, "ED25519"), strings.HasPrefix(algorithm, "ED448"),
+		strings.HasPrefix(algorithm, "FALCON"), strings.HasPrefix(algorithm, "SPHINCS"):
+		return PurposeSigning
+	case strings.Contains(algorithm, "KEM"), strings.Contains(algorithm, "KYBER"), strings.Contains(algorithm, "DH"),
+		strings.HasPrefix(algorithm, "X25519"), strings.HasPrefix(algorithm, "X448"):
+		return PurposeKeyEncapsulation
+	}
+
+	context := strings.ToLower(result.Method + " " + result.Description)
+	switch {
+	case result.ChainPosition != "", strings.Contains(context, "signature"), strings.Contains(context, "signing"),
+		strings.Contains(context, "certificate"):
+		return PurposeSigning
+	case strings.Contains(context, "key exchange"), strings.Contains(context, "key transport"),
+		strings.Contains(context, "encrypt"), strings.Contains(context, "cipher"):
+		return PurposeKeyEncapsulation
+	}
+	return ""
+}
+
+// signingCallPattern matches signing and verification calls and names on a source
+// line, e.g. key.sign(, rsa.SignPSS, Signature.getInstance("SHA256withRSA"), but not
+// words merely containing "sign" such as design, assign or signal
+var signingCallPattern = regexp.MustCompile(identifierWordPattern("sign", "signer", "signature", "signing", "signed", "verify", "pss") + `|(?i:withrsa)`)
+
+// sourceLinePurpose infers the purpose of a public-key algorithm from the source
+// line it was matched on, e.g. a Signature API call, or "" to fall back to AssessPurpose
+func sourceLinePurpose(algType, line string) string {
+	if NormalizeType(algType) != TypePublicKey {
+		return ""
+	}
+	lower := strings.ToLower(line)
+	switch {
+	case signingCallPattern.MatchString(line):
+		return PurposeSigning
+	case strings.Contains(lower, "encrypt"), strings.Contains(lower, "decrypt"), strings.Contains(lower, "oaep"),
+		strings.Contains(lower, "wrap"), strings.Contains(lower, "keyagreement"), strings.Contains(lower, "exchange"):
+		return PurposeKeyEncapsulation
+	}
+	return ""
+}
+
+// CryptoFunctions returns the CycloneDX cryptoFunctions of a purpose
+func CryptoFunctions(purpose string) []string {
+	switch purpose {
+	case PurposeSigning:
+		return []string{"sign", "verify"}
+	case PurposeKeyEncapsulation:
+		return []string{"encapsulate", "decapsulate"}
+	case PurposeEncrypt:
+		return []string{"encrypt", "decrypt"}
+	case PurposeHash:
+		return []string{"digest"}
+	case PurposeKeyDerivation:
+		return []string{"keyderive"}
+	}
+	return nil
+}
+
+// classifyResults canonicalizes the algorithm and type of each result and fills in
+// its purpose and HNDL risk where they are not set yet
+func classifyResults(results []Result) {
+	for i := range results {
+		if canonical := CanonicalAlgorithm(results[i].Algorithm); canonical != results[i].Algorithm {
+			if results[i].RawAlgorithm == "" {
+				results[i].RawAlgorithm = results[i].Algorithm
+			}
+			results[i].Algorithm = canonical
+		}
+		if results[i].Purpose == "" {
+			results[i].Purpose = AssessPurpose(results[i])
+		}
+		results[i].Type = NormalizeType(results[i].Type)
+	}
+	applyHNDLRisk(results)
+}
@@ -0,0 +1,307 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/hkdf"
+)
+
+// QUIC versions whose Initial packets can be unprotected
+const (
+	quicVersion1       = 0x00000001 // RFC 9000
+	quicVersion2       = 0x6B3343CF // RFC 9369
+	quicVersionDraft29 = 0xFF00001D
+)
+
+// quicInitialSalts are the version-specific salts Initial secrets are derived with
+// (RFC 9001 §5.2, RFC 9369 §3.3.1)
+var quicInitialSalts = map[uint32]string{
+	quicVersion1:       "38762cf7f55934b34d179ae6a4c80cadccbb7f0a",
+	quicVersion2:       "0dede3def700a6db819381be6e269dcbf9bd2ed9",
+	quicVersionDraft29: "afbfec289993d24c9e9786f19c6111e04390a899",
+}
+
+// Limits on reassembling ClientHellos split across Initial packets
+const (
+	maxQUICStreams     = 4096
+	maxQUICCryptoBytes = 64 << 10
+)
+
+// quicTracker reassembles the CRYPTO stream of client Initial packets per
+// connection, since post-quantum key shares often push a ClientHello past one packet
+type quicTracker struct {
+	streams map[string]*quicCryptoStream
+}
+
+// quicCryptoStream holds CRYPTO frame data received so far, keyed by stream offset
+type quicCryptoStream struct {
+	frames map[uint64][]byte
+	size   int
+	done   bool
+}
+
+func newQUICTracker() *quicTracker {
+	return &quicTracker{streams: make(map[string]*quicCryptoStream)}
+}
+
+// observe unprotects the client Initial packets in a UDP datagram and returns the
+// ClientHello once the connection's CRYPTO stream holds all of it
+func (t *quicTracker) observe(datagram []byte) *clientHello {
+	for len(datagram) > 0 {
+		version, dcid, frames, rest, err := openQUICInitial(datagram)
+		if err != nil {
+			return nil
+		}
+		datagram = rest
+
+		key := fmt.Sprintf("%x/%x", version, dcid)
+		stream, ok := t.streams[key]
+		if !ok {
+			if len(t.streams) >= maxQUICStreams {
+				return nil
+			}
+			stream = &quicCryptoStream{frames: make(map[uint64][]byte)}
+			t.streams[key] = stream
+		}
+		if stream.done {
+			continue
+		}
+		if err := stream.add(frames); err != nil {
+			continue
+		}
+		if hello := stream.clientHello(); hello != nil {
+			stream.done = true
+			stream.frames = nil
+			return hello
+		}
+	}
+	return nil
+}
+
+// add records the CRYPTO frames in an Initial packet payload
+func (s *quicCryptoStream) add(frames []byte) error {
+	for len(frames) > 0 {
+		frameType := frames[0]
+		frames = frames[1:]
+		switch frameType {
+		case 0x00, 0x01: // PADDING, PING
+		case 0x02, 0x03: // ACK, ACK with ECN counts
+			var err error
+			if frames, err = skipQUICAck(frames, frameType == 0x03); err != nil {
+				return err
+			}
+		case 0x06: // CRYPTO
+			offset, n := quicVarint(frames)
+			if n == 0 {
+				return errTruncated
+			}
+			length, m := quicVarint(frames[n:])
+			if m == 0 || uint64(len(frames)-n-m) < length {
+				return errTruncated
+			}
+			data := frames[n+m : n+m+int(length)]
+			frames = frames[n+m+int(length):]
+			if s.size+len(data) > maxQUICCryptoBytes {
+				return errors.New("CRYPTO stream too large")
+			}
+			s.frames[offset] = data
+			s.size += len(data)
+		default:
+			// Other frames are not expected in client Initials; keep what was read
+			return nil
+		}
+	}
+	return nil
+}
+
+// clientHello returns the ClientHello if the stream's contiguous prefix holds all of it
+func (s *quicCryptoStream) clientHello() *clientHello {
+	var stream []byte
+	for {
+		data, ok := s.frames[uint64(len(stream))]
+		if !ok || len(data) == 0 {
+			break
+		}
+		stream = append(stream, data...)
+	}
+	if len(stream) < 4 {
+		return nil
+	}
+	length := 4 + (int(stream[1])<<16 | int(stream[2])<<8 | int(stream[3]))
+	if len(stream) < length {
+		return nil
+	}
+	hello, err := parseClientHello(stream[:length])
+	if err != nil {
+		return nil
+	}
+	return hello
+}
+
+// skipQUICAck skips the body of an ACK frame
+func skipQUICAck(frames []byte, ecn bool) ([]byte, error) {
+	fields := 4 // Largest Acknowledged, ACK Delay, ACK Range Count, First ACK Range
+	rangeCount := uint64(0)
+	for i := 0; i < fields; i++ {
+		v, n := quicVarint(frames)
+		if n == 0 {
+			return nil, errTruncated
+		}
+		if i == 2 {
+			rangeCount = v
+		}
+		frames = frames[n:]
+	}
+	extra := 2 * rangeCount
+	if ecn {
+		extra += 3
+	}
+	for i := uint64(0); i < extra; i++ {
+		_, n := quicVarint(frames)
+		if n == 0 {
+			return nil, errTruncated
+		}
+		frames = frames[n:]
+	}
+	return frames, nil
+}
+
+// quicVarint decodes a QUIC variable-length integer, returning its value and size (0 if truncated)
+func quicVarint(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	n := 1 << (b[0] >> 6)
+	if len(b) < n {
+		return 0, 0
+	}
+	v := uint64(b[0] & 0x3F)
+	for _, c := range b[1:n] {
+		v = v<<8 | uint64(c)
+	}
+	return v, n
+}
+
+// openQUICInitial removes header protection from the client Initial packet at the
+// start of datagram and decrypts it (RFC 9001 §5), returning the version, the
+// destination connection ID, the frames and any coalesced packets that follow
+func openQUICInitial(datagram []byte) (version uint32, dcid, frames, rest []byte, err error) {
+	if len(datagram) < 7 || datagram[0]&0x80 == 0 {
+		return 0, nil, nil, nil, errors.New("not a long header packet")
+	}
+	version = binary.BigEndian.Uint32(datagram[1:5])
+	saltHex, ok := quicInitialSalts[version]
+	if !ok {
+		return 0, nil, nil, nil, fmt.Errorf("unsupported QUIC version 0x%08x", version)
+	}
+	packetType := datagram[0] >> 4 & 0x03
+	if (version == quicVersion2 && packetType != 1) || (version != quicVersion2 && packetType != 0) {
+		return 0, nil, nil, nil, errors.New("not an Initial packet")
+	}
+
+	pos := 5
+	dcidLen := int(datagram[pos])
+	pos++
+	if pos+dcidLen+1 > len(datagram) {
+		return 0, nil, nil, nil, errTruncated
+	}
+	dcid = datagram[pos : pos+dcidLen]
+	pos += dcidLen
+	pos += 1 + int(datagram[pos]) // Source Connection ID
+	if pos > len(datagram) {
+		return 0, nil, nil, nil, errTruncated
+	}
+	tokenLen, n := quicVarint(datagram[pos:])
+	if n == 0 || uint64(len(datagram)-pos-n) < tokenLen {
+		return 0, nil, nil, nil, errTruncated
+	}
+	pos += n + int(tokenLen)
+	length, n := quicVarint(datagram[pos:])
+	if n == 0 || uint64(len(datagram)-pos-n) < length {
+		return 0, nil, nil, nil, errTruncated
+	}
+	pos += n
+	pnOffset := pos
+	end := pnOffset + int(length)
+	if end < pnOffset+4+16 {
+		return 0, nil, nil, nil, errTruncated
+	}
+
+	salt, _ := hex.DecodeString(saltHex)
+	key, iv, hp := quicClientInitialKeys(version, salt, dcid)
+
+	// Remove header protection using a sample of the ciphertext
+	hpBlock, err := aes.NewCipher(hp)
+	if err != nil {
+		return 0, nil, nil, nil, err
+	}
+	mask := make([]byte, aes.BlockSize)
+	hpBlock.Encrypt(mask, datagram[pnOffset+4:pnOffset+4+16])
+
+	header := append([]byte(nil), datagram[:pnOffset+4]...)
+	header[0] ^= mask[0] & 0x0F
+	pnLen := int(header[0]&0x03) + 1
+	header = header[:pnOffset+pnLen]
+	var packetNumber uint64
+	for i := 0; i < pnLen; i++ {
+		header[pnOffset+i] ^= mask[1+i]
+		packetNumber = packetNumber<<8 | uint64(header[pnOffset+i])
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return 0, nil, nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return 0, nil, nil, nil, err
+	}
+	nonce := append([]byte(nil), iv...)
+	for i := 0; i < 8; i++ {
+		nonce[len(nonce)-1-i] ^= byte(packetNumber >> (8 * i))
+	}
+	frames, err = aead.Open(nil, nonce, datagram[pnOffset+pnLen:end], header)
+	if err != nil {
+		return 0, nil, nil, nil, fmt.Errorf("failed to decrypt Initial packet: %w", err)
+	}
+	return version, dcid, frames, datagram[end:], nil
+}
+
+// quicClientInitialKeys derives the client's Initial packet protection key, IV and
+// header protection key from the destination connection ID
+func quicClientInitialKeys(version uint32, salt, dcid []byte) (key, iv, hp []byte) {
+	labelPrefix := "quic "
+	if version == quicVersion2 {
+		labelPrefix = "quicv2 "
+	}
+	initialSecret := hkdf.Extract(sha256.New, dcid, salt)
+	clientSecret := hkdfExpandLabel(initialSecret, "client in", sha256.Size)
+	return hkdfExpandLabel(clientSecret, labelPrefix+"key", 16),
+		hkdfExpandLabel(clientSecret, labelPrefix+"iv", 12),
+		hkdfExpandLabel(clientSecret, labelPrefix+"hp", 16)
+}
+
+// hkdfExpandLabel is the TLS 1.3 HKDF-Expand-Label with an empty context (RFC 8446 §7.1)
+func hkdfExpandLabel(secret []byte, label string, length int) []byte {
+	var hkdfLabel cryptobyte.Builder
+	hkdfLabel.AddUint16(uint16(length))
+	hkdfLabel.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes([]byte("tls13 "))
+		b.AddBytes([]byte(label))
+	})
+	hkdfLabel.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {})
+
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, secret, hkdfLabel.BytesOrPanic()), out); err != nil {
+		panic("quic: HKDF-Expand-Label invocation failed unexpectedly")
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package crypto
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// weakRNGContextLines is how many lines around a weak RNG call are searched for crypto context
+const weakRNGContextLines = 3
+
+var (
+	// cryptoContextPattern matches identifiers suggesting the random value is security sensitive
+	cryptoContextPattern = regexp.MustCompile(identifierWordPattern("key", "nonce", "token", "salt", "secret", "passw(?:or)?d", "otp", "iv"))
+
+	// goMathRandImportPattern captures the (optional) alias of a math/rand import
+	goMathRandImportPattern = regexp.MustCompile(`(?:^|\s)(\w+)?\s*"math/rand(?:/v2)?"`)
+)
+
+// weakRNGSource describes a non-cryptographic random source for one language
+type weakRNGSource struct {
+	Name           string
+	Pattern        *regexp.Regexp
+	Recommendation string
+}
+
+// weakRNGSources maps file extensions to the non-cryptographic random sources to flag
+var weakRNGSources = map[string][]weakRNGSource{
+	".js":   {{Name: "Math.random", Pattern: regexp.MustCompile(`\bMath\.random\(\)`), Recommendation: "Use crypto.randomBytes() or crypto.getRandomValues() for security-sensitive values"}},
+	".ts":   {{Name: "Math.random", Pattern: regexp.MustCompile(`\bMath\.random\(\)`), Recommendation: "Use crypto.randomBytes() or crypto.getRandomValues() for security-sensitive values"}},
+	".py":   {{Name: "random", Pattern: regexp.MustCompile(`\brandom\.(random|randint|randrange|choice|choices|getrandbits|randbytes)\(`), Recommendation: "Use the secrets module or os.urandom() for security-sensitive values"}},
+	".java": {{Name: "java.util.Random", Pattern: regexp.MustCompile(`\bnew Random\(|\bjava\.util\.Random\b`), Recommendation: "Use java.security.SecureRandom for security-sensitive values"}},
+	".cs":   {{Name: "System.Random", Pattern: regexp.MustCompile(`\bnew Random\(`), Recommendation: "Use System.Security.Cryptography.RandomNumberGenerator for security-sensitive values"}},
+	".php":  {{Name: "mt_rand", Pattern: regexp.MustCompile(`\b(mt_rand|rand|uniqid)\(`), Recommendation: "Use random_bytes() or random_int() for security-sensitive values"}},
+	".rb":   {{Name: "rand", Pattern: regexp.MustCompile(`\b(rand|Random\.new)\b`), Recommendation: "Use SecureRandom for security-sensitive values"}},
+	".c":    {{Name: "rand", Pattern: regexp.MustCompile(`\b(rand|srand|random)\(`), Recommendation: "Use getrandom(), arc4random_buf() or RAND_bytes() for security-sensitive values"}},
+	".cpp":  {{Name: "rand", Pattern: regexp.MustCompile(`\b(rand|srand|random)\(|\bstd::mt19937(?:_64)?\b`), Recommendation: "Use getrandom(), arc4random_buf() or RAND_bytes() for security-sensitive values"}},
+}
+
+// detectWeakRandomness flags non-cryptographic RNG calls used near key, IV, nonce or token handling
+func (s *Scanner) detectWeakRandomness(filePath string, lines []string) []Result {
+	var results []Result
+
+	sources := weakRNGSources[strings.ToLower(filepath.Ext(filePath))]
+	if strings.ToLower(filepath.Ext(filePath)) == ".go" {
+		sources = goWeakRNGSources(lines)
+	}
+	if len(sources) == 0 {
+		return results
+	}
+
+	for i, line := range lines {
+		for _, source := range sources {
+			if !source.Pattern.MatchString(line) || !hasCryptoContext(lines, i) {
+				continue
+			}
+			results = append(results, Result{
+				File:              filePath,
+				Algorithm:         source.Name,
+				Type:              "RandomNumberGenerator",
+				Line:              i + 1,
+				Method:            "Randomness Analysis",
+				Risk:              "High",
+				VulnerabilityType: "Weak RNG",
+				Description:       fmt.Sprintf("%s is not a cryptographically secure random source but is used near key, IV, nonce or token generation", source.Name),
+				Recommendation:    source.Recommendation,
+			})
+			break
+		}
+	}
+
+	return results
+}
+
+// goWeakRNGSources builds the math/rand call pattern for a Go file, honoring import aliases.
+// Returns nothing when the file does not import math/rand, since rand.Read is then crypto/rand.
+func goWeakRNGSources(lines []string) []weakRNGSource {
+	for _, line := range lines {
+		match := goMathRandImportPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		alias := "rand"
+		if match[1] != "" && match[1] != "import" {
+			alias = match[1]
+		}
+		return []weakRNGSource{{
+			Name:           "math/rand",
+			Pattern:        regexp.MustCompile(`\b` + regexp.QuoteMeta(alias) + `\.(Read|Int|Intn|IntN|Int31|Int31n|Int63|Int63n|Uint32|Uint64|Float64|Perm|Shuffle|New)\(`),
+			Recommendation: "Use crypto/rand for keys, IVs, nonces and tokens",
+		}}
+	}
+	return nil
+}
+
+// hasCryptoContext reports whether security-sensitive identifiers appear within a few lines of index
+func hasCryptoContext(lines []string, index int) bool {
+	return matchesNearby(lines, index, weakRNGContextLines, cryptoContextPattern)
+}
+
+// matchesNearby reports whether pattern matches any line within radius lines of index
+func matchesNearby(lines []string, index, radius int, pattern *regexp.Regexp) bool {
+	start := index - radius
+	if start < 0 {
+		start = 0
+	}
+	end := index + radius
+	if end >= len(lines) {
+		end = len(lines) - 1
+	}
+
+	for i := start; i <= end; i++ {
+		if pattern.MatchString(lines[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,33 +1,86 @@
 package crypto
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Result represents a vulnerability finding
 type Result struct {
-	File              string    `json:"file"`
-	Algorithm         string    `json:"algorithm"`
-	Type              string    `json:"type"`
-	Line              int       `json:"line"`
-	Method            string    `json:"method"`
-	Risk              string    `json:"risk"`
-	VulnerabilityType string    `json:"vulnerability_type"` // What type of quantum vulnerability (Shor's, Grover's, etc.)
-	Description       string    `json:"description"`        // Description of the vulnerability
-	Recommendation    string    `json:"recommendation"`     // Recommendation for remediation
+	File              string   `json:"file"`
+	Fingerprint       string   `json:"fingerprint,omitempty"` // Stable ID of the finding across scans, see SetFingerprints
+	Algorithm         string   `json:"algorithm"`
+	RawAlgorithm      string   `json:"raw_algorithm,omitempty"` // Name as detected, when Algorithm is its canonical synonym
+	Type              string   `json:"type"`
+	Line              int      `json:"line"`
+	Column            int      `json:"column,omitempty"` // Column of the call, import or rule match, 1-based; 0 if unknown
+	Method            string   `json:"method"`
+	Risk              string   `json:"risk"`
+	DefaultRisk       string   `json:"default_risk,omitempty"` // Risk the scanner assigned, when a severity map or the environment replaced it
+	Environment       string   `json:"environment,omitempty"`  // Deployment environment of the code: prod, staging, dev or test; see Environments
+	VulnerabilityType string   `json:"vulnerability_type"`     // What type of quantum vulnerability (Shor's, Grover's, etc.)
+	CWE               []string `json:"cwe,omitempty"`          // CWE identifiers of the weakness, e.g. CWE-327
+	Description       string   `json:"description"`            // Description of the vulnerability
+	Recommendation    string   `json:"recommendation"`         // Recommendation for remediation
 	// NIST IR 8547 fields
-	NISTCategory      string    `json:"nist_category,omitempty"`      // "1", "2", "3", "4", "5", "deprecated", "disallowed"
-	DeprecationDate   *time.Time `json:"deprecation_date,omitempty"`   // 2030-01-01 for 112-bit algorithms
-	DisallowanceDate  *time.Time `json:"disallowance_date,omitempty"`  // 2035-01-01 for all vulnerable
-	QuantumResistant  bool      `json:"quantum_resistant"`
-	NISTAlgorithmID   string    `json:"nist_algorithm_id,omitempty"`  // e.g., "ML-KEM-512", "RSA-2048"
-	SecurityStrength  int       `json:"security_strength,omitempty"`  // Classical security strength in bits
-	NISTTable         string    `json:"nist_table,omitempty"`         // Which NIST IR 8547 table references this
+	NISTCategory                string              `json:"nist_category,omitempty"`     // "1", "2", "3", "4", "5", "deprecated", "disallowed"
+	DeprecationDate             *time.Time          `json:"deprecation_date,omitempty"`  // 2030-01-01 for 112-bit algorithms
+	DisallowanceDate            *time.Time          `json:"disallowance_date,omitempty"` // 2035-01-01 for all vulnerable
+	QuantumResistant            bool                `json:"quantum_resistant"`
+	NISTAlgorithmID             string              `json:"nist_algorithm_id,omitempty"`               // e.g., "ML-KEM-512", "RSA-2048"
+	SecurityStrength            int                 `json:"security_strength,omitempty"`               // Classical security strength in bits
+	NISTTable                   string              `json:"nist_table,omitempty"`                      // Which NIST IR 8547 table references this
+	HNDLRisk                    string              `json:"hndl_risk,omitempty"`                       // Harvest-now-decrypt-later exposure: High, Medium, Low, None
+	TimelineStatusAtProjectDate string              `json:"timeline_status_at_project_date,omitempty"` // NIST IR 8547 status on the project date, see SetTimelineStatusAt
+	ChainPosition               string              `json:"chain_position,omitempty"`                  // Certificate findings: root, intermediate or leaf
+	Subject                     string              `json:"subject,omitempty"`                         // Certificate findings: the subject's common name, or the full subject without one
+	Purpose                     string              `json:"purpose,omitempty"`                         // signing, keyEncapsulation, encrypt or hash; empty when unknown
+	Curve                       string              `json:"curve,omitempty"`                           // Elliptic-curve findings: the named curve, e.g. P-256 or Curve25519
+	KeySize                     int                 `json:"key_size,omitempty"`                        // Key size in bits, when read from the key itself
+	SignatureAlgorithm          string              `json:"signature_algorithm,omitempty"`             // Certificate findings: the algorithm the issuer signed with
+	NotAfter                    *time.Time          `json:"not_after,omitempty"`                       // Certificate findings: expiry
+	Role                        string              `json:"role,omitempty"`                            // Handshake findings: client or server side of the connection
+	Negotiation                 string              `json:"negotiation,omitempty"`                     // Handshake findings: offered by the client or negotiated by the server
+	Seen                        int                 `json:"seen,omitempty"`                            // Handshake findings: number of identical handshakes in the capture
+	Confidence                  float64             `json:"confidence,omitempty"`                      // Strength of a source-code match, 0-1
+	Snippet                     *Snippet            `json:"snippet,omitempty"`                         // Source-code findings: the matched line and its neighbors
+	RemediationExample          string              `json:"remediation_example,omitempty"`             // Fix snippet in the file's language, with ShowRemediation
+	SuggestedFix                string              `json:"suggested_fix,omitempty"`                   // Unified diff of a mechanical fix, with SuggestFixes
+	Compliance                  []ComplianceControl `json:"compliance,omitempty"`                      // Framework controls the finding violates, with -compliance
+	Simulated                   bool                `json:"simulated,omitempty"`                       // Placeholder from a fallback when the real backend was unavailable, not an observed finding
+	// SBOM provenance, set when the file belongs to a component of an ingested SBOM
+	PURL             string `json:"purl,omitempty"`
+	ComponentName    string `json:"component_name,omitempty"`
+	ComponentVersion string `json:"component_version,omitempty"`
+
+	fingerprintKey string // What Fingerprint hashes besides the path, kept to recompute it when the path changes
+}
+
+// ComplianceControl is a control of a compliance framework that a finding violates
+type ComplianceControl struct {
+	Framework string `json:"framework"` // e.g. NIST SP 800-131A Rev. 2
+	Control   string `json:"control"`   // e.g. SP800-131A-HASH
+	Title     string `json:"title"`
+	Status    string `json:"status,omitempty"` // What the control says of the finding, e.g. disallowed
+}
+
+// ScanWarning records a non-fatal problem that left part of a scan incomplete, such
+// as a namespace that could not be listed or a file that could not be read
+type ScanWarning struct {
+	Namespace  string `json:"namespace,omitempty"`
+	File       string `json:"file,omitempty"`
+	Reason     string `json:"reason"`
+	Capability string `json:"capability,omitempty"` // Unavailable feature the scan fell back from, e.g. CapabilityLiveCapture
 }
 
 // DetectionRule defines a pattern to detect vulnerable crypto
@@ -38,150 +91,437 @@ type DetectionRule struct {
 	Pattern           string
 	RiskLevel         string
 	VulnerabilityType string
+	CWE               []string // CWE identifiers of the weakness, e.g. CWE-327
 	Description       string
 	Recommendation    string
+	Confidence        float64 // Match strength from 0 to 1 (0: default for Method, see ruleConfidence)
 	// NIST IR 8547 fields
-	NISTAlgorithmID   string // Link to NIST algorithm identifier
+	NISTAlgorithmID string // Link to NIST algorithm identifier
+	// RemediationExample holds fix snippets keyed by sourceLanguage, e.g. "go"
+	RemediationExample map[string]string
+	// MultiLine rules match against the whole file, so a call split across lines
+	// is found; the finding is reported on the line where the match starts
+	MultiLine bool
+}
+
+// errFileTimeout reports a file whose scan took longer than Scanner.FileTimeout
+var errFileTimeout = errors.New("file scan timed out")
+
+// ErrScanCanceled is returned, wrapping the context error, when a scan stops
+// early because its context was canceled or its deadline passed. Results
+// returned alongside it are partial.
+var ErrScanCanceled = errors.New("scan canceled")
+
+// ErrBackendUnavailable is returned by Strict scanners when libpcap, the capture
+// interface or the Kubernetes API can't be used, instead of simulated results
+var ErrBackendUnavailable = errors.New("scan backend unavailable")
+
+// logf writes a diagnostic message to the scanner's Logger, or stderr if none is set
+func (s *Scanner) logf(format string, args ...interface{}) {
+	if s.Logger == nil {
+		fmt.Fprintf(os.Stderr, format, args...)
+		return
+	}
+	s.Logger.Printf(format, args...)
+}
+
+// canceledError returns ErrScanCanceled wrapping ctx's error, or nil if ctx is still active
+func canceledError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrScanCanceled, err)
+	}
+	return nil
 }
 
 // Scanner handles the scanning process
 type Scanner struct {
-	Verbose bool
-	Rules   []DetectionRule
+	Verbose      bool
+	Rules        []DetectionRule
+	ScanArchives bool // Descend into jar/war/zip/tar.gz archives during directory scans
+	ScanBinaries bool // Search ELF/PE/Mach-O binaries for crypto OIDs and symbols
+	ScanConfig   bool // Read YAML/JSON/TOML files for crypto-bearing keys, see ScanConfigFile
+	ScanScripts  bool // Scan Dockerfiles and shell scripts with the detection rules, see IsScriptFile
+	Workers      int  // Concurrent file scanners for directory scans (default: number of CPUs)
+
+	// Kubeconfig and KubeContext select the cluster for Kubernetes scans. When both
+	// are empty the in-cluster config is tried first, then the default kubeconfig.
+	Kubeconfig  string
+	KubeContext string
+
+	// Cache, if set, reuses the findings of files whose content was already scanned
+	// during directory scans
+	Cache *ScanCache
+
+	// Roots are the scan targets; finding fingerprints hash the path of a file
+	// relative to the first it is below, see SetFingerprints
+	Roots []string
+
+	// FileTimeout bounds the time spent on any one file during directory scans
+	// (0: no limit). Files that exceed it are skipped and listed by TimedOutFiles.
+	FileTimeout time.Duration
+
+	timedOutMu sync.Mutex
+	timedOut   []string
+
+	warningsMu sync.Mutex
+	warnings   []ScanWarning
+
+	timing phaseTimes // Time spent per phase, see Timing
+
+	// MinConfidence drops rule matches weaker than this (0-1). Findings from other
+	// detectors carry no confidence and are always kept.
+	MinConfidence float64
+
+	// ContextLines is the number of lines kept before and after each source-code
+	// finding in its Snippet (0: the matched line only; negative: no snippets)
+	ContextLines int
+
+	// ShowRemediation attaches each matched rule's example fix for the file's
+	// language to its findings as RemediationExample
+	ShowRemediation bool
+
+	// SuggestFixes attaches a unified diff to findings with a safe, mechanical fix,
+	// such as hashlib.md5 to hashlib.sha256; see SuggestFix
+	SuggestFixes bool
+
+	// KeystorePassword opens the PKCS#12 and Java keystores ScanKeystore reads (empty:
+	// only keystores without a password are opened)
+	KeystorePassword string
+
+	// Strict fails PCAP, live capture and Kubernetes scans with ErrBackendUnavailable
+	// when the real backend can't be used, rather than reporting simulated results
+	Strict bool
+
+	// Logger receives verbose and diagnostic messages so stdout stays reserved
+	// for results (default: stderr)
+	Logger *log.Logger
+
+	// ProgressFunc, if set, is called once per file as directory scans progress.
+	// Calls are made sequentially from a dedicated goroutine, never from scan workers.
+	ProgressFunc func(scanned, total int, current string)
 }
 
 // NewScanner creates a new scanner instance
 func NewScanner(verbose bool) *Scanner {
+	return NewScannerWithRules(verbose, buildDetectionRules())
+}
+
+// NewScannerWithRules creates a scanner matching rules instead of the built-in set
+func NewScannerWithRules(verbose bool, rules []DetectionRule) *Scanner {
 	return &Scanner{
 		Verbose: verbose,
-		Rules: buildDetectionRules(),
+		Rules:   rules,
+		Logger:  log.New(os.Stderr, "", 0),
 	}
 }
 
 // ScanDirectory scans all files in a directory recursively
 func (s *Scanner) ScanDirectory(dir string) []Result {
+	results, _ := s.ScanDirectoryWithMetadata(dir)
+	return results
+}
+
+// ScanFile scans a single file for vulnerable crypto
+func (s *Scanner) ScanFile(filePath string) []Result {
+	return s.scanFileContext(context.Background(), filePath)
+}
+
+// scanFileContext scans a single file, giving up between lines once ctx is done
+func (s *Scanner) scanFileContext(ctx context.Context, filePath string) []Result {
 	var results []Result
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
+	// Skip certain file types
+	if s.shouldSkip(filePath) {
+		return results
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		s.logf("Error reading file %s: %v\n", filePath, err)
+		s.warn("", filePath, fmt.Sprintf("failed to read file: %v", err))
+		return results
+	}
+
+	return s.scanContentContext(ctx, filePath, content)
+}
+
+// scanContent scans in-memory file content, reporting findings against filePath
+func (s *Scanner) scanContent(filePath string, content []byte) []Result {
+	return s.scanContentContext(context.Background(), filePath, content)
+}
+
+// scanContentContext scans in-memory file content, returning what was found so far
+// once ctx is done
+func (s *Scanner) scanContentContext(ctx context.Context, filePath string, content []byte) []Result {
+	var results []Result
+
+	lines := strings.Split(string(content), "\n")
+
+	// Go imports and calls are found from the syntax tree; the regex rules they
+	// replace only run when the file doesn't parse
+	var skip func(DetectionRule) bool
+	if sourceLanguage(filePath) == "go" {
+		if goResults, parsed := s.analyzeGoSource(filePath, content, lines); parsed {
+			results = append(results, goResults...)
+			skip = replacedByGoAnalysis
 		}
+	}
 
-		// Skip certain directories and file types
-		if s.shouldSkip(path) {
-			return nil
+	// Keys of known Terraform resources are read from their arguments; the regex
+	// rules skip those blocks
+	var skipLines map[int]bool
+	if isTerraformFile(filePath) {
+		var terraformResults []Result
+		terraformResults, skipLines = analyzeTerraform(filePath, lines)
+		results = append(results, terraformResults...)
+	}
+
+	for i, line := range lines {
+		if ctx.Err() != nil {
+			return results
+		}
+		if skipLines[i+1] {
+			continue
 		}
+		for _, rule := range s.matchRules(line) {
+			if skip != nil && skip(rule) {
+				continue
+			}
+			if result, ok := s.ruleResult(filePath, rule, line, i+1); ok {
+				results = append(results, result)
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return results
+	}
 
-		if s.Verbose {
-			fmt.Printf("Scanning file: %s\n", path)
+	// Multi-line rules run once over the whole file
+	results = append(results, s.matchMultiLineRules(filePath, string(content), lines, skip)...)
+
+	// Detect embedded private keys and hardcoded key material
+	results = append(results, s.detectEmbeddedSecrets(filePath, lines)...)
+
+	// Detect non-cryptographic randomness used for key material
+	results = append(results, s.detectWeakRandomness(filePath, lines)...)
+
+	// Detect passwords hashed with fast hashes instead of a key-derivation function
+	results = append(results, s.detectWeakPasswordHashing(filePath, lines)...)
+
+	// Detect key-derivation functions and weak work factors
+	results = append(results, s.detectKeyDerivation(filePath, lines)...)
+
+	// Detect JWT verification without an algorithm allowlist and hardcoded HMAC secrets
+	results = append(results, s.detectJWTMisuse(filePath, lines)...)
+
+	// Detect plaintext gRPC transports, disabled certificate checks and classical-only TLS configs
+	results = append(results, s.detectTransportSecurity(filePath, lines)...)
+
+	// Detect weak suites in cipher suite allowlists hardcoded in Go and Java
+	results = append(results, s.detectCipherSuiteAllowlists(filePath, lines)...)
+
+	// Detect environment variables in Dockerfiles and scripts that weaken crypto defaults
+	results = append(results, s.detectEnvironmentVariables(filePath, lines)...)
+
+	// Detect Diffie-Hellman groups generated or selected below 2048 bits
+	results = append(results, s.detectWeakDHParameters(filePath, lines)...)
+
+	s.attachSnippets(results, lines)
+	if s.SuggestFixes {
+		for i := range results {
+			results[i].SuggestedFix, _ = SuggestFix(results[i], content)
 		}
+	}
+	classifyResults(results)
+	SetFingerprints(results, lines, s.Roots)
 
-		fileResults := s.ScanFile(path)
-		results = append(results, fileResults...)
+	return results
+}
 
-		if s.Verbose && len(fileResults) > 0 {
-			fmt.Printf("Found %d vulnerabilities in file: %s\n", len(fileResults), path)
+// ruleResult builds the finding for a rule matched on line, which for multi-line
+// rules holds every line of the match. It reports false when the match is weaker
+// than MinConfidence.
+func (s *Scanner) ruleResult(filePath string, rule DetectionRule, line string, lineNumber int) (Result, bool) {
+	result := Result{
+		File:              filePath,
+		Algorithm:         rule.AlgorithmName,
+		Type:              rule.AlgorithmType,
+		Line:              lineNumber,
+		Method:            rule.Method,
+		Risk:              rule.RiskLevel,
+		VulnerabilityType: rule.VulnerabilityType,
+		CWE:               rule.CWE,
+		Description:       rule.Description,
+		Recommendation:    rule.Recommendation,
+		Column:            ruleColumn(rule, line),
+		Confidence:        ruleConfidence(rule, filePath, line),
+		Purpose:           sourceLinePurpose(rule.AlgorithmType, line),
+	}
+	if result.Confidence < s.MinConfidence {
+		return Result{}, false
+	}
+	if s.ShowRemediation {
+		result.RemediationExample = rule.RemediationExample[sourceLanguage(filePath)]
+	}
+
+	// Populate NIST IR 8547 fields for the curve named on the line, if any
+	populateNISTFields(&result, applyCurve(&result, line, rule.NISTAlgorithmID))
+
+	if s.Verbose {
+		nistCategory := ""
+		if result.NISTCategory != "" {
+			nistCategory = " NIST Category: " + result.NISTCategory
 		}
+		s.logf("Match found: %s (Line %d) Method: %s Risk: %s%s\n",
+			rule.AlgorithmName, lineNumber, rule.Method, result.Risk, nistCategory)
+	}
+	return result, true
+}
 
-		return nil
-	})
+// matchMultiLineRules runs the MultiLine rules over the whole content, with "."
+// matching newlines, and reports each match on the line where it starts. Rules
+// skip reports true for are not run; skip may be nil.
+func (s *Scanner) matchMultiLineRules(filePath, content string, lines []string, skip func(DetectionRule) bool) []Result {
+	var results []Result
 
-	if err != nil {
-		fmt.Printf("Error reading directory: %v\n", err)
+	// Hybrid names never span lines, so masking them keeps line numbers intact
+	classicalContent := hybridNamePattern.ReplaceAllString(content, "")
+	for _, rule := range s.Rules {
+		if !rule.MultiLine || (skip != nil && skip(rule)) {
+			continue
+		}
+		target := classicalContent
+		if rule.AlgorithmType == "Hybrid" {
+			target = content
+		}
+		pattern, err := regexp.Compile("(?s)" + rule.Pattern)
+		if err != nil {
+			continue
+		}
+		for _, match := range pattern.FindAllStringIndex(target, -1) {
+			first := strings.Count(target[:match[0]], "\n")
+			last := first + strings.Count(target[match[0]:match[1]], "\n")
+			if result, ok := s.ruleResult(filePath, rule, strings.Join(lines[first:last+1], "\n"), first+1); ok {
+				results = append(results, result)
+			}
+		}
 	}
 
 	return results
 }
 
-// ScanFile scans a single file for vulnerable crypto
-func (s *Scanner) ScanFile(filePath string) []Result {
-	var results []Result
+// matchRules returns the single-line rules matching a line of content
+func (s *Scanner) matchRules(line string) []DetectionRule {
+	var matched []DetectionRule
 
-	// Skip certain file types
-	if s.shouldSkip(filePath) {
-		return results
+	// Hybrid group names contain their classical component, so classical
+	// rules only see the line with hybrid names masked out
+	classicalLine := line
+	if hybridNamePattern.MatchString(line) {
+		classicalLine = hybridNamePattern.ReplaceAllString(line, "")
 	}
 
-	content, err := os.ReadFile(filePath)
+	for _, rule := range s.Rules {
+		if rule.MultiLine {
+			continue
+		}
+		target := classicalLine
+		if rule.AlgorithmType == "Hybrid" {
+			target = line
+		}
+		if match, _ := regexp.MatchString(rule.Pattern, target); match {
+			matched = append(matched, rule)
+		}
+	}
+
+	return matched
+}
+
+// ruleColumn returns the 1-based column where a single-line rule matches line, or 0
+// if it can't be placed. Hybrid names are blanked rather than removed, as for
+// matchRules, so the columns of classical matches stay those of the line.
+func ruleColumn(rule DetectionRule, line string) int {
+	if rule.MultiLine {
+		return 0
+	}
+	pattern, err := regexp.Compile(rule.Pattern)
 	if err != nil {
-		fmt.Printf("Error reading file %s: %v\n", filePath, err)
-		return results
+		return 0
+	}
+	target := line
+	if rule.AlgorithmType != "Hybrid" {
+		target = hybridNamePattern.ReplaceAllStringFunc(line, func(name string) string {
+			return strings.Repeat(" ", len(name))
+		})
 	}
+	if loc := pattern.FindStringIndex(target); loc != nil {
+		return loc[0] + 1
+	}
+	return 0
+}
 
-	lines := strings.Split(string(content), "\n")
-	for i, line := range lines {
-		for _, rule := range s.Rules {
-			if match, _ := regexp.MatchString(rule.Pattern, line); match {
-				result := Result{
-					File:              filePath,
-					Algorithm:         rule.AlgorithmName,
-					Type:              rule.AlgorithmType,
-					Line:              i + 1,
-					Method:            rule.Method,
-					Risk:              rule.RiskLevel,
-					VulnerabilityType: rule.VulnerabilityType,
-					Description:       rule.Description,
-					Recommendation:    rule.Recommendation,
-				}
+// populateNISTFields fills in NIST IR 8547 fields and applies timeline-based risk escalation
+func populateNISTFields(result *Result, nistAlgorithmID string) {
+	if nistAlgorithmID == "" {
+		return
+	}
 
-				// Populate NIST IR 8547 fields
-				if rule.NISTAlgorithmID != "" {
-					if nistInfo := GetNISTInfo(rule.NISTAlgorithmID); nistInfo != nil {
-						result.NISTCategory = string(nistInfo.Category)
-						result.DeprecationDate = nistInfo.DeprecationDate
-						result.DisallowanceDate = nistInfo.DisallowanceDate
-						result.QuantumResistant = nistInfo.QuantumResistant
-						result.NISTAlgorithmID = nistInfo.AlgorithmID
-						result.SecurityStrength = nistInfo.SecurityStrength
-						result.NISTTable = nistInfo.Table
-						
-						// Update risk level based on timeline
-						currentTime := time.Now()
-						if IsDisallowedByDate(nistInfo, currentTime) {
-							result.Risk = "Critical"
-							result.Description += " (NIST IR 8547: DISALLOWED as of " + currentTime.Format("2006-01-02") + ")"
-						} else if IsDeprecatedByDate(nistInfo, currentTime) {
-							if result.Risk == "Low" || result.Risk == "Medium" {
-								result.Risk = "High"
-							}
-							result.Description += " (NIST IR 8547: DEPRECATED as of " + currentTime.Format("2006-01-02") + ")"
-						}
-					}
-				}
+	nistInfo := GetNISTInfo(nistAlgorithmID)
+	if nistInfo == nil {
+		return
+	}
 
-				results = append(results, result)
+	result.NISTCategory = string(nistInfo.Category)
+	result.DeprecationDate = nistInfo.DeprecationDate
+	result.DisallowanceDate = nistInfo.DisallowanceDate
+	result.QuantumResistant = nistInfo.QuantumResistant
+	result.NISTAlgorithmID = nistInfo.AlgorithmID
+	result.SecurityStrength = nistInfo.SecurityStrength
+	result.NISTTable = nistInfo.Table
 
-				if s.Verbose {
-					fmt.Printf("Match found: %s (Line %d) Method: %s Risk: %s",
-						rule.AlgorithmName, i+1, rule.Method, result.Risk)
-					if result.NISTCategory != "" {
-						fmt.Printf(" NIST Category: %s", result.NISTCategory)
-					}
-					fmt.Println()
-				}
-			}
+	// Update risk level based on timeline
+	currentTime := time.Now()
+	if IsDisallowedByDate(nistInfo, currentTime) {
+		result.Risk = "Critical"
+		result.Description += " (NIST IR 8547: DISALLOWED as of " + currentTime.Format("2006-01-02") + ")"
+	} else if IsDeprecatedByDate(nistInfo, currentTime) {
+		if result.Risk == "Low" || result.Risk == "Medium" {
+			result.Risk = "High"
 		}
+		result.Description += " (NIST IR 8547: DEPRECATED as of " + currentTime.Format("2006-01-02") + ")"
 	}
+}
 
-	return results
+// sourceLanguage names the language of a source file as used to key
+// DetectionRule.RemediationExample, or "" for languages without examples
+func sourceLanguage(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		return "go"
+	case ".java":
+		return "java"
+	case ".py":
+		return "python"
+	case ".js", ".ts":
+		return "javascript"
+	case ".cs":
+		return "csharp"
+	}
+	return ""
 }
 
 // shouldSkip determines if a file should be skipped during scanning
 func (s *Scanner) shouldSkip(path string) bool {
-	// Skip node_modules, .git, etc.
-	if strings.Contains(path, "node_modules") ||
-		strings.Contains(path, ".git") ||
-		strings.Contains(path, "__pycache__") ||
-		strings.Contains(path, "vendor") {
+	if isExcludedPath(path) {
 		return true
 	}
+	if s.ScanScripts && IsScriptFile(path) {
+		return false
+	}
 
 	// Only scan certain file extensions
 	ext := strings.ToLower(filepath.Ext(path))
-	validExts := []string{".go", ".java", ".js", ".ts", ".py", ".php", ".rb", ".c", ".cpp", ".h", ".cs", ".swift"}
+	validExts := []string{".go", ".java", ".js", ".ts", ".py", ".php", ".rb", ".c", ".cpp", ".h", ".cs", ".swift", ".rs", ".kt", ".kts", ".tf", ".hcl"}
 
 	for _, validExt := range validExts {
 		if ext == validExt {
@@ -192,94 +532,390 @@ func (s *Scanner) shouldSkip(path string) bool {
 	return true
 }
 
+// isExcludedPath reports whether a path lies in a directory that is never scanned
+func isExcludedPath(path string) bool {
+	// Skip node_modules, .git, etc.
+	return strings.Contains(path, "node_modules") ||
+		strings.Contains(path, ".git") ||
+		strings.Contains(path, "__pycache__") ||
+		strings.Contains(path, "vendor")
+}
+
 // ScanDirectoryWithMetadata scans all files in a directory and returns asset count
 func (s *Scanner) ScanDirectoryWithMetadata(dir string) ([]Result, int) {
+	results, assetCount, _ := s.ScanDirectoryContext(context.Background(), dir)
+	return results, assetCount
+}
+
+// ScanDirectoryContext scans all files in a directory until ctx is done.
+// Files are scanned by a pool of Workers; results keep the walk order.
+// On cancellation the results gathered so far are returned with ErrScanCanceled.
+func (s *Scanner) ScanDirectoryContext(ctx context.Context, dir string) ([]Result, int, error) {
+	return s.scanPathList(ctx, s.walkFiles(ctx, dir))
+}
+
+// ScanFilesContext scans the listed files as if a directory walk had found them,
+// e.g. the files changed in a pull request. Paths that are missing or are
+// directories are skipped with a warning.
+func (s *Scanner) ScanFilesContext(ctx context.Context, paths []string) ([]Result, int, error) {
+	return s.scanPathList(ctx, s.listedFiles(paths))
+}
+
+// ScanFilesStream scans the listed files like ScanFilesContext, sending findings to
+// out as ScanDirectoryStream does
+func (s *Scanner) ScanFilesStream(ctx context.Context, paths []string, out chan<- Result) (int, error) {
+	return s.streamPathList(ctx, s.listedFiles(paths), out)
+}
+
+// scanPathList scans paths on the worker pool, returning findings in path order
+func (s *Scanner) scanPathList(ctx context.Context, paths []string) ([]Result, int, error) {
+	fileResults := make([][]Result, len(paths))
+	fileAssets := make([]int, len(paths))
+	s.scanPaths(ctx, paths, func(i int, results []Result, assets int) {
+		fileResults[i], fileAssets[i] = results, assets
+	})
+
 	var results []Result
 	assetCount := 0
+	for i := range paths {
+		results = append(results, fileResults[i]...)
+		assetCount += fileAssets[i]
+	}
+
+	return results, assetCount, canceledError(ctx)
+}
+
+// ScanDirectoryStream scans dir like ScanDirectoryContext but sends each finding to
+// out as soon as its file has been scanned, so findings arrive in completion order
+// rather than path order. out is not closed, letting several scans share a channel.
+func (s *Scanner) ScanDirectoryStream(ctx context.Context, dir string, out chan<- Result) (int, error) {
+	return s.streamPathList(ctx, s.walkFiles(ctx, dir), out)
+}
+
+// streamPathList scans paths on the worker pool, sending findings to out as each file is done
+func (s *Scanner) streamPathList(ctx context.Context, paths []string, out chan<- Result) (int, error) {
+	var assetCount int64
+	s.scanPaths(ctx, paths, func(i int, results []Result, assets int) {
+		atomic.AddInt64(&assetCount, int64(assets))
+		for _, result := range results {
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	return int(assetCount), canceledError(ctx)
+}
+
+// walkFiles lists the files under dir, stopping early once ctx is done. Entries
+// that can't be read, such as permission-denied directories, are skipped with a
+// warning so the rest of the tree is still scanned.
+func (s *Scanner) walkFiles(ctx context.Context, dir string) []string {
+	defer s.timing.add(&s.timing.walk, time.Now())
+	var paths []string
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
-			return err
+			if path == dir {
+				return err
+			}
+			s.logf("Warning: skipping %s: %v\n", path, err)
+			s.warn("", path, fmt.Sprintf("failed to read: %v", err))
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 		if info.IsDir() {
 			return nil
 		}
+		paths = append(paths, path)
+		return nil
+	})
 
-		// Skip certain directories and file types
-		if s.shouldSkip(path) {
-			return nil
+	if err != nil && ctx.Err() == nil {
+		s.logf("Error reading directory: %v\n", err)
+		s.warn("", dir, fmt.Sprintf("failed to read directory: %v", err))
+	}
+
+	return paths
+}
+
+// listedFiles returns the regular files among paths, warning about the rest
+func (s *Scanner) listedFiles(paths []string) []string {
+	defer s.timing.add(&s.timing.walk, time.Now())
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			s.logf("Warning: skipping %s: %v\n", path, err)
+			s.warn("", path, err.Error())
+			continue
+		}
+		if info.IsDir() {
+			s.logf("Warning: skipping %s: is a directory\n", path)
+			s.warn("", path, "listed path is a directory")
+			continue
 		}
+		files = append(files, path)
+	}
+	return files
+}
 
-		assetCount++
+// scanPaths scans paths on the worker pool, calling done from the worker with each
+// file's index, findings and asset count. done may be called concurrently.
+func (s *Scanner) scanPaths(ctx context.Context, paths []string, done func(i int, results []Result, assets int)) {
+	defer s.timing.add(&s.timing.match, time.Now())
+	progress := newProgressReporter(s.ProgressFunc, len(paths))
 
-		if s.Verbose {
-			fmt.Printf("Scanning file: %s\n", path)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < s.workerCount(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				results, assets := s.scanWalkedFile(paths[i])
+				done(i, results, assets)
+				progress.fileDone(paths[i])
+			}
+		}()
+	}
+dispatch:
+	for i := range paths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
 		}
+	}
+	close(jobs)
+	wg.Wait()
+	progress.close()
+}
 
-		fileResults := s.ScanFile(path)
-		results = append(results, fileResults...)
+// scanWalkedFile scans one file found during a directory walk, returning its
+// findings and the number of assets it contributed (0 when skipped)
+func (s *Scanner) scanWalkedFile(path string) ([]Result, int) {
+	scanFile := func() ([]Result, int, error) { return s.scanPathTimeout(path) }
+	// What a password opens depends on the password, which the cache isn't keyed on
+	passwordKeystore := s.KeystorePassword != "" && IsKeystoreFile(path)
+	if s.Cache != nil && s.scansPath(path) && !passwordKeystore {
+		return s.Cache.scan(s, path, scanFile)
+	}
+	results, assets, _ := scanFile()
+	return results, assets
+}
+
+// scanPathTimeout scans one walked file, abandoning it with errFileTimeout if it
+// runs past FileTimeout. An abandoned scan stops at its next line.
+func (s *Scanner) scanPathTimeout(path string) ([]Result, int, error) {
+	if s.FileTimeout <= 0 {
+		results, assets := s.scanPath(context.Background(), path)
+		return results, assets, nil
+	}
 
-		if s.Verbose && len(fileResults) > 0 {
-			fmt.Printf("Found %d vulnerabilities in file: %s\n", len(fileResults), path)
+	ctx, cancel := context.WithTimeout(context.Background(), s.FileTimeout)
+	defer cancel()
+
+	type outcome struct {
+		results []Result
+		assets  int
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		results, assets := s.scanPath(ctx, path)
+		done <- outcome{results, assets}
+	}()
+
+	select {
+	case o := <-done:
+		if ctx.Err() == nil {
+			return o.results, o.assets, nil
 		}
+	case <-ctx.Done():
+	}
 
-		return nil
-	})
+	s.timedOutMu.Lock()
+	s.timedOut = append(s.timedOut, path)
+	s.timedOutMu.Unlock()
+	s.logf("Warning: skipped %s: scan exceeded the %s file timeout\n", path, s.FileTimeout)
+	return nil, 1, errFileTimeout
+}
 
-	if err != nil {
-		fmt.Printf("Error reading directory: %v\n", err)
+// TimedOutFiles returns the files skipped so far because they exceeded FileTimeout
+func (s *Scanner) TimedOutFiles() []string {
+	s.timedOutMu.Lock()
+	defer s.timedOutMu.Unlock()
+	return append([]string(nil), s.timedOut...)
+}
+
+// warn records a non-fatal scan problem for Warnings
+func (s *Scanner) warn(namespace, file, reason string) {
+	s.addWarning(ScanWarning{Namespace: namespace, File: file, Reason: reason})
+}
+
+// addWarning records warning for Warnings
+func (s *Scanner) addWarning(warning ScanWarning) {
+	s.warningsMu.Lock()
+	defer s.warningsMu.Unlock()
+	s.warnings = append(s.warnings, warning)
+}
+
+// fallback decides what happens when capability can't be used for target: a Strict
+// scanner gets an ErrBackendUnavailable error, any other records a warning naming
+// the capability and gets nil so the caller reports simulated results
+func (s *Scanner) fallback(capability, target, reason string) error {
+	if s.Strict {
+		return fmt.Errorf("%w: %s: %s", ErrBackendUnavailable, target, reason)
 	}
+	s.addWarning(ScanWarning{File: target, Reason: reason + ", reporting simulated results", Capability: capability})
+	return nil
+}
 
-	return results, assetCount
+// Warnings returns the non-fatal problems recorded so far, in the order they occurred
+func (s *Scanner) Warnings() []ScanWarning {
+	s.warningsMu.Lock()
+	defer s.warningsMu.Unlock()
+	return append([]ScanWarning(nil), s.warnings...)
+}
+
+// scansPath reports whether a walked file is scanned at all, so skipped files are never hashed
+func (s *Scanner) scansPath(path string) bool {
+	if s.ScanArchives && IsArchive(path) {
+		return true
+	}
+	if s.ScanConfig && IsConfigFile(path) && !isExcludedPath(path) {
+		return true
+	}
+	if IsCertificateFile(path) && !isExcludedPath(path) {
+		return true
+	}
+	if IsKeystoreFile(path) && !isExcludedPath(path) {
+		return true
+	}
+	if !s.shouldSkip(path) {
+		return true
+	}
+	return s.ScanBinaries && !isExcludedPath(path) && IsBinaryFile(path)
+}
+
+// scanPath scans one walked file, descending into archives and binaries when enabled.
+// Source files stop being scanned once ctx is done.
+func (s *Scanner) scanPath(ctx context.Context, path string) ([]Result, int) {
+	if s.ScanArchives && IsArchive(path) {
+		return s.ScanArchive(path)
+	}
+	if s.ScanConfig && IsConfigFile(path) && !isExcludedPath(path) {
+		if s.Verbose {
+			s.logf("Scanning config file: %s\n", path)
+		}
+		return s.ScanConfigFile(ctx, path), 1
+	}
+	if IsCertificateFile(path) && !isExcludedPath(path) {
+		if s.Verbose {
+			s.logf("Scanning certificate file: %s\n", path)
+		}
+		return s.ScanCertificateFile(path), 1
+	}
+	if IsKeystoreFile(path) && !isExcludedPath(path) {
+		if s.Verbose {
+			s.logf("Scanning keystore: %s\n", path)
+		}
+		return s.ScanKeystore(path), 1
+	}
+
+	// Skip certain directories and file types
+	if s.shouldSkip(path) {
+		if s.ScanBinaries && !isExcludedPath(path) && IsBinaryFile(path) {
+			return s.ScanBinary(path), 1
+		}
+		return nil, 0
+	}
+
+	if s.Verbose {
+		s.logf("Scanning file: %s\n", path)
+	}
+
+	fileResults := s.scanFileContext(ctx, path)
+
+	if s.Verbose && len(fileResults) > 0 {
+		s.logf("Found %d vulnerabilities in file: %s\n", len(fileResults), path)
+	}
+
+	return fileResults, 1
+}
+
+// workerCount returns the number of concurrent file scanners to run
+func (s *Scanner) workerCount() int {
+	if s.Workers > 0 {
+		return s.Workers
+	}
+	return runtime.NumCPU()
 }
 
 // ScanKubernetes scans Kubernetes cluster resources for crypto vulnerabilities
-func (s *Scanner) ScanKubernetes(namespaces []string, secretScan, configMapScan, imageScan, networkPolicyScan, ingressScan, serviceMeshScan, deepCodeScan, includeKubeSystem bool) ([]Result, int) {
+func (s *Scanner) ScanKubernetes(ctx context.Context, namespaces []string, opts KubernetesScanOptions) ([]Result, int, error) {
 	if s.Verbose {
-		fmt.Printf("Starting Kubernetes cluster scan across %d namespaces...\n", len(namespaces))
+		s.logf("Starting Kubernetes cluster scan across %d namespaces...\n", len(namespaces))
 	}
 
 	// Create Kubernetes scanner with real client integration
 	k8sScanner, err := NewK8sScanner(s)
 	if err != nil {
+		if err := s.fallback(CapabilityKubernetes, "", fmt.Sprintf("failed to create Kubernetes client: %v", err)); err != nil {
+			return nil, 0, err
+		}
 		if s.Verbose {
-			fmt.Printf("Error creating Kubernetes client: %v\n", err)
-			fmt.Printf("Falling back to simulated scan results...\n")
+			s.logf("Error creating Kubernetes client: %v\n", err)
+			s.logf("Falling back to simulated scan results...\n")
 		}
 		// Fallback to simulated results if Kubernetes client fails
-		return s.scanKubernetesFallback(namespaces, secretScan, configMapScan, imageScan, networkPolicyScan, ingressScan, serviceMeshScan, deepCodeScan, includeKubeSystem)
+		results, assetCount := s.scanKubernetesFallback(namespaces, opts)
+		classifyResults(results)
+		return results, assetCount, nil
 	}
 
 	// Use real Kubernetes client integration
-	return k8sScanner.ScanKubernetesCluster(namespaces, secretScan, configMapScan, imageScan, networkPolicyScan, ingressScan, serviceMeshScan, deepCodeScan, includeKubeSystem)
+	results, assetCount := k8sScanner.ScanKubernetesCluster(ctx, namespaces, opts)
+	classifyResults(results)
+	return results, assetCount, canceledError(ctx)
 }
 
 // scanKubernetesFallback provides fallback scanning when Kubernetes client is unavailable
-func (s *Scanner) scanKubernetesFallback(namespaces []string, secretScan, configMapScan, imageScan, networkPolicyScan, ingressScan, serviceMeshScan, deepCodeScan, includeKubeSystem bool) ([]Result, int) {
+func (s *Scanner) scanKubernetesFallback(namespaces []string, opts KubernetesScanOptions) ([]Result, int) {
 	var results []Result
 	assetCount := 0
 
 	// Simulate scanning different Kubernetes resources
-	if secretScan {
+	if opts.SecretScan {
 		secretResults, secretCount := s.scanKubernetesSecretsSimulated(namespaces)
 		results = append(results, secretResults...)
 		assetCount += secretCount
 	}
-	
-	if configMapScan {
+
+	if opts.ConfigMapScan {
 		configMapResults, configMapCount := s.scanKubernetesConfigMapsSimulated(namespaces)
 		results = append(results, configMapResults...)
 		assetCount += configMapCount
 	}
-	
-	if imageScan {
+
+	if opts.ImageScan {
 		imageResults, imageCount := s.scanKubernetesImagesSimulated(namespaces)
 		results = append(results, imageResults...)
 		assetCount += imageCount
 	}
 
 	if s.Verbose {
-		fmt.Printf("Kubernetes fallback scan completed. Analyzed %d simulated assets across %d namespaces.\n", assetCount, len(namespaces))
+		s.logf("Kubernetes fallback scan completed. Analyzed %d simulated assets across %d namespaces.\n", assetCount, len(namespaces))
 	}
 
 	return results, assetCount
@@ -289,15 +925,15 @@ func (s *Scanner) scanKubernetesFallback(namespaces []string, secretScan, config
 func (s *Scanner) scanKubernetesSecretsSimulated(namespaces []string) ([]Result, int) {
 	var results []Result
 	assetCount := 0
-	
+
 	// TODO: Implement actual Kubernetes secret scanning
 	// This is a placeholder that simulates finding crypto in secrets
-	
+
 	for _, namespace := range namespaces {
 		if s.Verbose {
-			fmt.Printf("Scanning secrets in namespace: %s\n", namespace)
+			s.logf("Scanning secrets in namespace: %s\n", namespace)
 		}
-		
+
 		// Simulate finding TLS secrets with RSA certificates
 		results = append(results, Result{
 			File:              fmt.Sprintf("secret/tls-cert (%s)", namespace),
@@ -309,10 +945,11 @@ func (s *Scanner) scanKubernetesSecretsSimulated(namespaces []string) ([]Result,
 			VulnerabilityType: "Shor's Algorithm",
 			Description:       "TLS certificate uses RSA algorithm vulnerable to quantum attacks",
 			Recommendation:    "Replace with post-quantum certificate when available from CA",
+			Simulated:         true,
 		})
 		assetCount++
 	}
-	
+
 	return results, assetCount
 }
 
@@ -320,14 +957,14 @@ func (s *Scanner) scanKubernetesSecretsSimulated(namespaces []string) ([]Result,
 func (s *Scanner) scanKubernetesConfigMapsSimulated(namespaces []string) ([]Result, int) {
 	var results []Result
 	assetCount := 0
-	
+
 	// TODO: Implement actual Kubernetes ConfigMap scanning
-	
+
 	for _, namespace := range namespaces {
 		if s.Verbose {
-			fmt.Printf("Scanning ConfigMaps in namespace: %s\n", namespace)
+			s.logf("Scanning ConfigMaps in namespace: %s\n", namespace)
 		}
-		
+
 		// Simulate finding crypto configurations in ConfigMaps
 		results = append(results, Result{
 			File:              fmt.Sprintf("configmap/app-config (%s)", namespace),
@@ -339,10 +976,11 @@ func (s *Scanner) scanKubernetesConfigMapsSimulated(namespaces []string) ([]Resu
 			VulnerabilityType: "Grover's Algorithm",
 			Description:       "Application configured to use AES-128 which provides reduced quantum security",
 			Recommendation:    "Update application configuration to use AES-256",
+			Simulated:         true,
 		})
 		assetCount++
 	}
-	
+
 	return results, assetCount
 }
 
@@ -350,14 +988,14 @@ func (s *Scanner) scanKubernetesConfigMapsSimulated(namespaces []string) ([]Resu
 func (s *Scanner) scanKubernetesImagesSimulated(namespaces []string) ([]Result, int) {
 	var results []Result
 	assetCount := 0
-	
+
 	// TODO: Implement actual container image scanning
-	
+
 	for _, namespace := range namespaces {
 		if s.Verbose {
-			fmt.Printf("Scanning container images in namespace: %s\n", namespace)
+			s.logf("Scanning container images in namespace: %s\n", namespace)
 		}
-		
+
 		// Simulate finding crypto libraries in container images
 		results = append(results, Result{
 			File:              fmt.Sprintf("image/app:latest (%s)", namespace),
@@ -369,40 +1007,60 @@ func (s *Scanner) scanKubernetesImagesSimulated(namespaces []string) ([]Result,
 			VulnerabilityType: "Shor's Algorithm",
 			Description:       "Container image includes ECC cryptography library vulnerable to quantum attacks",
 			Recommendation:    "Rebuild image with post-quantum cryptography libraries",
+			Simulated:         true,
 		})
 		assetCount++
 	}
-	
+
 	return results, assetCount
 }
 
-// ScanPCAP analyzes PCAP files for crypto vulnerabilities in network traffic
-func (s *Scanner) ScanPCAP(pcapFile string, liveCapture bool, captureInterface, captureDuration string, tlsFilter bool) ([]Result, int) {
+// ScanPCAP analyzes PCAP files for crypto vulnerabilities in network traffic. A
+// pcapFile of PCAPStdin reads the capture from stdin.
+func (s *Scanner) ScanPCAP(ctx context.Context, pcapFile string, liveCapture bool, captureInterface, captureDuration string, tlsFilter bool) ([]Result, int, error) {
 	if s.Verbose {
 		if liveCapture {
-			fmt.Printf("Starting live network capture on %s for %s...\n", captureInterface, captureDuration)
+			s.logf("Starting live network capture on %s for %s...\n", captureInterface, captureDuration)
 		} else {
-			fmt.Printf("Analyzing PCAP file: %s\n", pcapFile)
+			s.logf("Analyzing PCAP file: %s\n", pcapFile)
 		}
 	}
 
 	// Create PCAP scanner with real gopacket integration
 	pcapScanner := NewPCAPScanner(s)
-	
-	if liveCapture {
-		return pcapScanner.PerformLiveCapture(captureInterface, captureDuration, tlsFilter)
-	} else {
-		return pcapScanner.AnalyzePCAPFile(pcapFile, tlsFilter)
+
+	var results []Result
+	var assetCount int
+	var err error
+	switch {
+	case liveCapture:
+		results, assetCount, err = pcapScanner.PerformLiveCapture(ctx, captureInterface, captureDuration, tlsFilter)
+	case pcapFile == PCAPStdin:
+		results, assetCount, err = pcapScanner.analyzePCAPStdin(ctx)
+	default:
+		results, assetCount, err = pcapScanner.AnalyzePCAPFile(ctx, pcapFile, tlsFilter)
+	}
+	if err != nil {
+		return nil, 0, err
 	}
+	classifyResults(results)
+
+	return results, assetCount, canceledError(ctx)
 }
 
 // ScanNetwork performs live network monitoring for crypto vulnerabilities
-func (s *Scanner) ScanNetwork(captureInterface, captureDuration string, tlsFilter bool) ([]Result, int) {
+func (s *Scanner) ScanNetwork(ctx context.Context, captureInterface, captureDuration string, tlsFilter bool) ([]Result, int, error) {
 	if s.Verbose {
-		fmt.Printf("Starting network monitoring on %s for %s...\n", captureInterface, captureDuration)
+		s.logf("Starting network monitoring on %s for %s...\n", captureInterface, captureDuration)
 	}
 
 	// Create PCAP scanner for live network monitoring
 	pcapScanner := NewPCAPScanner(s)
-	return pcapScanner.PerformLiveCapture(captureInterface, captureDuration, tlsFilter)
+	results, assetCount, err := pcapScanner.PerformLiveCapture(ctx, captureInterface, captureDuration, tlsFilter)
+	if err != nil {
+		return nil, 0, err
+	}
+	classifyResults(results)
+
+	return results, assetCount, canceledError(ctx)
 }
@@ -0,0 +1,22 @@
+package crypto
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// scriptExtensions are the shell script extensions read when ScanScripts is set
+var scriptExtensions = map[string]bool{".sh": true, ".bash": true}
+
+// IsScriptFile reports whether path is a Dockerfile or Containerfile (including
+// variants such as Dockerfile.prod and app.dockerfile) or a shell script
+func IsScriptFile(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	ext := filepath.Ext(base)
+	for _, name := range []string{"dockerfile", "containerfile"} {
+		if base == name || strings.HasPrefix(base, name+".") || ext == "."+name {
+			return true
+		}
+	}
+	return scriptExtensions[ext]
+}
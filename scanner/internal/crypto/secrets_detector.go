@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+var (
+	// pemPrivateKeyPattern matches PEM private key headers, capturing the key type
+	pemPrivateKeyPattern = regexp.MustCompile(`-----BEGIN ((?:RSA|EC|DSA|OPENSSH|ENCRYPTED) )?PRIVATE KEY-----`)
+
+	// secretAssignmentPattern matches string literals assigned to key-like identifiers.
+	// Requiring assignment context keeps the entropy heuristic from firing on arbitrary strings.
+	secretAssignmentPattern = regexp.MustCompile(identifierWordPattern("key", "secret", "token", "iv", "nonce", "passw(?:or)?d") + `["']?\s*(?::=|=|:)\s*["']([A-Za-z0-9+/=_\-]+)["']`)
+)
+
+// identifierWordPattern returns a regexp matching an identifier with one of words,
+// in any case and optionally plural, as a whole part of its name: the words are
+// bounded by \b, "_" or a camelCase hump, so "api_key" and "apiKeys" match "key" but
+// "monkey" and "keyboard" don't. words must start with a letter and have no
+// capturing groups.
+func identifierWordPattern(words ...string) string {
+	capitalized := make([]string, len(words))
+	for i, word := range words {
+		capitalized[i] = strings.ToUpper(word[:1]) + "(?i:" + word[1:] + ")"
+	}
+	suffix := `(?i:s?)(?:[_A-Z0-9]\w*)?\b`
+	return `(?:\b(?:\w*_)?(?i:` + strings.Join(words, "|") + `)` + suffix +
+		`|\b[a-z]\w*(?:` + strings.Join(capitalized, "|") + `)` + suffix + `)`
+}
+
+const (
+	// minSecretLength is the shortest literal considered a key (16 bytes base64-encoded is 24 chars)
+	minSecretLength = 24
+	// minSecretEntropy is the Shannon entropy (bits per character) above which a literal looks random
+	minSecretEntropy = 4.0
+)
+
+// pemKeyAlgorithms maps PEM header key types to reported algorithm names
+var pemKeyAlgorithms = map[string]string{
+	"RSA ":       "RSA Private Key",
+	"EC ":        "EC Private Key",
+	"DSA ":       "DSA Private Key",
+	"OPENSSH ":   "OpenSSH Private Key",
+	"ENCRYPTED ": "Encrypted Private Key",
+	"":           "Private Key",
+}
+
+// detectEmbeddedSecrets flags private keys and hardcoded high-entropy keys in file content
+func (s *Scanner) detectEmbeddedSecrets(filePath string, lines []string) []Result {
+	var results []Result
+
+	for i, line := range lines {
+		if match := pemPrivateKeyPattern.FindStringSubmatch(line); match != nil {
+			results = append(results, Result{
+				File:              filePath,
+				Algorithm:         pemKeyAlgorithms[match[1]],
+				Type:              "EmbeddedKey",
+				Line:              i + 1,
+				Method:            "Secret Detection",
+				Risk:              "Critical",
+				VulnerabilityType: "Hardcoded Secret",
+				Description:       "PEM-encoded private key embedded in source; anyone with repository access can impersonate or decrypt",
+				Recommendation:    "Remove the key from source control, rotate it, and load keys from a secrets manager or HSM at runtime",
+			})
+			continue
+		}
+
+		for _, match := range secretAssignmentPattern.FindAllStringSubmatch(line, -1) {
+			literal := match[1]
+			if len(literal) < minSecretLength || shannonEntropy(literal) < minSecretEntropy {
+				continue
+			}
+			results = append(results, Result{
+				File:              filePath,
+				Algorithm:         "Hardcoded Key",
+				Type:              "EmbeddedKey",
+				Line:              i + 1,
+				Method:            "Secret Detection",
+				Risk:              "High",
+				VulnerabilityType: "Hardcoded Secret",
+				Description:       "High-entropy string literal assigned to a key-like variable appears to be a hardcoded cryptographic key",
+				Recommendation:    "Load keys from a secrets manager or environment at runtime and rotate the exposed key",
+			})
+		}
+	}
+
+	return results
+}
+
+// shannonEntropy returns the Shannon entropy of a string in bits per character
+func shannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range value {
+		counts[r]++
+	}
+
+	entropy := 0.0
+	length := float64(len(value))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
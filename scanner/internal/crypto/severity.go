@@ -0,0 +1,90 @@
+package crypto
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// defaultEscalation is the severity SeverityMap.Escalate raises findings to when
+// EscalateTo is empty
+const defaultEscalation = "Critical"
+
+// SeverityMap remaps the risk of findings onto an organization's own severity
+// scale. It is applied to finished findings, after NIST IR 8547 escalation, so
+// Mappings match the risk the scanner would otherwise report.
+type SeverityMap struct {
+	// Mappings are tried in order; the first one matching a finding sets its risk
+	Mappings []SeverityMapping `yaml:"mappings"`
+	// Escalate lists algorithms whose findings are always raised to EscalateTo,
+	// whatever their risk or mapping
+	Escalate   []string `yaml:"escalate"`
+	EscalateTo string   `yaml:"escalate_to"` // Default: Critical
+}
+
+// SeverityMapping sets the severity of findings for an algorithm at a default risk
+type SeverityMapping struct {
+	Algorithm string `yaml:"algorithm"` // Compared by canonical name; empty matches any algorithm
+	Risk      string `yaml:"risk"`      // Risk reported by the scanner, case-insensitive; empty matches any risk
+	Severity  string `yaml:"severity"`  // Risk reported instead
+}
+
+// LoadSeverityMap reads a severity map from a YAML or JSON file. Unknown keys and
+// mappings without a severity or without anything to match are errors.
+func LoadSeverityMap(path string) (*SeverityMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read severity map: %w", err)
+	}
+
+	var m SeverityMap
+	if err := yaml.UnmarshalStrict(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse severity map %s: %w", path, err)
+	}
+	for i, mapping := range m.Mappings {
+		if mapping.Severity == "" {
+			return nil, fmt.Errorf("severity map %s: mapping %d has no severity", path, i+1)
+		}
+		if mapping.Algorithm == "" && mapping.Risk == "" {
+			return nil, fmt.Errorf("severity map %s: mapping %d needs an algorithm or a risk", path, i+1)
+		}
+	}
+	return &m, nil
+}
+
+// Apply sets the risk of result from the map and reports whether it changed.
+// The risk the scanner assigned is kept in DefaultRisk.
+func (m *SeverityMap) Apply(result *Result) bool {
+	risk := result.Risk
+	algorithm := CanonicalAlgorithm(result.Algorithm)
+	for _, mapping := range m.Mappings {
+		if mapping.Algorithm != "" && !strings.EqualFold(CanonicalAlgorithm(mapping.Algorithm), algorithm) {
+			continue
+		}
+		if mapping.Risk != "" && !strings.EqualFold(mapping.Risk, result.Risk) {
+			continue
+		}
+		risk = mapping.Severity
+		break
+	}
+	for _, escalated := range m.Escalate {
+		if strings.EqualFold(CanonicalAlgorithm(escalated), algorithm) {
+			risk = m.EscalateTo
+			if risk == "" {
+				risk = defaultEscalation
+			}
+			break
+		}
+	}
+
+	if risk == result.Risk {
+		return false
+	}
+	if result.DefaultRisk == "" {
+		result.DefaultRisk = result.Risk
+	}
+	result.Risk = risk
+	return true
+}
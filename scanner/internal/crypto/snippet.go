@@ -0,0 +1,58 @@
+package crypto
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxSnippetContextLines = 10  // Cap on Scanner.ContextLines
+	maxSnippetLineLength   = 200 // Longer snippet lines, e.g. minified code, are cut to this many bytes
+)
+
+// Snippet is the source around a finding: the matched line and up to
+// Scanner.ContextLines lines on each side
+type Snippet struct {
+	StartLine int    `json:"start_line"` // Line number of the first line of Text
+	Text      string `json:"text"`
+}
+
+// snippet returns the lines around lines[index] as configured by ContextLines, or
+// nil when snippets are disabled
+func (s *Scanner) snippet(lines []string, index int) *Snippet {
+	if s.ContextLines < 0 || index < 0 || index >= len(lines) {
+		return nil
+	}
+	context := min(s.ContextLines, maxSnippetContextLines)
+	start := max(index-context, 0)
+	end := min(index+context+1, len(lines))
+
+	text := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		text = append(text, truncateSnippetLine(strings.TrimRight(line, "\r")))
+	}
+	return &Snippet{StartLine: start + 1, Text: strings.Join(text, "\n")}
+}
+
+// truncateSnippetLine cuts line to maxSnippetLineLength bytes on a rune boundary
+func truncateSnippetLine(line string) string {
+	if len(line) <= maxSnippetLineLength {
+		return line
+	}
+	cut := maxSnippetLineLength
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "..."
+}
+
+// attachSnippets sets the snippet of each finding on a line of lines. Hardcoded
+// secrets get none, so key material is never copied into the output.
+func (s *Scanner) attachSnippets(results []Result, lines []string) {
+	for i := range results {
+		if results[i].Type == TypeEmbeddedKey || results[i].Line <= 0 {
+			continue
+		}
+		results[i].Snippet = s.snippet(lines, results[i].Line-1)
+	}
+}
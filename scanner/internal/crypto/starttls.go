@@ -0,0 +1,85 @@
+package crypto
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// tlsPorts carry TLS from the first byte (HTTPS, SMTPS, IMAPS, POP3S)
+var tlsPorts = map[layers.TCPPort]bool{443: true, 465: true, 993: true, 995: true}
+
+// tlsCaptureFilter selects implicit-TLS ports, QUIC, DTLS handshake records on any
+// UDP port, and the plaintext mail and messaging ports that upgrade in-band with
+// STARTTLS (SMTP, submission, IMAP, POP3, XMPP)
+const tlsCaptureFilter = "tcp port 443 or tcp port 465 or tcp port 993 or tcp port 995 or udp port 443 or (udp and udp[8] = 22) or " +
+	"tcp port 25 or tcp port 587 or tcp port 143 or tcp port 110 or tcp port 5222 or tcp port 5269"
+
+// starttlsTracker follows plaintext sessions and records those a client upgraded
+// to TLS, so the handshake that follows is analyzed whatever the port.
+// A refused upgrade is never followed by a handshake, so the server reply is not tracked.
+type starttlsTracker struct {
+	upgraded map[string]string // connection key -> protocol that issued STARTTLS
+}
+
+func newSTARTTLSTracker() *starttlsTracker {
+	return &starttlsTracker{upgraded: make(map[string]string)}
+}
+
+// observe records a STARTTLS command carried in payload on connection key
+func (t *starttlsTracker) observe(key string, payload []byte) {
+	if protocol := starttlsCommand(payload); protocol != "" {
+		t.upgraded[key] = protocol
+	}
+}
+
+// protocol returns the protocol that upgraded connection key, if any
+func (t *starttlsTracker) protocol(key string) (string, bool) {
+	protocol, ok := t.upgraded[key]
+	return protocol, ok
+}
+
+// starttlsCommand returns the protocol whose upgrade command payload carries, or ""
+func starttlsCommand(payload []byte) string {
+	if len(payload) == 0 || payload[0] == 0x16 || len(payload) > 512 {
+		return ""
+	}
+	trimmed := bytes.TrimSpace(payload)
+	if bytes.HasPrefix(trimmed, []byte("<starttls")) {
+		return "XMPP"
+	}
+
+	line := trimmed
+	if i := bytes.IndexAny(line, "\r\n"); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(strings.ToUpper(string(line)))
+	switch {
+	case len(fields) == 1 && fields[0] == "STARTTLS":
+		return "SMTP"
+	case len(fields) == 1 && fields[0] == "STLS":
+		return "POP3"
+	case len(fields) == 2 && fields[1] == "STARTTLS":
+		// IMAP commands are prefixed with a client-chosen tag
+		return "IMAP"
+	}
+	return ""
+}
+
+// connectionKey identifies a TCP connection independent of packet direction
+func connectionKey(packet gopacket.Packet, tcp *layers.TCP) string {
+	network := packet.NetworkLayer()
+	if network == nil {
+		return ""
+	}
+	flow := network.NetworkFlow()
+	a := fmt.Sprintf("%s:%d", flow.Src(), tcp.SrcPort)
+	b := fmt.Sprintf("%s:%d", flow.Dst(), tcp.DstPort)
+	if a > b {
+		a, b = b, a
+	}
+	return a + "-" + b
+}
@@ -0,0 +1,108 @@
+package crypto
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Suppressions lists findings left out of scan results, such as accepted risks and
+// false positives triaged with -review. Findings are matched by Fingerprint, so a
+// suppression survives edits elsewhere in the file.
+type Suppressions struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+
+	// Roots are the scan roots File is recorded relative to, as findings are
+	// fingerprinted, so the file doesn't depend on where the scan ran
+	Roots []string `yaml:"-"`
+
+	index map[string]int // Position of each fingerprint in Suppressions
+}
+
+// Suppression suppresses one finding. Only Fingerprint is matched; the other fields
+// describe the finding to readers of the file.
+type Suppression struct {
+	Fingerprint string `yaml:"fingerprint"`
+	Algorithm   string `yaml:"algorithm,omitempty"`
+	File        string `yaml:"file,omitempty"`
+	Line        int    `yaml:"line,omitempty"`
+	Reason      string `yaml:"reason,omitempty"`
+}
+
+// LoadSuppressions reads a suppression file in YAML or JSON. Unknown keys, entries
+// without a fingerprint and fingerprints listed twice are errors.
+func LoadSuppressions(path string) (*Suppressions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suppressions: %w", err)
+	}
+
+	var s Suppressions
+	if err := yaml.UnmarshalStrict(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse suppressions %s: %w", path, err)
+	}
+	s.index = make(map[string]int, len(s.Suppressions))
+	for i, suppression := range s.Suppressions {
+		if suppression.Fingerprint == "" {
+			return nil, fmt.Errorf("suppressions %s: entry %d has no fingerprint", path, i+1)
+		}
+		if j, ok := s.index[suppression.Fingerprint]; ok {
+			return nil, fmt.Errorf("suppressions %s: entries %d and %d have the same fingerprint %s", path, j+1, i+1, suppression.Fingerprint)
+		}
+		s.index[suppression.Fingerprint] = i
+	}
+	return &s, nil
+}
+
+// Suppresses reports whether result is suppressed
+func (s *Suppressions) Suppresses(result Result) bool {
+	_, ok := s.index[result.Fingerprint]
+	return ok && result.Fingerprint != ""
+}
+
+// Suppress adds result, which must have a Fingerprint, unless it is already
+// suppressed. Its File is recorded relative to Roots.
+func (s *Suppressions) Suppress(result Result, reason string) {
+	if result.Fingerprint == "" || s.Suppresses(result) {
+		return
+	}
+	if s.index == nil {
+		s.index = make(map[string]int)
+	}
+	s.index[result.Fingerprint] = len(s.Suppressions)
+	s.Suppressions = append(s.Suppressions, Suppression{
+		Fingerprint: result.Fingerprint,
+		Algorithm:   result.Algorithm,
+		File:        relativeToRoots(result.File, s.Roots),
+		Line:        result.Line,
+		Reason:      reason,
+	})
+}
+
+// Unsuppress removes the suppression of result, if any
+func (s *Suppressions) Unsuppress(result Result) {
+	i, ok := s.index[result.Fingerprint]
+	if !ok {
+		return
+	}
+	s.Suppressions = append(s.Suppressions[:i], s.Suppressions[i+1:]...)
+	delete(s.index, result.Fingerprint)
+	for fingerprint, j := range s.index {
+		if j > i {
+			s.index[fingerprint] = j - 1
+		}
+	}
+}
+
+// Save writes the suppressions to path as YAML
+func (s *Suppressions) Save(path string) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode suppressions: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write suppressions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package crypto
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// terraformMethod is the Method of findings from Terraform resource blocks
+const terraformMethod = "Terraform Resource Analysis"
+
+// terraformResource is a resource block of a Terraform file
+type terraformResource struct {
+	Type       string
+	Name       string
+	Start      int                    // Line of the block header
+	End        int                    // Line of the closing brace
+	Attributes map[string]configValue // Top-level arguments, with string values unquoted
+}
+
+var (
+	terraformResourcePattern  = regexp.MustCompile(`^\s*resource\s+"([\w-]+)"\s+"([\w-]+)"\s*\{\s*$`)
+	terraformAttributePattern = regexp.MustCompile(`^\s*([A-Za-z_][\w-]*)\s*=\s*(.*)$`)
+	terraformHeredocPattern   = regexp.MustCompile(`^<<-?(\w+)$`)
+)
+
+// terraformAnalyzers report the keys declared by the resource types they know
+var terraformAnalyzers = map[string]func(file string, resource terraformResource) []Result{
+	"tls_private_key": analyzeTLSPrivateKey,
+	"aws_kms_key":     analyzeKMSKey,
+}
+
+// isTerraformFile reports whether path is a Terraform or other HCL file
+func isTerraformFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".tf" || ext == ".hcl"
+}
+
+// analyzeTerraform reports the keys declared by the resources in terraformAnalyzers
+// from their arguments, with provider defaults for those left out, and returns the
+// lines of the resources it reported so the regex rules can skip them
+func analyzeTerraform(filePath string, lines []string) ([]Result, map[int]bool) {
+	var results []Result
+	covered := make(map[int]bool)
+	for _, resource := range parseTerraformResources(lines) {
+		analyze, ok := terraformAnalyzers[resource.Type]
+		if !ok {
+			continue
+		}
+		found := analyze(filePath, resource)
+		if len(found) == 0 {
+			// Left to the regex rules, e.g. a key size set from a variable
+			continue
+		}
+		results = append(results, found...)
+		for line := resource.Start; line <= resource.End; line++ {
+			covered[line] = true
+		}
+	}
+	return results, covered
+}
+
+// parseTerraformResources lists the resource blocks of a Terraform file with their
+// top-level arguments. Nested blocks are skipped by brace depth and heredocs, such
+// as inline policies, by their end marker. A block left open at the end of the file
+// is not listed.
+func parseTerraformResources(lines []string) []terraformResource {
+	var resources []terraformResource
+	for start := 0; start < len(lines); start++ {
+		header := terraformResourcePattern.FindStringSubmatch(strings.TrimSuffix(lines[start], "\r"))
+		if header == nil {
+			continue
+		}
+		resource := terraformResource{Type: header[1], Name: header[2], Start: start + 1, Attributes: make(map[string]configValue)}
+
+		depth := 1
+		i := start + 1
+		for ; i < len(lines) && depth > 0; i++ {
+			line := stripTerraformComment(strings.TrimSuffix(lines[i], "\r"))
+			if depth == 1 {
+				if attribute := terraformAttributePattern.FindStringSubmatch(line); attribute != nil {
+					value := strings.TrimSpace(attribute[2])
+					if heredoc := terraformHeredocPattern.FindStringSubmatch(value); heredoc != nil {
+						for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != heredoc[1] {
+							i++
+						}
+						i++
+						continue
+					}
+					resource.Attributes[attribute[1]] = configValue{Text: strings.Trim(value, `"`), Line: i + 1}
+				}
+			}
+			depth += terraformBraceDelta(line)
+		}
+		if depth > 0 {
+			// Unterminated, so the headers after this one are still looked for
+			continue
+		}
+		resource.End = i
+		resources = append(resources, resource)
+		start = i - 1
+	}
+	return resources
+}
+
+// stripTerraformComment removes a trailing # or // comment outside strings
+func stripTerraformComment(line string) string {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case inString && line[i] == '\\':
+			i++
+		case line[i] == '"':
+			inString = !inString
+		case !inString && (line[i] == '#' || strings.HasPrefix(line[i:], "//")):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// terraformBraceDelta returns the change in block depth across a line, ignoring
+// braces in strings
+func terraformBraceDelta(line string) int {
+	delta := 0
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case inString && line[i] == '\\':
+			i++
+		case line[i] == '"':
+			inString = !inString
+		case !inString && line[i] == '{':
+			delta++
+		case !inString && line[i] == '}':
+			delta--
+		}
+	}
+	return delta
+}
+
+// analyzeTLSPrivateKey reports the key of a hashicorp/tls tls_private_key, whose
+// rsa_bits defaults to 2048 and ecdsa_curve to P224
+func analyzeTLSPrivateKey(file string, resource terraformResource) []Result {
+	algorithm, ok := resource.Attributes["algorithm"]
+	if !ok {
+		return nil
+	}
+
+	switch strings.ToUpper(algorithm.Text) {
+	case "RSA":
+		bits, value := 2048, algorithm
+		if rsaBits, ok := resource.Attributes["rsa_bits"]; ok {
+			n, err := strconv.Atoi(rsaBits.Text)
+			if err != nil {
+				// Set from a variable or expression
+				return nil
+			}
+			bits, value = n, rsaBits
+		}
+		info, _ := configKeySizeAlgorithm("rsa", bits)
+		result := terraformResult(file, resource, value, info, info.Description)
+		result.KeySize = bits
+		populateNISTFields(&result, info.NISTAlgorithmID)
+		return []Result{result}
+
+	case "ECDSA":
+		curve, value := "P224", algorithm
+		if ecdsaCurve, ok := resource.Attributes["ecdsa_curve"]; ok {
+			curve, value = strings.ToUpper(ecdsaCurve.Text), ecdsaCurve
+		}
+		return []Result{terraformCurveResult(file, resource, value, configAlgorithmValues["ecdsa"], "P-"+strings.TrimPrefix(curve, "P"))}
+
+	case "ED25519":
+		return []Result{terraformCurveResult(file, resource, algorithm, configAlgorithmValues["ed25519"], "Ed25519")}
+	}
+	return nil
+}
+
+// analyzeKMSKey reports the key spec of an aws_kms_key. The default
+// SYMMETRIC_DEFAULT (AES-256-GCM) and HMAC specs are quantum-safe and not reported.
+func analyzeKMSKey(file string, resource terraformResource) []Result {
+	spec, ok := resource.Attributes["customer_master_key_spec"]
+	if !ok {
+		if spec, ok = resource.Attributes["key_spec"]; !ok {
+			return nil
+		}
+	}
+	usage := "ENCRYPT_DECRYPT"
+	if keyUsage, ok := resource.Attributes["key_usage"]; ok {
+		usage = strings.ToUpper(keyUsage.Text)
+	}
+
+	name := strings.ToUpper(spec.Text)
+	switch {
+	case strings.HasPrefix(name, "RSA_"):
+		bits, err := strconv.Atoi(strings.TrimPrefix(name, "RSA_"))
+		if err != nil {
+			return nil
+		}
+		info, _ := configKeySizeAlgorithm("rsa", bits)
+		if usage == "SIGN_VERIFY" {
+			info.Purpose = PurposeSigning
+		} else {
+			info.Purpose = PurposeKeyEncapsulation
+			info.Recommendation = configAlgorithmValues["rsa-oaep"].Recommendation
+		}
+		result := terraformResult(file, resource, spec, info, info.Description)
+		result.KeySize = bits
+		populateNISTFields(&result, info.NISTAlgorithmID)
+		return []Result{result}
+
+	case strings.HasPrefix(name, "ECC_NIST_P"):
+		info := configAlgorithmValues["ecdsa"]
+		if usage == "KEY_AGREEMENT" {
+			info = configAlgorithmValues["ecdh"]
+		}
+		return []Result{terraformCurveResult(file, resource, spec, info, "P-"+strings.TrimPrefix(name, "ECC_NIST_P"))}
+
+	case name == "ECC_SECG_P256K1":
+		return []Result{terraformCurveResult(file, resource, spec, configAlgorithmValues["ecdsa"], "secp256k1")}
+	}
+	return nil
+}
+
+// terraformCurveResult builds the finding for an elliptic-curve key on curve, named
+// as applyCurve recognizes it
+func terraformCurveResult(file string, resource terraformResource, value configValue, info configAlgorithmInfo, curve string) Result {
+	result := terraformResult(file, resource, value, info, fmt.Sprintf("%s key on %s vulnerable to quantum attacks", info.Algorithm, curve))
+	nistAlgorithmID := applyCurve(&result, curve, "")
+	if result.Curve == "" {
+		// Curves without a pattern, such as P-224
+		result.Curve = curve
+	}
+	populateNISTFields(&result, nistAlgorithmID)
+	return result
+}
+
+// terraformResult builds the finding for a key declared by a resource argument
+func terraformResult(file string, resource terraformResource, value configValue, info configAlgorithmInfo, description string) Result {
+	return Result{
+		File:              file,
+		Algorithm:         info.Algorithm,
+		Type:              info.Type,
+		Line:              value.Line,
+		Method:            terraformMethod,
+		Risk:              info.Risk,
+		VulnerabilityType: info.Vulnerability,
+		Description:       fmt.Sprintf("%s.%s: %s", resource.Type, resource.Name, description),
+		Recommendation:    info.Recommendation,
+		Purpose:           info.Purpose,
+	}
+}
@@ -0,0 +1,34 @@
+package crypto
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ScanTiming is the time a scanner spent in each phase of its scans
+type ScanTiming struct {
+	Walk          time.Duration // Listing the files to scan
+	Match         time.Duration // Scanning files, wall clock across the worker pool
+	KubernetesAPI time.Duration // Kubernetes API requests, summed across concurrent calls
+}
+
+// phaseTimes accumulates phase durations in nanoseconds from concurrent scans
+type phaseTimes struct {
+	walk  int64
+	match int64
+	api   int64
+}
+
+// add adds the time elapsed since start to the phase counter
+func (p *phaseTimes) add(counter *int64, start time.Time) {
+	atomic.AddInt64(counter, int64(time.Since(start)))
+}
+
+// Timing returns the time spent per phase by the scans made so far
+func (s *Scanner) Timing() ScanTiming {
+	return ScanTiming{
+		Walk:          time.Duration(atomic.LoadInt64(&s.timing.walk)),
+		Match:         time.Duration(atomic.LoadInt64(&s.timing.match)),
+		KubernetesAPI: time.Duration(atomic.LoadInt64(&s.timing.api)),
+	}
+}
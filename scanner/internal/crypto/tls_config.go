@@ -0,0 +1,319 @@
+package crypto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tlsDirectiveKind says how the value of a TLS configuration directive is read
+type tlsDirectiveKind int
+
+const (
+	directiveOpenSSLCiphers tlsDirectiveKind = iota // OpenSSL cipher list (nginx, Apache, HAProxy, Envoy)
+	directiveProtocols                              // Enabled protocol versions
+	directiveGroups                                 // Key exchange groups or curves
+	directiveSSHKex                                 // OpenSSH KexAlgorithms
+	directiveSSHCiphers                             // OpenSSH Ciphers
+	directiveJavaDisabled                           // Java jdk.tls.disabledAlgorithms
+	directiveDHSize                                 // Size in bits of ephemeral Diffie-Hellman groups
+)
+
+// tlsDirectives maps the configuration keys that pin TLS and SSH algorithms to how
+// their values are read. Keys are matched case-sensitively, as the servers do.
+var tlsDirectives = map[string]tlsDirectiveKind{
+	"ssl_ciphers":                directiveOpenSSLCiphers, // nginx
+	"ssl_protocols":              directiveProtocols,
+	"ssl_ecdh_curve":             directiveGroups,
+	"SSLCipherSuite":             directiveOpenSSLCiphers, // Apache httpd
+	"ssl-default-bind-ciphers":   directiveOpenSSLCiphers, // HAProxy
+	"ssl-default-server-ciphers": directiveOpenSSLCiphers,
+	"tune.ssl.default-dh-param":  directiveDHSize,
+	"KexAlgorithms":              directiveSSHKex, // OpenSSH
+	"Ciphers":                    directiveSSHCiphers,
+	"jdk.tls.disabledAlgorithms": directiveJavaDisabled, // Java security properties
+	"jdk.tls.namedGroups":        directiveGroups,
+	"jdk.tls.ephemeralDHKeySize": directiveDHSize,
+	"cipher_suites":              directiveOpenSSLCiphers, // Envoy TLS parameters
+	"ecdh_curves":                directiveGroups,
+}
+
+// tlsGroupAliases maps curve names used by OpenSSL, Java and Envoy to IANA group names
+var tlsGroupAliases = map[string]string{
+	"prime256v1": "secp256r1",
+	"p-256":      "secp256r1",
+	"p-384":      "secp384r1",
+	"p-521":      "secp521r1",
+}
+
+// javaLegacyAlgorithms are disabled by default in current JDKs. A
+// jdk.tls.disabledAlgorithms override that omits one re-enables it.
+var javaLegacyAlgorithms = []string{"SSLv3", "TLSv1", "TLSv1.1", "RC4", "DES", "3DES_EDE_CBC", "NULL"}
+
+// sshKexAlgorithms classifies OpenSSH key exchange methods; post-quantum hybrids are absent
+var sshKexAlgorithms = map[string]string{
+	"curve25519-sha256":                    "X25519",
+	"curve25519-sha256@libssh.org":         "X25519",
+	"ecdh-sha2-nistp256":                   "ECDH",
+	"ecdh-sha2-nistp384":                   "ECDH",
+	"ecdh-sha2-nistp521":                   "ECDH",
+	"diffie-hellman-group1-sha1":           "DH",
+	"diffie-hellman-group14-sha1":          "DH",
+	"diffie-hellman-group14-sha256":        "DH",
+	"diffie-hellman-group16-sha512":        "DH",
+	"diffie-hellman-group18-sha512":        "DH",
+	"diffie-hellman-group-exchange-sha1":   "DH",
+	"diffie-hellman-group-exchange-sha256": "DH",
+}
+
+// analyzeTLSDirectives reports each quantum-vulnerable or weak entry of the TLS and
+// SSH configuration directives in content, such as nginx ssl_ciphers or OpenSSH
+// KexAlgorithms. Values may be given inline, as Java properties continued with a
+// trailing backslash, or as YAML lists on the lines that follow the key.
+func analyzeTLSDirectives(file, content string) []Result {
+	var results []Result
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		key, value, ok := splitDirective(lines[i])
+		if !ok {
+			continue
+		}
+		kind, known := tlsDirectives[key]
+		if !known {
+			continue
+		}
+		lineNum := i + 1
+
+		// Java properties continue onto the next line after a trailing backslash
+		for strings.HasSuffix(value, `\`) && i+1 < len(lines) {
+			i++
+			value = strings.TrimSuffix(value, `\`) + " " + strings.TrimSpace(lines[i])
+		}
+
+		var entries []string
+		switch {
+		case value == "":
+			// A YAML block list follows the key
+			for i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "- ") {
+				i++
+				entries = append(entries, trimDirectiveValue(strings.TrimPrefix(strings.TrimSpace(lines[i]), "- ")))
+			}
+		case strings.HasPrefix(value, "["):
+			for _, entry := range strings.Split(strings.Trim(value, "[]"), ",") {
+				entries = append(entries, trimDirectiveValue(entry))
+			}
+		default:
+			entries = splitDirectiveEntries(kind, trimDirectiveValue(value))
+		}
+
+		results = append(results, analyzeDirectiveEntries(file, lineNum, key, kind, entries)...)
+	}
+	return results
+}
+
+// splitDirective splits a configuration line into its key and value, accepting
+// "key value", "key=value" and "key: value"
+func splitDirective(line string) (key, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+		return "", "", false
+	}
+	end := strings.IndexAny(line, " \t=:")
+	if end <= 0 {
+		return "", "", false
+	}
+	key = strings.Trim(line[:end], `"'`)
+	value = strings.TrimSpace(line[end:])
+	value = strings.TrimSpace(strings.TrimLeft(value, "=:"))
+	return key, value, true
+}
+
+// trimDirectiveValue strips quotes and a trailing semicolon from a value or entry
+func trimDirectiveValue(value string) string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSuffix(value, ";")
+	return strings.Trim(strings.TrimSpace(value), `"'`)
+}
+
+// splitDirectiveEntries splits an inline directive value into its entries
+func splitDirectiveEntries(kind tlsDirectiveKind, value string) []string {
+	switch kind {
+	case directiveOpenSSLCiphers:
+		return splitCipherList(value)
+	case directiveJavaDisabled:
+		// Entries may carry constraints, e.g. "DH keySize < 1024"
+		var entries []string
+		for _, entry := range strings.Split(value, ",") {
+			if fields := strings.Fields(entry); len(fields) > 0 {
+				entries = append(entries, fields[0])
+			}
+		}
+		return entries
+	}
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ':' || r == ',' || r == ' ' })
+}
+
+// analyzeDirectiveEntries reports the vulnerable entries of one directive
+func analyzeDirectiveEntries(file string, line int, key string, kind tlsDirectiveKind, entries []string) []Result {
+	finding := func(entry, algorithm, algType, risk, vulnerability, description, recommendation string) Result {
+		return Result{
+			File:              file,
+			Algorithm:         algorithm,
+			Type:              algType,
+			Line:              line,
+			Method:            "TLS Configuration Analysis",
+			Risk:              risk,
+			VulnerabilityType: vulnerability,
+			Description:       fmt.Sprintf("%s enables %s: %s", key, entry, description),
+			Recommendation:    recommendation,
+		}
+	}
+
+	var results []Result
+	switch kind {
+	case directiveOpenSSLCiphers:
+		for _, entry := range entries {
+			for _, algorithm := range opensslCipherAlgorithms(entry) {
+				switch algorithm {
+				case "3DES", "DES", "RC4":
+					results = append(results, finding(entry, algorithm, "SymmetricKey", "High", "Classical Weakness",
+						fmt.Sprintf("weak %s cipher", algorithm), "Remove legacy ciphers and use AES-256-GCM or ChaCha20-Poly1305"))
+				default:
+					result := finding(entry, algorithm, "PublicKey", "High", "Shor's Algorithm",
+						fmt.Sprintf("%s key exchange is vulnerable to quantum attacks", algorithm), "Prefer TLS 1.3 with hybrid post-quantum key exchange (X25519MLKEM768)")
+					if algorithm == "RSA" {
+						populateNISTFields(&result, "RSA-2048")
+					}
+					results = append(results, result)
+				}
+			}
+		}
+
+	case directiveProtocols:
+		for _, entry := range entries {
+			if version, ok := legacyProtocolName(entry); ok {
+				results = append(results, finding(entry, version, "Protocol", "High", "Protocol Weakness",
+					fmt.Sprintf("outdated %s protocol", version), "Allow only TLS 1.2 and TLS 1.3"))
+			}
+		}
+
+	case directiveGroups:
+		for _, entry := range entries {
+			name := strings.ToLower(entry)
+			if alias, ok := tlsGroupAliases[name]; ok {
+				name = alias
+			}
+			for _, group := range tlsNamedGroups {
+				if strings.ToLower(group.Name) != name || group.Type != "PublicKey" {
+					continue
+				}
+				result := finding(entry, group.Algorithm, group.Type, group.Risk, group.Vulnerability,
+					fmt.Sprintf("classical %s key exchange group", group.Name), "Add a hybrid post-quantum group such as X25519MLKEM768 first")
+				populateNISTFields(&result, group.NISTAlgorithmID)
+				results = append(results, result)
+			}
+		}
+
+	case directiveSSHKex:
+		for _, entry := range entries {
+			if strings.HasPrefix(entry, "-") {
+				continue // Removed from the defaults
+			}
+			name := strings.TrimLeft(entry, "+^")
+			algorithm, ok := sshKexAlgorithms[name]
+			if !ok {
+				continue
+			}
+			if name == "diffie-hellman-group1-sha1" {
+				result := dhGroupResult(file, line, "TLS Configuration Analysis", fmt.Sprintf("%s enables %s, which", key, entry), 1024, modpGroups[1024])
+				result.Description += "; it also uses SHA-1"
+				result.Recommendation = "Prefer mlkem768x25519-sha256 or sntrup761x25519-sha512@openssh.com"
+				results = append(results, result)
+				continue
+			}
+			description := fmt.Sprintf("%s key exchange is vulnerable to quantum attacks", algorithm)
+			if strings.HasSuffix(name, "-sha1") {
+				description += " and uses SHA-1"
+			}
+			results = append(results, finding(entry, algorithm, "PublicKey", "High", "Shor's Algorithm",
+				description, "Prefer mlkem768x25519-sha256 or sntrup761x25519-sha512@openssh.com"))
+		}
+
+	case directiveSSHCiphers:
+		for _, entry := range entries {
+			if strings.HasPrefix(entry, "-") {
+				continue
+			}
+			name := strings.TrimLeft(entry, "+^")
+			switch {
+			case strings.HasPrefix(name, "3des"):
+				results = append(results, finding(entry, "3DES", "SymmetricKey", "High", "Classical Weakness",
+					"weak 3DES cipher", "Use aes256-gcm@openssh.com or chacha20-poly1305@openssh.com"))
+			case strings.HasPrefix(name, "arcfour"):
+				results = append(results, finding(entry, "RC4", "SymmetricKey", "High", "Classical Weakness",
+					"weak RC4 cipher", "Use aes256-gcm@openssh.com or chacha20-poly1305@openssh.com"))
+			case strings.HasPrefix(name, "aes128-"):
+				result := finding(entry, "AES-128", "SymmetricKey", "Medium", "Grover's Algorithm",
+					"AES-128 provides reduced quantum security", "Prefer aes256-gcm@openssh.com")
+				populateNISTFields(&result, "AES-128")
+				results = append(results, result)
+			}
+		}
+
+	case directiveJavaDisabled:
+		disabled := make(map[string]bool, len(entries))
+		for _, entry := range entries {
+			disabled[entry] = true
+		}
+		for _, legacy := range javaLegacyAlgorithms {
+			if disabled[legacy] {
+				continue
+			}
+			algorithm, algType, vulnerability := legacy, "SymmetricKey", "Classical Weakness"
+			if version, ok := legacyProtocolName(legacy); ok {
+				algorithm, algType, vulnerability = version, "Protocol", "Protocol Weakness"
+			} else if legacy == "3DES_EDE_CBC" {
+				algorithm = "3DES"
+			}
+			results = append(results, Result{
+				File:              file,
+				Algorithm:         algorithm,
+				Type:              algType,
+				Line:              line,
+				Method:            "TLS Configuration Analysis",
+				Risk:              "High",
+				VulnerabilityType: vulnerability,
+				Description:       fmt.Sprintf("%s re-enables %s, which the JDK disables by default", key, legacy),
+				Recommendation:    "Keep the JDK default legacy algorithms in jdk.tls.disabledAlgorithms",
+			})
+		}
+
+	case directiveDHSize:
+		for _, entry := range entries {
+			bits, err := strconv.Atoi(entry)
+			if entry == "legacy" {
+				bits, err = 1024, nil
+			}
+			if err == nil && bits > 0 {
+				results = append(results, dhGroupResult(file, line, "TLS Configuration Analysis", key, bits, ""))
+			}
+		}
+	}
+	return results
+}
+
+// legacyProtocolName returns the display name of an outdated protocol version
+// as written in server configuration, e.g. "TLSv1.1"
+func legacyProtocolName(entry string) (string, bool) {
+	switch strings.TrimLeft(entry, "+") {
+	case "SSLv2":
+		return "SSL 2.0", true
+	case "SSLv3":
+		return "SSL 3.0", true
+	case "TLSv1", "TLSv1.0":
+		return "TLS 1.0", true
+	case "TLSv1.1":
+		return "TLS 1.1", true
+	}
+	return "", false
+}
@@ -0,0 +1,347 @@
+package crypto
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Handshake roles: the client offers algorithms, the server selects from them
+const (
+	RoleClient = "client"
+	RoleServer = "server"
+)
+
+// Whether a handshake finding reflects an algorithm only offered by the client or
+// one the server negotiated for the connection
+const (
+	NegotiationOffered    = "offered"
+	NegotiationNegotiated = "negotiated"
+)
+
+// tlsNamedGroup describes a key exchange group from the IANA TLS Supported Groups registry
+type tlsNamedGroup struct {
+	Name            string
+	Algorithm       string
+	Type            string
+	Risk            string
+	Vulnerability   string
+	NISTAlgorithmID string
+}
+
+// tlsNamedGroups lists the key exchange groups reported from handshakes; GREASE and
+// unknown values are skipped
+var tlsNamedGroups = map[uint16]tlsNamedGroup{
+	0x0017: {Name: "secp256r1", Algorithm: "ECDH", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDH-P256"},
+	0x0018: {Name: "secp384r1", Algorithm: "ECDH", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDH-P384"},
+	0x0019: {Name: "secp521r1", Algorithm: "ECDH", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "ECDH-P521"},
+	0x001D: {Name: "x25519", Algorithm: "X25519", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm"},
+	0x001E: {Name: "x448", Algorithm: "X448", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm"},
+	0x0100: {Name: "ffdhe2048", Algorithm: "DH", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "DH-2048"},
+	0x0101: {Name: "ffdhe3072", Algorithm: "DH", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm", NISTAlgorithmID: "DH-3072"},
+	0x0102: {Name: "ffdhe4096", Algorithm: "DH", Type: "PublicKey", Risk: "High", Vulnerability: "Shor's Algorithm"},
+	0x0200: {Name: "MLKEM512", Algorithm: "ML-KEM", Type: "PostQuantum", Risk: "Low", Vulnerability: "Quantum-Resistant", NISTAlgorithmID: "ML-KEM-512"},
+	0x0201: {Name: "MLKEM768", Algorithm: "ML-KEM", Type: "PostQuantum", Risk: "Low", Vulnerability: "Quantum-Resistant", NISTAlgorithmID: "ML-KEM-768"},
+	0x0202: {Name: "MLKEM1024", Algorithm: "ML-KEM", Type: "PostQuantum", Risk: "Low", Vulnerability: "Quantum-Resistant", NISTAlgorithmID: "ML-KEM-1024"},
+	0x11EB: {Name: "SecP256r1MLKEM768", Algorithm: "SecP256r1MLKEM768", Type: "Hybrid", Risk: "Low", Vulnerability: "Quantum-Resistant (Hybrid)", NISTAlgorithmID: "SecP256r1MLKEM768"},
+	0x11EC: {Name: "X25519MLKEM768", Algorithm: "X25519MLKEM768", Type: "Hybrid", Risk: "Low", Vulnerability: "Quantum-Resistant (Hybrid)", NISTAlgorithmID: "X25519MLKEM768"},
+	0x11ED: {Name: "SecP384r1MLKEM1024", Algorithm: "SecP384r1MLKEM1024", Type: "Hybrid", Risk: "Low", Vulnerability: "Quantum-Resistant (Hybrid)", NISTAlgorithmID: "SecP384r1MLKEM1024"},
+	0x6399: {Name: "X25519Kyber768Draft00", Algorithm: "X25519Kyber768", Type: "Hybrid", Risk: "Low", Vulnerability: "Quantum-Resistant (Hybrid)"},
+}
+
+// cipherSuiteCipher describes the symmetric cipher of a cipher suite from its IANA name
+func cipherSuiteCipher(name string) (tlsNamedGroup, bool) {
+	switch {
+	case strings.Contains(name, "AES_256"):
+		return tlsNamedGroup{Algorithm: "AES-256", Type: "SymmetricKey", Risk: "Low", Vulnerability: "Grover's Algorithm", NISTAlgorithmID: "AES-256"}, true
+	case strings.Contains(name, "AES_128"):
+		return tlsNamedGroup{Algorithm: "AES-128", Type: "SymmetricKey", Risk: "Medium", Vulnerability: "Grover's Algorithm", NISTAlgorithmID: "AES-128"}, true
+	case strings.Contains(name, "CHACHA20"):
+		return tlsNamedGroup{Algorithm: "ChaCha20", Type: "SymmetricKey", Risk: "Low", Vulnerability: "Grover's Algorithm"}, true
+	}
+	return tlsNamedGroup{}, false
+}
+
+// TLS extension types read from ClientHello messages
+const (
+	tlsExtServerName        = 0x0000
+	tlsExtSupportedGroups   = 0x000A
+	tlsExtSupportedVersions = 0x002B
+	tlsExtKeyShare          = 0x0033
+)
+
+// clientHello holds the parameters a TLS client offers
+type clientHello struct {
+	ServerName   string
+	CipherSuites []uint16
+	Groups       []uint16 // supported_groups, in preference order
+	KeyShares    []uint16 // Groups the client sent a key share for
+	Versions     []uint16 // supported_versions, when the client sent it
+}
+
+// serverHello holds the parameters a TLS server selected
+type serverHello struct {
+	Version     uint16 // Negotiated version, from supported_versions when present
+	CipherSuite uint16
+	Group       uint16 // Key share group selected in TLS 1.3; 0 otherwise
+}
+
+// errTruncated reports a handshake message shorter than its declared lengths
+var errTruncated = errors.New("truncated handshake message")
+
+// helloReader reads big-endian fields from a handshake message
+type helloReader struct {
+	data []byte
+	err  error
+}
+
+func (r *helloReader) bytes(n int) []byte {
+	if r.err != nil || n > len(r.data) {
+		r.err = errTruncated
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *helloReader) uint(n int) int {
+	v := 0
+	for _, b := range r.bytes(n) {
+		v = v<<8 | int(b)
+	}
+	return v
+}
+
+// vector reads a vector with an n-byte length prefix
+func (r *helloReader) vector(n int) *helloReader {
+	return &helloReader{data: r.bytes(r.uint(n)), err: r.err}
+}
+
+// uint16s reads the rest of r as a list of 16-bit values
+func (r *helloReader) uint16s() []uint16 {
+	var values []uint16
+	for len(r.data) >= 2 && r.err == nil {
+		values = append(values, uint16(r.uint(2)))
+	}
+	return values
+}
+
+// parseClientHello parses a ClientHello handshake message, starting at its type byte
+func parseClientHello(msg []byte) (*clientHello, error) {
+	r := &helloReader{data: msg}
+	if msgType := r.uint(1); r.err == nil && msgType != 1 {
+		return nil, fmt.Errorf("handshake type %d is not a ClientHello", msgType)
+	}
+	body := r.vector(3)
+	body.bytes(2 + 32) // legacy_version, random
+	body.vector(1)     // legacy_session_id
+
+	hello := &clientHello{CipherSuites: body.vector(2).uint16s()}
+	body.vector(1) // legacy_compression_methods
+
+	extensions := body.vector(2)
+	for len(extensions.data) > 0 && extensions.err == nil {
+		extType := extensions.uint(2)
+		ext := extensions.vector(2)
+		switch extType {
+		case tlsExtServerName:
+			names := ext.vector(2)
+			for len(names.data) > 0 && names.err == nil {
+				nameType := names.uint(1)
+				name := names.vector(2)
+				if nameType == 0 {
+					hello.ServerName = string(name.data)
+				}
+			}
+		case tlsExtSupportedGroups:
+			hello.Groups = ext.vector(2).uint16s()
+		case tlsExtSupportedVersions:
+			hello.Versions = ext.vector(1).uint16s()
+		case tlsExtKeyShare:
+			shares := ext.vector(2)
+			for len(shares.data) > 0 && shares.err == nil {
+				hello.KeyShares = append(hello.KeyShares, uint16(shares.uint(2)))
+				shares.vector(2)
+			}
+		}
+	}
+
+	for _, err := range []error{r.err, body.err, extensions.err} {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hello, nil
+}
+
+// parseServerHello parses a ServerHello handshake message, starting at its type byte
+func parseServerHello(msg []byte) (*serverHello, error) {
+	r := &helloReader{data: msg}
+	if msgType := r.uint(1); r.err == nil && msgType != 2 {
+		return nil, fmt.Errorf("handshake type %d is not a ServerHello", msgType)
+	}
+	body := r.vector(3)
+	hello := &serverHello{Version: uint16(body.uint(2))}
+	body.bytes(32) // random
+	body.vector(1) // legacy_session_id_echo
+	hello.CipherSuite = uint16(body.uint(2))
+	body.bytes(1) // legacy_compression_method
+
+	// Extensions are optional before TLS 1.3
+	extensions := &helloReader{}
+	if len(body.data) > 0 {
+		extensions = body.vector(2)
+	}
+	for len(extensions.data) > 0 && extensions.err == nil {
+		extType := extensions.uint(2)
+		ext := extensions.vector(2)
+		switch extType {
+		case tlsExtSupportedVersions:
+			hello.Version = uint16(ext.uint(2))
+		case tlsExtKeyShare:
+			hello.Group = uint16(ext.uint(2))
+		}
+	}
+
+	for _, err := range []error{r.err, body.err, extensions.err} {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hello, nil
+}
+
+// tlsVersionName returns the display name of a TLS protocol version
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	case dtlsVersion10:
+		return "DTLS 1.0"
+	case dtlsVersion12:
+		return "DTLS 1.2"
+	case dtlsVersion13:
+		return "DTLS 1.3"
+	}
+	return fmt.Sprintf("TLS %d.%d", version>>8, version&0xFF)
+}
+
+// analyzeClientHello reports the key exchange groups and symmetric ciphers a client
+// offered. These are only offers: the server may still negotiate something weaker.
+func analyzeClientHello(conn TLSConnection, source string) []Result {
+	hello := conn.hello
+	server := hello.ServerName
+	if server == "" {
+		server = conn.DestIP
+	}
+	method, client := "TLS ClientHello Analysis", "TLS client"
+	switch conn.Transport {
+	case "QUIC":
+		method, client = "QUIC Initial Analysis", "QUIC client"
+	case "DTLS":
+		method, client = "DTLS ClientHello Analysis", "DTLS client"
+	}
+
+	var results []Result
+	shares := make(map[uint16]bool, len(hello.KeyShares))
+	for _, id := range hello.KeyShares {
+		shares[id] = true
+	}
+	seen := make(map[uint16]bool)
+	for _, id := range append(append([]uint16(nil), hello.Groups...), hello.KeyShares...) {
+		group, ok := tlsNamedGroups[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		description := fmt.Sprintf("%s to %s offers the %s key exchange group", client, server, group.Name)
+		if shares[id] {
+			description += " and sent a key share for it"
+		}
+		recommendation := "Offer a hybrid post-quantum group such as X25519MLKEM768"
+		if group.Type != "PublicKey" {
+			recommendation = "Post-quantum key exchange offered. Confirm servers negotiate it"
+		}
+		result := Result{
+			File:              source,
+			Algorithm:         group.Algorithm,
+			Type:              group.Type,
+			Line:              1,
+			Method:            method,
+			Risk:              group.Risk,
+			VulnerabilityType: group.Vulnerability,
+			Description:       description,
+			Recommendation:    recommendation,
+		}
+		populateNISTFields(&result, group.NISTAlgorithmID)
+		results = append(results, result)
+	}
+
+	// One finding per symmetric cipher, listing the suites that use it
+	var ciphers []tlsNamedGroup
+	suites := make(map[string][]string)
+	for _, id := range hello.CipherSuites {
+		cipher, ok := cipherSuiteCipher(tls.CipherSuiteName(id))
+		if !ok {
+			continue
+		}
+		if _, ok := suites[cipher.Algorithm]; !ok {
+			ciphers = append(ciphers, cipher)
+		}
+		suites[cipher.Algorithm] = append(suites[cipher.Algorithm], tls.CipherSuiteName(id))
+	}
+	for _, cipher := range ciphers {
+		recommendation := "Strong quantum resistance. No action needed"
+		if cipher.Risk != "Low" {
+			recommendation = "Prefer cipher suites using AES-256 or ChaCha20"
+		}
+		result := Result{
+			File:              source,
+			Algorithm:         cipher.Algorithm,
+			Type:              cipher.Type,
+			Line:              1,
+			Method:            method,
+			Risk:              cipher.Risk,
+			VulnerabilityType: cipher.Vulnerability,
+			Description:       fmt.Sprintf("%s to %s offers cipher suites %s", client, server, strings.Join(suites[cipher.Algorithm], ", ")),
+			Recommendation:    recommendation,
+		}
+		populateNISTFields(&result, cipher.NISTAlgorithmID)
+		results = append(results, result)
+	}
+	return results
+}
+
+// analyzeNegotiatedGroup reports the key exchange group a TLS 1.3 server selected
+func analyzeNegotiatedGroup(conn TLSConnection, source string) []Result {
+	group, ok := tlsNamedGroups[conn.server.Group]
+	if !ok {
+		return nil
+	}
+	recommendation := "Configure the server to prefer a hybrid post-quantum group such as X25519MLKEM768"
+	if group.Type != "PublicKey" {
+		recommendation = "Post-quantum key exchange negotiated. No action needed"
+	}
+	result := Result{
+		File:              source,
+		Algorithm:         group.Algorithm,
+		Type:              group.Type,
+		Line:              1,
+		Method:            "TLS Key Exchange Analysis",
+		Risk:              group.Risk,
+		VulnerabilityType: group.Vulnerability,
+		Description:       fmt.Sprintf("TLS connection negotiated the %s key exchange group", group.Name),
+		Recommendation:    recommendation,
+	}
+	populateNISTFields(&result, group.NISTAlgorithmID)
+	return []Result{result}
+}
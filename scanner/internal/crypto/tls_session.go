@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// tlsSessionMethod is the Method of findings that compare both sides of a handshake
+const tlsSessionMethod = "TLS Session Analysis"
+
+// tlsFlowKey identifies one direction of a connection
+func tlsFlowKey(srcIP string, srcPort int, dstIP string, dstPort int, transport string) string {
+	return fmt.Sprintf("%s|%s:%d|%s:%d", transport, srcIP, srcPort, dstIP, dstPort)
+}
+
+// analyzeTLSSessions pairs each ServerHello with the ClientHello of the same flow
+// and reports possible downgrades: the client offered a post-quantum group or TLS
+// 1.3, but the server negotiated a classical key exchange or an older version.
+// Identical downgrades between the same endpoints are reported once, counted in Seen.
+func analyzeTLSSessions(conns []TLSConnection, source string) []Result {
+	var results []Result
+	index := make(map[string]int)
+	hellos := make(map[string]*TLSConnection)
+	for i := range conns {
+		conn := &conns[i]
+		switch {
+		case conn.Role == RoleClient && conn.hello != nil:
+			// A retried ClientHello after a HelloRetryRequest replaces the first
+			hellos[tlsFlowKey(conn.SourceIP, conn.SourcePort, conn.DestIP, conn.DestPort, conn.Transport)] = conn
+		case conn.Role == RoleServer && conn.server != nil:
+			client, ok := hellos[tlsFlowKey(conn.DestIP, conn.DestPort, conn.SourceIP, conn.SourcePort, conn.Transport)]
+			if !ok {
+				continue
+			}
+			result, ok := detectDowngrade(*client, *conn, source)
+			if !ok {
+				continue
+			}
+			key := fmt.Sprintf("%s|%s|%d|%s|%s", client.SourceIP, conn.SourceIP, conn.SourcePort, client.hello.ServerName, result.Description)
+			if j, ok := index[key]; ok {
+				results[j].Seen++
+				continue
+			}
+			result.Seen = 1
+			index[key] = len(results)
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// detectDowngrade compares what a client offered with what the server selected
+func detectDowngrade(client, server TLSConnection, source string) (Result, bool) {
+	hello := client.hello
+
+	// The client's strongest offer: any post-quantum or hybrid group, and TLS 1.3
+	var offeredGroup *tlsNamedGroup
+	for _, id := range append(append([]uint16(nil), hello.Groups...), hello.KeyShares...) {
+		if group, ok := tlsNamedGroups[id]; ok && group.Type != "PublicKey" {
+			offeredGroup = &group
+			break
+		}
+	}
+	offeredTLS13 := client.Transport == "QUIC"
+	for _, version := range hello.Versions {
+		offeredTLS13 = offeredTLS13 || tlsEquivalentVersion(version) == tls.VersionTLS13
+	}
+	offeredVersion := "TLS 1.3"
+	if client.Transport == "DTLS" {
+		offeredVersion = tlsVersionName(dtlsVersion13)
+	}
+
+	// What the server negotiated: the key share group in TLS 1.3, the cipher
+	// suite's key exchange before it
+	negotiated := server.server
+	var algorithm, name, nistID string
+	if group, ok := tlsNamedGroups[negotiated.Group]; ok {
+		if group.Type != "PublicKey" {
+			return Result{}, false
+		}
+		algorithm, name, nistID = group.Algorithm, group.Name, group.NISTAlgorithmID
+	} else {
+		switch server.KeyExchange {
+		case "ECDHE", "ECDH":
+			algorithm = "ECDH"
+		case "DHE":
+			algorithm = "DH"
+		case "RSA":
+			algorithm = "RSA"
+		}
+		name = tls.CipherSuiteName(negotiated.CipherSuite)
+	}
+
+	var reasons []string
+	if offeredGroup != nil && algorithm != "" {
+		reasons = append(reasons, fmt.Sprintf("offered the %s post-quantum group but the server negotiated %s", offeredGroup.Name, name))
+	}
+	if offeredTLS13 && tlsEquivalentVersion(negotiated.Version) < tls.VersionTLS13 {
+		reasons = append(reasons, fmt.Sprintf("offered %s but the server negotiated %s", offeredVersion, tlsVersionName(negotiated.Version)))
+	}
+	if len(reasons) == 0 {
+		return Result{}, false
+	}
+
+	serverName := hello.ServerName
+	if serverName == "" {
+		serverName = server.SourceIP
+	}
+	result := Result{
+		File:              source,
+		Algorithm:         algorithm,
+		Type:              "PublicKey",
+		Line:              1,
+		Method:            tlsSessionMethod,
+		Risk:              "High",
+		VulnerabilityType: "Downgrade",
+		Role:              RoleServer,
+		Negotiation:       NegotiationNegotiated,
+		Description: fmt.Sprintf("Possible downgrade: TLS client %s connecting to %s %s; the server may not support the stronger option, or an on-path attacker removed it",
+			client.SourceIP, serverName, strings.Join(reasons, " and ")),
+		Recommendation: "Enable TLS 1.3 and a hybrid post-quantum group such as X25519MLKEM768 on the server; if they are enabled, look for a middlebox stripping them",
+	}
+	if algorithm == "" {
+		// Only the version was downgraded and the suite's key exchange is unknown
+		result.Algorithm, result.Type = tlsVersionName(negotiated.Version), "Protocol"
+	}
+	populateNISTFields(&result, nistID)
+	return result, true
+}
@@ -0,0 +1,144 @@
+package crypto
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// curvePreferenceMaxLines bounds how many lines a Go CurvePreferences list is read across
+const curvePreferenceMaxLines = 8
+
+// insecureTransport is a call that sets up a connection without TLS
+type insecureTransport struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+var (
+	// insecureTransports are the gRPC plaintext credential idioms of the common languages
+	insecureTransports = []insecureTransport{
+		{Name: "insecure.NewCredentials", Pattern: regexp.MustCompile(`\binsecure\.NewCredentials\(\)`)},
+		{Name: "grpc.WithInsecure", Pattern: regexp.MustCompile(`\bgrpc\.WithInsecure\(\)`)},
+		{Name: "grpc.insecure_channel", Pattern: regexp.MustCompile(`\bgrpc\.(?:aio\.)?insecure_channel\(`)},
+		{Name: "add_insecure_port", Pattern: regexp.MustCompile(`\.add_insecure_port\(`)},
+		{Name: "usePlaintext", Pattern: regexp.MustCompile(`\.usePlaintext\(\)`)},
+		{Name: "createInsecure", Pattern: regexp.MustCompile(`\b(?:credentials|ServerCredentials)\.createInsecure\(\)`)},
+	}
+
+	// skipVerifyPattern matches TLS client settings that accept any server certificate
+	skipVerifyPattern = regexp.MustCompile(`\bInsecureSkipVerify:\s*true\b|\brejectUnauthorized:\s*false\b|\bssl\._create_unverified_context\(|\bverify_mode\s*=\s*ssl\.CERT_NONE\b`)
+
+	// goCurvePreferencesPattern starts a Go tls.Config curve preference list
+	goCurvePreferencesPattern = regexp.MustCompile(`\bCurvePreferences:\s*\[\]tls\.CurveID\{`)
+
+	// goCurveIDPattern captures the Go crypto/tls curve constants of a preference list
+	goCurveIDPattern = regexp.MustCompile(`\btls\.(\w+)`)
+
+	// goMaxTLS12Pattern matches a Go tls.Config capped below TLS 1.3
+	goMaxTLS12Pattern = regexp.MustCompile(`\bMaxVersion:\s*tls\.VersionTLS1[0-2]\b`)
+)
+
+// goCurveIDs maps Go crypto/tls CurveID constants to their TLS named group codes
+var goCurveIDs = map[string]uint16{
+	"CurveP256":          0x0017,
+	"CurveP384":          0x0018,
+	"CurveP521":          0x0019,
+	"X25519":             0x001D,
+	"SecP256r1MLKEM768":  0x11EB,
+	"X25519MLKEM768":     0x11EC,
+	"SecP384r1MLKEM1024": 0x11ED,
+}
+
+// detectTransportSecurity flags connections set up without TLS, such as gRPC
+// insecure credentials, and TLS clients that skip certificate verification. Go
+// tls.Config curve preferences without a hybrid post-quantum group are reported
+// as their classical key exchange.
+func (s *Scanner) detectTransportSecurity(filePath string, lines []string) []Result {
+	var results []Result
+	for i, line := range lines {
+		for _, transport := range insecureTransports {
+			if transport.Pattern.MatchString(line) {
+				results = append(results, transportResult(filePath, i+1, "Plaintext", TypeProtocol, "High", CWECleartextTransmission,
+					fmt.Sprintf("%s sets up a connection without TLS, so traffic and credentials cross the network unencrypted and unauthenticated", transport.Name),
+					"Use TLS transport credentials, e.g. grpc.WithTransportCredentials(credentials.NewTLS(cfg)), with mutual TLS between services"))
+				break
+			}
+		}
+
+		if skipVerifyPattern.MatchString(line) {
+			results = append(results, transportResult(filePath, i+1, "TLS", TypeProtocol, "Critical", CWEImproperCertificateValidation,
+				"TLS certificate verification is disabled, so any server certificate is accepted and connections can be intercepted",
+				"Verify certificates against the system roots or a pinned CA pool (RootCAs) instead of skipping verification"))
+		}
+
+		if goMaxTLS12Pattern.MatchString(line) {
+			results = append(results, transportResult(filePath, i+1, "TLS 1.2", TypeProtocol, "Medium", CWEBrokenCrypto,
+				"tls.Config caps the protocol below TLS 1.3, where no hybrid post-quantum key exchange is available",
+				"Allow TLS 1.3 so X25519MLKEM768 can be negotiated"))
+		}
+
+		if loc := goCurvePreferencesPattern.FindStringIndex(line); loc != nil {
+			results = append(results, goCurvePreferenceResults(filePath, lines, i, loc[1])...)
+		}
+	}
+	return results
+}
+
+// goCurvePreferenceResults classifies the groups of a Go CurvePreferences list whose
+// elements start at offset start of lines[index], read up to the closing brace or
+// curvePreferenceMaxLines lines. Lists with a hybrid post-quantum group are not
+// reported; otherwise each classical group is.
+func goCurvePreferenceResults(filePath string, lines []string, index, start int) []Result {
+	var text strings.Builder
+	rest := lines[index][start:]
+	for n := 0; n < curvePreferenceMaxLines; n++ {
+		if end := strings.Index(rest, "}"); end >= 0 {
+			text.WriteString(rest[:end])
+			break
+		}
+		text.WriteString(rest + "\n")
+		if index+n+1 >= len(lines) {
+			break
+		}
+		rest = lines[index+n+1]
+	}
+
+	var classical []tlsNamedGroup
+	for _, match := range goCurveIDPattern.FindAllStringSubmatch(text.String(), -1) {
+		group, ok := tlsNamedGroups[goCurveIDs[match[1]]]
+		if !ok {
+			continue
+		}
+		if group.Type != "PublicKey" {
+			return nil // Hybrid key exchange is offered
+		}
+		classical = append(classical, group)
+	}
+
+	var results []Result
+	for _, group := range classical {
+		result := transportResult(filePath, index+1, group.Algorithm, group.Type, group.Risk, CWEBrokenCrypto,
+			fmt.Sprintf("tls.Config CurvePreferences offers only classical key exchange, including %s, which is vulnerable to quantum attacks", group.Name),
+			"Add tls.X25519MLKEM768 first in CurvePreferences, or leave it unset to use Go's hybrid default")
+		populateNISTFields(&result, group.NISTAlgorithmID)
+		results = append(results, result)
+	}
+	return results
+}
+
+// transportResult builds a Transport Security finding
+func transportResult(filePath string, line int, algorithm, algType, risk, cwe, description, recommendation string) Result {
+	return Result{
+		File:              filePath,
+		Algorithm:         algorithm,
+		Type:              algType,
+		Line:              line,
+		Method:            "Transport Security Analysis",
+		Risk:              risk,
+		VulnerabilityType: "Transport Security",
+		CWE:               []string{cwe},
+		Description:       description,
+		Recommendation:    recommendation,
+	}
+}
@@ -0,0 +1,102 @@
+// Package metrics tracks scan volume and findings and exposes them to Prometheus.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the scan duration histogram
+var durationBuckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 600, 1800}
+
+// Registry holds scan metrics; it is safe for concurrent use
+type Registry struct {
+	registry *prometheus.Registry
+	scans    *prometheus.CounterVec
+	findings *prometheus.CounterVec
+	assets   prometheus.Counter
+	duration *prometheus.HistogramVec
+}
+
+// NewRegistry creates an empty metrics registry
+func NewRegistry() *Registry {
+	r := &Registry{
+		registry: prometheus.NewRegistry(),
+		scans: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cbom_scans_total",
+			Help: "Total number of scans run.",
+		}, []string{"mode"}),
+		findings: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cbom_findings_total",
+			Help: "Total number of crypto findings by risk and algorithm.",
+		}, []string{"risk", "algorithm"}),
+		assets: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "cbom_assets_scanned_total",
+			Help: "Total number of assets (files, resources, packets) scanned.",
+		}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cbom_scan_duration_seconds",
+			Help:    "Duration of scans in seconds.",
+			Buckets: durationBuckets,
+		}, []string{"mode"}),
+	}
+	r.registry.MustRegister(r.scans, r.findings, r.assets, r.duration)
+	return r
+}
+
+// Default is the registry updated by scans and served by Serve
+var Default = NewRegistry()
+
+// Finding is the subset of a crypto finding that metrics are labeled by
+type Finding struct {
+	Risk      string
+	Algorithm string
+}
+
+// RecordScan records a completed (or partial) scan
+func (r *Registry) RecordScan(mode string, duration time.Duration, assets int, findings []Finding) {
+	r.scans.WithLabelValues(mode).Inc()
+	r.assets.Add(float64(assets))
+	for _, f := range findings {
+		r.findings.WithLabelValues(f.Risk, f.Algorithm).Inc()
+	}
+	r.duration.WithLabelValues(mode).Observe(duration.Seconds())
+}
+
+// Handler serves the registry as a Prometheus scrape target
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{})
+}
+
+// Serve exposes the Default registry on addr at /metrics until ctx is done,
+// then shuts the server down
+func Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Default.Handler())
+
+	server := &http.Server{Addr: addr, Handler: mux}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
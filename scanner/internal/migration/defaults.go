@@ -0,0 +1,102 @@
+package migration
+
+import (
+	_ "embed"
+	"fmt"
+	"io/ioutil"
+)
+
+// Rules modes for combining a user rules file with the built-in defaults
+const (
+	RulesModeMerge   = "merge"   // User entries override matching default keys; new keys are added
+	RulesModeReplace = "replace" // The user file is used on its own
+)
+
+//go:embed default-rules.yaml
+var defaultRulesYAML []byte
+
+// DefaultRules returns the built-in migration rules
+func DefaultRules() (*MigrationRules, error) {
+	return ParseRules(defaultRulesYAML)
+}
+
+// LoadRulesWithMode loads migration rules according to mode. An empty path
+// yields the built-in defaults; otherwise the file at path is merged over the
+// defaults or replaces them.
+func LoadRulesWithMode(path, mode string) (*MigrationRules, error) {
+	switch mode {
+	case RulesModeMerge, "":
+	case RulesModeReplace:
+		if path == "" {
+			return DefaultRules()
+		}
+		return LoadRules(path)
+	default:
+		return nil, fmt.Errorf("unsupported rules mode '%s'. Use: merge, replace", mode)
+	}
+
+	if path == "" {
+		return DefaultRules()
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	overrides, errs, err := decodeRules(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, _, err := decodeRules(defaultRulesYAML)
+	if err != nil {
+		return nil, err
+	}
+	rules.merge(overrides)
+
+	errs = append(errs, rules.Validate()...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return rules, nil
+}
+
+// merge overlays o onto r: non-empty scalars replace r's, and map entries
+// replace or extend r's entries key by key
+func (r *MigrationRules) merge(o *MigrationRules) {
+	if o.Version != "" {
+		r.Version = o.Version
+	}
+	if o.LastUpdated != "" {
+		r.LastUpdated = o.LastUpdated
+	}
+
+	r.MigrationMatrix.KeyExchange = mergeMap(r.MigrationMatrix.KeyExchange, o.MigrationMatrix.KeyExchange)
+	r.MigrationMatrix.Signatures = mergeMap(r.MigrationMatrix.Signatures, o.MigrationMatrix.Signatures)
+	r.MigrationMatrix.Symmetric = mergeMap(r.MigrationMatrix.Symmetric, o.MigrationMatrix.Symmetric)
+	r.MigrationMatrix.Hashing = mergeMap(r.MigrationMatrix.Hashing, o.MigrationMatrix.Hashing)
+	r.MigrationMatrix.Hybrid = mergeMap(r.MigrationMatrix.Hybrid, o.MigrationMatrix.Hybrid)
+	r.DeploymentContexts = mergeMap(r.DeploymentContexts, o.DeploymentContexts)
+	r.Caveats = mergeMap(r.Caveats, o.Caveats)
+	r.Mitigations = mergeMap(r.Mitigations, o.Mitigations)
+	r.PriorityLevels = mergeMap(r.PriorityLevels, o.PriorityLevels)
+	r.ReadinessLevels = mergeMap(r.ReadinessLevels, o.ReadinessLevels)
+
+	if o.Output != (OutputConfig{}) {
+		r.Output = o.Output
+	}
+}
+
+// mergeMap copies the entries of overrides into base, allocating base if needed
+func mergeMap[V any](base, overrides map[string]V) map[string]V {
+	if len(overrides) == 0 {
+		return base
+	}
+	if base == nil {
+		base = make(map[string]V, len(overrides))
+	}
+	for key, value := range overrides {
+		base[key] = value
+	}
+	return base
+}
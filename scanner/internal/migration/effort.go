@@ -0,0 +1,172 @@
+package migration
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Effort models selectable with --effort-model
+const (
+	EffortModelFlat         = "flat"         // One day per finding that needs migration
+	EffortModelStandard     = "standard"     // Base days by algorithm type, scaled by context readiness
+	EffortModelConservative = "conservative" // Standard with a 50% contingency buffer
+)
+
+// baseEffortDays is the engineering effort to migrate one finding of each type
+var baseEffortDays = map[string]float64{
+	"PublicKey":             5,
+	"Protocol":              3,
+	"SymmetricKey":          2,
+	"Hash":                  1,
+	"KDF":                   1,
+	"EmbeddedKey":           1,
+	"RandomNumberGenerator": 1,
+}
+
+// baseComplexity is the starting complexity score (1-10) for each type
+var baseComplexity = map[string]int{
+	"PublicKey":             4,
+	"Protocol":              3,
+	"SymmetricKey":          2,
+	"Hash":                  1,
+	"KDF":                   1,
+	"EmbeddedKey":           2,
+	"RandomNumberGenerator": 1,
+}
+
+// readinessMultipliers scale effort by how ready the deployment context is
+var readinessMultipliers = map[string]float64{
+	"not-ready":        2.0,
+	"pilot-ready":      1.25,
+	"production-ready": 1.0,
+	"hybrid-active":    0.5,
+	"migrated":         0.25,
+}
+
+// readinessComplexity adds to the complexity score for less ready contexts
+var readinessComplexity = map[string]int{
+	"not-ready":   3,
+	"pilot-ready": 1,
+}
+
+// mitigationComplexity adds to the complexity score for the hardest mitigation required
+var mitigationComplexity = map[string]int{
+	"medium": 1,
+	"high":   2,
+}
+
+// EstimateEffort sizes each finding and aggregates the plan's effort using model.
+// Context mitigations are one-off work, so their effort is counted once in the summary.
+func (p *MigrationPlan) EstimateEffort(rules *MigrationRules, model string) error {
+	multiplier := 1.0
+	switch model {
+	case EffortModelFlat, EffortModelStandard:
+	case EffortModelConservative:
+		multiplier = 1.5
+	default:
+		return fmt.Errorf("unsupported effort model '%s'. Use: flat, standard, conservative", model)
+	}
+
+	p.Summary.EffortModel = model
+	p.Summary.FindingEffortDays = 0
+	p.Summary.MitigationEffortDays = 0
+
+	mitigationsSeen := make(map[string]bool)
+	for i := range p.Findings {
+		finding := &p.Findings[i]
+		finding.EffortDays = 0
+		finding.ComplexityScore = 0
+
+		maxMitigation := 0
+		for _, name := range finding.Mitigations {
+			mitigation, ok := rules.Mitigations[name]
+			if !ok {
+				continue
+			}
+			if c := mitigationComplexity[mitigation.Complexity]; c > maxMitigation {
+				maxMitigation = c
+			}
+			if !mitigationsSeen[name] {
+				mitigationsSeen[name] = true
+				p.Summary.MitigationEffortDays += ParseEffortDays(mitigation.Effort) * multiplier
+			}
+		}
+
+		if !needsMigration(*finding) {
+			continue
+		}
+
+		base, ok := baseEffortDays[finding.Type]
+		if !ok {
+			base = 2
+		}
+		readiness, ok := readinessMultipliers[finding.Readiness]
+		if !ok {
+			readiness = 1.0
+		}
+		if model == EffortModelFlat {
+			base, readiness = 1, 1
+		}
+		finding.EffortDays = roundDays(base * readiness * multiplier)
+
+		complexity, ok := baseComplexity[finding.Type]
+		if !ok {
+			complexity = 2
+		}
+		complexity += readinessComplexity[finding.Readiness] + maxMitigation
+		if finding.Priority == "critical" {
+			complexity++
+		}
+		if complexity > 10 {
+			complexity = 10
+		}
+		finding.ComplexityScore = complexity
+
+		p.Summary.FindingEffortDays += finding.EffortDays
+	}
+
+	p.Summary.FindingEffortDays = roundDays(p.Summary.FindingEffortDays)
+	p.Summary.MitigationEffortDays = roundDays(p.Summary.MitigationEffortDays)
+	p.Summary.TotalEffortDays = roundDays(p.Summary.FindingEffortDays + p.Summary.MitigationEffortDays)
+	return nil
+}
+
+// needsMigration reports whether a finding requires migration work at all
+func needsMigration(f MigrationFinding) bool {
+	if f.Type == "PostQuantum" || f.Type == "Hybrid" {
+		return false
+	}
+	return f.Priority != "none"
+}
+
+// ParseEffortDays converts rule effort strings such as "1 day", "2 weeks" or "0" to working days.
+// Open-ended values such as "Ongoing" count as zero.
+func ParseEffortDays(effort string) float64 {
+	fields := strings.Fields(strings.ToLower(effort))
+	if len(fields) == 0 {
+		return 0
+	}
+	n, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	if len(fields) == 1 {
+		return n
+	}
+	switch strings.TrimSuffix(fields[1], "s") {
+	case "day":
+		return n
+	case "week":
+		return n * 5
+	case "month":
+		return n * 20
+	}
+	return 0
+}
+
+// roundDays rounds to a quarter day
+func roundDays(days float64) float64 {
+	return math.Round(days*4) / 4
+}
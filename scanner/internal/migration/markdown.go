@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RenderPlanMarkdown renders a migration plan as a Markdown report for tickets and wikis.
+// Tables and findings are sorted so that reports of the same plan diff cleanly.
+func RenderPlanMarkdown(plan *MigrationPlan) string {
+	var b strings.Builder
+	summary := plan.Summary
+
+	b.WriteString("# PQC Migration Plan\n\n")
+	if summary.DeploymentContext != "" {
+		fmt.Fprintf(&b, "- **Deployment context:** %s\n", summary.DeploymentContext)
+	}
+	if summary.TargetTimeline != "" {
+		fmt.Fprintf(&b, "- **Target timeline:** %s\n", summary.TargetTimeline)
+	}
+	fmt.Fprintf(&b, "- **Total findings:** %d\n", summary.TotalFindings)
+	fmt.Fprintf(&b, "- **Crypto-agility score:** %d/100 (%s)\n", summary.CryptoAgilityScore, summary.CryptoAgilityGrade)
+	if summary.EffortModel != "" {
+		fmt.Fprintf(&b, "- **Estimated effort (%s model):** %.2f days (findings %.2f, mitigations %.2f)\n",
+			summary.EffortModel, summary.TotalEffortDays, summary.FindingEffortDays, summary.MitigationEffortDays)
+	}
+
+	b.WriteString("\n## Summary\n")
+	writeCountTable(&b, "By Priority", "Priority", summary.ByPriority, priorityRank)
+	writeCountTable(&b, "By Readiness", "Readiness", summary.ByReadiness, nil)
+	writeCountTable(&b, "By HNDL Risk", "HNDL Risk", summary.ByHNDLRisk, nil)
+
+	if len(summary.Phases) > 0 {
+		b.WriteString("\n## Migration Phases\n\n")
+		b.WriteString("| Phase | Name | Findings | Sequence | Description |\n")
+		b.WriteString("|---|---|---|---|---|\n")
+		for _, phase := range summary.Phases {
+			fmt.Fprintf(&b, "| %d | %s | %d | %d-%d | %s |\n", phase.Order, markdownCell(phase.Name),
+				phase.FindingCount, phase.FirstSequence, phase.LastSequence, markdownCell(phase.Description))
+		}
+	}
+
+	b.WriteString("\n## Findings\n")
+	if len(plan.Findings) == 0 {
+		b.WriteString("\nNo findings.\n")
+		return b.String()
+	}
+
+	groups := make(map[string][]MigrationFinding)
+	for _, finding := range plan.Findings {
+		groups[finding.Priority] = append(groups[finding.Priority], finding)
+	}
+
+	for _, priority := range sortedByRank(groups, priorityRank) {
+		findings := groups[priority]
+		sort.SliceStable(findings, func(i, j int) bool {
+			a, b := findings[i], findings[j]
+			if (a.Sequence == 0) != (b.Sequence == 0) {
+				return b.Sequence == 0
+			}
+			if a.Sequence != b.Sequence {
+				return a.Sequence < b.Sequence
+			}
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			return a.Algorithm < b.Algorithm
+		})
+
+		fmt.Fprintf(&b, "\n### Priority: %s (%d)\n\n", priorityLabel(priority), len(findings))
+		b.WriteString("| # | File | Algorithm | Type | Target | Timeline | Readiness | Caveats | Mitigations |\n")
+		b.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+		for _, f := range findings {
+			sequence := "-"
+			if f.Sequence > 0 {
+				sequence = fmt.Sprint(f.Sequence)
+			}
+			file := f.File
+			if f.Simulated {
+				file += " (simulated)"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				sequence, markdownCell(file), markdownCell(f.Algorithm), markdownCell(f.Type),
+				markdownCell(f.TargetAlgorithm), markdownCell(f.Timeline), markdownCell(f.Readiness),
+				markdownList(f.Caveats), markdownList(f.Mitigations))
+		}
+	}
+
+	return b.String()
+}
+
+// writeCountTable writes a two-column count table, ordered by rank (when given) and then name
+func writeCountTable(b *strings.Builder, title, column string, counts map[string]int, rank func(string) int) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s\n\n| %s | Findings |\n|---|---|\n", title, column)
+	for _, key := range sortedByRank(counts, rank) {
+		fmt.Fprintf(b, "| %s | %d |\n", markdownCell(key), counts[key])
+	}
+}
+
+// sortedByRank returns the keys of m ordered by rank and then name; a nil rank sorts by name only
+func sortedByRank[V any](m map[string]V, rank func(string) int) []string {
+	keys := sortedKeys(m)
+	if rank != nil {
+		sort.SliceStable(keys, func(i, j int) bool { return rank(keys[i]) < rank(keys[j]) })
+	}
+	return keys
+}
+
+// priorityLabel names a priority group, including findings without one
+func priorityLabel(priority string) string {
+	if priority == "" {
+		return "unspecified"
+	}
+	return priority
+}
+
+// markdownCell escapes a value for use in a Markdown table cell
+func markdownCell(s string) string {
+	if s == "" {
+		return "-"
+	}
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// markdownList renders a sorted, comma-separated list for a table cell
+func markdownList(items []string) string {
+	sorted := append([]string(nil), items...)
+	sort.Strings(sorted)
+	return markdownCell(strings.Join(sorted, ", "))
+}
@@ -0,0 +1,97 @@
+package migration
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"qvs-pro/scanner/internal/crypto"
+)
+
+// signatureFamilies are public-key algorithms looked up in the signatures matrix before
+// key exchange. ECC, an elliptic-curve key of unknown use, is mostly an ECDSA key.
+var signatureFamilies = map[string]bool{
+	"ECDSA": true,
+	"ECC":   true,
+	"DSA":   true,
+	"EDDSA": true,
+}
+
+// nameSeparators splits algorithm names into words
+var nameSeparators = regexp.MustCompile(`[\s_/+]+`)
+
+// canonicalAlgorithm upper-cases a name, normalizes separators to "-" and resolves
+// synonyms with crypto.CanonicalAlgorithm, both for the whole name and for its
+// leading family word ("ECDHE-P256" becomes "ECDH-P256")
+func canonicalAlgorithm(name string) string {
+	name = strings.ToUpper(crypto.CanonicalAlgorithm(name))
+	name = nameSeparators.ReplaceAllString(name, "-")
+	if family, rest, found := strings.Cut(name, "-"); found {
+		name = strings.ToUpper(crypto.CanonicalAlgorithm(family)) + "-" + rest
+	}
+	return name
+}
+
+// matrixSections returns the migration matrix sections to search for a finding type, in order.
+// The purpose of a public-key finding decides between signature and key exchange targets.
+func matrixSections(algorithm, algType, purpose string, matrix MigrationMatrix) []map[string]AlgorithmMapping {
+	if crypto.NormalizeType(algType) == crypto.TypePublicKey {
+		switch purpose {
+		case crypto.PurposeSigning:
+			return []map[string]AlgorithmMapping{matrix.Signatures}
+		case crypto.PurposeKeyEncapsulation, crypto.PurposeEncrypt:
+			return []map[string]AlgorithmMapping{matrix.KeyExchange}
+		}
+	}
+
+	switch strings.ToLower(algType) {
+	case "key exchange", "key establishment":
+		return []map[string]AlgorithmMapping{matrix.KeyExchange}
+	case "signature", "digital signature":
+		return []map[string]AlgorithmMapping{matrix.Signatures}
+	case "hash", "hashing":
+		return []map[string]AlgorithmMapping{matrix.Hashing}
+	case "hybrid":
+		return []map[string]AlgorithmMapping{matrix.Hybrid}
+	case "encryption", "cipher", "symmetric", "symmetrickey":
+		return []map[string]AlgorithmMapping{matrix.Symmetric}
+	case "publickey":
+		family, _, _ := strings.Cut(canonicalAlgorithm(algorithm), "-")
+		if signatureFamilies[family] {
+			return []map[string]AlgorithmMapping{matrix.Signatures, matrix.KeyExchange}
+		}
+		return []map[string]AlgorithmMapping{matrix.KeyExchange, matrix.Signatures}
+	}
+	return nil
+}
+
+// matchMapping finds the mapping for algorithm in one matrix section. It tries, in order:
+// an exact key, a key equal after alias resolution, then the first key (sorted) that
+// refines the algorithm on a word boundary, e.g. "ECDSA" selects "ECDSA-P256".
+// A more specific algorithm never matches a broader key, so "AES-128" does not match "AES".
+func matchMapping(algorithm string, section map[string]AlgorithmMapping) *AlgorithmMapping {
+	if mapping, ok := section[algorithm]; ok {
+		return &mapping
+	}
+
+	canonical := canonicalAlgorithm(algorithm)
+	keys := make([]string, 0, len(section))
+	for key := range section {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if canonicalAlgorithm(key) == canonical {
+			mapping := section[key]
+			return &mapping
+		}
+	}
+	for _, key := range keys {
+		if strings.HasPrefix(canonicalAlgorithm(key), canonical+"-") {
+			mapping := section[key]
+			return &mapping
+		}
+	}
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"strings"
 
 	"gopkg.in/yaml.v2"
 	"qvs-pro/scanner/internal/crypto"
@@ -13,12 +12,15 @@ import (
 
 // MigrationRules represents the complete migration rules configuration
 type MigrationRules struct {
-	Version          string                       `yaml:"version"`
-	LastUpdated      string                       `yaml:"last_updated"`
-	MigrationMatrix  MigrationMatrix              `yaml:"migration_matrix"`
+	Version            string                       `yaml:"version"`
+	LastUpdated        string                       `yaml:"last_updated"`
+	MigrationMatrix    MigrationMatrix              `yaml:"migration_matrix"`
 	DeploymentContexts map[string]DeploymentContext `yaml:"deployment_contexts"`
-	Caveats          map[string]Caveat            `yaml:"caveats"`
-	Mitigations      map[string]Mitigation        `yaml:"mitigations"`
+	Caveats            map[string]Caveat            `yaml:"caveats"`
+	Mitigations        map[string]Mitigation        `yaml:"mitigations"`
+	PriorityLevels     map[string]PriorityLevel     `yaml:"priority_levels"`
+	ReadinessLevels    map[string]ReadinessLevel    `yaml:"readiness_levels"`
+	Output             OutputConfig                 `yaml:"output"`
 }
 
 type MigrationMatrix struct {
@@ -26,6 +28,7 @@ type MigrationMatrix struct {
 	Signatures  map[string]AlgorithmMapping `yaml:"signatures"`
 	Symmetric   map[string]AlgorithmMapping `yaml:"symmetric"`
 	Hashing     map[string]AlgorithmMapping `yaml:"hashing"`
+	Hybrid      map[string]AlgorithmMapping `yaml:"hybrid"`
 }
 
 type AlgorithmMapping struct {
@@ -36,10 +39,12 @@ type AlgorithmMapping struct {
 }
 
 type DeploymentContext struct {
-	Description    string   `yaml:"description"`
-	Caveats        []string `yaml:"caveats"`
-	Mitigations    []string `yaml:"mitigations"`
-	ReadinessLevel string   `yaml:"readiness_level"`
+	Description        string   `yaml:"description"`
+	Caveats            []string `yaml:"caveats"`
+	Mitigations        []string `yaml:"mitigations"`
+	ReadinessLevel     string   `yaml:"readiness_level"`
+	DefaultTimeline    string   `yaml:"default_timeline"`    // Replaces mapping timelines in this context
+	TimelineMultiplier float64  `yaml:"timeline_multiplier"` // Scales mapping timelines from the earliest matrix quarter; ignored with default_timeline
 }
 
 type Caveat struct {
@@ -53,6 +58,26 @@ type Mitigation struct {
 	Category    string `yaml:"category"`
 	Description string `yaml:"description"`
 	Effort      string `yaml:"effort"`
+	Complexity  string `yaml:"complexity"`
+}
+
+type PriorityLevel struct {
+	Description string   `yaml:"description"`
+	Timeline    string   `yaml:"timeline"`
+	Examples    []string `yaml:"examples"`
+}
+
+type ReadinessLevel struct {
+	Description string `yaml:"description"`
+	Action      string `yaml:"action"`
+}
+
+type OutputConfig struct {
+	MigrationPlanFile           string `yaml:"migration_plan_file"`
+	FormatVersion               string `yaml:"format_version"`
+	IncludePerformanceEstimates bool   `yaml:"include_performance_estimates"`
+	IncludeEffortEstimates      bool   `yaml:"include_effort_estimates"`
+	IncludeTimelineSuggestions  bool   `yaml:"include_timeline_suggestions"`
 }
 
 // MigrationPlan is the output structure
@@ -65,6 +90,7 @@ type MigrationFinding struct {
 	File              string   `json:"file"`
 	Algorithm         string   `json:"algorithm"`
 	Type              string   `json:"type"`
+	Purpose           string   `json:"purpose,omitempty"` // signing, keyEncapsulation, encrypt or hash
 	Risk              string   `json:"risk"`
 	TargetAlgorithm   string   `json:"target_algorithm"`
 	Readiness         string   `json:"readiness"`
@@ -73,29 +99,73 @@ type MigrationFinding struct {
 	Priority          string   `json:"priority"`
 	Timeline          string   `json:"timeline"`
 	DeploymentContext string   `json:"deployment_context,omitempty"`
+	HNDLRisk          string   `json:"hndl_risk,omitempty"`
+	EffortDays        float64  `json:"effort_days"`
+	ComplexityScore   int      `json:"complexity_score"` // 1 (trivial) to 10 (hard); 0 when no migration is needed
+	ChainPosition     string   `json:"chain_position,omitempty"`
+	Component         string   `json:"component,omitempty"` // Package URL of the SBOM component the finding belongs to
+	Phase             int      `json:"phase"`               // Order of the migration phase; 0 when no migration is needed
+	Sequence          int      `json:"sequence"`            // Position in the dependency-ordered migration, from 1
+	Simulated         bool     `json:"simulated,omitempty"` // Planned from a fallback placeholder, not an observed finding
 }
 
 type MigrationSummary struct {
-	TotalFindings     int               `json:"total_findings"`
-	ByPriority        map[string]int    `json:"by_priority"`
-	ByReadiness       map[string]int    `json:"by_readiness"`
-	DeploymentContext string            `json:"deployment_context,omitempty"`
-	TargetTimeline    string            `json:"target_timeline,omitempty"`
+	TotalFindings        int              `json:"total_findings"`
+	ByPriority           map[string]int   `json:"by_priority"`
+	ByReadiness          map[string]int   `json:"by_readiness"`
+	ByHNDLRisk           map[string]int   `json:"by_hndl_risk"`
+	DeploymentContext    string           `json:"deployment_context,omitempty"`
+	TargetTimeline       string           `json:"target_timeline,omitempty"`
+	EffortModel          string           `json:"effort_model"`
+	FindingEffortDays    float64          `json:"finding_effort_days"`
+	MitigationEffortDays float64          `json:"mitigation_effort_days"`
+	TotalEffortDays      float64          `json:"total_effort_days"`
+	Phases               []MigrationPhase `json:"phases"`
+	CryptoAgilityScore   int              `json:"crypto_agility_score"` // 0-100, see crypto.CryptoAgilityScore
+	CryptoAgilityGrade   string           `json:"crypto_agility_grade"`
 }
 
-// LoadRules loads migration rules from YAML file
+// LoadRules loads migration rules from YAML file.
+// Unknown keys and invalid values are reported as ValidationErrors.
 func LoadRules(filepath string) (*MigrationRules, error) {
 	data, err := ioutil.ReadFile(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read rules file: %w", err)
 	}
 
-	var rules MigrationRules
-	if err := yaml.Unmarshal(data, &rules); err != nil {
-		return nil, fmt.Errorf("failed to parse rules YAML: %w", err)
+	return ParseRules(data)
+}
+
+// ParseRules parses and validates migration rules YAML
+func ParseRules(data []byte) (*MigrationRules, error) {
+	rules, errs, err := decodeRules(data)
+	if err != nil {
+		return nil, err
+	}
+
+	errs = append(errs, rules.Validate()...)
+	if len(errs) > 0 {
+		return nil, errs
 	}
 
-	return &rules, nil
+	return rules, nil
+}
+
+// decodeRules strictly decodes rules YAML without validating it.
+// Unknown keys (typos) are returned as validation errors; the rest of the document is still decoded.
+func decodeRules(data []byte) (*MigrationRules, ValidationErrors, error) {
+	var rules MigrationRules
+	var errs ValidationErrors
+	if err := yaml.UnmarshalStrict(data, &rules); err != nil {
+		typeErr, ok := err.(*yaml.TypeError)
+		if !ok {
+			return nil, nil, fmt.Errorf("failed to parse rules YAML: %w", err)
+		}
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, ValidationError{Path: "(document)", Message: msg})
+		}
+	}
+	return &rules, errs, nil
 }
 
 // GeneratePlan generates a migration plan from scan results
@@ -105,6 +175,7 @@ func GeneratePlan(results []crypto.Result, rules *MigrationRules, context, timel
 		Summary: MigrationSummary{
 			ByPriority:        make(map[string]int),
 			ByReadiness:       make(map[string]int),
+			ByHNDLRisk:        make(map[string]int),
 			DeploymentContext: context,
 			TargetTimeline:    timeline,
 		},
@@ -117,6 +188,7 @@ func GeneratePlan(results []crypto.Result, rules *MigrationRules, context, timel
 			contextInfo = &ctx
 		}
 	}
+	baseQuarter := rules.earliestQuarter()
 
 	for _, result := range results {
 		finding := MigrationFinding{
@@ -125,14 +197,27 @@ func GeneratePlan(results []crypto.Result, rules *MigrationRules, context, timel
 			Type:              result.Type,
 			Risk:              result.Risk,
 			DeploymentContext: context,
+			HNDLRisk:          result.HNDLRisk,
+			ChainPosition:     result.ChainPosition,
+			Component:         result.PURL,
+			Purpose:           result.Purpose,
+			Simulated:         result.Simulated,
+		}
+
+		if finding.Purpose == "" {
+			finding.Purpose = crypto.AssessPurpose(result)
+		}
+
+		if finding.HNDLRisk == "" {
+			finding.HNDLRisk = crypto.AssessHNDLRisk(result.Algorithm, result.Type)
 		}
 
 		// Find matching algorithm in migration matrix
-		mapping := findAlgorithmMapping(result.Algorithm, result.Type, rules)
+		mapping := findAlgorithmMapping(result.Algorithm, result.Type, finding.Purpose, rules)
 		if mapping != nil {
 			finding.TargetAlgorithm = mapping.Target
 			finding.Priority = mapping.Priority
-			finding.Timeline = mapping.Timeline
+			finding.Timeline = contextTimeline(mapping.Timeline, contextInfo, baseQuarter)
 
 			// Override timeline if user specified one
 			if timeline != "" {
@@ -159,58 +244,22 @@ func GeneratePlan(results []crypto.Result, rules *MigrationRules, context, timel
 		// Update summary counts
 		plan.Summary.ByPriority[finding.Priority]++
 		plan.Summary.ByReadiness[finding.Readiness]++
+		plan.Summary.ByHNDLRisk[finding.HNDLRisk]++
 	}
 
 	plan.Summary.TotalFindings = len(plan.Findings)
+	plan.Summary.CryptoAgilityScore, plan.Summary.CryptoAgilityGrade = crypto.CryptoAgilityScore(results)
+	plan.EstimateEffort(rules, EffortModelStandard)
+	plan.AssignSequence()
 
 	return plan
 }
 
-// findAlgorithmMapping finds the migration mapping for an algorithm
-func findAlgorithmMapping(algorithm, algType string, rules *MigrationRules) *AlgorithmMapping {
-	algoUpper := strings.ToUpper(algorithm)
-
-	// Try to match based on type
-	switch strings.ToLower(algType) {
-	case "key exchange", "key establishment":
-		if mapping, ok := rules.MigrationMatrix.KeyExchange[algorithm]; ok {
-			return &mapping
-		}
-		// Try variations
-		for key, mapping := range rules.MigrationMatrix.KeyExchange {
-			if strings.Contains(algoUpper, strings.ToUpper(key)) {
-				return &mapping
-			}
-		}
-
-	case "signature", "digital signature":
-		if mapping, ok := rules.MigrationMatrix.Signatures[algorithm]; ok {
-			return &mapping
-		}
-		for key, mapping := range rules.MigrationMatrix.Signatures {
-			if strings.Contains(algoUpper, strings.ToUpper(key)) {
-				return &mapping
-			}
-		}
-
-	case "hash", "hashing":
-		if mapping, ok := rules.MigrationMatrix.Hashing[algorithm]; ok {
-			return &mapping
-		}
-		for key, mapping := range rules.MigrationMatrix.Hashing {
-			if strings.Contains(algoUpper, strings.ToUpper(key)) {
-				return &mapping
-			}
-		}
-
-	case "encryption", "cipher", "symmetric":
-		if mapping, ok := rules.MigrationMatrix.Symmetric[algorithm]; ok {
-			return &mapping
-		}
-		for key, mapping := range rules.MigrationMatrix.Symmetric {
-			if strings.Contains(algoUpper, strings.ToUpper(key)) {
-				return &mapping
-			}
+// findAlgorithmMapping finds the migration mapping for an algorithm used for purpose
+func findAlgorithmMapping(algorithm, algType, purpose string, rules *MigrationRules) *AlgorithmMapping {
+	for _, section := range matrixSections(algorithm, algType, purpose, rules.MigrationMatrix) {
+		if mapping := matchMapping(algorithm, section); mapping != nil {
+			return mapping
 		}
 	}
 
@@ -0,0 +1,129 @@
+package migration
+
+import (
+	"sort"
+	"strings"
+
+	"qvs-pro/scanner/internal/crypto"
+)
+
+// MigrationPhase is one step of the ordered migration, summarizing the findings it contains
+type MigrationPhase struct {
+	Order         int    `json:"order"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	FindingCount  int    `json:"finding_count"`
+	FirstSequence int    `json:"first_sequence"`
+	LastSequence  int    `json:"last_sequence"`
+}
+
+// migrationPhases orders the work so that nothing is migrated before what it depends on:
+// trust anchors before the certificates they issue, key exchange and signatures before the
+// symmetric and hashing layers built on them, and application configuration last.
+var migrationPhases = []MigrationPhase{
+	{Order: 1, Name: "Root CAs", Description: "Re-issue trust anchors so that new certificates can chain to a quantum-safe root"},
+	{Order: 2, Name: "Intermediate CAs", Description: "Re-issue intermediate certificates under the migrated roots"},
+	{Order: 3, Name: "Key exchange", Description: "Move key establishment and protocols to hybrid or post-quantum KEMs"},
+	{Order: 4, Name: "Signatures and leaf certificates", Description: "Replace signing keys and end-entity certificates"},
+	{Order: 5, Name: "Symmetric ciphers", Description: "Upgrade ciphers and key sizes"},
+	{Order: 6, Name: "Hashing", Description: "Upgrade hash functions"},
+	{Order: 7, Name: "Application configuration", Description: "Embedded keys, random number generation and remaining settings"},
+}
+
+// priorityOrder ranks priorities, most urgent first
+var priorityOrder = map[string]int{
+	"critical": 0,
+	"high":     1,
+	"medium":   2,
+	"low":      3,
+}
+
+// findingPhase returns the migration phase order of a finding, or 0 when it needs no migration
+func findingPhase(f MigrationFinding) int {
+	if !needsMigration(f) {
+		return 0
+	}
+
+	switch f.ChainPosition {
+	case crypto.ChainPositionRoot:
+		return 1
+	case crypto.ChainPositionIntermediate:
+		return 2
+	case crypto.ChainPositionLeaf:
+		return 4
+	}
+
+	switch f.Type {
+	case "Protocol":
+		return 3
+	case "PublicKey":
+		// Same split as the matrix lookup: signature families migrate with signatures,
+		// everything else (RSA, DH, ECDHE) with key exchange
+		family, _, _ := strings.Cut(canonicalAlgorithm(f.Algorithm), "-")
+		if signatureFamilies[family] {
+			return 4
+		}
+		return 3
+	case "SymmetricKey":
+		return 5
+	case "Hash", "KDF":
+		return 6
+	}
+	return 7
+}
+
+// AssignSequence orders findings by dependency and numbers them from 1.
+// Findings are ordered by phase; within a phase, third-party components (identified by
+// an SBOM package URL) come before the first-party code that uses them, then by priority.
+// Findings that need no migration keep sequence 0. The order of plan.Findings is unchanged.
+func (p *MigrationPlan) AssignSequence() {
+	order := make([]int, 0, len(p.Findings))
+	for i := range p.Findings {
+		p.Findings[i].Phase = findingPhase(p.Findings[i])
+		p.Findings[i].Sequence = 0
+		if p.Findings[i].Phase > 0 {
+			order = append(order, i)
+		}
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		fa, fb := p.Findings[order[a]], p.Findings[order[b]]
+		if fa.Phase != fb.Phase {
+			return fa.Phase < fb.Phase
+		}
+		if (fa.Component != "") != (fb.Component != "") {
+			return fa.Component != ""
+		}
+		if ra, rb := priorityRank(fa.Priority), priorityRank(fb.Priority); ra != rb {
+			return ra < rb
+		}
+		if fa.File != fb.File {
+			return fa.File < fb.File
+		}
+		return fa.Algorithm < fb.Algorithm
+	})
+
+	p.Summary.Phases = make([]MigrationPhase, 0)
+	for n, i := range order {
+		finding := &p.Findings[i]
+		finding.Sequence = n + 1
+
+		last := len(p.Summary.Phases) - 1
+		if last < 0 || p.Summary.Phases[last].Order != finding.Phase {
+			phase := migrationPhases[finding.Phase-1]
+			phase.FirstSequence = finding.Sequence
+			p.Summary.Phases = append(p.Summary.Phases, phase)
+			last++
+		}
+		p.Summary.Phases[last].FindingCount++
+		p.Summary.Phases[last].LastSequence = finding.Sequence
+	}
+}
+
+// priorityRank returns the sort rank of a priority; unknown priorities sort last
+func priorityRank(priority string) int {
+	if rank, ok := priorityOrder[priority]; ok {
+		return rank
+	}
+	return len(priorityOrder)
+}
@@ -0,0 +1,98 @@
+package migration
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// timelineParts captures the start quarter, open-ended marker and optional end quarter of a timeline
+var timelineParts = regexp.MustCompile(`^(\d{4})-Q([1-4])(?:(\+)| to (?:(\d{4})-)?Q([1-4]))?$`)
+
+// contextTimeline returns the timeline for a finding under a deployment context, applying
+// the context's default timeline, or else its multiplier, to the mapping timeline.
+// The --timeline flag takes precedence over both and is applied by the caller.
+func contextTimeline(mappingTimeline string, ctx *DeploymentContext, base int) string {
+	if ctx == nil {
+		return mappingTimeline
+	}
+	if ctx.DefaultTimeline != "" {
+		return ctx.DefaultTimeline
+	}
+	if ctx.TimelineMultiplier > 0 && ctx.TimelineMultiplier != 1 {
+		return scaleTimeline(mappingTimeline, base, ctx.TimelineMultiplier)
+	}
+	return mappingTimeline
+}
+
+// scaleTimeline stretches (multiplier > 1) or compresses (< 1) the distance of each quarter
+// in a timeline from the base quarter. Timelines that aren't quarters, such as "N/A",
+// are returned unchanged.
+func scaleTimeline(timeline string, base int, multiplier float64) string {
+	m := timelineParts.FindStringSubmatch(timeline)
+	if m == nil {
+		return timeline
+	}
+	start := quarterIndex(m[1], m[2])
+	scale := func(q int) int {
+		scaled := base + int(math.Round(float64(q-base)*multiplier))
+		if scaled < base {
+			return base
+		}
+		return scaled
+	}
+
+	scaled := formatQuarter(scale(start))
+	switch {
+	case m[3] == "+":
+		scaled += "+"
+	case m[5] != "":
+		endYear := m[4]
+		if endYear == "" {
+			endYear = m[1]
+		}
+		end := scale(quarterIndex(endYear, m[5]))
+		if end/4 == scale(start)/4 {
+			scaled += fmt.Sprintf(" to Q%d", end%4+1)
+		} else {
+			scaled += " to " + formatQuarter(end)
+		}
+	}
+	return scaled
+}
+
+// earliestQuarter returns the earliest start quarter in the migration matrix,
+// the fixed point that timeline multipliers scale from
+func (r *MigrationRules) earliestQuarter() int {
+	earliest := -1
+	sections := []map[string]AlgorithmMapping{
+		r.MigrationMatrix.KeyExchange,
+		r.MigrationMatrix.Signatures,
+		r.MigrationMatrix.Symmetric,
+		r.MigrationMatrix.Hashing,
+		r.MigrationMatrix.Hybrid,
+	}
+	for _, section := range sections {
+		for _, mapping := range section {
+			if m := timelineParts.FindStringSubmatch(mapping.Timeline); m != nil {
+				if q := quarterIndex(m[1], m[2]); earliest < 0 || q < earliest {
+					earliest = q
+				}
+			}
+		}
+	}
+	return earliest
+}
+
+// quarterIndex converts a year and quarter number to a count of quarters
+func quarterIndex(year, quarter string) int {
+	y, _ := strconv.Atoi(year)
+	q, _ := strconv.Atoi(quarter)
+	return y*4 + q - 1
+}
+
+// formatQuarter converts a quarter count back to "YYYY-Qn"
+func formatQuarter(index int) string {
+	return fmt.Sprintf("%d-Q%d", index/4, index%4+1)
+}
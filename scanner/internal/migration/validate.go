@@ -0,0 +1,134 @@
+package migration
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultPriorities are the valid priorities when the rules define no priority_levels
+var defaultPriorities = []string{"critical", "high", "medium", "low", "none"}
+
+// timelinePattern accepts quarters ("2025-Q2"), open-ended quarters ("2026-Q2+"),
+// ranges ("2025-Q2 to Q3", "2025-Q4 to 2026-Q1") and "N/A"
+var timelinePattern = regexp.MustCompile(`^(N/A|\d{4}-Q[1-4](\+| to (\d{4}-)?Q[1-4])?)$`)
+
+// ValidationError is a single problem in a rules file, located by its YAML path
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors lists every problem found in a rules file
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = "  " + err.Error()
+	}
+	return fmt.Sprintf("invalid migration rules (%d problems):\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// Validate checks required sections, algorithm mappings and context references
+func (r *MigrationRules) Validate() ValidationErrors {
+	var errs ValidationErrors
+	add := func(path, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if r.Version == "" {
+		add("version", "required field is missing")
+	}
+
+	priorities := make(map[string]bool)
+	for _, p := range defaultPriorities {
+		priorities[p] = true
+	}
+	if len(r.PriorityLevels) > 0 {
+		priorities = make(map[string]bool)
+		for p := range r.PriorityLevels {
+			priorities[p] = true
+		}
+	}
+
+	sections := []struct {
+		name     string
+		mappings map[string]AlgorithmMapping
+		required bool
+	}{
+		{"key_exchange", r.MigrationMatrix.KeyExchange, true},
+		{"signatures", r.MigrationMatrix.Signatures, true},
+		{"symmetric", r.MigrationMatrix.Symmetric, true},
+		{"hashing", r.MigrationMatrix.Hashing, true},
+		{"hybrid", r.MigrationMatrix.Hybrid, false},
+	}
+	for _, section := range sections {
+		path := "migration_matrix." + section.name
+		if len(section.mappings) == 0 {
+			if section.required {
+				add(path, "required section is missing or empty")
+			}
+			continue
+		}
+		for _, algorithm := range sortedKeys(section.mappings) {
+			mapping := section.mappings[algorithm]
+			mappingPath := path + "." + algorithm
+			if strings.TrimSpace(mapping.Target) == "" {
+				add(mappingPath+".target", "must not be empty")
+			}
+			if !priorities[mapping.Priority] {
+				add(mappingPath+".priority", "invalid priority %q (valid: %s)", mapping.Priority, strings.Join(sortedKeys(priorities), ", "))
+			}
+			if !timelinePattern.MatchString(mapping.Timeline) {
+				add(mappingPath+".timeline", "invalid timeline %q (expected e.g. 2025-Q2, 2026-Q2+, 2025-Q2 to Q3 or N/A)", mapping.Timeline)
+			}
+		}
+	}
+
+	if len(r.DeploymentContexts) == 0 {
+		add("deployment_contexts", "required section is missing or empty")
+	}
+	for _, name := range sortedKeys(r.DeploymentContexts) {
+		ctx := r.DeploymentContexts[name]
+		path := "deployment_contexts." + name
+		for i, caveat := range ctx.Caveats {
+			if _, ok := r.Caveats[caveat]; !ok {
+				add(fmt.Sprintf("%s.caveats[%d]", path, i), "unknown caveat %q", caveat)
+			}
+		}
+		for i, mitigation := range ctx.Mitigations {
+			if _, ok := r.Mitigations[mitigation]; !ok {
+				add(fmt.Sprintf("%s.mitigations[%d]", path, i), "unknown mitigation %q", mitigation)
+			}
+		}
+		if ctx.DefaultTimeline != "" && !timelinePattern.MatchString(ctx.DefaultTimeline) {
+			add(path+".default_timeline", "invalid timeline %q (expected e.g. 2025-Q2, 2026-Q2+, 2025-Q2 to Q3 or N/A)", ctx.DefaultTimeline)
+		}
+		if ctx.TimelineMultiplier < 0 {
+			add(path+".timeline_multiplier", "must not be negative, got %g", ctx.TimelineMultiplier)
+		}
+		if len(r.ReadinessLevels) > 0 {
+			if _, ok := r.ReadinessLevels[ctx.ReadinessLevel]; !ok {
+				add(path+".readiness_level", "unknown readiness level %q", ctx.ReadinessLevel)
+			}
+		}
+	}
+
+	return errs
+}
+
+// sortedKeys returns map keys in sorted order so errors are reported deterministically
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,177 @@
+// Package review is the terminal UI of -review, which pages through the findings of
+// a scan so each can be accepted or suppressed. Model is a bubbletea model driven by
+// key and window size messages, so it can be tested without a terminal; Run
+// connects it to one.
+package review
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"qvs-pro/scanner/internal/crypto"
+)
+
+// suppressReason is recorded with suppressions added in the review
+const suppressReason = "Suppressed in -review"
+
+// headerLines and footerLines are the rows View uses around the finding list
+const (
+	headerLines = 2
+	footerLines = 5
+)
+
+// Model is the state of a review: the findings, which of them are suppressed and
+// the one selected
+type Model struct {
+	Findings     []crypto.Result
+	Suppressions *crypto.Suppressions
+	Cursor       int
+	PageSize     int  // Findings per page for page up and page down; kept in step with the terminal height
+	Saved        bool // Set when the user quit with q, asking for the suppressions to be written
+
+	width, height int    // Terminal size, 0 until the first tea.WindowSizeMsg
+	offset        int    // First finding shown
+	status        string // Error of the last editor opened, if it failed
+}
+
+// editorClosedMsg reports that the editor opened on a finding has exited
+type editorClosedMsg struct{ err error }
+
+// NewModel starts a review of findings, of which those in suppressions are marked
+// suppressed
+func NewModel(findings []crypto.Result, suppressions *crypto.Suppressions) *Model {
+	if suppressions == nil {
+		suppressions = &crypto.Suppressions{}
+	}
+	return &Model{Findings: findings, Suppressions: suppressions, PageSize: 10}
+}
+
+// Selected returns the finding under the cursor, if there are any findings
+func (m *Model) Selected() (crypto.Result, bool) {
+	if m.Cursor < 0 || m.Cursor >= len(m.Findings) {
+		return crypto.Result{}, false
+	}
+	return m.Findings[m.Cursor], true
+}
+
+// Init implements tea.Model
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model: key presses move through and triage the findings,
+// and the window size sets the page size
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.PageSize = max(1, m.height-headerLines-footerLines)
+	case editorClosedMsg:
+		m.status = ""
+		if msg.err != nil {
+			m.status = msg.err.Error()
+		}
+	case tea.KeyMsg:
+		return m, m.handleKey(msg)
+	}
+	return m, nil
+}
+
+// handleKey applies a key press and returns the command it starts, if any
+func (m *Model) handleKey(key tea.KeyMsg) tea.Cmd {
+	switch key.String() {
+	case "k", "up":
+		m.move(-1)
+	case "j", "down":
+		m.move(1)
+	case "b", "pgup":
+		m.move(-m.PageSize)
+	case " ", "pgdown":
+		m.move(m.PageSize)
+	case "s":
+		if finding, ok := m.Selected(); ok {
+			m.Suppressions.Suppress(finding, suppressReason)
+			m.move(1)
+		}
+	case "a":
+		if finding, ok := m.Selected(); ok {
+			m.Suppressions.Unsuppress(finding)
+			m.move(1)
+		}
+	case "o", "enter":
+		if finding, ok := m.Selected(); ok {
+			return openInEditor(finding.File, finding.Line)
+		}
+	case "q":
+		m.Saved = true
+		return tea.Quit
+	case "ctrl+c":
+		return tea.Quit
+	}
+	return nil
+}
+
+// move moves the cursor by delta findings, stopping at either end
+func (m *Model) move(delta int) {
+	m.Cursor += delta
+	if m.Cursor >= len(m.Findings) {
+		m.Cursor = len(m.Findings) - 1
+	}
+	if m.Cursor < 0 {
+		m.Cursor = 0
+	}
+}
+
+// View implements tea.Model. It renders the page of findings around the cursor,
+// then the selected finding's details and the key bindings, each line cut to the
+// terminal width.
+func (m *Model) View() string {
+	if m.Cursor < m.offset {
+		m.offset = m.Cursor
+	}
+	if m.Cursor >= m.offset+m.PageSize {
+		m.offset = m.Cursor - m.PageSize + 1
+	}
+
+	var b strings.Builder
+	line := func(text string) {
+		if m.width > 0 {
+			text = runewidth.Truncate(text, m.width, "")
+		}
+		b.WriteString(text + "\n")
+	}
+
+	suppressed := 0
+	for _, finding := range m.Findings {
+		if m.Suppressions.Suppresses(finding) {
+			suppressed++
+		}
+	}
+	line(fmt.Sprintf("Review: %d findings, %d suppressed", len(m.Findings), suppressed))
+	line("")
+	for i := m.offset; i < len(m.Findings) && i < m.offset+m.PageSize; i++ {
+		finding := m.Findings[i]
+		cursor, mark := " ", " "
+		if i == m.Cursor {
+			cursor = ">"
+		}
+		if m.Suppressions.Suppresses(finding) {
+			mark = "S"
+		}
+		line(fmt.Sprintf("%s [%s] %-8s %-16s %s:%d", cursor, mark, finding.Risk, finding.Algorithm, finding.File, finding.Line))
+	}
+
+	line(m.status)
+	if finding, ok := m.Selected(); ok {
+		line(finding.Method + ": " + finding.Description)
+		line("Fix: " + finding.Recommendation)
+	} else {
+		line("No findings to review")
+		line("")
+	}
+	line("j/k move  s suppress  a accept  o open  q save and quit  ctrl-c quit without saving")
+	return b.String()
+}
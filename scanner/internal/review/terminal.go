@@ -0,0 +1,49 @@
+package review
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+)
+
+// Run reviews m on the terminal of in and out until the user quits, and reports
+// whether they asked to save. The review takes the alternate screen, which is
+// handed to the editor while a finding is open.
+func Run(in, out *os.File, m *Model) (bool, error) {
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return false, fmt.Errorf("-review needs an interactive terminal")
+	}
+	program := tea.NewProgram(m, tea.WithInput(in), tea.WithOutput(out), tea.WithAltScreen())
+	if _, err := program.Run(); err != nil {
+		return false, fmt.Errorf("review failed: %w", err)
+	}
+	return m.Saved, nil
+}
+
+// openInEditor opens file at line in $VISUAL or $EDITOR (default: vi), which takes
+// the terminal until it exits and then reports back with an editorClosedMsg
+func openInEditor(file string, line int) tea.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+	if line > 0 {
+		args = append(args, "+"+strconv.Itoa(line))
+	}
+	cmd := exec.Command(args[0], append(args[1:], file)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			err = fmt.Errorf("failed to open %s in %s: %w", file, args[0], err)
+		}
+		return editorClosedMsg{err: err}
+	})
+}
@@ -0,0 +1,287 @@
+// Package sbom loads CycloneDX and SPDX SBOMs and correlates crypto findings
+// with the components they list, giving provenance for third-party crypto.
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"qvs-pro/scanner/internal/crypto"
+)
+
+// Component is a package listed in an SBOM together with the paths it occupies
+type Component struct {
+	Name      string
+	Version   string
+	PURL      string
+	Locations []string // Normalized path prefixes owned by the component
+}
+
+// SBOM is the set of components loaded from an SBOM document
+type SBOM struct {
+	Format     string // "CycloneDX" or "SPDX"
+	Components []Component
+}
+
+// manifestFiles are package manifests whose directory, rather than the file itself, belongs to the component
+var manifestFiles = map[string]bool{
+	"package.json":      true,
+	"package-lock.json": true,
+	"pom.xml":           true,
+	"pom.properties":    true,
+	"go.mod":            true,
+	"Cargo.toml":        true,
+	"composer.json":     true,
+	"setup.py":          true,
+	"pyproject.toml":    true,
+	"METADATA":          true,
+	"PKG-INFO":          true,
+	"RECORD":            true,
+}
+
+// Load reads a CycloneDX or SPDX JSON SBOM
+func Load(sbomPath string) (*SBOM, error) {
+	data, err := os.ReadFile(sbomPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading SBOM: %w", err)
+	}
+
+	var probe struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("error parsing SBOM %s: %w", sbomPath, err)
+	}
+
+	switch {
+	case strings.EqualFold(probe.BOMFormat, "CycloneDX"):
+		return parseCycloneDX(data)
+	case probe.SPDXVersion != "":
+		return parseSPDX(data)
+	default:
+		return nil, fmt.Errorf("unrecognized SBOM format in %s: expected CycloneDX or SPDX JSON", sbomPath)
+	}
+}
+
+// cycloneDXComponent is the subset of a CycloneDX component used for correlation
+type cycloneDXComponent struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	PURL       string `json:"purl"`
+	Properties []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"properties"`
+	Evidence struct {
+		Occurrences []struct {
+			Location string `json:"location"`
+		} `json:"occurrences"`
+	} `json:"evidence"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// parseCycloneDX extracts components and their locations from a CycloneDX document
+func parseCycloneDX(data []byte) (*SBOM, error) {
+	var doc struct {
+		Components []cycloneDXComponent `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing CycloneDX SBOM: %w", err)
+	}
+
+	result := &SBOM{Format: "CycloneDX"}
+	var walk func(components []cycloneDXComponent)
+	walk = func(components []cycloneDXComponent) {
+		for _, c := range components {
+			var locations []string
+			for _, occurrence := range c.Evidence.Occurrences {
+				locations = append(locations, occurrence.Location)
+			}
+			// Syft records file locations as "syft:location:<n>:path" properties
+			for _, property := range c.Properties {
+				if strings.HasPrefix(property.Name, "syft:location:") && strings.HasSuffix(property.Name, ":path") {
+					locations = append(locations, property.Value)
+				}
+			}
+			result.add(c.Name, c.Version, c.PURL, locations)
+			walk(c.Components)
+		}
+	}
+	walk(doc.Components)
+
+	return result, nil
+}
+
+// parseSPDX extracts packages and their locations from an SPDX document
+func parseSPDX(data []byte) (*SBOM, error) {
+	var doc struct {
+		Packages []struct {
+			SPDXID          string `json:"SPDXID"`
+			Name            string `json:"name"`
+			VersionInfo     string `json:"versionInfo"`
+			PackageFileName string `json:"packageFileName"`
+			SourceInfo      string `json:"sourceInfo"`
+			ExternalRefs    []struct {
+				ReferenceType    string `json:"referenceType"`
+				ReferenceLocator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Files []struct {
+			SPDXID   string `json:"SPDXID"`
+			FileName string `json:"fileName"`
+		} `json:"files"`
+		Relationships []struct {
+			Element          string `json:"spdxElementId"`
+			RelatedElement   string `json:"relatedSpdxElement"`
+			RelationshipType string `json:"relationshipType"`
+		} `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing SPDX SBOM: %w", err)
+	}
+
+	fileNames := make(map[string]string)
+	for _, f := range doc.Files {
+		fileNames[f.SPDXID] = f.FileName
+	}
+	containedFiles := make(map[string][]string)
+	for _, rel := range doc.Relationships {
+		if rel.RelationshipType == "CONTAINS" {
+			if name, ok := fileNames[rel.RelatedElement]; ok {
+				containedFiles[rel.Element] = append(containedFiles[rel.Element], name)
+			}
+		}
+	}
+
+	result := &SBOM{Format: "SPDX"}
+	for _, p := range doc.Packages {
+		purl := ""
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				purl = ref.ReferenceLocator
+				break
+			}
+		}
+
+		var locations []string
+		if p.PackageFileName != "" {
+			locations = append(locations, p.PackageFileName)
+		}
+		locations = append(locations, containedFiles[p.SPDXID]...)
+		// Syft writes "acquired package info from <source>: /path/a, /path/b"
+		if i := strings.LastIndex(p.SourceInfo, ": "); i >= 0 {
+			for _, location := range strings.Split(p.SourceInfo[i+2:], ", ") {
+				locations = append(locations, location)
+			}
+		}
+
+		result.add(p.Name, p.VersionInfo, purl, locations)
+	}
+
+	return result, nil
+}
+
+// add records a component if it has a usable location
+func (s *SBOM) add(name, version, purl string, locations []string) {
+	component := Component{Name: name, Version: version, PURL: purl}
+	for _, location := range locations {
+		if prefix := locationPrefix(location); prefix != "" {
+			component.Locations = append(component.Locations, prefix)
+		}
+	}
+	if len(component.Locations) > 0 {
+		s.Components = append(s.Components, component)
+	}
+}
+
+// locationPrefix normalizes an SBOM location into the path prefix the component owns
+func locationPrefix(location string) string {
+	location = normalizePath(location)
+	if location == "" {
+		return ""
+	}
+	if manifestFiles[path.Base(location)] {
+		if dir := path.Dir(location); dir != "." {
+			return dir
+		}
+		return ""
+	}
+	return location
+}
+
+// normalizePath converts a path to slash form without leading "/" or "./"
+func normalizePath(p string) string {
+	p = path.Clean(filepath.ToSlash(strings.TrimSpace(p)))
+	p = strings.TrimPrefix(p, "/")
+	if p == "." {
+		return ""
+	}
+	return p
+}
+
+// Match returns the component owning filePath (relative to a scan root), preferring the longest prefix
+func (s *SBOM) Match(filePath string) *Component {
+	filePath = normalizePath(filePath)
+
+	var best *Component
+	bestLen := 0
+	for i := range s.Components {
+		for _, prefix := range s.Components[i].Locations {
+			if len(prefix) > bestLen && hasPathPrefix(filePath, prefix) {
+				best = &s.Components[i]
+				bestLen = len(prefix)
+			}
+		}
+	}
+	return best
+}
+
+// hasPathPrefix reports whether p equals prefix or lies beneath it, including entries inside an archive ("lib.jar!/...")
+func hasPathPrefix(p, prefix string) bool {
+	if !strings.HasPrefix(p, prefix) {
+		return false
+	}
+	if len(p) == len(prefix) {
+		return true
+	}
+	next := p[len(prefix)]
+	return next == '/' || next == '!'
+}
+
+// Enrich attaches component provenance to results whose files fall under an SBOM component.
+// File paths are matched relative to whichever of roots contains them.
+// Returns the number of results enriched.
+func (s *SBOM) Enrich(results []crypto.Result, roots []string) int {
+	enriched := 0
+	for i := range results {
+		component := s.Match(relativeToRoots(results[i].File, roots))
+		if component == nil {
+			continue
+		}
+		results[i].PURL = component.PURL
+		results[i].ComponentName = component.Name
+		results[i].ComponentVersion = component.Version
+		enriched++
+	}
+	return enriched
+}
+
+// relativeToRoots makes filePath relative to the first root containing it
+func relativeToRoots(filePath string, roots []string) string {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, filePath)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			if rel == "." {
+				// The root is the scanned file itself
+				return filepath.Base(filePath)
+			}
+			return rel
+		}
+	}
+	return filePath
+}
@@ -0,0 +1,183 @@
+// Package schema holds the JSON Schemas of the scanner's JSON documents, the legacy
+// CBOM report, the CycloneDX 1.6 CBOM and the migration plan, and validates
+// documents against them. The CycloneDX schemas are the published ones,
+// bom-1.6.schema.json with the SPDX and JSF schemas it references.
+package schema
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Names of the embedded schemas
+const (
+	CBOMReport    = "cbom"
+	CBOM16Report  = "cbom-1.6"
+	MigrationPlan = "migration-plan"
+)
+
+//go:embed cbom-report.schema.json
+var cbomReportSchema []byte
+
+//go:embed bom-1.6.schema.json
+var cycloneDX16Schema []byte
+
+//go:embed spdx.schema.json
+var spdxSchema []byte
+
+//go:embed jsf-0.82.schema.json
+var jsfSchema []byte
+
+//go:embed migration-plan.schema.json
+var migrationPlanSchema []byte
+
+// schemas maps schema names to their JSON
+var schemas = map[string][]byte{
+	CBOMReport:    cbomReportSchema,
+	CBOM16Report:  cycloneDX16Schema,
+	MigrationPlan: migrationPlanSchema,
+}
+
+// referenced holds the schemas the CycloneDX schema refers to, by their $id
+var referenced = map[string][]byte{
+	"http://cyclonedx.org/schema/spdx.schema.json":     spdxSchema,
+	"http://cyclonedx.org/schema/jsf-0.82.schema.json": jsfSchema,
+}
+
+// Names lists the embedded schemas
+func Names() []string {
+	return []string{CBOMReport, CBOM16Report, MigrationPlan}
+}
+
+// Schema returns the JSON of the named schema
+func Schema(name string) ([]byte, error) {
+	data, ok := schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema '%s'. Use: %s", name, strings.Join(Names(), ", "))
+	}
+	return data, nil
+}
+
+// Violation is a single way a document breaks its schema, located by a JSON
+// Pointer such as /findings/2/line ("" for the whole document)
+type Violation struct {
+	Path    string
+	Message string
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("#%s: %s", v.Path, v.Message)
+}
+
+// Violations lists every way a document breaks its schema
+type Violations []Violation
+
+func (v Violations) Error() string {
+	lines := make([]string, len(v))
+	for i, violation := range v {
+		lines[i] = "  " + violation.Error()
+	}
+	return fmt.Sprintf("document does not match its schema (%d violations):\n%s", len(v), strings.Join(lines, "\n"))
+}
+
+// Detect names the schema a document is written in from its bomFormat and
+// specVersion: CycloneDX 1.4 is the legacy CBOM report and 1.6 the CycloneDX 1.6
+// CBOM. A document without a bomFormat is taken to be a migration plan.
+func Detect(document []byte) (string, error) {
+	var header struct {
+		BOMFormat   string `json:"bomFormat"`
+		SpecVersion string `json:"specVersion"`
+	}
+	if err := json.Unmarshal(document, &header); err != nil {
+		return "", fmt.Errorf("document is not valid JSON: %w", err)
+	}
+	switch {
+	case header.BOMFormat == "":
+		return MigrationPlan, nil
+	case header.BOMFormat != "CycloneDX":
+		return "", fmt.Errorf("unsupported bomFormat %q", header.BOMFormat)
+	case header.SpecVersion == "1.4":
+		return CBOMReport, nil
+	case header.SpecVersion == "1.6":
+		return CBOM16Report, nil
+	}
+	return "", fmt.Errorf("unsupported CycloneDX specVersion %q", header.SpecVersion)
+}
+
+// Validate checks document against the named schema. The error is Violations when
+// the document is valid JSON that breaks the schema.
+func Validate(name string, document []byte) error {
+	compiled, err := compile(name)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("document is not valid JSON: %w", err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("document is not valid JSON: unexpected data after the top-level value")
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if err := compiled.Validate(value); errors.As(err, &validationErr) {
+		return violations(validationErr)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// compile compiles the named schema, resolving its references among the
+// embedded schemas only
+func compile(name string) (*jsonschema.Schema, error) {
+	data, err := Schema(name)
+	if err != nil {
+		return nil, err
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("schema %s is not embedded", url)
+	}
+	for url, referencedData := range referenced {
+		if err := compiler.AddResource(url, bytes.NewReader(referencedData)); err != nil {
+			return nil, fmt.Errorf("failed to parse schema %s: %w", url, err)
+		}
+	}
+	url := name + ".schema.json"
+	if err := compiler.AddResource(url, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to parse schema %s: %w", name, err)
+	}
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema %s: %w", name, err)
+	}
+	return compiled, nil
+}
+
+// violations flattens a validation error to the failures it was caused by
+func violations(err *jsonschema.ValidationError) Violations {
+	var list Violations
+	var flatten func(*jsonschema.ValidationError)
+	flatten = func(err *jsonschema.ValidationError) {
+		if len(err.Causes) == 0 {
+			list = append(list, Violation{Path: err.InstanceLocation, Message: err.Message})
+		}
+		for _, cause := range err.Causes {
+			flatten(cause)
+		}
+	}
+	flatten(err)
+	return list
+}
@@ -0,0 +1,165 @@
+// Package signing signs CBOM reports with detached JWS (RFC 7515, appendix F) so
+// consumers can verify they were produced by a trusted pipeline. The signature
+// covers the RFC 8785 canonical JSON of the report, so reformatting the report
+// doesn't invalidate it.
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/gowebpki/jcs"
+)
+
+// ErrInvalidSignature is returned by Verify when the signature doesn't match the document
+var ErrInvalidSignature = errors.New("signature does not match the document")
+
+// algorithms are the JWS algorithms Sign produces
+var algorithms = []jose.SignatureAlgorithm{jose.RS256, jose.ES256, jose.ES384, jose.ES512, jose.EdDSA}
+
+// Canonicalize returns the RFC 8785 (JCS) canonical form of a JSON document
+func Canonicalize(document []byte) ([]byte, error) {
+	canonical, err := jcs.Transform(document)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing document: %w", err)
+	}
+	return canonical, nil
+}
+
+// Sign returns a detached JWS over the canonical form of document. The algorithm
+// follows the key: RS256 for RSA, ES256/ES384/ES512 for ECDSA P-256/P-384/P-521
+// and EdDSA for Ed25519.
+func Sign(document []byte, key crypto.Signer) (string, error) {
+	alg, err := algorithm(key.Public())
+	if err != nil {
+		return "", err
+	}
+	payload, err := Canonicalize(document)
+	if err != nil {
+		return "", err
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, nil)
+	if err != nil {
+		return "", fmt.Errorf("error signing: %w", err)
+	}
+	object, err := signer.Sign(payload)
+	if err != nil {
+		return "", fmt.Errorf("error signing: %w", err)
+	}
+	// The payload is left out; it is the document itself
+	return object.DetachedCompactSerialize()
+}
+
+// Verify checks a detached JWS made by Sign against document and the signer's
+// public key. The header's algorithm must be the one the key's type signs with.
+func Verify(document []byte, jws string, key crypto.PublicKey) error {
+	alg, err := algorithm(key)
+	if err != nil {
+		return err
+	}
+	payload, err := Canonicalize(document)
+	if err != nil {
+		return err
+	}
+	object, err := jose.ParseDetached(strings.TrimSpace(jws), payload, algorithms)
+	if err != nil {
+		return fmt.Errorf("not a detached JWS: %w", err)
+	}
+	if len(object.Signatures) != 1 {
+		return fmt.Errorf("expected one signature, got %d", len(object.Signatures))
+	}
+	if signed := jose.SignatureAlgorithm(object.Signatures[0].Header.Algorithm); signed != alg {
+		return fmt.Errorf("signature algorithm %s does not match the %s key", signed, alg)
+	}
+	if _, err := object.Verify(key); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// algorithm returns the JWS algorithm a public key signs with
+func algorithm(key crypto.PublicKey) (jose.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return jose.RS256, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+		return "", fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return jose.EdDSA, nil
+	}
+	return "", fmt.Errorf("unsupported key type %T", key)
+}
+
+// LoadPrivateKey reads a PEM-encoded PKCS #8, PKCS #1 or SEC 1 private key
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, fmt.Errorf("unsupported private key type %T in %s", key, path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no supported private key in %s", path)
+}
+
+// LoadPublicKey reads a PEM-encoded PKIX public key, or the public key of a
+// PEM-encoded certificate
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing certificate %s: %w", path, err)
+		}
+		return cert.PublicKey, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing public key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// readPEM returns the first PEM block of a file
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+	return block, nil
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"qvs-pro/scanner/internal/crypto"
+)
+
+// OutputCapabilities writes capabilities to w as a JSON array when format is
+// "json", or as a table otherwise
+func OutputCapabilities(w io.Writer, capabilities []crypto.Capability, format string) error {
+	if format == "json" {
+		return OutputJSON(w, capabilities)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CAPABILITY\tAVAILABLE\tDETAIL")
+	for _, capability := range capabilities {
+		available := "no"
+		if capability.Available {
+			available = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", capability.Name, available, capability.Detail)
+	}
+	return tw.Flush()
+}
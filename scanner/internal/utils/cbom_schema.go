@@ -0,0 +1,400 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"qvs-pro/scanner/internal/crypto"
+)
+
+// CBOM schemas OutputCBOM can write, selected by ScanMetadata.CBOMSchema
+const (
+	// CBOMSchemaLegacy is the report shape the scanner has always written: a
+	// CycloneDX 1.4 envelope with the scanner's own crypto, findings and summary
+	// fields. It is pinned; changes to it break downstream parsers.
+	CBOMSchemaLegacy = "1.4"
+
+	// CBOMSchema16 is a CycloneDX 1.6 CBOM with cryptographic-asset components
+	CBOMSchema16 = "1.6"
+)
+
+// CBOMSchemas lists the supported CBOM schemas, the default first
+var CBOMSchemas = []string{CBOMSchemaLegacy, CBOMSchema16}
+
+// CheckCBOMSchema reports an unsupported CBOM schema; empty selects the legacy one
+func CheckCBOMSchema(schema string) error {
+	switch schema {
+	case "", CBOMSchemaLegacy, CBOMSchema16:
+		return nil
+	}
+	return fmt.Errorf("unsupported CBOM schema '%s'. Use: %s", schema, strings.Join(CBOMSchemas, ", "))
+}
+
+// CBOM16Report is a CycloneDX 1.6 CBOM. It is derived from the legacy report of
+// the same scan (see cbom16Report), which differs field by field as follows:
+//
+//   - specVersion: "1.4" becomes "1.6".
+//   - serialNumber: the legacy "urn:uuid:qvs-pro-<mode>-<unix time>" is not a UUID;
+//     1.6 uses a name-based UUID derived from it, so it stays stable per scan.
+//   - metadata.tools: the legacy array of {vendor, name, version} becomes
+//     {components: [...]} of application components with a publisher.
+//   - metadata.supplier.url: a string in the legacy report, an array in 1.6.
+//   - metadata.configuration: the scan configuration object becomes
+//     "qvs-pro:config:*" properties of the metadata, one per option.
+//   - components: each legacy "file" component carried a non-standard "crypto"
+//     object describing only its first finding, a "scope" and an "evidence.identity"
+//     whose methods were free-form strings. In 1.6 files keep their bom-ref, hashes
+//     and PURL but lose those fields; every distinct algorithm, key size and curve
+//     found becomes its own "cryptographic-asset" component with cryptoProperties
+//     (assetType, algorithmProperties.primitive, parameterSetIdentifier, curve,
+//     cryptoFunctions, classicalSecurityLevel, nistQuantumSecurityLevel) and an
+//     evidence.occurrences entry per line and column it was found at, so several
+//     findings at one place are one occurrence. Quantum safety and the highest
+//     risk, formerly crypto.quantumSafe and crypto.quantumRisk, become
+//     "qvs-pro:" properties of the asset.
+//   - dependencies: the application still depends on every file and certificates
+//     on their issuers; each file additionally "provides" the crypto assets in it.
+//   - findings: not emitted; findings are occurrences of their crypto asset. Use
+//     JSON output for recommendations, CWEs and the other per-finding details.
+//   - summary and warnings: the legacy top-level objects become "qvs-pro:summary:*"
+//     and "qvs-pro:warning" properties of the BOM.
+type CBOM16Report struct {
+	BOMFormat    string             `json:"bomFormat"`
+	SpecVersion  string             `json:"specVersion"`
+	SerialNumber string             `json:"serialNumber"`
+	Version      int                `json:"version"`
+	Metadata     CBOM16Metadata     `json:"metadata"`
+	Components   []CBOM16Component  `json:"components"`
+	Dependencies []CBOM16Dependency `json:"dependencies,omitempty"`
+	Properties   []CBOMProperty     `json:"properties,omitempty"`
+}
+
+// CBOM16Metadata contains metadata about a CycloneDX 1.6 report
+type CBOM16Metadata struct {
+	Timestamp  string                 `json:"timestamp"`
+	Tools      CBOM16Tools            `json:"tools"`
+	Authors    []CBOMAuthor           `json:"authors"`
+	Supplier   CBOM16Supplier         `json:"supplier"`
+	Component  *CBOMMetadataComponent `json:"component,omitempty"`
+	Properties []CBOMProperty         `json:"properties,omitempty"`
+}
+
+// CBOM16Tools lists the tools that produced a CycloneDX 1.6 report
+type CBOM16Tools struct {
+	Components []CBOM16Tool `json:"components"`
+}
+
+// CBOM16Tool is a tool described as a CycloneDX component
+type CBOM16Tool struct {
+	Type      string `json:"type"`
+	Publisher string `json:"publisher"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+}
+
+// CBOM16Supplier is the organization supplying a CycloneDX 1.6 report
+type CBOM16Supplier struct {
+	Name string   `json:"name"`
+	URL  []string `json:"url"`
+}
+
+// CBOM16Component is a file, library or cryptographic asset
+type CBOM16Component struct {
+	Type             string                `json:"type"`
+	BOMRef           string                `json:"bom-ref"`
+	Name             string                `json:"name"`
+	Version          string                `json:"version,omitempty"`
+	PURL             string                `json:"purl,omitempty"`
+	Hashes           []CBOMHash            `json:"hashes,omitempty"`
+	CryptoProperties *CBOMCryptoProperties `json:"cryptoProperties,omitempty"`
+	Evidence         *CBOM16Evidence       `json:"evidence,omitempty"`
+	Properties       []CBOMProperty        `json:"properties,omitempty"`
+}
+
+// CBOMCryptoProperties describes a cryptographic asset
+type CBOMCryptoProperties struct {
+	AssetType           string                   `json:"assetType"` // algorithm, protocol or related-crypto-material
+	AlgorithmProperties *CBOMAlgorithmProperties `json:"algorithmProperties,omitempty"`
+	ProtocolProperties  *CBOMProtocolProperties  `json:"protocolProperties,omitempty"`
+}
+
+// CBOMAlgorithmProperties describes a cryptographic algorithm
+type CBOMAlgorithmProperties struct {
+	Primitive                string   `json:"primitive"`
+	ParameterSetIdentifier   string   `json:"parameterSetIdentifier,omitempty"` // Key size in bits
+	Curve                    string   `json:"curve,omitempty"`
+	CryptoFunctions          []string `json:"cryptoFunctions,omitempty"`
+	ClassicalSecurityLevel   int      `json:"classicalSecurityLevel,omitempty"`
+	NISTQuantumSecurityLevel int      `json:"nistQuantumSecurityLevel"` // 0 for algorithms broken by a quantum computer
+}
+
+// CBOMProtocolProperties describes a cryptographic protocol
+type CBOMProtocolProperties struct {
+	Type    string `json:"type"`
+	Version string `json:"version,omitempty"`
+}
+
+// CBOM16Evidence lists where a cryptographic asset was found
+type CBOM16Evidence struct {
+	Occurrences []CBOMOccurrence `json:"occurrences"`
+}
+
+// CBOMOccurrence is one finding of a cryptographic asset
+type CBOMOccurrence struct {
+	BOMRef            string `json:"bom-ref,omitempty"` // finding:<fingerprint>
+	Location          string `json:"location"`
+	Line              int    `json:"line,omitempty"`
+	Offset            int    `json:"offset,omitempty"`
+	AdditionalContext string `json:"additionalContext,omitempty"`
+}
+
+// CBOM16Dependency lists the components a component depends on and the crypto assets it provides
+type CBOM16Dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+	Provides  []string `json:"provides,omitempty"`
+}
+
+// CBOMProperty is a CycloneDX name-value property
+type CBOMProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// cbom16Report converts the legacy report of a scan to CycloneDX 1.6
+func cbom16Report(legacy CBOMReport) CBOM16Report {
+	report := CBOM16Report{
+		BOMFormat:    legacy.BOMFormat,
+		SpecVersion:  CBOMSchema16,
+		SerialNumber: nameUUID(legacy.SerialNumber),
+		Version:      legacy.Version,
+		Metadata: CBOM16Metadata{
+			Timestamp: legacy.Metadata.Timestamp,
+			Authors:   legacy.Metadata.Authors,
+			Supplier:  CBOM16Supplier{Name: legacy.Metadata.Supplier.Name, URL: []string{legacy.Metadata.Supplier.URL}},
+			Component: legacy.Metadata.Component,
+		},
+		Components: make([]CBOM16Component, 0, len(legacy.Components)),
+	}
+	if config := legacy.Metadata.Configuration; config != nil {
+		report.Metadata.Properties = configurationProperties(*config)
+	}
+	for _, tool := range legacy.Metadata.Tools {
+		report.Metadata.Tools.Components = append(report.Metadata.Tools.Components,
+			CBOM16Tool{Type: "application", Publisher: tool.Vendor, Name: tool.Name, Version: tool.Version})
+	}
+
+	fileRefs := make(map[string]string)
+	for _, component := range legacy.Components {
+		report.Components = append(report.Components, CBOM16Component{
+			Type:    component.Type,
+			BOMRef:  component.BOMRef,
+			Name:    component.Name,
+			Version: component.Version,
+			PURL:    component.PURL,
+			Hashes:  component.Hashes,
+		})
+		fileRefs[component.Name] = component.BOMRef
+	}
+
+	// One asset per distinct algorithm, in the order of the sorted findings, with
+	// one occurrence per place it was found however many findings are there
+	assets := make(map[string]int)
+	riskiest := make(map[string]string)
+	provides := make(map[string][]string)
+	occurrenceRefs := make(map[string]bool)
+	places := make(map[string]bool)
+	for _, finding := range legacy.Findings {
+		asset := cryptoAsset(finding)
+		i, ok := assets[asset.BOMRef]
+		if !ok {
+			i = len(report.Components)
+			assets[asset.BOMRef] = i
+			report.Components = append(report.Components, asset)
+		}
+		if highest, ok := riskiest[asset.BOMRef]; !ok || riskRank(finding.Risk) < riskRank(highest) {
+			riskiest[asset.BOMRef] = finding.Risk
+		}
+		file := fileRefs[componentName(finding)]
+		provides[file] = appendUnique(provides[file], asset.BOMRef)
+
+		place := fmt.Sprintf("%s\x00%s\x00%d\x00%d", asset.BOMRef, finding.File, finding.Line, finding.Column)
+		if places[place] {
+			continue
+		}
+		places[place] = true
+		occurrence := CBOMOccurrence{
+			Location:          finding.File,
+			Line:              finding.Line,
+			Offset:            finding.Column,
+			AdditionalContext: finding.Description,
+		}
+		// bom-refs must be unique within the BOM
+		if ref := "finding:" + finding.Fingerprint; finding.Fingerprint != "" && !occurrenceRefs[ref] {
+			occurrence.BOMRef = ref
+			occurrenceRefs[ref] = true
+		}
+		report.Components[i].Evidence.Occurrences = append(report.Components[i].Evidence.Occurrences, occurrence)
+	}
+	for ref, i := range assets {
+		if risk := riskiest[ref]; risk != "" {
+			report.Components[i].Properties = append(report.Components[i].Properties, CBOMProperty{Name: "qvs-pro:risk", Value: risk})
+		}
+	}
+
+	for _, dependency := range legacy.Dependencies {
+		report.Dependencies = append(report.Dependencies, CBOM16Dependency{Ref: dependency.Ref, DependsOn: dependency.DependsOn, Provides: provides[dependency.Ref]})
+		delete(provides, dependency.Ref)
+	}
+	files := make([]string, 0, len(provides))
+	for ref := range provides {
+		files = append(files, ref)
+	}
+	sort.Strings(files)
+	for _, ref := range files {
+		report.Dependencies = append(report.Dependencies, CBOM16Dependency{Ref: ref, Provides: provides[ref]})
+	}
+
+	summary := legacy.Summary
+	report.Properties = []CBOMProperty{
+		{Name: "qvs-pro:summary:total_assets", Value: strconv.Itoa(summary.TotalAssets)},
+		{Name: "qvs-pro:summary:vulnerable_assets", Value: strconv.Itoa(summary.VulnerableAssets)},
+		{Name: "qvs-pro:summary:quantum_safe_assets", Value: strconv.Itoa(summary.QuantumSafeAssets)},
+		{Name: "qvs-pro:summary:quantum_safe_ratio", Value: strconv.FormatFloat(summary.QuantumSafeRatio, 'f', -1, 64)},
+		{Name: "qvs-pro:summary:crypto_agility_score", Value: strconv.Itoa(summary.CryptoAgilityScore)},
+		{Name: "qvs-pro:summary:crypto_agility_grade", Value: summary.CryptoAgilityGrade},
+	}
+	if summary.ScanDuration != "" {
+		report.Properties = append(report.Properties, CBOMProperty{Name: "qvs-pro:summary:scan_duration", Value: summary.ScanDuration})
+	}
+	for _, warning := range legacy.Warnings {
+		location := warning.File
+		if location == "" {
+			location = warning.Namespace
+		}
+		value := warning.Reason
+		if location != "" {
+			value = location + ": " + warning.Reason
+		}
+		report.Properties = append(report.Properties, CBOMProperty{Name: "qvs-pro:warning", Value: value})
+	}
+	return report
+}
+
+// cryptoAsset returns the component of the algorithm, protocol or key material a
+// finding is about, without its occurrences. Findings of the same algorithm, type,
+// key size and curve share a component.
+func cryptoAsset(finding crypto.Result) CBOM16Component {
+	keySize := ""
+	if finding.KeySize > 0 {
+		keySize = strconv.Itoa(finding.KeySize)
+	}
+	asset := CBOM16Component{
+		Type:     "cryptographic-asset",
+		BOMRef:   contentRef("crypto", finding.Algorithm, finding.Type, keySize, finding.Curve),
+		Name:     finding.Algorithm,
+		Evidence: &CBOM16Evidence{},
+		Properties: []CBOMProperty{
+			{Name: "qvs-pro:quantum-safe", Value: strconv.FormatBool(crypto.IsQuantumSafe(finding))},
+		},
+	}
+	if finding.VulnerabilityType != "" {
+		asset.Properties = append(asset.Properties, CBOMProperty{Name: "qvs-pro:quantum-risk", Value: finding.VulnerabilityType})
+	}
+
+	switch finding.Type {
+	case crypto.TypeProtocol:
+		protocol, version, _ := strings.Cut(finding.Algorithm, " ")
+		protocolType := strings.ToLower(protocol)
+		if protocolType != "tls" && protocolType != "ssh" && protocolType != "ipsec" {
+			protocolType = "other"
+		}
+		asset.CryptoProperties = &CBOMCryptoProperties{AssetType: "protocol", ProtocolProperties: &CBOMProtocolProperties{Type: protocolType, Version: version}}
+	case crypto.TypeEmbeddedKey:
+		asset.CryptoProperties = &CBOMCryptoProperties{AssetType: "related-crypto-material"}
+	default:
+		quantumLevel := 0
+		if level, err := strconv.Atoi(finding.NISTCategory); err == nil {
+			quantumLevel = level
+		}
+		asset.CryptoProperties = &CBOMCryptoProperties{
+			AssetType: "algorithm",
+			AlgorithmProperties: &CBOMAlgorithmProperties{
+				Primitive:                cryptoPrimitive(finding),
+				ParameterSetIdentifier:   keySize,
+				Curve:                    finding.Curve,
+				CryptoFunctions:          crypto.CryptoFunctions(finding.Purpose),
+				ClassicalSecurityLevel:   finding.SecurityStrength,
+				NISTQuantumSecurityLevel: quantumLevel,
+			},
+		}
+	}
+	return asset
+}
+
+// configurationProperties flattens a scan configuration into metadata properties,
+// with options in name order
+func configurationProperties(config ScanConfiguration) []CBOMProperty {
+	properties := []CBOMProperty{
+		{Name: "qvs-pro:config:mode", Value: config.Mode},
+		{Name: "qvs-pro:config:rule_set_digest", Value: config.RuleSetDigest},
+		{Name: "qvs-pro:config:rule_count", Value: strconv.Itoa(config.RuleCount)},
+	}
+	names := make([]string, 0, len(config.Options))
+	for name := range config.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		properties = append(properties, CBOMProperty{Name: "qvs-pro:config:option:" + name, Value: config.Options[name]})
+	}
+	for _, property := range []CBOMProperty{
+		{Name: "qvs-pro:config:git_commit", Value: config.GitCommit},
+		{Name: "qvs-pro:config:host", Value: config.Host},
+		{Name: "qvs-pro:config:os", Value: config.OS},
+		{Name: "qvs-pro:config:go_version", Value: config.GoVersion},
+	} {
+		if property.Value != "" {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// cryptoPrimitive returns the CycloneDX primitive of a finding's algorithm
+func cryptoPrimitive(finding crypto.Result) string {
+	algorithm := strings.ToUpper(finding.Algorithm)
+	switch {
+	case finding.Type == crypto.TypeHash:
+		return "hash"
+	case finding.Type == crypto.TypeRandom:
+		return "drbg"
+	case finding.Type == crypto.TypeKDF:
+		return "kdf"
+	case finding.Type == crypto.TypeSymmetricKey && (strings.HasPrefix(algorithm, "RC4") || strings.HasPrefix(algorithm, "CHACHA")):
+		return "stream-cipher"
+	case finding.Type == crypto.TypeSymmetricKey:
+		return "block-cipher"
+	case finding.Purpose == crypto.PurposeSigning:
+		return "signature"
+	case finding.Purpose == crypto.PurposeKeyEncapsulation && (strings.Contains(algorithm, "KEM") || strings.Contains(algorithm, "KYBER")):
+		return "kem"
+	case finding.Purpose == crypto.PurposeKeyEncapsulation:
+		return "key-agree"
+	case finding.Purpose == crypto.PurposeEncrypt:
+		return "pke"
+	}
+	return "unknown"
+}
+
+// nameUUID derives an RFC 4122 version 5 style UUID URN from name, so the same
+// legacy serial number always maps to the same 1.6 serial number
+func nameUUID(name string) string {
+	sum := sha1.Sum([]byte(name))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -1,42 +1,108 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"qvs-pro/scanner/internal/compliance"
 	"qvs-pro/scanner/internal/crypto"
 )
 
 // ScanMetadata contains metadata about the scanning process
 type ScanMetadata struct {
-	Mode        string    `json:"mode"`
-	Target      string    `json:"target"`
-	TotalAssets int       `json:"total_assets"`
-	ScanTime    string    `json:"scan_time"`
-	Namespaces  []string  `json:"namespaces,omitempty"`
-	Duration    string    `json:"duration,omitempty"`
+	Mode               string               `json:"mode"`
+	Target             string               `json:"target"`
+	TotalAssets        int                  `json:"total_assets"`
+	ScanTime           string               `json:"scan_time"`
+	Namespaces         []string             `json:"namespaces,omitempty"`
+	Duration           string               `json:"duration,omitempty"`
+	Partial            bool                 `json:"partial,omitempty"`
+	CacheHits          int                  `json:"cache_hits,omitempty"`          // Files whose findings came from the scan cache
+	CacheMisses        int                  `json:"cache_misses,omitempty"`        // Files scanned with the scan cache enabled
+	TimedOutFiles      []string             `json:"timed_out_files,omitempty"`     // Files skipped for exceeding the per-file timeout
+	Warnings           []crypto.ScanWarning `json:"warnings,omitempty"`            // Non-fatal problems that left the scan incomplete
+	Targets            []TargetAssets       `json:"targets,omitempty"`             // Per-target asset counts when several targets were scanned
+	FilteredFindings   int                  `json:"filtered_findings,omitempty"`   // Findings left out of the results by -min-risk, -algorithm or -nist-category
+	SuppressedFindings int                  `json:"suppressed_findings,omitempty"` // Findings left out of the results by -suppressions
+	Timing             *PhaseTiming         `json:"timing,omitempty"`              // Per-phase breakdown of Duration, with -timing
+	ProjectDate        string               `json:"project_date,omitempty"`        // Date findings' timeline_status_at_project_date refers to, with -project-date
+	Compliance         *compliance.Summary  `json:"compliance,omitempty"`          // Pass/fail per control of the -compliance framework
+	CBOMSchema         string               `json:"cbom_schema,omitempty"`         // Schema of CBOM output, see CBOMSchemas (default: CBOMSchemaLegacy)
+	Configuration      *ScanConfiguration   `json:"configuration,omitempty"`       // Effective configuration of the scan, recorded in CBOM metadata
+}
+
+// ScanConfiguration is the effective configuration a scan ran with, so a report can
+// be reproduced and a difference between two reports traced to the scanned code,
+// the rules or the options
+type ScanConfiguration struct {
+	Mode          string            `json:"mode"`
+	RuleSetDigest string            `json:"rule_set_digest"` // SHA-256 of the detection rules, see crypto.RuleSetDigest
+	RuleCount     int               `json:"rule_count"`
+	Options       map[string]string `json:"options,omitempty"`    // Scan options that differ from their defaults, by flag name
+	GitCommit     string            `json:"git_commit,omitempty"` // Commit checked out in the scanned repository
+	Host          string            `json:"host,omitempty"`       // Left out when paths are redacted
+	OS            string            `json:"os"`                   // GOOS/GOARCH of the scanner
+	GoVersion     string            `json:"go_version"`
+}
+
+// PhaseTiming is the time a scan spent in each phase, as Go duration strings.
+// Phases the scan did not go through are left empty.
+type PhaseTiming struct {
+	Walk          string `json:"walk,omitempty"`           // Listing the files to scan
+	Match         string `json:"match,omitempty"`          // Scanning the files
+	KubernetesAPI string `json:"kubernetes_api,omitempty"` // Kubernetes API requests, summed across concurrent requests
+}
+
+// TargetAssets is the number of assets scanned under one target of a multi-target scan
+type TargetAssets struct {
+	Target      string `json:"target"`
+	TotalAssets int    `json:"total_assets"`
 }
 
 // CBOMReport represents a comprehensive CBOM (Cryptographic Bill of Materials) report
 type CBOMReport struct {
-	BOMFormat   string                  `json:"bomFormat"`
-	SpecVersion string                  `json:"specVersion"`
-	SerialNumber string                 `json:"serialNumber"`
-	Version     int                     `json:"version"`
-	Metadata    CBOMMetadata            `json:"metadata"`
-	Components  []CBOMComponent         `json:"components"`
-	Findings    []crypto.Result         `json:"findings"`
-	Summary     CBOMSummary             `json:"summary"`
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     CBOMMetadata         `json:"metadata"`
+	Components   []CBOMComponent      `json:"components"`
+	Findings     []crypto.Result      `json:"findings"`
+	Summary      CBOMSummary          `json:"summary"`
+	Dependencies []CBOMDependency     `json:"dependencies,omitempty"`
+	Warnings     []crypto.ScanWarning `json:"warnings,omitempty"` // Non-fatal scan problems; empty for a complete scan
 }
 
 // CBOMMetadata contains metadata about the CBOM report
 type CBOMMetadata struct {
-	Timestamp string       `json:"timestamp"`
-	Tools     []CBOMTool   `json:"tools"`
-	Authors   []CBOMAuthor `json:"authors"`
-	Supplier  CBOMSupplier `json:"supplier"`
+	Timestamp string                 `json:"timestamp"`
+	Tools     []CBOMTool             `json:"tools"`
+	Authors   []CBOMAuthor           `json:"authors"`
+	Supplier  CBOMSupplier           `json:"supplier"`
+	Component *CBOMMetadataComponent `json:"component,omitempty"`
+
+	Configuration *ScanConfiguration `json:"configuration,omitempty"` // Effective scan configuration, when the scan recorded one
+}
+
+// CBOMMetadataComponent is the top-level application the report describes
+type CBOMMetadataComponent struct {
+	Type   string `json:"type"`
+	BOMRef string `json:"bom-ref"`
+	Name   string `json:"name"`
+}
+
+// CBOMDependency lists the components a component depends on
+type CBOMDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 // CBOMTool represents the scanning tool information
@@ -60,15 +126,16 @@ type CBOMSupplier struct {
 
 // CBOMComponent represents a scanned component/asset
 type CBOMComponent struct {
-	Type      string            `json:"type"`
-	BOMRef    string            `json:"bom-ref"`
-	Name      string            `json:"name"`
-	Version   string            `json:"version,omitempty"`
-	Scope     string            `json:"scope"`
-	Hashes    []CBOMHash        `json:"hashes,omitempty"`
-	Licenses  []CBOMLicense     `json:"licenses,omitempty"`
-	Crypto    CBOMCrypto        `json:"crypto,omitempty"`
-	Evidence  CBOMEvidence      `json:"evidence,omitempty"`
+	Type     string        `json:"type"`
+	BOMRef   string        `json:"bom-ref"`
+	Name     string        `json:"name"`
+	Version  string        `json:"version,omitempty"`
+	PURL     string        `json:"purl,omitempty"`
+	Scope    string        `json:"scope"`
+	Hashes   []CBOMHash    `json:"hashes,omitempty"`
+	Licenses []CBOMLicense `json:"licenses,omitempty"`
+	Crypto   CBOMCrypto    `json:"crypto,omitempty"`
+	Evidence CBOMEvidence  `json:"evidence,omitempty"`
 }
 
 // CBOMHash represents file hashes
@@ -90,11 +157,13 @@ type CBOMLicenseChoice struct {
 
 // CBOMCrypto represents cryptographic information
 type CBOMCrypto struct {
-	Algorithm     string `json:"algorithm"`
-	KeySize       int    `json:"keySize,omitempty"`
-	Purpose       string `json:"purpose"`
-	QuantumSafe   bool   `json:"quantumSafe"`
-	QuantumRisk   string `json:"quantumRisk"`
+	Algorithm       string   `json:"algorithm"`
+	KeySize         int      `json:"keySize,omitempty"`
+	Curve           string   `json:"curve,omitempty"`
+	Purpose         string   `json:"purpose"`
+	QuantumSafe     bool     `json:"quantumSafe"`
+	QuantumRisk     string   `json:"quantumRisk"`
+	CryptoFunctions []string `json:"cryptoFunctions,omitempty"` // CycloneDX crypto functions of the finding's purpose
 }
 
 // CBOMEvidence represents evidence of where crypto was found
@@ -104,19 +173,28 @@ type CBOMEvidence struct {
 
 // CBOMIdentity represents identity evidence
 type CBOMIdentity struct {
-	Field      string `json:"field"`
-	Confidence float64 `json:"confidence"`
+	Field      string   `json:"field"`
+	Confidence float64  `json:"confidence"`
 	Methods    []string `json:"methods"`
 }
 
 // CBOMSummary provides a summary of the scan results
 type CBOMSummary struct {
-	TotalAssets      int                    `json:"total_assets"`
-	VulnerableAssets int                    `json:"vulnerable_assets"`
-	QuantumSafeAssets int                   `json:"quantum_safe_assets"`
-	RiskBreakdown    map[string]int         `json:"risk_breakdown"`
-	AlgorithmBreakdown map[string]int       `json:"algorithm_breakdown"`
-	ScanDuration     string                 `json:"scan_duration"`
+	TotalAssets          int                 `json:"total_assets"`
+	VulnerableAssets     int                 `json:"vulnerable_assets"`
+	QuantumSafeAssets    int                 `json:"quantum_safe_assets"`
+	QuantumSafeRatio     float64             `json:"quantum_safe_ratio"` // Quantum-safe share of the assets with findings, 0-1
+	RiskBreakdown        map[string]int      `json:"risk_breakdown"`
+	AlgorithmBreakdown   map[string]int      `json:"algorithm_breakdown"`
+	EnvironmentBreakdown map[string]int      `json:"environment_breakdown,omitempty"` // Findings per -env label; empty when none are labeled
+	ScanDuration         string              `json:"scan_duration"`
+	CryptoAgilityScore   int                 `json:"crypto_agility_score"` // 0-100, see crypto.CryptoAgilityScore
+	CryptoAgilityGrade   string              `json:"crypto_agility_grade"`
+	SimulatedFindings    int                 `json:"simulated_findings,omitempty"` // Fallback placeholders included in the findings
+	Targets              []TargetAssets      `json:"targets,omitempty"`            // Per-target asset counts of a multi-target scan
+	FilteredFindings     int                 `json:"filtered_findings,omitempty"`  // Findings left out by output filters; the counts above exclude them
+	Timing               *PhaseTiming        `json:"timing,omitempty"`             // Per-phase breakdown of ScanDuration, with -timing
+	Compliance           *compliance.Summary `json:"compliance,omitempty"`         // Pass/fail per control of the -compliance framework
 }
 
 // GetCurrentTimestamp returns the current timestamp in ISO format
@@ -124,113 +202,418 @@ func GetCurrentTimestamp() string {
 	return time.Now().UTC().Format(time.RFC3339)
 }
 
-// OutputJSON outputs scan results in JSON format
-func OutputJSON(results interface{}) {
+// CreateOutputFile creates (or truncates) the file at path for writing results,
+// creating any missing parent directories
+func CreateOutputFile(path string) (*os.File, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating output directory: %w", err)
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating output file: %w", err)
+	}
+	return file, nil
+}
+
+// WriteOutput writes results to w in the named format: text, json or cbom
+func WriteOutput(w io.Writer, format string, results []crypto.Result, metadata ScanMetadata, mode string) error {
+	switch format {
+	case "cbom":
+		return OutputCBOM(w, results, metadata, mode)
+	case "json":
+		return OutputJSON(w, results)
+	case "jsonl":
+		return OutputJSONL(w, results)
+	case "text", "":
+		return OutputText(w, results)
+	default:
+		return fmt.Errorf("unsupported output format '%s'. Use: text, json, jsonl, cbom", format)
+	}
+}
+
+// ContentType returns the MIME type of an output format
+func ContentType(format string) string {
+	switch format {
+	case "text", "":
+		return "text/plain; charset=utf-8"
+	case "jsonl":
+		return "application/x-ndjson"
+	}
+	return "application/json"
+}
+
+// OutputJSON writes scan results to w in JSON format
+func OutputJSON(w io.Writer, results interface{}) error {
 	jsonData, err := json.MarshalIndent(results, "", "  ")
 	if err != nil {
-		fmt.Printf("Error converting to JSON: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error converting to JSON: %w", err)
 	}
 
-	fmt.Println(string(jsonData))
+	_, err = fmt.Fprintln(w, string(jsonData))
+	return err
+}
+
+// OutputJSONL writes scan results to w as JSON Lines: one compact JSON object per
+// finding, each terminated by a newline. Dates are written in UTC so lines from
+// different scans compare and sort consistently.
+func OutputJSONL(w io.Writer, results []crypto.Result) error {
+	encoder := json.NewEncoder(w)
+	for _, result := range results {
+		result.DeprecationDate = utcTime(result.DeprecationDate)
+		result.DisallowanceDate = utcTime(result.DisallowanceDate)
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("error converting to JSON: %w", err)
+		}
+	}
+	return nil
 }
 
-// OutputText outputs scan results in human-readable text format
-func OutputText(results interface{}) {
+// utcTime returns a copy of t in UTC, or nil for a nil t
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// OutputText writes scan results to w in human-readable text format
+func OutputText(w io.Writer, results interface{}) error {
 	// Type assertion to access the Result struct fields
 	typedResults, ok := results.([]crypto.Result)
 
 	if !ok {
-		fmt.Println("Error: Could not format results")
-		return
+		return fmt.Errorf("could not format results of type %T", results)
 	}
 
 	if len(typedResults) == 0 {
-		fmt.Println("No vulnerabilities found.")
-		return
+		_, err := fmt.Fprintln(w, "No vulnerabilities found.")
+		return err
 	}
 
-	fmt.Printf("Found %d potential vulnerabilities:\n\n", len(typedResults))
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d potential vulnerabilities:\n\n", len(typedResults))
+	if simulated := countSimulated(typedResults); simulated > 0 {
+		fmt.Fprintf(&b, "WARNING: %d of these are simulated placeholders, not observed findings\n\n", simulated)
+	}
 	for _, result := range typedResults {
-		fmt.Printf("File: %s\n", result.File)
-		fmt.Printf("Algorithm: %s (%s)\n", result.Algorithm, result.Type)
-		fmt.Printf("Line: %d\n", result.Line)
-		fmt.Printf("Method: %s\n", result.Method)
-		fmt.Printf("Risk Level: %s\n", result.Risk)
-		fmt.Println("----------------------")
+		fmt.Fprintf(&b, "File: %s\n", result.File)
+		fmt.Fprintf(&b, "Algorithm: %s (%s)\n", result.Algorithm, result.Type)
+		fmt.Fprintf(&b, "Line: %d\n", result.Line)
+		fmt.Fprintf(&b, "Method: %s\n", result.Method)
+		fmt.Fprintf(&b, "Risk Level: %s\n", result.Risk)
+		if result.Fingerprint != "" {
+			fmt.Fprintf(&b, "Fingerprint: %s\n", result.Fingerprint)
+		}
+		if len(result.CWE) > 0 {
+			fmt.Fprintf(&b, "CWE: %s\n", strings.Join(result.CWE, ", "))
+		}
+		if len(result.Compliance) > 0 {
+			var controls []string
+			for _, control := range result.Compliance {
+				controls = append(controls, fmt.Sprintf("%s (%s)", control.Control, control.Status))
+			}
+			fmt.Fprintf(&b, "Compliance: %s\n", strings.Join(controls, ", "))
+		}
+		if result.TimelineStatusAtProjectDate != "" {
+			fmt.Fprintf(&b, "NIST Status at Project Date: %s\n", result.TimelineStatusAtProjectDate)
+		}
+		if result.Simulated {
+			fmt.Fprintln(&b, "Simulated: yes (fallback result, not an observed finding)")
+		}
+		if result.Recommendation != "" {
+			fmt.Fprintf(&b, "Recommendation: %s\n", result.Recommendation)
+		}
+		if result.RemediationExample != "" {
+			fmt.Fprintln(&b, "Remediation example:")
+			writeIndented(&b, result.RemediationExample, "    ")
+		}
+		if result.SuggestedFix != "" {
+			fmt.Fprintln(&b, "Suggested fix:")
+			writeIndented(&b, strings.TrimSuffix(result.SuggestedFix, "\n"), "    ")
+		}
+		fmt.Fprintln(&b, "----------------------")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Text output groupings for OutputTextGrouped
+const (
+	GroupByFile        = "file"
+	GroupByAlgorithm   = "algorithm"
+	GroupByRisk        = "risk"
+	GroupByEnvironment = "environment"
+)
+
+// unlabeledEnvironment names the group of findings without an environment
+const unlabeledEnvironment = "(none)"
+
+// riskOrder ranks risk levels from most to least severe
+var riskOrder = map[string]int{"Critical": 0, "High": 1, "Medium": 2, "Low": 3}
+
+// OutputTextGrouped writes scan results to w in human-readable text, bucketed by
+// file, algorithm, risk or environment. Each group is printed once with its finding count and
+// highest risk, followed by its findings indented. Groups are sorted: files and
+// algorithms and environments by name, risks from most severe.
+func OutputTextGrouped(w io.Writer, results []crypto.Result, groupBy string) error {
+	var key func(crypto.Result) string
+	var label string
+	switch groupBy {
+	case GroupByFile:
+		key, label = func(r crypto.Result) string { return r.File }, "File"
+	case GroupByAlgorithm:
+		key, label = func(r crypto.Result) string { return r.Algorithm }, "Algorithm"
+	case GroupByRisk:
+		key, label = func(r crypto.Result) string { return r.Risk }, "Risk"
+	case GroupByEnvironment:
+		key, label = func(r crypto.Result) string {
+			if r.Environment == "" {
+				return unlabeledEnvironment
+			}
+			return r.Environment
+		}, "Environment"
+	default:
+		return fmt.Errorf("unsupported grouping '%s'. Use: file, algorithm, risk, environment", groupBy)
+	}
+
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(w, "No vulnerabilities found.")
+		return err
+	}
+
+	groups := make(map[string][]crypto.Result)
+	var names []string
+	for _, result := range results {
+		name := key(result)
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], result)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if groupBy == GroupByRisk {
+			if ri, rj := riskRank(names[i]), riskRank(names[j]); ri != rj {
+				return ri < rj
+			}
+		}
+		return names[i] < names[j]
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d potential vulnerabilities in %d %s groups:\n\n", len(results), len(names), groupBy)
+	for _, name := range names {
+		findings := groups[name]
+		highest := findings[0].Risk
+		for _, finding := range findings[1:] {
+			if riskRank(finding.Risk) < riskRank(highest) {
+				highest = finding.Risk
+			}
+		}
+
+		noun := "findings"
+		if len(findings) == 1 {
+			noun = "finding"
+		}
+		fmt.Fprintf(&b, "%s: %s (%d %s, highest risk: %s)\n", label, name, len(findings), noun, highest)
+		for _, result := range findings {
+			simulated := ""
+			if result.Simulated {
+				simulated = " [SIMULATED]"
+			}
+			switch groupBy {
+			case GroupByFile:
+				fmt.Fprintf(&b, "  Line %d: %s (%s) - %s, Risk: %s%s\n", result.Line, result.Algorithm, result.Type, result.Method, result.Risk, simulated)
+			default:
+				fmt.Fprintf(&b, "  %s:%d: %s (%s) - %s, Risk: %s%s\n", result.File, result.Line, result.Algorithm, result.Type, result.Method, result.Risk, simulated)
+			}
+			if result.RemediationExample != "" {
+				fmt.Fprintln(&b, "    Remediation example:")
+				writeIndented(&b, result.RemediationExample, "      ")
+			}
+			if result.SuggestedFix != "" {
+				fmt.Fprintln(&b, "    Suggested fix:")
+				writeIndented(&b, strings.TrimSuffix(result.SuggestedFix, "\n"), "      ")
+			}
+		}
+		fmt.Fprintln(&b, "----------------------")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// OutputComplianceSummary writes the pass/fail outcome of each control of a
+// compliance framework to w as text
+func OutputComplianceSummary(w io.Writer, summary *compliance.Summary) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nCompliance: %s (%d passed, %d failed)\n", summary.Name, summary.Passed, summary.Failed)
+	for _, control := range summary.Controls {
+		status := "PASS"
+		if control.Result == compliance.ResultFail {
+			status = fmt.Sprintf("FAIL (findings: %d)", control.Findings)
+		}
+		fmt.Fprintf(&b, "  %-20s %s: %s\n", control.ID, control.Title, status)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeIndented writes each line of text to b behind indent
+func writeIndented(b *strings.Builder, text, indent string) {
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(b, "%s%s\n", indent, line)
+	}
+}
+
+// riskRank returns the sort rank of a risk level; unknown levels sort last
+func riskRank(risk string) int {
+	if rank, ok := riskOrder[risk]; ok {
+		return rank
 	}
+	return len(riskOrder)
 }
 
-// OutputCBOM outputs scan results in CBOM (Cryptographic Bill of Materials) format
-func OutputCBOM(results []crypto.Result, metadata ScanMetadata, mode string) {
-	report := generateCBOMReport(results, metadata, mode)
-	
+// OutputCBOM writes scan results to w in CBOM (Cryptographic Bill of Materials)
+// format, in the schema named by metadata.CBOMSchema
+func OutputCBOM(w io.Writer, results []crypto.Result, metadata ScanMetadata, mode string) error {
+	if err := CheckCBOMSchema(metadata.CBOMSchema); err != nil {
+		return err
+	}
+	legacy := generateCBOMReport(results, metadata, mode)
+	var report interface{} = legacy
+	if metadata.CBOMSchema == CBOMSchema16 {
+		report = cbom16Report(legacy)
+	}
+
 	jsonData, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
-		fmt.Printf("Error converting CBOM to JSON: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error converting CBOM to JSON: %w", err)
 	}
 
-	fmt.Println(string(jsonData))
+	_, err = fmt.Fprintln(w, string(jsonData))
+	return err
 }
 
 // generateCBOMReport creates a comprehensive CBOM report
+// The same results and metadata always produce the same report: findings and
+// components are sorted, and the timestamp and serial number come from the scan time.
 func generateCBOMReport(results []crypto.Result, metadata ScanMetadata, mode string) CBOMReport {
-	timestamp := GetCurrentTimestamp()
-	
-	// Generate unique serial number based on timestamp and target
-	serialNumber := fmt.Sprintf("urn:uuid:qvs-pro-%s-%d", mode, time.Now().Unix())
-	
+	timestamp := metadata.ScanTime
+	if timestamp == "" {
+		timestamp = GetCurrentTimestamp()
+	}
+	scanTime := time.Now()
+	if parsed, err := time.Parse(time.RFC3339, timestamp); err == nil {
+		scanTime = parsed
+	}
+
+	// Generate unique serial number based on scan time and target
+	serialNumber := fmt.Sprintf("urn:uuid:qvs-pro-%s-%d", mode, scanTime.Unix())
+
 	// Create components from scan results
 	components := make([]CBOMComponent, 0)
-	algorithmBreakdown := make(map[string]int)
-	riskBreakdown := make(map[string]int)
-	vulnerableAssets := 0
-	quantumSafeAssets := 0
-	
+
 	// Process results to create components and statistics
-	processedFiles := make(map[string]bool)
-	
-	for _, result := range results {
-		// Count algorithm usage
-		algorithmBreakdown[result.Algorithm]++
-		riskBreakdown[result.Risk]++
-		
-		// Count vulnerable vs quantum-safe assets
-		if result.Type == "PostQuantum" {
-			quantumSafeAssets++
-		} else if result.Risk == "High" || result.Risk == "Medium" {
-			vulnerableAssets++
-		}
-		
+	processedFiles := make(map[string]string) // component name -> bom-ref
+
+	// Certificate chains are emitted leaf first; link each certificate to its issuer
+	chainLinks := make(map[string][]string)
+	previousCert := ""
+
+	sorted := SortFindings(results)
+	for _, result := range sorted {
 		// Create component if file not already processed
-		if !processedFiles[result.File] {
+		name := componentName(result)
+		if _, ok := processedFiles[name]; !ok {
 			component := CBOMComponent{
-				Type:    "file",
-				BOMRef:  fmt.Sprintf("file-%d", len(components)),
-				Name:    result.File,
-				Scope:   "required",
+				Type:  "file",
+				Name:  name,
+				Scope: "required",
 				Crypto: CBOMCrypto{
-					Algorithm:   result.Algorithm,
-					Purpose:     result.Type,
-					QuantumSafe: result.Type == "PostQuantum",
-					QuantumRisk: result.VulnerabilityType,
+					Algorithm:       result.Algorithm,
+					KeySize:         result.KeySize,
+					Curve:           result.Curve,
+					Purpose:         result.Type,
+					QuantumSafe:     crypto.IsQuantumSafe(result),
+					QuantumRisk:     result.VulnerabilityType,
+					CryptoFunctions: crypto.CryptoFunctions(result.Purpose),
 				},
 				Evidence: CBOMEvidence{
 					Identity: []CBOMIdentity{
 						{
 							Field:      "source-code",
-							Confidence: 0.95,
+							Confidence: evidenceConfidence(result),
 							Methods:    []string{"regex-pattern-matching", "static-analysis"},
 						},
 					},
 				},
 			}
+			if result.Simulated {
+				component.Evidence.Identity[0].Methods = []string{"simulated-fallback"}
+			}
+			// Only file scans have content to hash; k8s/pcap/network sources and archive entries don't
+			digest := ""
+			if mode == "file" {
+				if sum, ok := fileSHA256(result.File); ok {
+					digest = sum
+					component.Hashes = []CBOMHash{{Algorithm: "SHA-256", Content: digest}}
+				}
+			}
+			component.BOMRef = contentRef("file", name, digest)
+			if result.PURL != "" {
+				component.Version = result.ComponentVersion
+				component.PURL = result.PURL
+				component.Evidence.Identity = append(component.Evidence.Identity, CBOMIdentity{
+					Field:      "purl",
+					Confidence: 0.8,
+					Methods:    []string{"sbom-correlation"},
+				})
+			}
 			components = append(components, component)
-			processedFiles[result.File] = true
+			processedFiles[name] = component.BOMRef
 		}
 	}
-	
+
+	// Chains are linked in scan order, which sorting by file would break
+	for _, result := range results {
+		ref := processedFiles[componentName(result)]
+		switch result.ChainPosition {
+		case crypto.ChainPositionLeaf:
+			previousCert = ref
+		case crypto.ChainPositionIntermediate, crypto.ChainPositionRoot:
+			if previousCert != "" && previousCert != ref {
+				chainLinks[previousCert] = appendUnique(chainLinks[previousCert], ref)
+			}
+			previousCert = ref
+			if result.ChainPosition == crypto.ChainPositionRoot {
+				previousCert = ""
+			}
+		default:
+			previousCert = ""
+		}
+	}
+
+	// The scanned target depends on every asset found in it
+	application := &CBOMMetadataComponent{
+		Type:   "application",
+		BOMRef: contentRef("application", mode, metadata.Target),
+		Name:   metadata.Target,
+	}
+	if application.Name == "" {
+		application.Name = mode
+	}
+	dependencies := []CBOMDependency{{Ref: application.BOMRef, DependsOn: make([]string, 0, len(components))}}
+	for _, component := range components {
+		dependencies[0].DependsOn = append(dependencies[0].DependsOn, component.BOMRef)
+	}
+	for _, component := range components {
+		if issuers, ok := chainLinks[component.BOMRef]; ok {
+			dependencies = append(dependencies, CBOMDependency{Ref: component.BOMRef, DependsOn: issuers})
+		}
+	}
+
 	// Create CBOM metadata
 	cbomMetadata := CBOMMetadata{
 		Timestamp: timestamp,
@@ -247,22 +630,16 @@ func generateCBOMReport(results []crypto.Result, metadata ScanMetadata, mode str
 				Email: "scanner@qvs-pro.com",
 			},
 		},
+		Component: application,
 		Supplier: CBOMSupplier{
 			Name: "QVS-Pro",
 			URL:  "https://qvs-pro.com",
 		},
+		Configuration: metadata.Configuration,
 	}
-	
-	// Create summary
-	summary := CBOMSummary{
-		TotalAssets:        metadata.TotalAssets,
-		VulnerableAssets:   vulnerableAssets,
-		QuantumSafeAssets:  quantumSafeAssets,
-		RiskBreakdown:      riskBreakdown,
-		AlgorithmBreakdown: algorithmBreakdown,
-		ScanDuration:       metadata.Duration,
-	}
-	
+
+	summary := Summarize(results, metadata)
+
 	// Create the complete CBOM report
 	report := CBOMReport{
 		BOMFormat:    "CycloneDX",
@@ -271,9 +648,151 @@ func generateCBOMReport(results []crypto.Result, metadata ScanMetadata, mode str
 		Version:      1,
 		Metadata:     cbomMetadata,
 		Components:   components,
-		Findings:     results,
+		Findings:     sorted,
 		Summary:      summary,
+		Dependencies: dependencies,
+		Warnings:     metadata.Warnings,
 	}
-	
+
 	return report
 }
+
+// fileSHA256 returns the hex SHA-256 of a regular file's content, or false if it can't be read
+func fileSHA256(path string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", false
+	}
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
+
+// componentName names the component of a finding: its file, or for a certificate the
+// certificate within it, so each certificate of a chain is its own component and
+// can be linked to its issuer
+func componentName(result crypto.Result) string {
+	if result.ChainPosition == "" || result.Subject == "" {
+		return result.File
+	}
+	return fmt.Sprintf("%s [%s certificate: %s]", result.File, result.ChainPosition, result.Subject)
+}
+
+// contentRef derives a stable bom-ref from the identifying parts of a component,
+// so the same asset gets the same reference across scans
+func contentRef(prefix string, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return prefix + "-" + hex.EncodeToString(sum[:8])
+}
+
+// appendUnique appends value to list unless it is already present
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// SortFindings returns a copy of results ordered by file, line, algorithm and
+// method, so output does not depend on the order files finished scanning
+func SortFindings(results []crypto.Result) []crypto.Result {
+	sorted := append([]crypto.Result(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Algorithm != b.Algorithm {
+			return a.Algorithm < b.Algorithm
+		}
+		return a.Method < b.Method
+	})
+	return sorted
+}
+
+// Summarize computes the CBOM summary of results: asset totals, risk and
+// algorithm breakdowns and the crypto agility score
+func Summarize(results []crypto.Result, metadata ScanMetadata) CBOMSummary {
+	algorithmBreakdown := make(map[string]int)
+	riskBreakdown := make(map[string]int)
+	var environmentBreakdown map[string]int
+	files := make(map[string]bool)           // files with findings
+	vulnerableFiles := make(map[string]bool) // an asset is vulnerable if any of its findings is
+	for _, result := range results {
+		algorithmBreakdown[crypto.CanonicalAlgorithm(result.Algorithm)]++
+		riskBreakdown[result.Risk]++
+		if result.Environment != "" {
+			if environmentBreakdown == nil {
+				environmentBreakdown = make(map[string]int)
+			}
+			environmentBreakdown[result.Environment]++
+		}
+		files[result.File] = true
+		if !crypto.IsQuantumSafe(result) {
+			vulnerableFiles[result.File] = true
+		}
+	}
+
+	// Every asset is either vulnerable or quantum-safe
+	vulnerableAssets := len(vulnerableFiles)
+	quantumSafeAssets := len(files) - vulnerableAssets
+	quantumSafeRatio := 0.0
+	if len(files) > 0 {
+		quantumSafeRatio = float64(quantumSafeAssets) / float64(len(files))
+	}
+
+	agilityScore, agilityGrade := crypto.CryptoAgilityScore(results)
+	return CBOMSummary{
+		TotalAssets:          metadata.TotalAssets,
+		VulnerableAssets:     vulnerableAssets,
+		QuantumSafeAssets:    quantumSafeAssets,
+		QuantumSafeRatio:     quantumSafeRatio,
+		RiskBreakdown:        riskBreakdown,
+		AlgorithmBreakdown:   algorithmBreakdown,
+		EnvironmentBreakdown: environmentBreakdown,
+		ScanDuration:         metadata.Duration,
+		CryptoAgilityScore:   agilityScore,
+		CryptoAgilityGrade:   agilityGrade,
+		SimulatedFindings:    countSimulated(results),
+		Targets:              metadata.Targets,
+		FilteredFindings:     metadata.FilteredFindings,
+		Timing:               metadata.Timing,
+		Compliance:           metadata.Compliance,
+	}
+}
+
+// evidenceConfidence is the confidence of a finding's source evidence, defaulting
+// to 0.95 for detectors that don't score their matches; simulated results have none
+func evidenceConfidence(result crypto.Result) float64 {
+	if result.Simulated {
+		return 0
+	}
+	if result.Confidence > 0 {
+		return result.Confidence
+	}
+	return 0.95
+}
+
+// countSimulated returns how many results are fallback placeholders rather than observed findings
+func countSimulated(results []crypto.Result) int {
+	count := 0
+	for _, result := range results {
+		if result.Simulated {
+			count++
+		}
+	}
+	return count
+}
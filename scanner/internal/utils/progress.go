@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// progressBarWidth is the number of cells in the progress bar
+const progressBarWidth = 30
+
+// NewProgressBar returns a progress callback that redraws a single-line bar on w
+func NewProgressBar(w io.Writer) func(scanned, total int, current string) {
+	return func(scanned, total int, current string) {
+		if total <= 0 {
+			return
+		}
+		filled := scanned * progressBarWidth / total
+		bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+		fmt.Fprintf(w, "\r\033[K[%s] %d/%d %s", bar, scanned, total, truncateLeft(current, 50))
+		if scanned == total {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// IsTerminal reports whether f is attached to a terminal rather than a file or pipe
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// truncateLeft shortens s to at most n characters, keeping its end
+func truncateLeft(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n+3:]
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"qvs-pro/scanner/internal/crypto"
+)
+
+// PathRedactor replaces file paths with stable salted hashes so results can leave
+// privacy-sensitive environments. The same salt always maps a path to the same
+// hash, letting findings be correlated across scans within an organization.
+type PathRedactor struct {
+	Salt string
+	Root string // Optional absolute scan root; paths inside it are made relative instead of hashed
+}
+
+// Redact returns the redacted form of p: a path relative to Root when p lies
+// inside it, otherwise "redacted/" followed by a salted hash and p's extension
+func (r PathRedactor) Redact(p string) string {
+	if r.Root != "" {
+		if rel, err := filepath.Rel(r.Root, p); err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(r.Salt + "\x00" + filepath.ToSlash(p)))
+	return "redacted/" + hex.EncodeToString(sum[:8]) + path.Ext(filepath.ToSlash(p))
+}
+
+// RedactResults redacts the file of each result in place
+func (r PathRedactor) RedactResults(results []crypto.Result) {
+	for i := range results {
+		results[i].File = r.Redact(results[i].File)
+	}
+}
+
+// RedactList redacts each path of a comma-separated list, such as ScanMetadata.Target
+func (r PathRedactor) RedactList(list string) string {
+	if list == "" {
+		return ""
+	}
+	paths := strings.Split(list, ",")
+	for i, p := range paths {
+		paths[i] = r.Redact(p)
+	}
+	return strings.Join(paths, ",")
+}
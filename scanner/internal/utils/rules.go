@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"qvs-pro/scanner/internal/crypto"
+)
+
+// RuleInfo describes one detection rule for -list-rules
+type RuleInfo struct {
+	Algorithm       string   `json:"algorithm"`
+	Type            string   `json:"type"`
+	Method          string   `json:"method"`
+	Pattern         string   `json:"pattern"`
+	Risk            string   `json:"risk"`
+	CWE             []string `json:"cwe,omitempty"`
+	NISTAlgorithmID string   `json:"nist_algorithm_id,omitempty"`
+	NISTCategory    string   `json:"nist_category,omitempty"`
+	MultiLine       bool     `json:"multi_line,omitempty"` // Pattern is matched across lines
+}
+
+// DescribeRules returns the listing of rules in rule order, resolving each rule's
+// NIST IR 8547 link to its category
+func DescribeRules(rules []crypto.DetectionRule) []RuleInfo {
+	infos := make([]RuleInfo, 0, len(rules))
+	for _, rule := range rules {
+		info := RuleInfo{
+			Algorithm:       rule.AlgorithmName,
+			Type:            rule.AlgorithmType,
+			Method:          rule.Method,
+			Pattern:         rule.Pattern,
+			Risk:            rule.RiskLevel,
+			NISTAlgorithmID: rule.NISTAlgorithmID,
+			CWE:             rule.CWE,
+			MultiLine:       rule.MultiLine,
+		}
+		if nist := crypto.GetNISTInfo(rule.NISTAlgorithmID); nist != nil {
+			info.NISTCategory = string(nist.Category)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// OutputRules writes rules to w as a JSON array when format is "json", or as a
+// table otherwise
+func OutputRules(w io.Writer, rules []crypto.DetectionRule, format string) error {
+	infos := DescribeRules(rules)
+	if format == "json" {
+		return OutputJSON(w, infos)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ALGORITHM\tTYPE\tMETHOD\tRISK\tNIST\tPATTERN")
+	for _, info := range infos {
+		nist := "-"
+		if info.NISTAlgorithmID != "" {
+			nist = info.NISTAlgorithmID
+			if info.NISTCategory != "" {
+				nist += " (" + info.NISTCategory + ")"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", info.Algorithm, info.Type, info.Method, info.Risk, nist, info.Pattern)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d rules\n", len(infos))
+	return err
+}
@@ -0,0 +1,110 @@
+// Package webhook pushes rendered scan results to an HTTP collector.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Defaults applied when Config leaves them unset
+const (
+	DefaultTimeout = 30 * time.Second
+	DefaultRetries = 3
+)
+
+// Config describes where and how results are posted
+type Config struct {
+	URL        string
+	AuthHeader string        // Optional "Name: value" header, e.g. "Authorization: Bearer <token>"
+	Timeout    time.Duration // Per-attempt timeout (default: 30s)
+	Retries    int           // Attempts after the first on network errors, 429 and 5xx (default: 3)
+	Backoff    time.Duration // Delay before the first retry, doubled on each retry (default: 1s)
+	Client     *http.Client  // HTTP client (default: http.DefaultClient)
+}
+
+// Post sends body to cfg.URL, retrying transient failures. Any non-2xx final
+// response is returned as an error.
+func Post(ctx context.Context, cfg Config, contentType string, body []byte) error {
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	retries := cfg.Retries
+	if retries < 0 {
+		retries = 0
+	} else if retries == 0 {
+		retries = DefaultRetries
+	}
+	backoff := cfg.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	var headerName, headerValue string
+	if cfg.AuthHeader != "" {
+		name, value, ok := strings.Cut(cfg.AuthHeader, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid webhook auth header %q: expected \"Name: value\"", cfg.AuthHeader)
+		}
+		headerName, headerValue = strings.TrimSpace(name), strings.TrimSpace(value)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff << (attempt - 1)):
+			case <-ctx.Done():
+				return fmt.Errorf("webhook post canceled: %w (last error: %v)", ctx.Err(), lastErr)
+			}
+		}
+
+		retry, err := postOnce(ctx, client, timeout, cfg.URL, contentType, headerName, headerValue, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+// postOnce makes a single POST attempt and reports whether a failure is worth retrying
+func postOnce(ctx context.Context, client *http.Client, timeout time.Duration, url, contentType, headerName, headerValue string, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("error creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if headerName != "" {
+		req.Header.Set(headerName, headerValue)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("error posting to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned %s", resp.Status)
+}
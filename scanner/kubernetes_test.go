@@ -0,0 +1,753 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	k8stesting "k8s.io/client-go/testing"
+	"qvs-pro/scanner/internal/crypto"
+)
+
+// scanSecretsWithFakeClient scans the given secrets with a fake clientset
+func scanSecretsWithFakeClient(t *testing.T, secrets ...*corev1.Secret) []crypto.Result {
+	t.Helper()
+	client := fake.NewSimpleClientset()
+	for _, secret := range secrets {
+		if _, err := client.CoreV1().Secrets(secret.Namespace).Create(context.Background(), secret, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	k8s := crypto.NewK8sScannerWithClient(crypto.NewScanner(false), client)
+	results, _ := k8s.ScanKubernetesCluster(context.Background(), []string{"apps"}, crypto.KubernetesScanOptions{SecretScan: true})
+	return results
+}
+
+func TestKubernetesTLSSecretAnalysis(t *testing.T) {
+	root, rootKey := newTestCertificate(t, "Test Root CA", true, nil, nil)
+	leaf, leafKey := newTestCertificate(t, "shop.example", false, root, rootKey)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})
+	keyDER, err := x509.MarshalECPrivateKey(leafKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	results := scanSecretsWithFakeClient(t,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop-tls", Namespace: "apps"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{"tls.crt": certPEM, "tls.key": keyPEM},
+		},
+		// Some tools base64-encode PEM before storing it, so the value is base64 text
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "wrapped", Namespace: "apps"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"ca.pem": []byte(base64.StdEncoding.EncodeToString(certPEM))},
+		},
+	)
+
+	var certFindings int
+	for _, result := range results {
+		if result.Algorithm != "ECDSA" {
+			t.Errorf("Expected only ECDSA findings, got %s in %s (%s)", result.Algorithm, result.File, result.Method)
+		}
+		if result.Method == "TLS Certificate Analysis" && strings.Contains(result.Description, "shop.example") {
+			certFindings++
+			if result.NISTAlgorithmID != "ECDSA-P256" {
+				t.Errorf("Expected parsed certificate key size, got NIST ID %q", result.NISTAlgorithmID)
+			}
+		}
+	}
+	if certFindings != 2 {
+		t.Errorf("Expected the certificate to be parsed from both secrets, got %d findings: %+v", certFindings, results)
+	}
+}
+
+func TestKubernetesPKCS8KeyClassification(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8 := func(key interface{}) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+
+	results := scanSecretsWithFakeClient(t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keys", Namespace: "apps"},
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"rsa.key":   pkcs8(rsaKey),
+			"ec.key":    pkcs8(ecKey),
+			"pkcs1.key": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+		},
+	})
+
+	expected := map[string]string{
+		"secret/keys/rsa.key (apps)":   "RSA-2048",
+		"secret/keys/ec.key (apps)":    "ECDSA-P384",
+		"secret/keys/pkcs1.key (apps)": "RSA-2048",
+	}
+	found := make(map[string]string)
+	for _, result := range results {
+		if result.Method == "TLS Key Analysis" {
+			found[result.File] = result.NISTAlgorithmID
+		}
+	}
+	for file, id := range expected {
+		if found[file] != id {
+			t.Errorf("%s: expected %s, got %q", file, id, found[file])
+		}
+	}
+	for _, result := range results {
+		if result.File == "secret/keys/ec.key (apps)" && result.Algorithm == "RSA" {
+			t.Errorf("PKCS#8 EC key misreported as RSA: %+v", result)
+		}
+	}
+}
+
+func TestKubernetesIngressTLSResolution(t *testing.T) {
+	root, rootKey := newTestCertificate(t, "Test Root CA", true, nil, nil)
+	leaf, _ := newTestCertificate(t, "www.example", false, root, rootKey)
+
+	client := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "www-tls", Namespace: "apps"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "www",
+				Namespace:   "apps",
+				Annotations: map[string]string{"nginx.ingress.kubernetes.io/ssl-ciphers": "ECDHE-ECDSA-AES128-GCM-SHA256:AES256-SHA:DES-CBC3-SHA:!aNULL"},
+			},
+			Spec: networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{{Hosts: []string{"www.example"}, SecretName: "www-tls"}}},
+		},
+	)
+	k8s := crypto.NewK8sScannerWithClient(crypto.NewScanner(false), client)
+	results, assets := k8s.ScanKubernetesCluster(context.Background(), []string{"apps"}, crypto.KubernetesScanOptions{IngressScan: true})
+	if assets != 1 {
+		t.Errorf("Expected 1 ingress asset, got %d", assets)
+	}
+
+	byFile := make(map[string][]string)
+	for _, result := range results {
+		byFile[result.File] = append(byFile[result.File], result.Algorithm)
+		if result.File == "ingress/www/tls/www-tls (apps)" && result.NISTAlgorithmID != "ECDSA-P256" {
+			t.Errorf("Expected the referenced certificate's key size, got %q", result.NISTAlgorithmID)
+		}
+	}
+	if got := strings.Join(byFile["ingress/www/tls/www-tls (apps)"], ","); got != "ECDSA" {
+		t.Errorf("Expected the ingress certificate to be reported as ECDSA, got %q", got)
+	}
+	if got := strings.Join(byFile["ingress/www (apps)"], ","); got != "3DES,ECDH,RSA" {
+		t.Errorf("Expected pinned ciphers to report 3DES, ECDH and RSA, got %q", got)
+	}
+}
+
+func TestKubernetesWebhookCABundles(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notAfter := time.Date(2031, 3, 1, 0, 0, 0, 0, time.UTC)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "policy-webhook-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset(&admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name:         "validate.policy.example",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})},
+			},
+			{Name: "audit.policy.example"},
+		},
+	})
+	k8s := crypto.NewK8sScannerWithClient(crypto.NewScanner(false), client)
+	results, assets := k8s.ScanKubernetesCluster(context.Background(), []string{"apps"}, crypto.KubernetesScanOptions{WebhookScan: true})
+	if assets != 2 {
+		t.Errorf("Expected both webhooks to be counted, got %d assets", assets)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected one finding for the webhook with a CA bundle, got %+v", results)
+	}
+
+	result := results[0]
+	if result.File != "validatingwebhookconfiguration/policy/validate.policy.example" || result.Method != "Webhook CA Bundle Analysis" {
+		t.Errorf("Unexpected location %q or method %q", result.File, result.Method)
+	}
+	if result.Algorithm != "RSA" || result.KeySize != 2048 {
+		t.Errorf("Expected an RSA-2048 finding, got %s-%d", result.Algorithm, result.KeySize)
+	}
+	if result.NotAfter == nil || !result.NotAfter.Equal(notAfter) {
+		t.Errorf("Expected the CA's expiry %s, got %v", notAfter, result.NotAfter)
+	}
+}
+
+func TestKubernetesPodEnvironment(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "apps"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate", Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}}},
+			Containers: []corev1.Container{{
+				Name: "server",
+				Env: []corev1.EnvVar{
+					{Name: "PORT", Value: "8443"},
+					{Name: "NODE_OPTIONS", Value: "--tls-min-v1.0"},
+					{Name: "JAVA_TOOL_OPTIONS", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "opts"}}},
+				},
+			}},
+		},
+	})
+	k8s := crypto.NewK8sScannerWithClient(crypto.NewScanner(false), client)
+	results, assets := k8s.ScanKubernetesCluster(context.Background(), []string{"apps"}, crypto.KubernetesScanOptions{EnvScan: true})
+	if assets != 2 {
+		t.Errorf("Expected both containers to be counted, got %d assets", assets)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected one finding for the weak TLS minimum, got %+v", results)
+	}
+
+	result := results[0]
+	if result.File != "pod/api/container/server/env (apps)" || result.Line != 2 || result.Method != "Environment Variable Analysis" {
+		t.Errorf("Unexpected location %s:%d or method %q", result.File, result.Line, result.Method)
+	}
+	if result.Algorithm != "TLS 1.0" || result.Risk != "High" {
+		t.Errorf("Expected a High TLS 1.0 finding, got %s %s", result.Risk, result.Algorithm)
+	}
+}
+
+// fixtureImageFetcher serves a prebuilt image tarball for every image
+type fixtureImageFetcher struct {
+	path    string
+	fetched []string
+}
+
+func (f *fixtureImageFetcher) FetchImage(ctx context.Context, image, dir string) (string, error) {
+	f.fetched = append(f.fetched, image)
+	return f.path, nil
+}
+
+// writeTar writes files to w as a tar stream
+func writeTar(t *testing.T, w io.Writer, files map[string][]byte, mode int64) {
+	t.Helper()
+	tw := tar.NewWriter(w)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: int64(len(files[name])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKubernetesDeepCodeScan(t *testing.T) {
+	// A docker save tarball with one gzip-compressed layer
+	var layer bytes.Buffer
+	gz := gzip.NewWriter(&layer)
+	writeTar(t, gz, map[string][]byte{
+		"app/src/KeyGen.java":     []byte(`KeyPairGenerator kpg = KeyPairGenerator.getInstance("RSA");`),
+		"usr/share/doc/Demo.java": []byte(`KeyPairGenerator kpg = KeyPairGenerator.getInstance("RSA");`),
+	}, 0644)
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var image bytes.Buffer
+	writeTar(t, &image, map[string][]byte{
+		"manifest.json":    []byte(`[{"Config":"config.json","RepoTags":["shop/api:1.0"],"Layers":["abc123/layer.tar"]}]`),
+		"config.json":      []byte(`{}`),
+		"abc123/layer.tar": layer.Bytes(),
+	}, 0644)
+	imagePath := filepath.Join(t.TempDir(), "image.tar")
+	if err := os.WriteFile(imagePath, image.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "apps"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "api", Image: "shop/api:1.0"},
+			{Name: "worker", Image: "shop/api:1.0"},
+		}},
+	})
+
+	for _, deep := range []bool{false, true} {
+		fetcher := &fixtureImageFetcher{path: imagePath}
+		k8s := crypto.NewK8sScannerWithClient(crypto.NewScanner(false), client)
+		k8s.ImageFetcher = fetcher
+		results, _ := k8s.ScanKubernetesCluster(context.Background(), []string{"apps"}, crypto.KubernetesScanOptions{DeepCodeScan: deep})
+
+		files := make(map[string]bool)
+		for _, result := range results {
+			files[result.File] = true
+		}
+		if !deep {
+			if len(results) != 0 || len(fetcher.fetched) != 0 {
+				t.Errorf("deep scan disabled: expected no findings or fetches, got %d findings, fetched %v", len(results), fetcher.fetched)
+			}
+			continue
+		}
+
+		if len(fetcher.fetched) != 1 {
+			t.Errorf("expected the shared image to be fetched once, got %v", fetcher.fetched)
+		}
+		for _, want := range []string{
+			"pod/api-0/container/api/app/src/KeyGen.java (apps)",
+			"pod/api-0/container/worker/app/src/KeyGen.java (apps)",
+		} {
+			if !files[want] {
+				t.Errorf("missing deep finding for %s, got %v", want, files)
+			}
+		}
+		for file := range files {
+			if strings.Contains(file, "usr/share") {
+				t.Errorf("system path should not be deep scanned: %s", file)
+			}
+		}
+	}
+}
+
+func TestImageTarUnreadableLayer(t *testing.T) {
+	var layer bytes.Buffer
+	writeTar(t, &layer, map[string][]byte{"app/src/KeyGen.java": []byte(`KeyPairGenerator.getInstance("RSA")`)}, 0644)
+	var image bytes.Buffer
+	writeTar(t, &image, map[string][]byte{
+		"manifest.json":    []byte(`[{"Config":"config.json","Layers":["good/layer.tar","broken/layer.tar"]}]`),
+		"good/layer.tar":   layer.Bytes(),
+		"broken/layer.tar": {0x1F, 0x8B, 0x08, 0x00}, // A truncated gzip header
+	}, 0644)
+	imagePath := filepath.Join(t.TempDir(), "image.tar")
+	if err := os.WriteFile(imagePath, image.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The readable layer is still scanned; the other is a report warning, verbose or not
+	scanner := crypto.NewScanner(false)
+	results, _ := scanner.ScanImageTar(imagePath)
+	if len(results) != 1 || results[0].File != "app/src/KeyGen.java" {
+		t.Errorf("expected the readable layer's finding, got %+v", results)
+	}
+	if warnings := scanner.Warnings(); len(warnings) != 1 || warnings[0].File != "broken/layer.tar" || !strings.Contains(warnings[0].Reason, "image layer") {
+		t.Errorf("expected a warning for the unreadable layer, got %+v", warnings)
+	}
+}
+
+func TestKubernetesConcurrentNamespaceScan(t *testing.T) {
+	var objects []runtime.Object
+	var namespaces []string
+	for i := 0; i < 25; i++ {
+		namespace := fmt.Sprintf("team-%02d", i)
+		namespaces = append(namespaces, namespace)
+		objects = append(objects,
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: namespace},
+				Data:       map[string][]byte{"crypto.txt": []byte(`KeyPairGenerator.getInstance("RSA")`)},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: namespace},
+				Data:       map[string]string{"ssl.conf": "ssl_ciphers RC4-MD5;"},
+			},
+		)
+	}
+	client := fake.NewSimpleClientset(objects...)
+
+	// team-03 is throttled once before succeeding; team-07 always fails
+	var mu sync.Mutex
+	throttled := false
+	client.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		switch action.GetNamespace() {
+		case "team-03":
+			mu.Lock()
+			defer mu.Unlock()
+			if !throttled {
+				throttled = true
+				return true, nil, apierrors.NewTooManyRequests("slow down", 0)
+			}
+		case "team-07":
+			return true, nil, apierrors.NewForbidden(corev1.Resource("secrets"), "", fmt.Errorf("denied"))
+		}
+		return false, nil, nil
+	})
+
+	k8s := crypto.NewK8sScannerWithClient(crypto.NewScanner(false), client)
+	results, assets := k8s.ScanKubernetesCluster(context.Background(), namespaces, crypto.KubernetesScanOptions{SecretScan: true, ConfigMapScan: true})
+
+	// 25 ConfigMaps plus 24 readable secrets
+	if assets != 49 {
+		t.Errorf("expected 49 assets, got %d", assets)
+	}
+	if !throttled {
+		t.Error("expected the throttled namespace to be listed")
+	}
+	secrets := make(map[string]bool)
+	configMaps := make(map[string]bool)
+	for _, result := range results {
+		switch {
+		case strings.HasPrefix(result.File, "secret/"):
+			secrets[result.File] = true
+		case strings.HasPrefix(result.File, "configmap/"):
+			configMaps[result.File] = true
+		}
+	}
+	for _, namespace := range namespaces {
+		if want := fmt.Sprintf("secret/app-config/crypto.txt (%s)", namespace); secrets[want] == (namespace == "team-07") {
+			t.Errorf("unexpected secret coverage for %s: found=%v", namespace, secrets[want])
+		}
+		if want := fmt.Sprintf("configmap/tls/ssl.conf (%s)", namespace); !configMaps[want] {
+			t.Errorf("missing ConfigMap finding for %s", namespace)
+		}
+	}
+}
+
+func TestKubernetesConcurrentAssetCount(t *testing.T) {
+	var objects []runtime.Object
+	var namespaces []string
+	want := 0
+	for i := 0; i < 16; i++ {
+		namespace := fmt.Sprintf("ns-%02d", i)
+		namespaces = append(namespaces, namespace)
+		for j := 0; j < i%3+1; j++ {
+			objects = append(objects, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("secret-%d", j), Namespace: namespace}})
+			want++
+		}
+		for j := 0; j < i%4; j++ {
+			objects = append(objects, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("config-%d", j), Namespace: namespace}})
+			want++
+		}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace}}
+		for j := 0; j < i%2+1; j++ {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: fmt.Sprintf("c%d", j), Image: "openssl:3"})
+			want++
+		}
+		objects = append(objects, pod)
+		for j := 0; j < i%2; j++ {
+			objects = append(objects, &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("policy-%d", j), Namespace: namespace}})
+			want++
+		}
+		if i%5 == 0 {
+			objects = append(objects, &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace}})
+			want++
+		}
+	}
+	client := fake.NewSimpleClientset(objects...)
+	scan := func(namespaces []string) ([]crypto.Result, int) {
+		// A scanner per scan, so runs don't share the API rate limit
+		k8s := crypto.NewK8sScannerWithClient(crypto.NewScanner(false), client)
+		return k8s.ScanKubernetesCluster(context.Background(), namespaces, crypto.KubernetesScanOptions{SecretScan: true, ConfigMapScan: true, ImageScan: true, NetworkPolicyScan: true, IngressScan: true})
+	}
+
+	// Scanning one namespace at a time is the serial count
+	serial := 0
+	for _, namespace := range namespaces {
+		_, assets := scan([]string{namespace})
+		serial += assets
+	}
+	if serial != want {
+		t.Fatalf("expected %d assets from serial scans, got %d", want, serial)
+	}
+
+	first, _ := scan(namespaces)
+	for run := 0; run < 3; run++ {
+		results, assets := scan(namespaces)
+		if assets != serial {
+			t.Fatalf("run %d: expected %d assets, got %d", run, serial, assets)
+		}
+		if len(results) != len(first) {
+			t.Fatalf("run %d: expected %d findings, got %d", run, len(first), len(results))
+		}
+		for i := range results {
+			if results[i].File != first[i].File {
+				t.Fatalf("run %d: finding %d is %s, expected %s", run, i, results[i].File, first[i].File)
+			}
+		}
+	}
+}
+
+func TestBuildKubeConfigContextSelection(t *testing.T) {
+	kubeconfig := `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example:6443
+- name: staging
+  cluster:
+    server: https://staging.example:6443
+users:
+- name: ci
+  user:
+    token: test-token
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: ci
+- name: staging
+  context:
+    cluster: staging
+    user: ci
+`
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for kubeContext, want := range map[string]string{
+		"":        "https://prod.example:6443",
+		"staging": "https://staging.example:6443",
+	} {
+		config, err := crypto.BuildKubeConfig(path, kubeContext)
+		if err != nil {
+			t.Fatalf("context %q: %v", kubeContext, err)
+		}
+		if config.Host != want {
+			t.Errorf("context %q: expected host %s, got %s", kubeContext, want, config.Host)
+		}
+	}
+
+	if _, err := crypto.BuildKubeConfig(path, "missing"); err == nil {
+		t.Error("expected an error for an unknown context")
+	}
+}
+
+func TestKubernetesConfigMapTLSDirectives(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "apps"},
+		Data: map[string]string{
+			"nginx.conf":  "server {\n    listen 443 ssl;\n    ssl_ciphers ECDHE-RSA-AES256-GCM-SHA384:DES-CBC3-SHA:!aNULL;\n}\n",
+			"sshd_config": "Port 22\nKexAlgorithms sntrup761x25519-sha512@openssh.com,curve25519-sha256,diffie-hellman-group14-sha1\n",
+		},
+	})
+	k8s := crypto.NewK8sScannerWithClient(crypto.NewScanner(false), client)
+	results, _ := k8s.ScanKubernetesCluster(context.Background(), []string{"apps"}, crypto.KubernetesScanOptions{ConfigMapScan: true})
+
+	found := make(map[string]crypto.Result)
+	for _, result := range results {
+		if result.Method != "TLS Configuration Analysis" {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d %s", result.File, result.Line, result.Algorithm)
+		if _, dup := found[key]; dup {
+			t.Errorf("duplicate finding %s", key)
+		}
+		found[key] = result
+	}
+
+	// Each vulnerable entry is reported on its own
+	nginx := "configmap/edge/nginx.conf (apps):3 "
+	ssh := "configmap/edge/sshd_config (apps):2 "
+	for _, want := range []string{nginx + "ECDH", nginx + "RSA", nginx + "3DES", ssh + "X25519", ssh + "DH"} {
+		if _, ok := found[want]; !ok {
+			t.Errorf("missing finding %q, got %v", want, found)
+		}
+	}
+	if len(found) != 5 {
+		t.Errorf("expected 5 directive findings, got %d: %v", len(found), found)
+	}
+	if weak := found[nginx+"3DES"]; !strings.Contains(weak.Description, "DES-CBC3-SHA") {
+		t.Errorf("expected the weak suite to be named, got %q", weak.Description)
+	}
+	if dh := found[ssh+"DH"]; !strings.Contains(dh.Description, "diffie-hellman-group14-sha1") {
+		t.Errorf("expected the KEX entry to be named, got %q", dh.Description)
+	}
+}
+
+func TestKubernetesConfigMapWeakDH(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "apps"},
+		Data: map[string]string{
+			"haproxy.cfg": "global\n    tune.ssl.default-dh-param 1024\n",
+			"sshd_config": "KexAlgorithms curve25519-sha256,diffie-hellman-group1-sha1\n",
+		},
+	})
+	k8s := crypto.NewK8sScannerWithClient(crypto.NewScanner(false), client)
+	results, _ := k8s.ScanKubernetesCluster(context.Background(), []string{"apps"}, crypto.KubernetesScanOptions{ConfigMapScan: true})
+
+	found := make(map[string]crypto.Result)
+	for _, result := range results {
+		if result.Method == "TLS Configuration Analysis" && result.Algorithm == "DH" {
+			found[result.File] = result
+		}
+	}
+	for _, file := range []string{"configmap/edge/haproxy.cfg (apps)", "configmap/edge/sshd_config (apps)"} {
+		dh, ok := found[file]
+		if !ok {
+			t.Errorf("missing 1024-bit DH finding in %s, got %v", file, found)
+			continue
+		}
+		if dh.Risk != "Critical" || !reflect.DeepEqual(dh.CWE, []string{"CWE-326"}) {
+			t.Errorf("expected the 1024-bit group in %s to be Critical with CWE-326, got %+v", file, dh)
+		}
+	}
+}
+
+// pagedClient serves secrets from a page-aware List, as the API server does, on
+// top of a fake clientset, which ignores Limit and Continue
+type pagedClient struct {
+	*fake.Clientset
+	secrets []corev1.Secret
+
+	mu        sync.Mutex
+	calls     []metav1.ListOptions
+	throttled bool
+	expireAt  string // Continue token answered with 410 Gone, if set
+}
+
+func (c *pagedClient) CoreV1() typedcorev1.CoreV1Interface {
+	return pagedCoreV1{c.Clientset.CoreV1(), c}
+}
+
+type pagedCoreV1 struct {
+	typedcorev1.CoreV1Interface
+	client *pagedClient
+}
+
+func (c pagedCoreV1) Secrets(namespace string) typedcorev1.SecretInterface {
+	return pagedSecrets{c.CoreV1Interface.Secrets(namespace), c.client}
+}
+
+type pagedSecrets struct {
+	typedcorev1.SecretInterface
+	client *pagedClient
+}
+
+// List returns the page starting at the offset in opts.Continue, throttling the
+// first request for the second page
+func (s pagedSecrets) List(ctx context.Context, opts metav1.ListOptions) (*corev1.SecretList, error) {
+	c := s.client
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, opts)
+
+	start := 0
+	if opts.Continue != "" && opts.Continue == c.expireAt {
+		return nil, apierrors.NewResourceExpired("the provided continue parameter is too old")
+	}
+	if opts.Continue != "" {
+		start, _ = strconv.Atoi(opts.Continue)
+		if !c.throttled {
+			c.throttled = true
+			return nil, apierrors.NewTooManyRequests("slow down", 0)
+		}
+	}
+	end := len(c.secrets)
+	if opts.Limit > 0 && start+int(opts.Limit) < end {
+		end = start + int(opts.Limit)
+	}
+	list := &corev1.SecretList{Items: c.secrets[start:end]}
+	if end < len(c.secrets) {
+		list.Continue = strconv.Itoa(end)
+	}
+	return list, nil
+}
+
+func TestKubernetesSecretPagination(t *testing.T) {
+	client := &pagedClient{Clientset: fake.NewSimpleClientset()}
+	for i := 0; i < 7; i++ {
+		client.secrets = append(client.secrets, corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("app-%d", i), Namespace: "default"},
+			Data:       map[string][]byte{"crypto.txt": []byte(`KeyPairGenerator.getInstance("RSA")`)},
+		})
+	}
+
+	k8s := crypto.NewK8sScannerWithClient(crypto.NewScanner(false), client)
+	k8s.ListPageSize = 3
+	results, assets := k8s.ScanKubernetesCluster(context.Background(), []string{"default"}, crypto.KubernetesScanOptions{SecretScan: true})
+
+	if assets != 7 {
+		t.Errorf("expected all 7 secrets across 3 pages, got %d assets", assets)
+	}
+	scanned := make(map[string]bool)
+	for _, result := range results {
+		scanned[result.File] = true
+	}
+	for i := 0; i < 7; i++ {
+		if file := fmt.Sprintf("secret/app-%d/crypto.txt (default)", i); !scanned[file] {
+			t.Errorf("missing finding for %s", file)
+		}
+	}
+
+	// The throttled second page is retried with the same continue token
+	var continues []string
+	for _, call := range client.calls {
+		if call.Limit != 3 {
+			t.Errorf("expected every List call to ask for 3 secrets, got %d", call.Limit)
+		}
+		continues = append(continues, call.Continue)
+	}
+	if want := []string{"", "3", "3", "6"}; !reflect.DeepEqual(continues, want) {
+		t.Errorf("expected continue tokens %q, got %q", want, continues)
+	}
+
+	// A continue token expiring mid-listing keeps the pages already scanned
+	client = &pagedClient{Clientset: fake.NewSimpleClientset(), secrets: client.secrets, expireAt: "6"}
+	scanner := crypto.NewScanner(false)
+	k8s = crypto.NewK8sScannerWithClient(scanner, client)
+	k8s.ListPageSize = 3
+	results, assets = k8s.ScanKubernetesCluster(context.Background(), []string{"default"}, crypto.KubernetesScanOptions{SecretScan: true})
+	if assets != 6 || len(results) != 6 {
+		t.Errorf("expected the 6 secrets of the first two pages, got %d assets and %d findings", assets, len(results))
+	}
+	if warnings := scanner.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0].Reason, "partial") {
+		t.Errorf("expected a warning that the listing is partial, got %+v", warnings)
+	}
+}
@@ -1,29 +1,102 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	gocrypto "crypto"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+	"unicode"
 
 	"qvs-pro/scanner/internal/crypto"
+	"qvs-pro/scanner/internal/metrics"
 	"qvs-pro/scanner/internal/migration"
+	"qvs-pro/scanner/internal/review"
+	"qvs-pro/scanner/internal/schema"
+	"qvs-pro/scanner/internal/signing"
 	"qvs-pro/scanner/internal/utils"
+	"qvs-pro/scanner/internal/webhook"
+	"qvs-pro/scanner/pkg/cbom"
 )
 
 const version = "2.0.0"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		if err := verifyReport(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		if err := validateDocument(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Define command-line flags
-	mode := flag.String("mode", "file", "Scan mode: file, k8s, cluster-scan, pcap, network")
-	dirToScan := flag.String("dir", "", "Directory or file to scan (default: current directory)")
+	mode := flag.String("mode", envDefault("CBOM_MODE", "file"), "Scan mode: file, k8s, cluster-scan, pcap, network (default from $CBOM_MODE when set)")
+	var dirs stringList
+	flag.Var(&dirs, "dir", "Directory or file to scan; repeatable to scan several targets in one run (default: current directory)")
+	repo := flag.String("repo", "", "Shallow-clone this Git repository URL into a temporary directory and scan it in file mode; $CBOM_GIT_TOKEN authenticates to private https repositories")
+	ref := flag.String("ref", "", "Branch, tag or commit of -repo to scan (default: the default branch)")
+	targetsFrom := flag.String("targets-from", "", "Scan the directories or files listed one per line in this file (- for stdin), adding to -dir")
 	namespaces := flag.String("namespace", "", "Kubernetes namespaces to scan (comma-separated)")
-	pcapFile := flag.String("pcap-file", "", "PCAP file to analyze")
+	pcapFile := flag.String("pcap-file", "", "PCAP file to analyze (- reads a libpcap or pcapng capture from stdin, e.g. tcpdump -w -)")
 	outputJSON := flag.Bool("json", false, "Output results as JSON")
+	outputJSONL := flag.Bool("jsonl", false, "Stream results as JSON Lines, one finding per line as it is found")
 	outputCBOM := flag.Bool("output-cbom", false, "Output results in CBOM format")
+	summaryOnly := flag.Bool("summary-only", false, "Output only the JSON summary (totals, breakdowns, agility score and, with -migration-plan, the plan summary) without findings")
 	verbose := flag.Bool("verbose", false, "Enable verbose output")
 	versionFlag := flag.Bool("version", false, "Print the version")
+	listRules := flag.Bool("list-rules", false, "Print the active detection rules (as JSON with -json) and exit")
+	checkCapabilities := flag.Bool("check-capabilities", false, "Report whether capture file analysis, live capture and access to the -kubeconfig cluster are available (as JSON with -json) and exit")
+	scanArchives := flag.Bool("scan-archives", false, "Scan inside jar/war/zip/tar.gz archives")
+	scanBinaries := flag.Bool("scan-binaries", false, "Scan ELF/PE/Mach-O binaries for crypto OIDs and library symbols")
+	scanConfig := flag.Bool("scan-config", false, "Scan YAML/JSON/TOML config files for JWT algorithms, cipher lists and key sizes")
+	scanScripts := flag.Bool("scan-scripts", false, "Scan Dockerfiles and shell scripts for OpenSSL version pins, cipher flags and ssh-keygen key types")
+	workers := flag.Int("workers", 0, "Number of files scanned in parallel (default: number of CPUs)")
+	minConfidence := flag.Float64("min-confidence", 0, "Drop source-code matches below this confidence (0-1), e.g. 0.5 to skip comment mentions")
+	contextLines := flag.Int("context-lines", 2, "Source lines shown before and after each finding in its JSON snippet (-1 omits snippets)")
+	showRemediation := flag.Bool("show-remediation", false, "Include example fixes in the file's language with findings, in text and JSON output")
+	suggestFixes := flag.Bool("suggest-fixes", false, "Include a unified diff with findings that have a safe, mechanical fix (e.g. MD5 to SHA-256, TLS 1.0 to 1.2), to apply with patch FILE < DIFF")
+	keystorePassword := flag.String("keystore-password", "", "Password opening .p12/.pfx and JKS keystores to report the keys and certificates they hold (default: $CBOM_KEYSTORE_PASSWORD)")
+	timing := flag.Bool("timing", false, "Report the time spent walking, scanning files and calling the Kubernetes API, on stderr and in the CBOM summary")
+	progress := flag.Bool("progress", false, "Show a progress bar on stderr when results are not written to the terminal")
+	groupBy := flag.String("group-by", "", "Group text output by file, algorithm, risk or environment (default: flat list)")
+	outputPath := flag.String("output", "", "Write results to this file instead of stdout (parent directories are created) (default: $CBOM_OUTPUT, unless -output-dir is given)")
+	outputDir := flag.String("output-dir", "", "Scan each -dir target separately and write one report per target into this directory")
+	metricsAddr := flag.String("metrics-addr", "", "Serve Prometheus metrics on this address (e.g. :9090) while scanning")
+	redactPaths := flag.Bool("redact-paths", false, "Replace file paths in results with salted hashes (or paths relative to -scan-root)")
+	redactSalt := flag.String("redact-salt", os.Getenv("CBOM_REDACT_SALT"), "Salt for -redact-paths hashes; reuse it to correlate findings across scans (default: $CBOM_REDACT_SALT)")
+	scanRoot := flag.String("scan-root", "", "With -redact-paths, report paths under this directory relative to it instead of hashing them")
+	fileTimeout := flag.String("file-timeout", "30s", "Skip any single file that takes longer than this to scan (0 disables)")
+	filesFrom := flag.String("files-from", "", "Scan only the files listed one per line in this file (- for stdin), e.g. from git diff --name-only")
+	var files stringList
+	flag.Var(&files, "file", "Scan only this file; repeatable, combines with -files-from")
+	cacheDir := flag.String("cache-dir", "", "Cache per-file findings here by content hash so re-scans skip unchanged files")
+	severityMap := flag.String("severity-map", "", "YAML or JSON file remapping finding risks to your own severity scale and force-escalating algorithms")
+	suppressionsPath := flag.String("suppressions", "", "YAML or JSON file of finding fingerprints left out of the results, e.g. written by -review")
+	reviewFindings := flag.Bool("review", false, "Page through the findings of a file-mode scan on the terminal, suppressing or accepting each, and write the -suppressions file (default: "+defaultSuppressionsFile+") on quit")
+	signKey := flag.String("sign-key", "", "PEM private key (RSA, ECDSA or Ed25519) used to sign the -output-cbom report; the detached JWS is written to <output>.jws (verify with: verify -key <public key> <report>)")
+	cbomSchema := flag.String("cbom-schema", utils.CBOMSchemaLegacy, "Schema of -output-cbom reports: 1.4 (the pinned legacy shape) or 1.6 (CycloneDX 1.6 cryptographic assets)")
+	sbomPath := flag.String("sbom", "", "CycloneDX or SPDX JSON SBOM used to attach component PURLs and versions to findings")
+
+	// Webhook flags
+	webhookURL := flag.String("webhook-url", "", "POST results to this URL")
+	webhookAuthHeader := flag.String("webhook-auth-header", "", "Header sent with the webhook POST, e.g. \"Authorization: Bearer <token>\"")
+	webhookFormat := flag.String("webhook-format", "", "Format posted to the webhook: text, json, jsonl, cbom (default: the selected output format)")
+	webhookRequired := flag.Bool("webhook-required", false, "Exit non-zero if the webhook POST fails")
 	
 	// Kubernetes-specific flags (for operator compatibility)
 	secretScan := flag.Bool("secret-scan", true, "Scan Kubernetes secrets")
@@ -32,9 +105,24 @@ func main() {
 	networkPolicyScan := flag.Bool("network-policy-scan", false, "Scan network policies")
 	ingressScan := flag.Bool("ingress-scan", false, "Scan ingress configurations")
 	serviceMeshScan := flag.Bool("service-mesh-scan", false, "Scan service mesh configurations")
-	deepCodeScan := flag.Bool("deep-code-scan", false, "Deep scan of application code")
+	deepCodeScan := flag.Bool("deep-code-scan", false, "Pull each pod image with docker and scan its application code and binaries")
+	webhookScan := flag.Bool("webhook-scan", false, "Scan the CA bundles of admission webhook configurations and APIServices")
+	envScan := flag.Bool("env-scan", false, "Scan pod container env values for settings that weaken crypto, e.g. GODEBUG=x509sha1=1 or NODE_OPTIONS=--tls-min-v1.0")
 	includeKubeSystem := flag.Bool("include-kube-system", false, "Include kube-system namespace")
-	timeout := flag.String("timeout", "1200s", "Scan timeout duration")
+	kubeconfig := flag.String("kubeconfig", "", "Path to the kubeconfig file (default: in-cluster config, then $KUBECONFIG or ~/.kube/config)")
+	kubeContext := flag.String("kube-context", "", "Kubeconfig context to use (default: the current context)")
+	strict := flag.Bool("strict", false, "Exit with an error instead of reporting simulated results when libpcap, the capture interface or the Kubernetes API is unavailable")
+	complianceFramework := flag.String("compliance", "", "Map findings to the controls of a compliance framework and summarize pass/fail per control: pci, fips140-3, nist-sp800-131a")
+	projectDate := flag.String("project-date", "", "Report each finding's NIST IR 8547 status (deprecated, disallowed) on this date: YYYY-MM-DD, or the end of YYYY, YYYY-MM or YYYY-Qn")
+	minRisk := flag.String("min-risk", "", "Report only findings at or above this risk: low, medium, high, critical")
+	failOn := flag.String("fail-on", envDefault("CBOM_FAIL_ON", ""), "Exit with status 3 when a finding is at or above this risk: low, medium, high, critical (default from $CBOM_FAIL_ON when set)")
+	var algorithms, nistCategories, onlyEnvironments, environmentPaths stringList
+	flag.Var(&algorithms, "algorithm", "Report only findings for this algorithm, e.g. RSA (repeatable)")
+	flag.Var(&nistCategories, "nist-category", "Report only findings in this NIST IR 8547 category: 1-5, deprecated, disallowed (repeatable)")
+	flag.Var(&onlyEnvironments, "only-env", "Report only findings labeled with this environment by -env or -env-path (repeatable)")
+	environment := flag.String("env", "", "Label findings with the deployment environment of the scanned code: prod, staging, dev, test; dev and test findings are lowered one risk level")
+	flag.Var(&environmentPaths, "env-path", "Label findings in files matching PATTERN with an environment instead of -env, as PATTERN=ENV, e.g. test/=test or *_test.go=test; a pattern ending in / matches that directory at any depth (repeatable, first match wins)")
+	timeout := flag.String("timeout", "1200s", "Scan timeout duration (0 disables); partial results are reported when it expires")
 	
 	// PCAP-specific flags
 	liveCapture := flag.Bool("live-capture", false, "Capture live network traffic")
@@ -46,10 +134,22 @@ func main() {
 	migrationPlan := flag.Bool("migration-plan", false, "Generate PQC migration plan")
 	migrationContext := flag.String("migration-context", "", "Deployment context (edge_ingress, service_mesh, internal_api, etc.)")
 	migrationTimeline := flag.String("migration-timeline", "", "Target timeline (e.g., 2025-Q2)")
-	migrationRulesFile := flag.String("migration-rules", "migration-rules.yaml", "Path to migration rules file")
+	migrationRulesFile := flag.String("migration-rules", os.Getenv("CBOM_RULES_FILE"), "Path to a migration rules file (default: $CBOM_RULES_FILE, else built-in rules only)")
+	rulesMode := flag.String("rules-mode", migration.RulesModeMerge, "How -migration-rules combines with the built-in rules: merge (override matching keys) or replace")
+	effortModel := flag.String("effort-model", migration.EffortModelStandard, "Migration effort sizing model: flat, standard, conservative")
+	migrationFormat := flag.String("migration-format", "text", "Migration plan summary format: text or md (Markdown report)")
+	hndlSummary := flag.Bool("hndl", false, "Include harvest-now-decrypt-later (HNDL) risk breakdown in the migration plan summary")
 
 	// Parse command-line flags
 	flag.Parse()
+	if *keystorePassword == "" {
+		// Read from the environment rather than the flag default so help doesn't show it
+		*keystorePassword = os.Getenv("CBOM_KEYSTORE_PASSWORD")
+	}
+	if *outputPath == "" && *outputDir == "" {
+		// Read after parsing so $CBOM_OUTPUT doesn't conflict with -output-dir
+		*outputPath = os.Getenv("CBOM_OUTPUT")
+	}
 
 	// Check if version flag is set
 	if *versionFlag {
@@ -59,70 +159,330 @@ func main() {
 		return
 	}
 
+	if *listRules {
+		format := "text"
+		if *outputJSON {
+			format = "json"
+		}
+		if err := utils.OutputRules(os.Stdout, cbom.DefaultRules(), format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *checkCapabilities {
+		format := "text"
+		if *outputJSON {
+			format = "json"
+		}
+		capabilities := cbom.Capabilities(cbom.KubernetesOptions{Kubeconfig: *kubeconfig, Context: *kubeContext})
+		if err := utils.OutputCapabilities(os.Stdout, capabilities, format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	switch *groupBy {
+	case "", utils.GroupByFile, utils.GroupByAlgorithm, utils.GroupByRisk, utils.GroupByEnvironment:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported -group-by '%s'. Use: file, algorithm, risk, environment\n", *groupBy)
+		os.Exit(1)
+	}
+
+	if *summaryOnly && (*outputJSONL || *outputDir != "") {
+		fmt.Fprintf(os.Stderr, "Error: -summary-only cannot be combined with -jsonl or -output-dir\n")
+		os.Exit(1)
+	}
+
+	if *minConfidence < 0 || *minConfidence > 1 {
+		fmt.Fprintf(os.Stderr, "Error: -min-confidence must be between 0 and 1, got %g\n", *minConfidence)
+		os.Exit(1)
+	}
+
+	switch *effortModel {
+	case migration.EffortModelFlat, migration.EffortModelStandard, migration.EffortModelConservative:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported -effort-model '%s'. Use: flat, standard, conservative\n", *effortModel)
+		os.Exit(1)
+	}
+
+	if *ref != "" && *repo == "" {
+		fmt.Fprintf(os.Stderr, "Error: -ref needs -repo\n")
+		os.Exit(1)
+	}
+
+	var signer gocrypto.Signer
+	if *signKey != "" {
+		if !*outputCBOM || *outputPath == "" || *summaryOnly || *outputDir != "" {
+			fmt.Fprintf(os.Stderr, "Error: -sign-key needs -output-cbom and -output, and cannot be combined with -summary-only or -output-dir\n")
+			os.Exit(1)
+		}
+		var err error
+		if signer, err = signing.LoadPrivateKey(*signKey); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var stopMetrics func()
+	if *metricsAddr != "" {
+		stopMetrics = startMetricsServer(*metricsAddr)
+	}
+
+	// Results go to stdout unless --output names a file; logs always go to stderr
+	var out io.Writer = os.Stdout
+
 	if *verbose {
-		fmt.Printf("Aqua-CBOM Scanner v%s\n", version)
-		fmt.Printf("Mode: %s\n", *mode)
+		fmt.Fprintf(os.Stderr, "Aqua-CBOM Scanner v%s\n", version)
+		fmt.Fprintf(os.Stderr, "Mode: %s\n", *mode)
 	}
 
-	var results []crypto.Result
-	var scanMetadata utils.ScanMetadata
-	
-	scanner := crypto.NewScanner(*verbose)
+	format := "text"
+	if *outputCBOM {
+		format = "cbom"
+	} else if *outputJSON {
+		format = "json"
+	} else if *outputJSONL {
+		format = "jsonl"
+	}
+
+	opts := cbom.ScanOptions{
+		Mode:             *mode,
+		Concurrency:      *workers,
+		MinConfidence:    *minConfidence,
+		ContextLines:     *contextLines,
+		ShowRemediation:  *showRemediation,
+		SuggestFixes:     *suggestFixes,
+		KeystorePassword: *keystorePassword,
+		Verbose:          *verbose,
+		ScanArchives:     *scanArchives,
+		ScanBinaries:     *scanBinaries,
+		ScanConfig:       *scanConfig,
+		ScanScripts:      *scanScripts,
+		CacheDir:         *cacheDir,
+		Strict:           *strict,
+		Timing:           *timing,
+		Filter: cbom.FindingFilter{
+			MinRisk:        *minRisk,
+			Algorithms:     algorithms,
+			NISTCategories: nistCategories,
+			Environments:   onlyEnvironments,
+		},
+		Kubernetes: cbom.KubernetesOptions{
+			SecretScan:        *secretScan,
+			ConfigMapScan:     *configMapScan,
+			ImageScan:         *imageScan,
+			NetworkPolicyScan: *networkPolicyScan,
+			IngressScan:       *ingressScan,
+			ServiceMeshScan:   *serviceMeshScan,
+			DeepCodeScan:      *deepCodeScan,
+			WebhookScan:       *webhookScan,
+			EnvScan:           *envScan,
+			IncludeKubeSystem: *includeKubeSystem,
+			Kubeconfig:        *kubeconfig,
+			Context:           *kubeContext,
+		},
+		Network: cbom.NetworkOptions{
+			LiveCapture: *liveCapture,
+			Interface:   *captureInterface,
+			Duration:    *captureDuration,
+			TLSOnly:     *tlsFilter,
+		},
+		Format:           format,
+		SBOM:             *sbomPath,
+		SeverityMap:      *severityMap,
+		Suppressions:     *suppressionsPath,
+		Environment:      *environment,
+		EnvironmentPaths: environmentPaths,
+		Compliance:       *complianceFramework,
+		CBOMSchema:       *cbomSchema,
+		RedactPaths:      *redactPaths,
+		RedactSalt:       *redactSalt,
+		ScanRoot:         *scanRoot,
+		Repo:             *repo,
+		Ref:              *ref,
+		RepoToken:        os.Getenv("CBOM_GIT_TOKEN"),
+	}
+
+	// Rejected before any output file is created
+	if err := opts.Filter.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := (cbom.FindingFilter{MinRisk: *failOn}).Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -fail-on: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Only draw the bar when it cannot interleave with results on the terminal
+	if *progress && (*outputPath != "" || !utils.IsTerminal(os.Stdout)) {
+		opts.Progress = utils.NewProgressBar(os.Stderr)
+	}
 
-	// Route to appropriate scan mode
+	// Route targets to the selected scan mode
 	switch *mode {
 	case "file":
-		results, scanMetadata = handleFileMode(scanner, dirToScan, verbose)
+		opts.Targets = dirs
+		if *targetsFrom != "" {
+			listed, err := readFileList(*targetsFrom)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			opts.Targets = append(opts.Targets, listed...)
+		}
+		opts.Files = files
+		if *filesFrom != "" {
+			listed, err := readFileList(*filesFrom)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			opts.Files = append(opts.Files, listed...)
+		}
 	case "k8s", "cluster-scan":
-		results, scanMetadata = handleKubernetesMode(scanner, namespaces, secretScan, configMapScan, imageScan, networkPolicyScan, ingressScan, serviceMeshScan, deepCodeScan, includeKubeSystem, timeout, verbose)
+		if *namespaces != "" {
+			opts.Targets = strings.Split(*namespaces, ",")
+		}
 	case "pcap":
-		results, scanMetadata = handlePCAPMode(scanner, pcapFile, liveCapture, captureInterface, captureDuration, tlsFilter, verbose)
-	case "network":
-		results, scanMetadata = handleNetworkMode(scanner, captureInterface, captureDuration, tlsFilter, verbose)
-	default:
-		fmt.Printf("Error: Unsupported mode '%s'. Use: file, k8s, cluster-scan, pcap, network\n", *mode)
+		opts.Targets = []string{*pcapFile}
+	}
+
+	ctx := context.Background()
+	scanTimeout, err := time.ParseDuration(*timeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid timeout '%s': %v\n", *timeout, err)
+		os.Exit(1)
+	}
+	if opts.FileTimeout, err = time.ParseDuration(*fileTimeout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid file timeout '%s': %v\n", *fileTimeout, err)
+		os.Exit(1)
+	}
+	if *projectDate != "" {
+		if opts.ProjectDate, err = parseProjectDate(*projectDate); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if scanTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, scanTimeout)
+		defer cancel()
+	}
+
+	// Each target gets its own report, so there is no single result to write, plan or post
+	if *outputDir != "" {
+		if *mode != "file" || len(opts.Targets) == 0 || len(opts.Files) > 0 || *outputPath != "" || *webhookURL != "" || *migrationPlan {
+			fmt.Fprintf(os.Stderr, "Error: -output-dir needs -dir or -targets-from targets in file mode and cannot be combined with -file, -files-from, -output, -webhook-url or -migration-plan\n")
+			os.Exit(1)
+		}
+		failed := scanEachTarget(ctx, opts, *outputDir, *groupBy)
+		if stopMetrics != nil {
+			stopMetrics()
+		}
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *reviewFindings {
+		if *mode != "file" || *outputDir != "" || *outputPath != "" || *webhookURL != "" || *migrationPlan {
+			fmt.Fprintf(os.Stderr, "Error: -review needs file mode and cannot be combined with -output-dir, -output, -webhook-url or -migration-plan\n")
+			os.Exit(1)
+		}
+		if err := reviewScan(ctx, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// JSON Lines output is written while scanning, so the destination is opened first
+	var outputFile *os.File
+	if *outputPath != "" {
+		outputFile, err = utils.CreateOutputFile(*outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		out = outputFile
+	}
+
+	var scanResult cbom.ScanResult
+	var outputErr error
+	var stream *jsonlStream
+	if opts.Format == "jsonl" {
+		stream = &jsonlStream{w: out, keep: *webhookURL != ""}
+		scanResult, err = stream.scan(ctx, opts)
+		outputErr = stream.err
+	} else {
+		scanResult, err = cbom.Scan(ctx, opts)
+	}
+	if errors.Is(err, cbom.ErrScanCanceled) {
+		fmt.Fprintf(os.Stderr, "Warning: scan did not finish within %s; reporting partial results\n", scanTimeout)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	results := scanResult.Findings
+	scanMetadata := scanResult.Metadata
+	for _, warning := range scanMetadata.Warnings {
+		if warning.Capability != "" {
+			fmt.Fprintf(os.Stderr, "Warning: %s is unavailable: %s (-check-capabilities reports what this build supports, -strict fails instead)\n", warning.Capability, warning.Reason)
+		}
+	}
 
 	if *verbose {
-		fmt.Printf("\nScan complete. Found %d potential vulnerabilities across %d assets.\n\n", len(results), scanMetadata.TotalAssets)
+		findingCount := len(results)
+		if stream != nil {
+			findingCount = stream.count
+		}
+		fmt.Fprintf(os.Stderr, "\nScan complete. Found %d potential vulnerabilities across %d assets.\n\n", findingCount, scanMetadata.TotalAssets)
 	}
 
 	// Output results in requested format
-	if *outputCBOM {
-		utils.OutputCBOM(results, scanMetadata, *mode)
+	switch {
+	case *summaryOnly:
+		report := summaryReport{Summary: utils.Summarize(results, scanMetadata)}
+		if *migrationPlan && len(results) > 0 {
+			if plan, err := generateMigrationPlan(results, *migrationRulesFile, *rulesMode, *migrationContext, *migrationTimeline, *effortModel); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			} else {
+				report.Migration = &plan.Summary
+			}
+		}
+		outputErr = utils.OutputJSON(out, report)
+	case opts.Format == "jsonl":
+		// Already written as the scan ran
+	case opts.Format == "text" && *groupBy != "":
+		outputErr = utils.OutputTextGrouped(out, results, *groupBy)
+	default:
+		outputErr = utils.WriteOutput(out, opts.Format, results, scanMetadata, *mode)
+	}
+	if opts.Format == "text" && !*summaryOnly && scanMetadata.Compliance != nil && outputErr == nil {
+		outputErr = utils.OutputComplianceSummary(out, scanMetadata.Compliance)
+	}
 
+	if opts.Format == "cbom" && !*summaryOnly {
 		// Generate migration plan if requested
 		if *migrationPlan && len(results) > 0 {
 			if *verbose {
 				fmt.Fprintf(os.Stderr, "\nGenerating PQC migration plan...\n")
 			}
 
-			// Load migration rules
-			rules, err := migration.LoadRules(*migrationRulesFile)
+			plan, err := generateMigrationPlan(results, *migrationRulesFile, *rulesMode, *migrationContext, *migrationTimeline, *effortModel)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: Failed to load migration rules: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 				fmt.Fprintf(os.Stderr, "Skipping migration plan generation.\n")
 			} else {
-				// Generate plan
-				plan := migration.GeneratePlan(results, rules, *migrationContext, *migrationTimeline)
-
 				// Write to stderr (separate from CBOM JSON on stdout)
-				fmt.Fprintf(os.Stderr, "\n=== PQC Migration Plan ===\n")
-				fmt.Fprintf(os.Stderr, "Total Findings: %d\n", plan.Summary.TotalFindings)
-				if plan.Summary.DeploymentContext != "" {
-					fmt.Fprintf(os.Stderr, "Context: %s\n", plan.Summary.DeploymentContext)
-				}
-				if plan.Summary.TargetTimeline != "" {
-					fmt.Fprintf(os.Stderr, "Timeline: %s\n", plan.Summary.TargetTimeline)
-				}
-				fmt.Fprintf(os.Stderr, "\nPriority Breakdown:\n")
-				for priority, count := range plan.Summary.ByPriority {
-					fmt.Fprintf(os.Stderr, "  %s: %d\n", priority, count)
-				}
-				fmt.Fprintf(os.Stderr, "\nReadiness Breakdown:\n")
-				for readiness, count := range plan.Summary.ByReadiness {
-					fmt.Fprintf(os.Stderr, "  %s: %d\n", readiness, count)
+				if *migrationFormat == "md" {
+					fmt.Fprintf(os.Stderr, "\n%s", migration.RenderPlanMarkdown(plan))
+				} else {
+					printPlanSummary(os.Stderr, plan, *hndlSummary)
 				}
 
 				if *verbose {
@@ -130,140 +490,441 @@ func main() {
 				}
 			}
 		}
-	} else if *outputJSON {
-		utils.OutputJSON(results)
-	} else {
-		utils.OutputText(results)
 	}
-}
 
-// handleFileMode processes traditional file/directory scanning
-func handleFileMode(scanner *crypto.Scanner, dirToScan *string, verbose *bool) ([]crypto.Result, utils.ScanMetadata) {
-	// If no directory specified, use current directory
-	if *dirToScan == "" {
-		currentDir, err := os.Getwd()
-		if err != nil {
-			fmt.Printf("Error getting current directory: %v\n", err)
+	if outputFile != nil {
+		if err := outputFile.Close(); err != nil && outputErr == nil {
+			outputErr = err
+		}
+	}
+	if outputErr != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", outputErr)
+		os.Exit(1)
+	}
+
+	if *verbose && *outputPath != "" {
+		fmt.Fprintf(os.Stderr, "Results written to %s\n", *outputPath)
+	}
+
+	if signer != nil {
+		if err := signReport(*outputPath, signer); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		*dirToScan = currentDir
+		if *verbose {
+			fmt.Fprintf(os.Stderr, "Signature written to %s.jws\n", *outputPath)
+		}
+	}
+
+	// Push results to a collector if requested
+	if *webhookURL != "" {
+		webhookFmt := *webhookFormat
+		if webhookFmt == "" {
+			webhookFmt = opts.Format
+		}
+		if err := postResults(*webhookURL, *webhookAuthHeader, webhookFmt, results, scanMetadata, *mode); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: webhook delivery failed: %v\n", err)
+			if *webhookRequired {
+				os.Exit(1)
+			}
+		} else if *verbose {
+			fmt.Fprintf(os.Stderr, "Results posted to %s\n", *webhookURL)
+		}
 	}
+	if stopMetrics != nil {
+		stopMetrics()
+	}
+
+	// Gate last, so every output is written and delivered before failing the build
+	if *failOn != "" {
+		if failing := cbom.FailingFindings(results, *failOn); len(failing) > 0 {
+			fmt.Fprintf(os.Stderr, "Failing: %d finding(s) at or above %s risk\n", len(failing), *failOn)
+			os.Exit(failOnExitStatus)
+		}
+	}
+}
+
+// failOnExitStatus is the exit status of a scan -fail-on fails, distinct from the
+// status 1 of an error and 2 of a usage error
+const failOnExitStatus = 3
+
+// summaryReport is the -summary-only output
+type summaryReport struct {
+	Summary   utils.CBOMSummary           `json:"summary"`
+	Migration *migration.MigrationSummary `json:"migration,omitempty"`
+}
 
-	absPath, err := filepath.Abs(*dirToScan)
+// signReport writes a detached JWS of the report at path to path.jws
+func signReport(path string, key gocrypto.Signer) error {
+	report, err := os.ReadFile(path)
 	if err != nil {
-		fmt.Printf("Error resolving path: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("error reading report to sign: %w", err)
+	}
+	jws, err := signing.Sign(report, key)
+	if err != nil {
+		return fmt.Errorf("error signing %s: %w", path, err)
 	}
+	return os.WriteFile(path+".jws", []byte(jws+"\n"), 0644)
+}
 
-	if *verbose {
-		fmt.Printf("Scanning: %s\n", absPath)
+// verifyReport runs the verify subcommand: it checks a report against its detached
+// JWS and the signer's public key
+func verifyReport(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "PEM public key or certificate of the signer")
+	signaturePath := fs.String("signature", "", "Detached JWS of the report (default: <report>.jws)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s verify -key <public key> [-signature <jws>] <report>\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *keyPath == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("verify needs -key and one report")
+	}
+	reportPath := fs.Arg(0)
+	if *signaturePath == "" {
+		*signaturePath = reportPath + ".jws"
 	}
 
-	fileInfo, err := os.Stat(absPath)
+	key, err := signing.LoadPublicKey(*keyPath)
 	if err != nil {
-		fmt.Printf("Error reading path: %v\n", err)
-		os.Exit(1)
+		return err
+	}
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		return fmt.Errorf("error reading report: %w", err)
+	}
+	jws, err := os.ReadFile(*signaturePath)
+	if err != nil {
+		return fmt.Errorf("error reading signature: %w", err)
+	}
+	if err := signing.Verify(report, string(jws), key); err != nil {
+		return fmt.Errorf("%s: %w", reportPath, err)
 	}
+	fmt.Printf("Verified: %s\n", reportPath)
+	return nil
+}
 
-	var results []crypto.Result
-	var assetCount int
+// validateDocument runs the validate subcommand: it checks a CBOM report, in
+// either schema, or a migration plan against its embedded JSON Schema. Without
+// -schema, the schema is picked from the document itself.
+func validateDocument(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	inputPath := fs.String("input", "", "JSON document to validate")
+	schemaName := fs.String("schema", "", "Schema to validate against: "+strings.Join(schema.Names(), ", ")+" (default: picked from the document's bomFormat and specVersion)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s validate -input <file.json> [-schema <name>]\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if *inputPath == "" || fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("validate needs -input")
+	}
 
-	if fileInfo.IsDir() {
-		results, assetCount = scanner.ScanDirectoryWithMetadata(absPath)
-	} else {
-		result := scanner.ScanFile(absPath)
-		results = result
-		assetCount = 1
+	document, err := os.ReadFile(*inputPath)
+	if err != nil {
+		return fmt.Errorf("error reading document: %w", err)
+	}
+	if *schemaName == "" {
+		if *schemaName, err = schema.Detect(document); err != nil {
+			return fmt.Errorf("%s: %w; pass -schema", *inputPath, err)
+		}
+	}
+	if err := schema.Validate(*schemaName, document); err != nil {
+		return fmt.Errorf("%s: %w", *inputPath, err)
 	}
+	fmt.Printf("Valid %s: %s\n", *schemaName, *inputPath)
+	return nil
+}
 
-	metadata := utils.ScanMetadata{
-		Mode:        "file",
-		Target:      absPath,
-		TotalAssets: assetCount,
-		ScanTime:    utils.GetCurrentTimestamp(),
+// envDefault returns the environment variable name, or fallback when it is unset or
+// empty, as the default of a flag so the command line still takes precedence
+func envDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
 	}
+	return fallback
+}
 
-	return results, metadata
+// parseProjectDate parses a -project-date. A year, month or quarter stands for its
+// last day, so an algorithm is reported deprecated if it is at any point of it.
+func parseProjectDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	if month, err := time.Parse("2006-01", value); err == nil {
+		return month.AddDate(0, 1, -1), nil
+	}
+	if year, err := time.Parse("2006", value); err == nil {
+		return year.AddDate(1, 0, -1), nil
+	}
+	var year, quarter int
+	if n, _ := fmt.Sscanf(value, "%4d-Q%1d", &year, &quarter); n == 2 && quarter >= 1 && quarter <= 4 && len(value) == 7 {
+		return time.Date(year, time.Month(quarter*3+1), 0, 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid project date '%s'. Use YYYY-MM-DD, YYYY-MM, YYYY or YYYY-Qn", value)
 }
 
-// handleKubernetesMode processes Kubernetes cluster scanning
-func handleKubernetesMode(scanner *crypto.Scanner, namespaces *string, secretScan, configMapScan, imageScan, networkPolicyScan, ingressScan, serviceMeshScan, deepCodeScan, includeKubeSystem *bool, timeout *string, verbose *bool) ([]crypto.Result, utils.ScanMetadata) {
-	if *verbose {
-		fmt.Printf("Starting Kubernetes cluster scan...\n")
-		if *namespaces != "" {
-			fmt.Printf("Target namespaces: %s\n", *namespaces)
-		} else {
-			fmt.Printf("Scanning all accessible namespaces\n")
-		}
+// generateMigrationPlan loads the migration rules and plans the migration of
+// results, sizing its effort with effortModel
+func generateMigrationPlan(results []crypto.Result, rulesFile, rulesMode, deployment, timeline, effortModel string) (*migration.MigrationPlan, error) {
+	rules, err := migration.LoadRulesWithMode(rulesFile, rulesMode)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load migration rules: %w", err)
+	}
+	plan := migration.GeneratePlan(results, rules, deployment, timeline)
+	if err := plan.EstimateEffort(rules, effortModel); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
+	return plan, nil
+}
 
-	// Parse namespaces
-	var targetNamespaces []string
-	if *namespaces != "" {
-		targetNamespaces = strings.Split(*namespaces, ",")
-		// Trim whitespace
-		for i, ns := range targetNamespaces {
-			targetNamespaces[i] = strings.TrimSpace(ns)
+// jsonlStream writes findings as JSON Lines while a scan runs
+type jsonlStream struct {
+	w     io.Writer
+	keep  bool // Retain findings for later use, such as the webhook
+	kept  []crypto.Result
+	count int
+	err   error // First write error
+}
+
+// scan runs the scan, writing each finding as soon as it is found
+func (j *jsonlStream) scan(ctx context.Context, opts cbom.ScanOptions) (cbom.ScanResult, error) {
+	findings := make(chan cbom.Finding)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for f := range findings {
+			j.count++
+			if j.err == nil {
+				j.err = utils.OutputJSONL(j.w, []crypto.Result{f})
+			}
+			if j.keep {
+				j.kept = append(j.kept, f)
+			}
 		}
-	}
+	}()
+
+	result, err := cbom.ScanStream(ctx, opts, findings)
+	<-done
+	result.Findings = j.kept
+	return result, err
+}
+
+// reportExtensions are the file extensions of per-target reports by output format
+var reportExtensions = map[string]string{"cbom": ".cbom.json", "json": ".json", "jsonl": ".jsonl", "text": ".txt"}
 
-	// Perform Kubernetes scanning
-	results, assetCount := scanner.ScanKubernetes(targetNamespaces, *secretScan, *configMapScan, *imageScan, *networkPolicyScan, *ingressScan, *serviceMeshScan, *deepCodeScan, *includeKubeSystem)
+// defaultSuppressionsFile is the suppression file -review writes without -suppressions
+const defaultSuppressionsFile = ".cbom-suppressions.yaml"
 
-	metadata := utils.ScanMetadata{
-		Mode:        "kubernetes",
-		Target:      strings.Join(targetNamespaces, ","),
-		TotalAssets: assetCount,
-		ScanTime:    utils.GetCurrentTimestamp(),
-		Namespaces:  targetNamespaces,
+// reviewScan scans with opts, lets the user triage the findings on the terminal and
+// writes the suppression file when they quit with q. The scan ignores the file so
+// findings suppressed earlier are shown, marked, and can be accepted again.
+func reviewScan(ctx context.Context, opts cbom.ScanOptions) error {
+	path := opts.Suppressions
+	if path == "" {
+		path = defaultSuppressionsFile
+	}
+	suppressions := &crypto.Suppressions{}
+	if _, err := os.Stat(path); err == nil {
+		if suppressions, err = crypto.LoadSuppressions(path); err != nil {
+			return err
+		}
+	}
+	suppressions.Roots = cbom.ScanRoots(opts)
+
+	opts.Suppressions = ""
+	result, err := cbom.Scan(ctx, opts)
+	if errors.Is(err, cbom.ErrScanCanceled) {
+		fmt.Fprintf(os.Stderr, "Warning: scan did not finish in time; reviewing partial results\n")
+	} else if err != nil {
+		return err
 	}
 
-	return results, metadata
+	save, err := review.Run(os.Stdin, os.Stdout, review.NewModel(result.Findings, suppressions))
+	if err != nil || !save {
+		return err
+	}
+	if err := suppressions.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d suppressions to %s\n", len(suppressions.Suppressions), path)
+	return nil
 }
 
-// handlePCAPMode processes PCAP file analysis
-func handlePCAPMode(scanner *crypto.Scanner, pcapFile *string, liveCapture *bool, captureInterface, captureDuration *string, tlsFilter *bool, verbose *bool) ([]crypto.Result, utils.ScanMetadata) {
-	if *verbose {
-		if *liveCapture {
-			fmt.Printf("Starting live network capture on interface %s for %s...\n", *captureInterface, *captureDuration)
-		} else {
-			fmt.Printf("Analyzing PCAP file: %s\n", *pcapFile)
+// scanEachTarget scans each of opts.Targets on its own and writes its report in
+// opts.Format to dir. A target that fails is reported and the rest are still
+// scanned; the return value says whether any failed.
+func scanEachTarget(ctx context.Context, opts cbom.ScanOptions, dir, groupBy string) bool {
+	scanner, err := cbom.NewScanner(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return true
+	}
+	failed := false
+	used := make(map[string]bool)
+	for i, target := range opts.Targets {
+		result, err := scanner.Scan(ctx, []string{target})
+		if errors.Is(err, cbom.ErrScanCanceled) {
+			fmt.Fprintf(os.Stderr, "Warning: scan of %s did not finish in time; reporting partial results\n", target)
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", target, err)
+			failed = true
+			continue
+		}
+
+		// Redacted scans don't leak target names through their report names
+		name := fmt.Sprintf("target-%d", i+1)
+		if !opts.RedactPaths {
+			name = reportName(target, used)
+		}
+		path := filepath.Join(dir, name+reportExtensions[opts.Format])
+		if err := writeReport(path, opts.Format, groupBy, result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			failed = true
+			continue
+		}
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "Wrote %d findings across %d assets for %s to %s\n", len(result.Findings), result.Metadata.TotalAssets, target, path)
 		}
 	}
+	return failed
+}
 
-	// Perform PCAP analysis
-	results, assetCount := scanner.ScanPCAP(*pcapFile, *liveCapture, *captureInterface, *captureDuration, *tlsFilter)
+// reportName names a target's report after its base name, made unique among used
+func reportName(target string, used map[string]bool) string {
+	if abs, err := filepath.Abs(target); err == nil {
+		target = abs
+	}
+	base := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, filepath.Base(target))
 
-	target := *pcapFile
-	if *liveCapture {
-		target = fmt.Sprintf("%s (live:%s)", *captureInterface, *captureDuration)
+	name := base
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s-%d", base, n)
 	}
+	used[name] = true
+	return name
+}
 
-	metadata := utils.ScanMetadata{
-		Mode:        "pcap",
-		Target:      target,
-		TotalAssets: assetCount,
-		ScanTime:    utils.GetCurrentTimestamp(),
+// writeReport writes the result of one scan to path in format
+func writeReport(path, format, groupBy string, result cbom.ScanResult) error {
+	f, err := utils.CreateOutputFile(path)
+	if err != nil {
+		return err
 	}
+	if format == "text" && groupBy != "" {
+		err = utils.OutputTextGrouped(f, result.Findings, groupBy)
+	} else {
+		err = utils.WriteOutput(f, format, result.Findings, result.Metadata, "file")
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
 
-	return results, metadata
+// stringList collects the values of a repeatable flag
+type stringList []string
+
+func (f *stringList) String() string { return strings.Join(*f, ",") }
+
+func (f *stringList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
 }
 
-// handleNetworkMode processes live network monitoring
-func handleNetworkMode(scanner *crypto.Scanner, captureInterface, captureDuration *string, tlsFilter *bool, verbose *bool) ([]crypto.Result, utils.ScanMetadata) {
-	if *verbose {
-		fmt.Printf("Starting network monitoring on interface %s for %s...\n", *captureInterface, *captureDuration)
+// readFileList reads the paths listed one per line in path, or on stdin for "-".
+// Blank lines are ignored.
+func readFileList(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file list: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var paths []string
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		if line := strings.TrimSpace(lines.Text()); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file list: %w", err)
 	}
+	return paths, nil
+}
 
-	// Perform network monitoring
-	results, assetCount := scanner.ScanNetwork(*captureInterface, *captureDuration, *tlsFilter)
+// postResults renders results in format and POSTs them to url
+func postResults(url, authHeader, format string, results []crypto.Result, metadata utils.ScanMetadata, mode string) error {
+	var body bytes.Buffer
+	if err := utils.WriteOutput(&body, format, results, metadata, mode); err != nil {
+		return err
+	}
+	return webhook.Post(context.Background(), webhook.Config{URL: url, AuthHeader: authHeader}, utils.ContentType(format), body.Bytes())
+}
 
-	metadata := utils.ScanMetadata{
-		Mode:        "network",
-		Target:      fmt.Sprintf("%s (duration:%s)", *captureInterface, *captureDuration),
-		TotalAssets: assetCount,
-		ScanTime:    utils.GetCurrentTimestamp(),
+// startMetricsServer serves Prometheus metrics on addr until the returned stop function is called
+func startMetricsServer(addr string) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := metrics.Serve(ctx, addr); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: metrics server on %s failed: %v\n", addr, err)
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
 	}
+}
 
-	return results, metadata
+// printPlanSummary writes the text summary of a migration plan to w
+func printPlanSummary(w io.Writer, plan *migration.MigrationPlan, hndl bool) {
+	fmt.Fprintf(w, "\n=== PQC Migration Plan ===\n")
+	fmt.Fprintf(w, "Total Findings: %d\n", plan.Summary.TotalFindings)
+	fmt.Fprintf(w, "Crypto-Agility Score: %d/100 (%s)\n", plan.Summary.CryptoAgilityScore, plan.Summary.CryptoAgilityGrade)
+	if plan.Summary.DeploymentContext != "" {
+		fmt.Fprintf(w, "Context: %s\n", plan.Summary.DeploymentContext)
+	}
+	if plan.Summary.TargetTimeline != "" {
+		fmt.Fprintf(w, "Timeline: %s\n", plan.Summary.TargetTimeline)
+	}
+	fmt.Fprintf(w, "\nPriority Breakdown:\n")
+	for priority, count := range plan.Summary.ByPriority {
+		fmt.Fprintf(w, "  %s: %d\n", priority, count)
+	}
+	fmt.Fprintf(w, "\nReadiness Breakdown:\n")
+	for readiness, count := range plan.Summary.ByReadiness {
+		fmt.Fprintf(w, "  %s: %d\n", readiness, count)
+	}
+	fmt.Fprintf(w, "\nEstimated Effort (%s model): %.2f days\n", plan.Summary.EffortModel, plan.Summary.TotalEffortDays)
+	fmt.Fprintf(w, "  Findings: %.2f days\n", plan.Summary.FindingEffortDays)
+	fmt.Fprintf(w, "  Mitigations: %.2f days\n", plan.Summary.MitigationEffortDays)
+	if hndl {
+		fmt.Fprintf(w, "\nHNDL Risk Breakdown:\n")
+		for _, level := range []string{crypto.HNDLRiskHigh, crypto.HNDLRiskMedium, crypto.HNDLRiskLow, crypto.HNDLRiskNone} {
+			if count, ok := plan.Summary.ByHNDLRisk[level]; ok {
+				fmt.Fprintf(w, "  %s: %d\n", level, count)
+			}
+		}
+	}
 }
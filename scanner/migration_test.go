@@ -0,0 +1,440 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"qvs-pro/scanner/internal/crypto"
+	"qvs-pro/scanner/internal/migration"
+)
+
+func TestShippedMigrationRulesAreValid(t *testing.T) {
+	if _, err := migration.DefaultRules(); err != nil {
+		t.Fatalf("Built-in migration rules failed validation: %v", err)
+	}
+	// The repository root ships an editable copy for deployments that mount a rules file
+	if _, err := os.Stat("../migration-rules.yaml"); err == nil {
+		if _, err := migration.LoadRules("../migration-rules.yaml"); err != nil {
+			t.Fatalf("Example migration rules failed validation: %v", err)
+		}
+	}
+}
+
+func TestMigrationRulesValidation(t *testing.T) {
+	malformed := `version: "1.0"
+migration_matrix:
+  key_exchang:
+    RSA:
+      target: "ML-KEM-768"
+      priority: "high"
+      timeline: "2025-Q2"
+  signatures:
+    ECDSA:
+      target: ""
+      priority: "urgent"
+      timeline: "2025-Q2"
+  symmetric:
+    AES-128:
+      target: "AES-256"
+      priority: "low"
+      timeline: "soon"
+  hashing:
+    SHA-1:
+      target: "SHA-256"
+      priority: "critical"
+      timeline: "2025-Q1"
+deployment_contexts:
+  edge_ingress:
+    caveats:
+      - protocol_not_ready
+      - no_such_caveat
+    mitigations:
+      - dual_listener
+    readiness_level: "pilot-ready"
+caveats:
+  protocol_not_ready:
+    description: "Protocol support missing"
+mitigations:
+  dual_listener:
+    description: "Run classical and PQC listeners"
+`
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(malformed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := migration.LoadRules(path)
+	var validationErrs migration.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+
+	expected := []string{
+		"field key_exchang not found",
+		"migration_matrix.key_exchange: required section is missing or empty",
+		"migration_matrix.signatures.ECDSA.target: must not be empty",
+		`migration_matrix.signatures.ECDSA.priority: invalid priority "urgent"`,
+		`migration_matrix.symmetric.AES-128.timeline: invalid timeline "soon"`,
+		`deployment_contexts.edge_ingress.caveats[1]: unknown caveat "no_such_caveat"`,
+	}
+	message := err.Error()
+	for _, want := range expected {
+		if !strings.Contains(message, want) {
+			t.Errorf("Expected validation error containing %q, got:\n%s", want, message)
+		}
+	}
+	if len(validationErrs) != len(expected) {
+		t.Errorf("Expected %d validation errors, got %d:\n%s", len(expected), len(validationErrs), message)
+	}
+}
+
+func TestMigrationRulesMerge(t *testing.T) {
+	defaults, err := migration.DefaultRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := defaults.MigrationMatrix.KeyExchange["RSA-2048"]; !ok {
+		t.Fatal("Expected built-in RSA-2048 key_exchange mapping")
+	}
+
+	override := `migration_matrix:
+  key_exchange:
+    RSA-2048:
+      target: "ML-KEM-1024"
+      use_case: "Org policy: category 5 everywhere"
+      priority: "critical"
+      timeline: "2025-Q1"
+    FFDHE:
+      target: "ML-KEM-768"
+      priority: "high"
+      timeline: "2025-Q3"
+`
+	path := filepath.Join(t.TempDir(), "overrides.yaml")
+	if err := os.WriteFile(path, []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := migration.LoadRulesWithMode(path, migration.RulesModeMerge)
+	if err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	if got := merged.MigrationMatrix.KeyExchange["RSA-2048"].Target; got != "ML-KEM-1024" {
+		t.Errorf("Expected user override for RSA-2048 to win, got %q", got)
+	}
+	if _, ok := merged.MigrationMatrix.KeyExchange["FFDHE"]; !ok {
+		t.Error("Expected new user key to be added")
+	}
+	for key, mapping := range defaults.MigrationMatrix.KeyExchange {
+		if key == "RSA-2048" {
+			continue
+		}
+		if merged.MigrationMatrix.KeyExchange[key] != mapping {
+			t.Errorf("Expected default %s mapping to remain, got %+v", key, merged.MigrationMatrix.KeyExchange[key])
+		}
+	}
+	if len(merged.MigrationMatrix.Signatures) != len(defaults.MigrationMatrix.Signatures) || len(merged.DeploymentContexts) != len(defaults.DeploymentContexts) {
+		t.Error("Expected untouched sections to keep their defaults")
+	}
+
+	if _, err := migration.LoadRulesWithMode(path, migration.RulesModeReplace); err == nil {
+		t.Error("Expected replace mode to reject a partial rules file")
+	}
+}
+
+func TestAlgorithmMappingAliases(t *testing.T) {
+	rules, err := migration.DefaultRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := []crypto.Result{
+		{File: "sign.go", Algorithm: "ECDSA", Type: "PublicKey"},
+		{File: "curve.go", Algorithm: "EC", Type: "PublicKey"},
+		{File: "kex.go", Algorithm: "ECDH", Type: "PublicKey"},
+		{File: "digest.go", Algorithm: "SHA1", Type: "Hash"},
+		{File: "cipher.go", Algorithm: "CHACHA20-RSA", Type: "PublicKey"},
+	}
+	plan := migration.GeneratePlan(results, rules, "", "")
+
+	expected := []string{
+		rules.MigrationMatrix.Signatures["ECDSA-P256"].Target,
+		rules.MigrationMatrix.Signatures["ECDSA-P256"].Target,
+		rules.MigrationMatrix.KeyExchange["ECDHE-P256"].Target,
+		rules.MigrationMatrix.Hashing["SHA-1"].Target,
+		"Unknown",
+	}
+	for i, finding := range plan.Findings {
+		if finding.TargetAlgorithm != expected[i] {
+			t.Errorf("%s (%s): expected target %q, got %q", finding.Algorithm, finding.Type, expected[i], finding.TargetAlgorithm)
+		}
+	}
+
+	// A broad key meant for AES-256 must not capture AES-128
+	custom := &migration.MigrationRules{
+		MigrationMatrix: migration.MigrationMatrix{
+			Symmetric: map[string]migration.AlgorithmMapping{
+				"AES": {Target: "Keep AES-256", Priority: "none", Timeline: "N/A"},
+			},
+		},
+	}
+	plan = migration.GeneratePlan([]crypto.Result{
+		{File: "a.go", Algorithm: "AES-128", Type: "SymmetricKey"},
+		{File: "b.go", Algorithm: "AES", Type: "SymmetricKey"},
+	}, custom, "", "")
+	if got := plan.Findings[0].TargetAlgorithm; got != "Unknown" {
+		t.Errorf("Expected AES-128 not to match the AES key, got %q", got)
+	}
+	if got := plan.Findings[1].TargetAlgorithm; got != "Keep AES-256" {
+		t.Errorf("Expected AES to match its exact key, got %q", got)
+	}
+}
+
+func TestMigrationEffortEstimation(t *testing.T) {
+	rules, err := migration.DefaultRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := []crypto.Result{
+		{File: "tls.go", Algorithm: "RSA", Type: "PublicKey"},
+		{File: "cipher.go", Algorithm: "3DES", Type: "SymmetricKey"},
+		{File: "digest.go", Algorithm: "MD5", Type: "Hash"},
+		{File: "kem.go", Algorithm: "ML-KEM", Type: "PostQuantum"},
+	}
+	plan := migration.GeneratePlan(results, rules, "edge_ingress", "")
+
+	// edge_ingress is pilot-ready: standard model scales base days by 1.25
+	expectedDays := []float64{6.25, 2.5, 1.25, 0}
+	var findingSum float64
+	for i, finding := range plan.Findings {
+		if finding.EffortDays != expectedDays[i] {
+			t.Errorf("%s: expected %.2f effort days, got %.2f", finding.Algorithm, expectedDays[i], finding.EffortDays)
+		}
+		if (finding.ComplexityScore == 0) != (expectedDays[i] == 0) {
+			t.Errorf("%s: unexpected complexity score %d", finding.Algorithm, finding.ComplexityScore)
+		}
+		findingSum += finding.EffortDays
+	}
+
+	var mitigationSum float64
+	for _, name := range rules.DeploymentContexts["edge_ingress"].Mitigations {
+		mitigationSum += migration.ParseEffortDays(rules.Mitigations[name].Effort)
+	}
+
+	summary := plan.Summary
+	if summary.EffortModel != migration.EffortModelStandard {
+		t.Errorf("Expected standard effort model by default, got %q", summary.EffortModel)
+	}
+	if summary.FindingEffortDays != findingSum {
+		t.Errorf("Expected finding effort %.2f, got %.2f", findingSum, summary.FindingEffortDays)
+	}
+	if summary.MitigationEffortDays != mitigationSum {
+		t.Errorf("Expected mitigation effort %.2f counted once, got %.2f", mitigationSum, summary.MitigationEffortDays)
+	}
+	if summary.TotalEffortDays != findingSum+mitigationSum {
+		t.Errorf("Expected total effort %.2f, got %.2f", findingSum+mitigationSum, summary.TotalEffortDays)
+	}
+
+	if err := plan.EstimateEffort(rules, migration.EffortModelFlat); err != nil {
+		t.Fatal(err)
+	}
+	if plan.Summary.FindingEffortDays != 3 {
+		t.Errorf("Expected flat model to size 3 findings at one day each, got %.2f", plan.Summary.FindingEffortDays)
+	}
+	if err := plan.EstimateEffort(rules, "guesswork"); err == nil {
+		t.Error("Expected error for unknown effort model")
+	}
+}
+
+// tlsCertificateRecord wraps DER certificates in a TLS Certificate handshake record
+func tlsCertificateRecord(certs ...[]byte) []byte {
+	uint24 := func(n int) []byte { return []byte{byte(n >> 16), byte(n >> 8), byte(n)} }
+
+	var list []byte
+	for _, der := range certs {
+		list = append(append(list, uint24(len(der))...), der...)
+	}
+	body := append(uint24(len(list)), list...)
+	handshake := append(append([]byte{0x0b}, uint24(len(body))...), body...)
+	return append([]byte{0x16, 0x03, 0x03, byte(len(handshake) >> 8), byte(len(handshake))}, handshake...)
+}
+
+// newTestCertificate issues an ECDSA certificate signed by issuer, or a self-signed root when issuer is nil
+func newTestCertificate(t *testing.T, name string, isCA bool, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	if isCA {
+		template.KeyUsage = x509.KeyUsageCertSign
+	}
+	if issuer == nil {
+		issuer, issuerKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestMigrationSequenceRootBeforeLeaf(t *testing.T) {
+	rootCert, rootKey := newTestCertificate(t, "Test Root CA", true, nil, nil)
+	leafCert, _ := newTestCertificate(t, "service.example", false, rootCert, rootKey)
+
+	// Servers send the leaf first
+	results := crypto.AnalyzeTLSCertificates(tlsCertificateRecord(leafCert.Raw, rootCert.Raw), "capture.pcap")
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 certificate findings, got %d", len(results))
+	}
+	if results[0].ChainPosition != crypto.ChainPositionLeaf || results[1].ChainPosition != crypto.ChainPositionRoot {
+		t.Fatalf("Expected leaf then root, got %q then %q", results[0].ChainPosition, results[1].ChainPosition)
+	}
+
+	rules, err := migration.DefaultRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := migration.GeneratePlan(results, rules, "", "")
+	leaf, root := plan.Findings[0], plan.Findings[1]
+	if root.Sequence == 0 || leaf.Sequence == 0 {
+		t.Fatalf("Expected both certificates to be sequenced, got root %d and leaf %d", root.Sequence, leaf.Sequence)
+	}
+	if root.Sequence >= leaf.Sequence {
+		t.Errorf("Expected root (sequence %d) to migrate before leaf (sequence %d)", root.Sequence, leaf.Sequence)
+	}
+
+	phases := plan.Summary.Phases
+	if len(phases) != 2 || phases[0].Name != "Root CAs" || phases[1].Name != "Signatures and leaf certificates" {
+		t.Errorf("Unexpected phase list: %+v", phases)
+	}
+}
+
+func TestRenderPlanMarkdown(t *testing.T) {
+	rules, err := migration.DefaultRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []crypto.Result{
+		{File: "src/tls.go", Algorithm: "RSA-2048", Type: "PublicKey", Risk: "High"},
+		{File: "src/hash.go", Algorithm: "SHA-1", Type: "Hash", Risk: "Medium"},
+		{File: "src/cipher.go", Algorithm: "AES-128", Type: "SymmetricKey", Risk: "Low"},
+		{File: "src/kem.go", Algorithm: "ML-KEM-768", Type: "PostQuantum", Risk: "Low"},
+	}
+	plan := migration.GeneratePlan(results, rules, "edge_ingress", "")
+
+	report := migration.RenderPlanMarkdown(plan)
+	for _, heading := range []string{"# PQC Migration Plan", "## Summary", "### By Priority", "### By Readiness", "## Migration Phases", "## Findings", "### Priority: "} {
+		if !strings.Contains(report, heading) {
+			t.Errorf("Expected report to contain heading %q", heading)
+		}
+	}
+	for _, finding := range plan.Findings {
+		if !strings.Contains(report, "| "+finding.File+" | "+finding.Algorithm+" |") {
+			t.Errorf("Expected report to list %s in %s", finding.Algorithm, finding.File)
+		}
+	}
+	for _, mitigation := range rules.DeploymentContexts["edge_ingress"].Mitigations {
+		if !strings.Contains(report, mitigation) {
+			t.Errorf("Expected report to include mitigation %q", mitigation)
+		}
+	}
+
+	// Reversing the input must not change the report
+	reversed := make([]crypto.Result, len(results))
+	for i, result := range results {
+		reversed[len(results)-1-i] = result
+	}
+	if again := migration.RenderPlanMarkdown(migration.GeneratePlan(reversed, rules, "edge_ingress", "")); again != report {
+		t.Errorf("Expected deterministic report, got:\n%s\nthen:\n%s", report, again)
+	}
+}
+
+func TestMigrationContextTimelines(t *testing.T) {
+	rules, err := migration.DefaultRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []crypto.Result{{File: "tls.go", Algorithm: "RSA-2048", Type: "PublicKey", Risk: "High"}}
+	timelineFor := func(context, flag string) string {
+		return migration.GeneratePlan(results, rules, context, flag).Findings[0].Timeline
+	}
+
+	edge, iot := timelineFor("edge_ingress", ""), timelineFor("iot_embedded", "")
+	if edge >= iot {
+		t.Errorf("Expected edge_ingress (%s) to migrate RSA earlier than iot_embedded (%s)", edge, iot)
+	}
+	if mapping := timelineFor("", ""); mapping != "2025-Q2" {
+		t.Errorf("Expected mapping timeline without a context, got %s", mapping)
+	}
+	if iot != "2025-Q4" {
+		t.Errorf("Expected iot_embedded multiplier to move 2025-Q2 to 2025-Q4, got %s", iot)
+	}
+
+	// Precedence: flag > context default > mapping
+	ctx := rules.DeploymentContexts["iot_embedded"]
+	ctx.DefaultTimeline = "2027-Q1"
+	rules.DeploymentContexts["iot_embedded"] = ctx
+	if got := timelineFor("iot_embedded", ""); got != "2027-Q1" {
+		t.Errorf("Expected context default timeline 2027-Q1, got %s", got)
+	}
+	if got := timelineFor("iot_embedded", "2025-Q3"); got != "2025-Q3" {
+		t.Errorf("Expected --migration-timeline to win, got %s", got)
+	}
+
+	ctx.DefaultTimeline = "someday"
+	rules.DeploymentContexts["iot_embedded"] = ctx
+	if errs := rules.Validate(); len(errs) == 0 || !strings.Contains(errs.Error(), "deployment_contexts.iot_embedded.default_timeline") {
+		t.Errorf("Expected invalid default_timeline to be reported, got %v", errs)
+	}
+}
+
+func TestMigrationRSAPurposeBuckets(t *testing.T) {
+	rules, err := migration.DefaultRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := migration.GeneratePlan([]crypto.Result{
+		{File: "sign.go", Algorithm: "RSA-2048", Type: "PublicKey", Purpose: crypto.PurposeSigning},
+		{File: "kex.go", Algorithm: "RSA-2048", Type: "PublicKey", Purpose: crypto.PurposeKeyEncapsulation},
+		{File: "cert.pem", Algorithm: "RSA-2048", Type: "PublicKey", Description: "Certificate signature uses RSA"},
+	}, rules, "", "")
+
+	signing := rules.MigrationMatrix.Signatures["RSA-2048"].Target
+	keyExchange := rules.MigrationMatrix.KeyExchange["RSA-2048"].Target
+	if signing == keyExchange {
+		t.Fatalf("Expected the default matrix to map RSA-2048 differently per bucket, both are %q", signing)
+	}
+	for i, expected := range []string{signing, keyExchange, signing} {
+		if got := plan.Findings[i].TargetAlgorithm; got != expected {
+			t.Errorf("%s: expected target %q, got %q", plan.Findings[i].File, expected, got)
+		}
+	}
+	if got := plan.Findings[2].Purpose; got != crypto.PurposeSigning {
+		t.Errorf("Expected purpose assessed from the description to be signing, got %q", got)
+	}
+}
@@ -0,0 +1,719 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"qvs-pro/scanner/internal/crypto"
+	"qvs-pro/scanner/internal/migration"
+	"qvs-pro/scanner/internal/schema"
+	"qvs-pro/scanner/internal/utils"
+)
+
+var sampleResults = []crypto.Result{
+	{File: "KeyGen.java", Algorithm: "RSA", Type: "PublicKey", Line: 3, Method: "Pattern Matching", Risk: "High"},
+	{File: "digest.py", Algorithm: "AES-256", Type: "SymmetricKey", Line: 7, Method: "Pattern Matching", Risk: "Low"},
+}
+
+func TestOutputJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := utils.OutputJSON(&buf, sampleResults); err != nil {
+		t.Fatalf("OutputJSON returned error: %v", err)
+	}
+
+	var decoded []crypto.Result
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if len(decoded) != len(sampleResults) || decoded[0].Algorithm != "RSA" {
+		t.Errorf("Unexpected decoded results: %+v", decoded)
+	}
+}
+
+func TestOutputJSONLWriter(t *testing.T) {
+	deprecation := time.Date(2029, 12, 31, 19, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	results := append([]crypto.Result{{
+		File: "tls.go", Algorithm: "ECDSA", Type: "PublicKey", Line: 12, Risk: "High",
+		Confidence: 0.85, SecurityStrength: 128, DeprecationDate: &deprecation,
+	}}, sampleResults...)
+
+	var buf bytes.Buffer
+	if err := utils.OutputJSONL(&buf, results); err != nil {
+		t.Fatalf("OutputJSONL returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(results) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(results), len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var decoded crypto.Result
+		if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+			t.Fatalf("line %d is not a standalone Result: %v\n%s", i+1, err, line)
+		}
+		if decoded.File != results[i].File || decoded.Line != results[i].Line || decoded.Algorithm != results[i].Algorithm {
+			t.Errorf("line %d: expected %+v, got %+v", i+1, results[i], decoded)
+		}
+	}
+
+	if !strings.Contains(lines[0], `"deprecation_date":"2030-01-01T00:00:00Z"`) || !strings.Contains(lines[0], `"confidence":0.85`) {
+		t.Errorf("expected UTC date and plain confidence, got %s", lines[0])
+	}
+	if deprecation.Location().String() != "EST" {
+		t.Error("OutputJSONL modified the caller's result")
+	}
+}
+
+func TestOutputTextWriter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := utils.OutputText(&buf, sampleResults); err != nil {
+		t.Fatalf("OutputText returned error: %v", err)
+	}
+	output := buf.String()
+	for _, expected := range []string{"Found 2 potential vulnerabilities", "File: KeyGen.java", "Algorithm: RSA (PublicKey)", "Risk Level: Low"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected text output to contain %q", expected)
+		}
+	}
+
+	buf.Reset()
+	if err := utils.OutputText(&buf, []crypto.Result{}); err != nil || !strings.Contains(buf.String(), "No vulnerabilities found.") {
+		t.Errorf("Expected empty-result message, got %q (err %v)", buf.String(), err)
+	}
+
+	if err := utils.OutputText(&buf, "not results"); err == nil {
+		t.Error("Expected error for unsupported result type")
+	}
+}
+
+func TestOutputCBOMWriter(t *testing.T) {
+	var buf bytes.Buffer
+	metadata := utils.ScanMetadata{Mode: "file", Target: "/src", TotalAssets: 2, ScanTime: utils.GetCurrentTimestamp()}
+	if err := utils.OutputCBOM(&buf, sampleResults, metadata, "file"); err != nil {
+		t.Fatalf("OutputCBOM returned error: %v", err)
+	}
+
+	var report utils.CBOMReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Output is not valid CBOM JSON: %v", err)
+	}
+	if report.BOMFormat == "" || len(report.Components) == 0 {
+		t.Errorf("Expected populated CBOM report, got %+v", report)
+	}
+}
+
+func TestCryptoAgilityScore(t *testing.T) {
+	results := []crypto.Result{
+		{File: "kem.go", Algorithm: "ML-KEM-768", Type: "PostQuantum", Risk: "Low", HNDLRisk: crypto.HNDLRiskNone},
+		{File: "tls.go", Algorithm: "X25519MLKEM768", Type: "Hybrid", Risk: "Low", HNDLRisk: crypto.HNDLRiskNone},
+		{File: "kex.go", Algorithm: "RSA", Type: "PublicKey", Risk: "High", HNDLRisk: crypto.HNDLRiskHigh},
+		{File: "hash.go", Algorithm: "SHA-1", Type: "Hash", Risk: "Medium", HNDLRisk: crypto.HNDLRiskNone},
+		{File: "cipher.go", Algorithm: "AES-128", Type: "SymmetricKey", Risk: "Low", HNDLRisk: crypto.HNDLRiskMedium},
+	}
+
+	// safe = 2; vulnerable = 3*2 + 2*1 + 1*1.5 = 9.5; 100*2/11.5 = 17.39
+	score, grade := crypto.CryptoAgilityScore(results)
+	if score != 17 || grade != "F" {
+		t.Errorf("Expected score 17 (F), got %d (%s)", score, grade)
+	}
+	if score, grade := crypto.CryptoAgilityScore(results[:2]); score != 100 || grade != "A" {
+		t.Errorf("Expected all quantum-safe findings to score 100 (A), got %d (%s)", score, grade)
+	}
+	if score, _ := crypto.CryptoAgilityScore(nil); score != 100 {
+		t.Errorf("Expected no findings to score 100, got %d", score)
+	}
+
+	var buf bytes.Buffer
+	if err := utils.OutputCBOM(&buf, results, utils.ScanMetadata{Mode: "file"}, "file"); err != nil {
+		t.Fatal(err)
+	}
+	var report utils.CBOMReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.CryptoAgilityScore != 17 || report.Summary.CryptoAgilityGrade != "F" {
+		t.Errorf("Expected CBOM summary score 17 (F), got %d (%s)", report.Summary.CryptoAgilityScore, report.Summary.CryptoAgilityGrade)
+	}
+
+	rules, err := migration.DefaultRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := migration.GeneratePlan(results, rules, "", "")
+	if plan.Summary.CryptoAgilityScore != 17 || plan.Summary.CryptoAgilityGrade != "F" {
+		t.Errorf("Expected migration summary score 17 (F), got %d (%s)", plan.Summary.CryptoAgilityScore, plan.Summary.CryptoAgilityGrade)
+	}
+}
+
+func TestCBOMComponentHashes(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "KeyGen.java")
+	content := []byte(`KeyPairGenerator kpg = KeyPairGenerator.getInstance("RSA");`)
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	results := crypto.NewScanner(false).ScanFile(tmpFile)
+	if len(results) == 0 {
+		t.Fatal("Expected findings in scanned file")
+	}
+	// k8s findings have no file to hash
+	results = append(results, crypto.Result{File: "secret/tls/tls.key (default)", Algorithm: "RSA", Type: "PublicKey", Risk: "High"})
+
+	var buf bytes.Buffer
+	if err := utils.OutputCBOM(&buf, results, utils.ScanMetadata{Mode: "file"}, "file"); err != nil {
+		t.Fatal(err)
+	}
+	var report utils.CBOMReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(content)
+	expected := hex.EncodeToString(sum[:])
+	for _, component := range report.Components {
+		switch component.Name {
+		case tmpFile:
+			if len(component.Hashes) != 1 || component.Hashes[0].Algorithm != "SHA-256" || component.Hashes[0].Content != expected {
+				t.Errorf("Expected SHA-256 %s for scanned file, got %+v", expected, component.Hashes)
+			}
+		default:
+			if len(component.Hashes) != 0 {
+				t.Errorf("Expected no hashes for %s, got %+v", component.Name, component.Hashes)
+			}
+		}
+	}
+}
+
+func TestCBOMDependencies(t *testing.T) {
+	rootCert, rootKey := newTestCertificate(t, "Test Root CA", true, nil, nil)
+	intermediateCert, intermediateKey := newTestCertificate(t, "Test Issuing CA", true, rootCert, rootKey)
+	leafCert, _ := newTestCertificate(t, "service.example", false, intermediateCert, intermediateKey)
+
+	results := append([]crypto.Result(nil), sampleResults...)
+	results = append(results, crypto.AnalyzeTLSCertificates(tlsCertificateRecord(leafCert.Raw, intermediateCert.Raw, rootCert.Raw), "capture.pcap")...)
+
+	var buf bytes.Buffer
+	if err := utils.OutputCBOM(&buf, results, utils.ScanMetadata{Mode: "file", Target: "/src"}, "file"); err != nil {
+		t.Fatal(err)
+	}
+	var report utils.CBOMReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Metadata.Component == nil {
+		t.Fatal("Expected a top-level application component")
+	}
+
+	refs := map[string]bool{report.Metadata.Component.BOMRef: true}
+	refsByPosition := make(map[string]string)
+	for _, component := range report.Components {
+		if refs[component.BOMRef] {
+			t.Errorf("Duplicate bom-ref %s", component.BOMRef)
+		}
+		refs[component.BOMRef] = true
+	}
+	for _, result := range results {
+		if result.ChainPosition == "" {
+			continue
+		}
+		if result.File != "capture.pcap" || result.Subject == "" || !strings.Contains(result.Description, result.Subject) {
+			t.Errorf("Expected the certificate subject in Subject and Description, not File; got file %q, subject %q", result.File, result.Subject)
+		}
+		for _, component := range report.Components {
+			if strings.Contains(component.Name, result.Subject) {
+				refsByPosition[result.ChainPosition] = component.BOMRef
+			}
+		}
+	}
+
+	graph := make(map[string][]string)
+	for _, dependency := range report.Dependencies {
+		if !refs[dependency.Ref] {
+			t.Errorf("Dangling dependency ref %s", dependency.Ref)
+		}
+		for _, ref := range dependency.DependsOn {
+			if !refs[ref] {
+				t.Errorf("Dangling dependsOn ref %s in %s", ref, dependency.Ref)
+			}
+		}
+		graph[dependency.Ref] = dependency.DependsOn
+	}
+
+	if len(graph[report.Metadata.Component.BOMRef]) != len(report.Components) {
+		t.Errorf("Expected application to depend on all %d components, got %v", len(report.Components), graph[report.Metadata.Component.BOMRef])
+	}
+	leaf, intermediate, root := refsByPosition[crypto.ChainPositionLeaf], refsByPosition[crypto.ChainPositionIntermediate], refsByPosition[crypto.ChainPositionRoot]
+	if deps := graph[leaf]; len(deps) != 1 || deps[0] != intermediate {
+		t.Errorf("Expected leaf to depend on intermediate %s, got %v", intermediate, deps)
+	}
+	if deps := graph[intermediate]; len(deps) != 1 || deps[0] != root {
+		t.Errorf("Expected intermediate to depend on root %s, got %v", root, deps)
+	}
+	if deps, ok := graph[root]; ok {
+		t.Errorf("Expected root to have no dependencies, got %v", deps)
+	}
+}
+
+func TestCBOMAssetCounts(t *testing.T) {
+	results := []crypto.Result{
+		{File: "mixed.go", Algorithm: "AES-256", Type: "SymmetricKey", Risk: "Low", QuantumResistant: true},
+		{File: "mixed.go", Algorithm: "RSA", Type: "PublicKey", Risk: "High"},
+		{File: "mixed.go", Algorithm: "ECDSA", Type: "PublicKey", Risk: "High"},
+		{File: "safe.go", Algorithm: "ML-KEM-768", Type: "PostQuantum", Risk: "Low", QuantumResistant: true},
+		{File: "safe.go", Algorithm: "SHA-256", Type: "Hash", Risk: "Low", QuantumResistant: true},
+	}
+
+	var buf bytes.Buffer
+	if err := utils.OutputCBOM(&buf, results, utils.ScanMetadata{Mode: "file", TotalAssets: 2}, "file"); err != nil {
+		t.Fatal(err)
+	}
+	var report utils.CBOMReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	// mixed.go counts once, as vulnerable; safe.go once, as quantum-safe
+	if report.Summary.VulnerableAssets != 1 {
+		t.Errorf("Expected 1 vulnerable asset, got %d", report.Summary.VulnerableAssets)
+	}
+	if report.Summary.QuantumSafeAssets != 1 {
+		t.Errorf("Expected 1 quantum-safe asset, got %d", report.Summary.QuantumSafeAssets)
+	}
+}
+
+func TestOutputTextGrouped(t *testing.T) {
+	results := []crypto.Result{
+		{File: "a.go", Algorithm: "RSA", Type: "PublicKey", Line: 3, Method: "Function Name", Risk: "High"},
+		{File: "b.go", Algorithm: "SHA-1", Type: "Hash", Line: 1, Method: "Function Name", Risk: "Medium"},
+		{File: "a.go", Algorithm: "MD5", Type: "Hash", Line: 9, Method: "Function Name", Risk: "Critical"},
+		{File: "a.go", Algorithm: "AES-128", Type: "SymmetricKey", Line: 12, Method: "Cipher Mode", Risk: "Low"},
+	}
+
+	var buf bytes.Buffer
+	if err := utils.OutputTextGrouped(&buf, results, utils.GroupByFile); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+	for _, header := range []string{"File: a.go (3 findings, highest risk: Critical)", "File: b.go (1 finding, highest risk: Medium)"} {
+		if count := strings.Count(output, header); count != 1 {
+			t.Errorf("Expected header %q exactly once, got %d in:\n%s", header, count, output)
+		}
+	}
+	if strings.Index(output, "File: a.go") > strings.Index(output, "File: b.go") {
+		t.Error("Expected file groups sorted by name")
+	}
+	if !strings.Contains(output, "  Line 9: MD5 (Hash)") {
+		t.Errorf("Expected indented findings under their file, got:\n%s", output)
+	}
+
+	buf.Reset()
+	if err := utils.OutputTextGrouped(&buf, results, utils.GroupByRisk); err != nil {
+		t.Fatal(err)
+	}
+	output = buf.String()
+	if !(strings.Index(output, "Risk: Critical") < strings.Index(output, "Risk: High") && strings.Index(output, "Risk: High") < strings.Index(output, "Risk: Low")) {
+		t.Errorf("Expected risk groups ordered by severity, got:\n%s", output)
+	}
+
+	if err := utils.OutputTextGrouped(&buf, results, "owner"); err == nil {
+		t.Error("Expected error for unsupported grouping")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestOutputWriterErrors(t *testing.T) {
+	if err := utils.OutputJSON(failingWriter{}, sampleResults); err == nil {
+		t.Error("Expected OutputJSON to report write error")
+	}
+	if err := utils.OutputText(failingWriter{}, sampleResults); err == nil {
+		t.Error("Expected OutputText to report write error")
+	}
+	if err := utils.OutputCBOM(failingWriter{}, sampleResults, utils.ScanMetadata{}, "file"); err == nil {
+		t.Error("Expected OutputCBOM to report write error")
+	}
+}
+
+func TestOutputToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "nightly", "cbom.json")
+
+	file, err := utils.CreateOutputFile(path)
+	if err != nil {
+		t.Fatalf("CreateOutputFile returned error: %v", err)
+	}
+	metadata := utils.ScanMetadata{Mode: "file", Target: "/src", TotalAssets: 2, ScanTime: utils.GetCurrentTimestamp()}
+	if err := utils.OutputCBOM(file, sampleResults, metadata, "file"); err != nil {
+		t.Fatalf("OutputCBOM returned error: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected CBOM file at %s: %v", path, err)
+	}
+	var report utils.CBOMReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Written file is not valid CBOM JSON: %v", err)
+	}
+	if len(report.Components) != len(sampleResults) {
+		t.Error("Expected written CBOM to contain components")
+	}
+}
+
+func TestCanonicalAlgorithmBreakdown(t *testing.T) {
+	results := []crypto.Result{
+		{File: "tls.pcap", Algorithm: "ECDHE", Type: "PublicKey", Risk: "High"},
+		{File: "kex.go", Algorithm: "ECDH", Type: "PublicKey", Risk: "High"},
+		{File: "digest.go", Algorithm: "SHA1", Type: "Hash", Risk: "High"},
+	}
+
+	var buf bytes.Buffer
+	if err := utils.OutputCBOM(&buf, results, utils.ScanMetadata{Mode: "file"}, "file"); err != nil {
+		t.Fatal(err)
+	}
+	var report utils.CBOMReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	breakdown := report.Summary.AlgorithmBreakdown
+	if breakdown["ECDH"] != 2 || breakdown["ECDHE"] != 0 {
+		t.Errorf("Expected ECDHE and ECDH in one ECDH bucket, got %v", breakdown)
+	}
+	if breakdown["SHA-1"] != 1 {
+		t.Errorf("Expected SHA1 to count as SHA-1, got %v", breakdown)
+	}
+
+	// Scanner findings carry the canonical name and keep the detected one
+	tmpFile := filepath.Join(t.TempDir(), "kex.go")
+	if err := os.WriteFile(tmpFile, []byte(`suite := "ECDHE-RSA"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	scanner := crypto.NewScanner(false)
+	scanner.Rules = []crypto.DetectionRule{{AlgorithmType: "PublicKey", AlgorithmName: "ECDHE", Method: "Configuration", Pattern: `ECDHE`, RiskLevel: "High"}}
+	found := scanner.ScanFile(tmpFile)
+	if len(found) != 1 || found[0].Algorithm != "ECDH" || found[0].RawAlgorithm != "ECDHE" {
+		t.Errorf("Expected one ECDH finding detected as ECDHE, got %+v", found)
+	}
+}
+
+func TestCBOMDeterministicOutput(t *testing.T) {
+	results := []crypto.Result{
+		{File: "b.go", Algorithm: "RSA", Type: "PublicKey", Line: 9, Risk: "High"},
+		{File: "a.py", Algorithm: "SHA-1", Type: "Hash", Line: 4, Risk: "High"},
+		{File: "b.go", Algorithm: "MD5", Type: "Hash", Line: 2, Risk: "Medium"},
+		{File: "a.py", Algorithm: "AES-256", Type: "SymmetricKey", Line: 4, Risk: "Low", QuantumResistant: true},
+		{File: "c.js", Algorithm: "DES", Type: "SymmetricKey", Line: 1, Risk: "Critical"},
+	}
+	reversed := make([]crypto.Result, len(results))
+	for i, result := range results {
+		reversed[len(results)-1-i] = result
+	}
+	metadata := utils.ScanMetadata{Mode: "file", Target: "/src", TotalAssets: 3, ScanTime: "2026-01-02T03:04:05Z"}
+
+	var first, second bytes.Buffer
+	if err := utils.OutputCBOM(&first, results, metadata, "file"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1100 * time.Millisecond) // Past a second boundary, so wall-clock stamps would differ
+	if err := utils.OutputCBOM(&second, reversed, metadata, "file"); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Fatalf("Expected identical CBOMs for the same findings in any order, got:\n%s\nand:\n%s", first.String(), second.String())
+	}
+
+	var report utils.CBOMReport
+	if err := json.Unmarshal(first.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, finding := range report.Findings {
+		order = append(order, fmt.Sprintf("%s:%d:%s", finding.File, finding.Line, finding.Algorithm))
+	}
+	want := []string{"a.py:4:AES-256", "a.py:4:SHA-1", "b.go:2:MD5", "b.go:9:RSA", "c.js:1:DES"}
+	if strings.Join(order, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected findings sorted by file, line and algorithm, got %v", order)
+	}
+	if report.Metadata.Timestamp != metadata.ScanTime {
+		t.Errorf("Expected the CBOM timestamp to be the scan time, got %s", report.Metadata.Timestamp)
+	}
+}
+
+func TestOutputRulesCustomRule(t *testing.T) {
+	rules := append(crypto.NewScanner(false).Rules, crypto.DetectionRule{
+		AlgorithmType: "KDF",
+		AlgorithmName: "Custom-KDF",
+		Method:        "Function Name",
+		Pattern:       `legacyKdf\(`,
+		RiskLevel:     "Medium",
+	})
+
+	var buf bytes.Buffer
+	if err := utils.OutputRules(&buf, rules, "text"); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Custom-KDF") || !strings.Contains(output, `legacyKdf\(`) || !strings.HasSuffix(output, fmt.Sprintf("%d rules\n", len(rules))) {
+		t.Errorf("Expected the custom rule in a listing of %d rules, got:\n%s", len(rules), output)
+	}
+}
+
+// schemaResults are findings rendered in both CBOM schemas by TestCBOMSchemas
+var schemaResults = []crypto.Result{
+	{File: "tls.go", Algorithm: "RSA", Type: "PublicKey", Line: 12, Column: 5, Method: "Go Analyzer", Risk: "High", VulnerabilityType: "Shor's Algorithm", KeySize: 2048, Purpose: "signing", NISTCategory: "deprecated", SecurityStrength: 112, Description: "RSA-2048 signature"},
+	{File: "tls.go", Algorithm: "TLS 1.0", Type: "Protocol", Line: 20, Method: "Pattern Matching", Risk: "Critical", Description: "TLS 1.0 enabled"},
+	{File: "kem.go", Algorithm: "ML-KEM-768", Type: "PostQuantum", Line: 3, Method: "Go Analyzer", Risk: "Low", QuantumResistant: true, Purpose: "keyEncapsulation", NISTCategory: "3", Description: "ML-KEM-768 key encapsulation"},
+	{File: "hash.py", Algorithm: "SHA-1", Type: "Hash", Line: 8, Method: "Pattern Matching", Risk: "Medium", VulnerabilityType: "Classical Weakness", Purpose: "hash", Description: "SHA-1 digest"},
+	{File: "kem.go", Algorithm: "SHA-1", Type: "Hash", Line: 9, Method: "Pattern Matching", Risk: "High", VulnerabilityType: "Classical Weakness", Purpose: "hash", Description: "SHA-1 digest"},
+}
+
+// legacyCBOMDigest is the SHA-256 of the legacy CBOM of schemaResults. Downstream
+// parsers depend on that shape byte for byte; only change it with a new schema.
+const legacyCBOMDigest = "3a49ad15e3cb79c843df220b96a180d500e97db1124c041745ae6a7c3a18f900"
+
+func TestCBOMSchemas(t *testing.T) {
+	metadata := utils.ScanMetadata{
+		Mode: "file", Target: "/src", TotalAssets: 3, ScanTime: "2026-01-02T03:04:05Z", Duration: "1.5s",
+		Warnings: []crypto.ScanWarning{{File: "big.bin", Reason: "file exceeded the scan timeout"}},
+	}
+
+	// The default and the pinned legacy schema are the same bytes
+	for _, schema := range []string{"", utils.CBOMSchemaLegacy} {
+		metadata.CBOMSchema = schema
+		var buf bytes.Buffer
+		if err := utils.OutputCBOM(&buf, schemaResults, metadata, "file"); err != nil {
+			t.Fatal(err)
+		}
+		if sum := sha256.Sum256(buf.Bytes()); hex.EncodeToString(sum[:]) != legacyCBOMDigest {
+			t.Errorf("Legacy CBOM with schema %q changed; got:\n%s", schema, buf.String())
+		}
+	}
+
+	metadata.CBOMSchema = utils.CBOMSchema16
+	var buf bytes.Buffer
+	if err := utils.OutputCBOM(&buf, schemaResults, metadata, "file"); err != nil {
+		t.Fatal(err)
+	}
+	var report utils.CBOM16Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.SpecVersion != "1.6" || !strings.HasPrefix(report.SerialNumber, "urn:uuid:") || len(report.SerialNumber) != len("urn:uuid:")+36 {
+		t.Errorf("Expected a 1.6 report with a UUID serial number, got %s %s", report.SpecVersion, report.SerialNumber)
+	}
+	if len(report.Metadata.Tools.Components) != 1 || len(report.Metadata.Supplier.URL) != 1 {
+		t.Errorf("Expected tools as components and supplier URLs as a list, got %+v", report.Metadata)
+	}
+
+	assets := make(map[string]utils.CBOM16Component)
+	files := 0
+	for _, component := range report.Components {
+		switch component.Type {
+		case "file":
+			files++
+		case "cryptographic-asset":
+			assets[component.Name] = component
+		}
+	}
+	if files != 3 || len(assets) != 4 {
+		t.Fatalf("Expected 3 files and 4 crypto assets, got %d and %v", files, assets)
+	}
+	if digest := assets["SHA-1"]; len(digest.Evidence.Occurrences) != 2 || digest.CryptoProperties.AlgorithmProperties.Primitive != "hash" {
+		t.Errorf("Expected both SHA-1 findings as occurrences of one hash asset, got %+v", digest)
+	}
+	rsa := assets["RSA"].CryptoProperties.AlgorithmProperties
+	if rsa.Primitive != "signature" || rsa.ParameterSetIdentifier != "2048" || rsa.ClassicalSecurityLevel != 112 || rsa.NISTQuantumSecurityLevel != 0 {
+		t.Errorf("Unexpected RSA algorithm properties %+v", rsa)
+	}
+	if kem := assets["ML-KEM-768"].CryptoProperties.AlgorithmProperties; kem.Primitive != "kem" || kem.NISTQuantumSecurityLevel != 3 {
+		t.Errorf("Unexpected ML-KEM-768 algorithm properties %+v", kem)
+	}
+	if protocol := assets["TLS 1.0"].CryptoProperties; protocol.AssetType != "protocol" || protocol.ProtocolProperties.Type != "tls" || protocol.ProtocolProperties.Version != "1.0" {
+		t.Errorf("Unexpected TLS 1.0 crypto properties %+v", protocol)
+	}
+
+	provided := 0
+	for _, dependency := range report.Dependencies {
+		provided += len(dependency.Provides)
+	}
+	if provided != 5 {
+		t.Errorf("Expected files to provide 5 crypto assets, got %d in %+v", provided, report.Dependencies)
+	}
+	properties := make(map[string]string)
+	for _, property := range report.Properties {
+		properties[property.Name] = property.Value
+	}
+	if properties["qvs-pro:summary:total_assets"] != "3" || properties["qvs-pro:warning"] != "big.bin: file exceeded the scan timeout" {
+		t.Errorf("Expected the summary and warnings as properties, got %v", properties)
+	}
+	for _, legacyOnly := range []string{`"findings"`, `"summary"`, `"quantumSafe"`, `"scope"`} {
+		if strings.Contains(buf.String(), legacyOnly) {
+			t.Errorf("Expected no legacy %s field in the 1.6 report", legacyOnly)
+		}
+	}
+
+	metadata.CBOMSchema = "1.5"
+	if err := utils.OutputCBOM(&buf, schemaResults, metadata, "file"); err == nil || !strings.Contains(err.Error(), "unsupported CBOM schema") {
+		t.Errorf("Expected an unsupported schema error, got %v", err)
+	}
+}
+
+func TestCBOM16Occurrences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Keys.java")
+	source := "class Keys {\n" +
+		"  KeyPair a() throws Exception { return KeyPairGenerator.getInstance(\"RSA\").generateKeyPair(); }\n" +
+		"  KeyPair b() throws Exception { return KeyPairGenerator.getInstance(\"RSA\").generateKeyPair(); }\n" +
+		"  Cipher c() throws Exception { return Cipher.getInstance(\"RSA/ECB/OAEPPadding\"); }\n" +
+		"}\n"
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var results []crypto.Result
+	for _, result := range crypto.NewScanner(false).ScanFile(path) {
+		if result.Algorithm == "RSA" {
+			results = append(results, result)
+		}
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected three RSA findings, got %+v", results)
+	}
+	// A second finding at the same place is not another occurrence
+	duplicate := results[0]
+	duplicate.Method = "Another Rule"
+	results = append(results, duplicate)
+
+	var buf bytes.Buffer
+	if err := utils.OutputCBOM(&buf, results, utils.ScanMetadata{Mode: "file", CBOMSchema: utils.CBOMSchema16}, "file"); err != nil {
+		t.Fatal(err)
+	}
+	var report utils.CBOM16Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	var assets []utils.CBOM16Component
+	for _, component := range report.Components {
+		if component.Type == "cryptographic-asset" {
+			assets = append(assets, component)
+		}
+	}
+	if len(assets) != 1 || assets[0].Name != "RSA" {
+		t.Fatalf("Expected one RSA asset, got %+v", assets)
+	}
+	occurrences := assets[0].Evidence.Occurrences
+	if len(occurrences) != 3 {
+		t.Fatalf("Expected three occurrences, got %+v", occurrences)
+	}
+	for i, occurrence := range occurrences {
+		if occurrence.Location != path || occurrence.Line != i+2 {
+			t.Errorf("Expected occurrence %d on line %d of %s, got %+v", i, i+2, path, occurrence)
+		}
+		// The rules match from the class name after "return "
+		if want := strings.Index(strings.Split(source, "\n")[i+1], "return ") + len("return ") + 1; occurrence.Offset != want {
+			t.Errorf("Expected occurrence %d at column %d, got %d", i, want, occurrence.Offset)
+		}
+	}
+}
+
+func TestOutputSchemas(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "KeyGen.java")
+	if err := os.WriteFile(source, []byte("KeyPairGenerator.getInstance(\"RSA\");\nMessageDigest.getInstance(\"MD5\");\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	results := append(crypto.NewScanner(false).ScanFile(source), schemaResults...)
+	metadata := utils.ScanMetadata{
+		Mode: "file", Target: filepath.Dir(source), TotalAssets: 4, ScanTime: "2026-01-02T03:04:05Z", Duration: "1.5s",
+		Warnings: []crypto.ScanWarning{{File: "big.bin", Reason: "file exceeded the scan timeout"}},
+	}
+	var report bytes.Buffer
+	if err := utils.OutputCBOM(&report, results, metadata, "file"); err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate(schema.CBOMReport, report.Bytes()); err != nil {
+		t.Errorf("Generated CBOM does not match its schema: %v", err)
+	}
+
+	var report16 bytes.Buffer
+	metadata.CBOMSchema = utils.CBOMSchema16
+	if err := utils.OutputCBOM(&report16, results, metadata, "file"); err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate(schema.CBOM16Report, report16.Bytes()); err != nil {
+		t.Errorf("Generated CycloneDX 1.6 CBOM does not match its schema: %v", err)
+	}
+	if err := schema.Validate(schema.CBOM16Report, report.Bytes()); err == nil {
+		t.Error("Expected the legacy CBOM not to match the CycloneDX 1.6 schema")
+	}
+
+	rules, err := migration.DefaultRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := json.Marshal(migration.GeneratePlan(results, rules, "edge_ingress", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate(schema.MigrationPlan, plan); err != nil {
+		t.Errorf("Generated migration plan does not match its schema: %v", err)
+	}
+
+	// Each document's schema is recognized from the document itself
+	for want, document := range map[string][]byte{schema.CBOMReport: report.Bytes(), schema.CBOM16Report: report16.Bytes(), schema.MigrationPlan: plan} {
+		if name, err := schema.Detect(document); err != nil || name != want {
+			t.Errorf("Expected %s to be detected, got %q (%v)", want, name, err)
+		}
+	}
+	if _, err := schema.Detect([]byte(`{"bomFormat": "CycloneDX", "specVersion": "1.5"}`)); err == nil {
+		t.Error("Expected an unsupported specVersion to be rejected")
+	}
+
+	// A renamed field, a wrong type and a missing section are each reported
+	malformed := strings.Replace(report.String(), `"vulnerability_type"`, `"vulnerabilityType"`, 1)
+	malformed = strings.Replace(malformed, `"version": 1`, `"version": "1"`, 1)
+	var document map[string]interface{}
+	if err := json.Unmarshal([]byte(malformed), &document); err != nil {
+		t.Fatal(err)
+	}
+	delete(document, "summary")
+	data, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var violations schema.Violations
+	if err := schema.Validate(schema.CBOMReport, data); !errors.As(err, &violations) {
+		t.Fatalf("Expected schema violations, got %v", err)
+	}
+	expected := map[string]bool{
+		`#: missing properties: 'summary'`:                                   false,
+		`#/version: expected integer, but got string`:                        false,
+		`#/findings/0: missing properties: 'vulnerability_type'`:             false,
+		`#/findings/0: additionalProperties 'vulnerabilityType' not allowed`: false,
+	}
+	for _, violation := range violations {
+		if _, ok := expected[violation.Error()]; ok {
+			expected[violation.Error()] = true
+		}
+	}
+	for message, found := range expected {
+		if !found {
+			t.Errorf("Expected violation %q, got %v", message, violations)
+		}
+	}
+
+	if err := schema.Validate(schema.CBOMReport, []byte(`{"bomFormat": `)); err == nil || errors.As(err, &violations) {
+		t.Errorf("Expected a JSON syntax error, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+//go:build !cgo
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"qvs-pro/scanner/internal/crypto"
+	"qvs-pro/scanner/pkg/cbom"
+)
+
+func TestStubReportsCaptureUnavailable(t *testing.T) {
+	stdout, _ := runScanner(t, "-check-capabilities", "-json", "-kubeconfig", filepath.Join(t.TempDir(), "missing"))
+	var capabilities []crypto.Capability
+	if err := json.Unmarshal([]byte(stdout), &capabilities); err != nil {
+		t.Fatalf("-check-capabilities -json output is not JSON: %v\n%s", err, stdout)
+	}
+	available := make(map[string]bool)
+	for _, capability := range capabilities {
+		available[capability.Name] = capability.Available
+		if !capability.Available && capability.Detail == "" {
+			t.Errorf("Expected %s to say why it is unavailable", capability.Name)
+		}
+	}
+	want := map[string]bool{crypto.CapabilityPCAPFile: true, crypto.CapabilityLiveCapture: false, crypto.CapabilityKubernetes: false}
+	for name, wantAvailable := range want {
+		if got, ok := available[name]; !ok || got != wantAvailable {
+			t.Errorf("Expected %s available=%v, got %v (reported: %v)", name, wantAvailable, got, ok)
+		}
+	}
+
+	opts := cbom.ScanOptions{Mode: cbom.ModeNetwork, Network: cbom.NetworkOptions{Interface: "eth0", Duration: "1s"}, Logger: log.New(io.Discard, "", 0)}
+	result, err := cbom.Scan(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	if len(result.Metadata.Warnings) != 1 || result.Metadata.Warnings[0].Capability != crypto.CapabilityLiveCapture {
+		t.Fatalf("Expected one live-capture warning, got %+v", result.Metadata.Warnings)
+	}
+
+	opts.Strict = true
+	if _, err := cbom.Scan(context.Background(), opts); !errors.Is(err, cbom.ErrBackendUnavailable) {
+		t.Errorf("Expected ErrBackendUnavailable in strict mode, got %v", err)
+	}
+}
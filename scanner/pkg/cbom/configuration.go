@@ -0,0 +1,129 @@
+package cbom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"qvs-pro/scanner/internal/crypto"
+	"qvs-pro/scanner/internal/utils"
+)
+
+// scanConfiguration records the configuration scans run with opts share, for their
+// metadata. Options are keyed by their command-line flag and only listed when they
+// differ from the default. Secrets are recorded as "set", and files by name only.
+// The parts that depend on the scanned target are added by targetConfiguration.
+func scanConfiguration(rules []DetectionRule, opts ScanOptions) *utils.ScanConfiguration {
+	mode := opts.Mode
+	if mode == "" {
+		mode = ModeFile
+	}
+	config := &utils.ScanConfiguration{
+		Mode:          mode,
+		RuleSetDigest: crypto.RuleSetDigest(rules),
+		RuleCount:     len(rules),
+		Options:       make(map[string]string),
+		OS:            runtime.GOOS + "/" + runtime.GOARCH,
+		GoVersion:     runtime.Version(),
+	}
+
+	set := func(name string, enabled bool, value string) {
+		if enabled {
+			config.Options[name] = value
+		}
+	}
+	flag := func(name string, enabled bool) {
+		set(name, enabled, "true")
+	}
+	file := func(name, path string) {
+		set(name, path != "", filepath.Base(path))
+	}
+
+	flag("strict", opts.Strict)
+	set("cbom-schema", opts.CBOMSchema != "" && opts.CBOMSchema != utils.CBOMSchemaLegacy, opts.CBOMSchema)
+	file("sbom", opts.SBOM)
+	file("severity-map", opts.SeverityMap)
+	file("suppressions", opts.Suppressions)
+	set("compliance", opts.Compliance != "", opts.Compliance)
+	set("project-date", !opts.ProjectDate.IsZero(), opts.ProjectDate.Format("2006-01-02"))
+	set("min-risk", opts.Filter.MinRisk != "", opts.Filter.MinRisk)
+	set("algorithm", len(opts.Filter.Algorithms) > 0, strings.Join(opts.Filter.Algorithms, ","))
+	set("nist-category", len(opts.Filter.NISTCategories) > 0, strings.Join(opts.Filter.NISTCategories, ","))
+	set("only-env", len(opts.Filter.Environments) > 0, strings.Join(opts.Filter.Environments, ","))
+	set("env", opts.Environment != "", opts.Environment)
+	set("env-path", len(opts.EnvironmentPaths) > 0, strings.Join(opts.EnvironmentPaths, ","))
+
+	switch mode {
+	case ModeFile:
+		flag("scan-archives", opts.ScanArchives)
+		flag("scan-binaries", opts.ScanBinaries)
+		flag("scan-config", opts.ScanConfig)
+		flag("scan-scripts", opts.ScanScripts)
+		set("min-confidence", opts.MinConfidence > 0, strconv.FormatFloat(opts.MinConfidence, 'f', -1, 64))
+		set("context-lines", opts.ContextLines != 0, strconv.Itoa(opts.ContextLines))
+		flag("show-remediation", opts.ShowRemediation)
+		flag("suggest-fixes", opts.SuggestFixes)
+		set("keystore-password", opts.KeystorePassword != "", "set")
+		set("file-timeout", opts.FileTimeout > 0, opts.FileTimeout.String())
+		set("ref", opts.Ref != "", opts.Ref)
+		flag("redact-paths", opts.RedactPaths)
+	case ModeKubernetes, ModeClusterScan:
+		k := opts.Kubernetes
+		// Secret and ConfigMap scans are on by default, so turning them off is recorded
+		set("secret-scan", !k.SecretScan, "false")
+		set("configmap-scan", !k.ConfigMapScan, "false")
+		flag("image-scan", k.ImageScan)
+		flag("network-policy-scan", k.NetworkPolicyScan)
+		flag("ingress-scan", k.IngressScan)
+		flag("service-mesh-scan", k.ServiceMeshScan)
+		flag("deep-code-scan", k.DeepCodeScan)
+		flag("webhook-scan", k.WebhookScan)
+		flag("env-scan", k.EnvScan)
+		flag("include-kube-system", k.IncludeKubeSystem)
+		set("kube-context", k.Context != "", k.Context)
+	case ModePCAP, ModeNetwork:
+		n := opts.Network
+		flag("live-capture", n.LiveCapture)
+		set("interface", n.LiveCapture || mode == ModeNetwork, n.Interface)
+		set("duration", n.LiveCapture || mode == ModeNetwork, n.Duration)
+		flag("tls-only", n.TLSOnly)
+	}
+
+	// The host name identifies the machine, which redacted reports must not
+	if !opts.RedactPaths {
+		config.Host, _ = os.Hostname()
+	}
+	return config
+}
+
+// targetConfiguration returns the configuration of a scan of opts.Targets: the
+// shared configuration with the capture file analyzed or the commit checked out
+func (s *Scanner) targetConfiguration(ctx context.Context, opts ScanOptions) *utils.ScanConfiguration {
+	config := *s.configuration
+	config.Options = make(map[string]string, len(s.configuration.Options)+1)
+	for name, value := range s.configuration.Options {
+		config.Options[name] = value
+	}
+
+	switch config.Mode {
+	case ModePCAP:
+		if len(opts.Targets) > 0 && opts.Targets[0] != "" && !opts.Network.LiveCapture {
+			pcapFile := opts.Targets[0]
+			if pcapFile != crypto.PCAPStdin {
+				pcapFile = filepath.Base(pcapFile)
+			}
+			config.Options["pcap-file"] = pcapFile
+		}
+	case ModeFile:
+		targets := append(append([]string(nil), opts.Targets...), opts.Files...)
+		if len(targets) == 0 {
+			targets = []string{"."}
+		}
+		// A canceled scan still reports the commit its partial findings came from
+		config.GitCommit = crypto.GitCommit(context.WithoutCancel(ctx), targets[0])
+	}
+	return &config
+}
@@ -0,0 +1,920 @@
+// Package cbom is the embeddable entrypoint to the crypto scanner. It runs a scan
+// described by ScanOptions and returns findings and metadata instead of printing them.
+package cbom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"qvs-pro/scanner/internal/compliance"
+	"qvs-pro/scanner/internal/crypto"
+	"qvs-pro/scanner/internal/metrics"
+	"qvs-pro/scanner/internal/sbom"
+	"qvs-pro/scanner/internal/utils"
+)
+
+// Finding is a single crypto finding
+type Finding = crypto.Result
+
+// Metadata describes the scan that produced a set of findings
+type Metadata = utils.ScanMetadata
+
+// DetectionRule is a pattern-based detection rule
+type DetectionRule = crypto.DetectionRule
+
+// Capability reports whether a feature such as live capture works in this build and
+// environment
+type Capability = crypto.Capability
+
+// ErrScanCanceled is returned, wrapping the context error, when ctx is canceled or
+// its deadline passes mid-scan. The ScanResult returned with it holds partial findings.
+var ErrScanCanceled = crypto.ErrScanCanceled
+
+// ErrBackendUnavailable is returned in Strict mode when a pcap, network or Kubernetes
+// scan can't reach libpcap, the capture interface or the cluster
+var ErrBackendUnavailable = crypto.ErrBackendUnavailable
+
+// Supported scan modes
+const (
+	ModeFile        = "file"
+	ModeKubernetes  = "k8s"
+	ModeClusterScan = "cluster-scan"
+	ModePCAP        = "pcap"
+	ModeNetwork     = "network"
+)
+
+// ScanOptions configures a scan
+type ScanOptions struct {
+	Mode             string          // Scan mode: file, k8s, cluster-scan, pcap, network
+	Targets          []string        // file: paths to scan (default: current directory); k8s: namespaces; pcap: the PCAP file, or "-" for stdin
+	Files            []string        // file: individual files to scan without walking a directory; missing ones are skipped with a warning
+	Rules            []DetectionRule // Detection rules (default: built-in rule set)
+	Concurrency      int             // Files scanned in parallel in file mode (default: number of CPUs)
+	MinConfidence    float64         // Drop rule matches below this confidence, 0-1 (default: keep all)
+	ContextLines     int             // Source lines kept around each finding in its snippet (default: the matched line only; negative: none)
+	ShowRemediation  bool            // Attach each rule's example fix in the file's language to its findings
+	SuggestFixes     bool            // Attach a unified diff to findings with a safe, mechanical fix
+	KeystorePassword string          // Password opening PKCS#12 and Java keystores (default: only unprotected ones are opened)
+	Verbose          bool            // Print progress while scanning
+	Logger           *log.Logger     // Destination for verbose and diagnostic messages (default: stderr)
+
+	// Progress, if set, is called once per file during directory scans with the
+	// number of files done, the total found and the file just finished
+	Progress func(scanned, total int, current string)
+
+	ScanArchives bool // Descend into jar/war/zip/tar.gz archives
+	ScanBinaries bool // Search ELF/PE/Mach-O binaries for crypto OIDs and symbols
+	ScanConfig   bool // Read YAML/JSON/TOML files for algorithms, cipher lists and key sizes under known keys
+	ScanScripts  bool // Scan Dockerfiles and shell scripts for OpenSSL pins, cipher flags and ssh-keygen key types
+
+	// FileTimeout skips any file in a directory scan that takes longer than this to
+	// scan, listing it in Metadata.TimedOutFiles (default: no limit)
+	FileTimeout time.Duration
+
+	// Repo, if set, shallow-clones this Git repository at Ref (default: its default
+	// branch) into a temporary directory and scans it in file mode instead of Targets.
+	// Findings are reported relative to the repository root, and the clone is removed
+	// afterwards. RepoToken authenticates to private https repositories.
+	Repo      string
+	Ref       string
+	RepoToken string
+
+	// CacheDir, if set, caches per-file findings in file mode keyed by content hash,
+	// so unchanged files are not scanned again. Entries from another rule set are ignored.
+	CacheDir string
+
+	// RedactPaths replaces filesystem paths in file and pcap scan findings and
+	// metadata with hashes salted by RedactSalt. Paths under ScanRoot are reported
+	// relative to it instead.
+	RedactPaths bool
+	RedactSalt  string
+	ScanRoot    string
+
+	// Strict makes pcap, network and Kubernetes scans fail with ErrBackendUnavailable
+	// instead of returning simulated findings when the real backend can't be used
+	Strict bool
+
+	// ProjectDate, if set, gives each finding with a NIST IR 8547 entry its
+	// deprecated/disallowed status on that date, e.g. when the project ships
+	ProjectDate time.Time
+
+	// Timing adds the time spent walking, scanning files and calling the Kubernetes
+	// API to Metadata.Timing
+	Timing bool
+
+	// Filter drops findings after scanning, before they are returned or streamed.
+	// Metadata.FilteredFindings counts the findings it dropped.
+	Filter FindingFilter
+
+	Kubernetes KubernetesOptions
+	Network    NetworkOptions
+
+	Format string // Preferred output format for callers rendering the result: text, json, cbom
+
+	SBOM string // Optional CycloneDX or SPDX JSON SBOM; findings in its components get PURL and version
+
+	// SeverityMap is an optional YAML or JSON file remapping finding risks to an
+	// organization's own scale, see crypto.SeverityMap. It is applied before Filter.
+	SeverityMap string
+
+	// Environment labels every finding with the deployment environment of the scanned
+	// code: prod, staging, dev or test (default: unlabeled). EnvironmentPaths label the
+	// files they match instead, as PATTERN=ENV; see crypto.EnvironmentPath. Findings in
+	// dev and test code are lowered one risk level, after SeverityMap and before Filter.
+	Environment      string
+	EnvironmentPaths []string
+
+	// Suppressions is an optional YAML or JSON file of finding fingerprints left out
+	// of the results, see crypto.Suppressions. Metadata.SuppressedFindings counts them.
+	Suppressions string
+
+	// Compliance, if set, maps findings to the controls of this framework (pci,
+	// fips140-3 or nist-sp800-131a) and adds a pass/fail summary per control to
+	// Metadata.Compliance. Filtered findings are not assessed.
+	Compliance string
+
+	// CBOMSchema is the schema of CBOM output rendered from the result, recorded
+	// in Metadata.CBOMSchema: utils.CBOMSchemaLegacy (default) or utils.CBOMSchema16
+	CBOMSchema string
+}
+
+// KubernetesOptions selects which cluster resources are scanned
+type KubernetesOptions struct {
+	SecretScan        bool
+	ConfigMapScan     bool
+	ImageScan         bool
+	NetworkPolicyScan bool
+	IngressScan       bool
+	ServiceMeshScan   bool
+	DeepCodeScan      bool
+	WebhookScan       bool
+	EnvScan           bool
+	IncludeKubeSystem bool
+
+	Kubeconfig string // Path to a kubeconfig file (default: in-cluster config, then $KUBECONFIG or ~/.kube/config)
+	Context    string // Kubeconfig context to use (default: the current context)
+}
+
+// scanOptions returns the resources k selects for crypto.Scanner.ScanKubernetes
+func (k KubernetesOptions) scanOptions() crypto.KubernetesScanOptions {
+	return crypto.KubernetesScanOptions{
+		SecretScan:        k.SecretScan,
+		ConfigMapScan:     k.ConfigMapScan,
+		ImageScan:         k.ImageScan,
+		NetworkPolicyScan: k.NetworkPolicyScan,
+		IngressScan:       k.IngressScan,
+		ServiceMeshScan:   k.ServiceMeshScan,
+		DeepCodeScan:      k.DeepCodeScan,
+		WebhookScan:       k.WebhookScan,
+		EnvScan:           k.EnvScan,
+		IncludeKubeSystem: k.IncludeKubeSystem,
+	}
+}
+
+// FindingFilter selects the findings a scan reports. Empty fields keep everything.
+type FindingFilter struct {
+	MinRisk        string   // Lowest risk kept: Low, Medium, High or Critical (case-insensitive)
+	Algorithms     []string // Algorithms kept, compared by canonical name, e.g. "RSA" or "SHA1"
+	NISTCategories []string // NIST IR 8547 categories kept: "1" to "5", "deprecated" or "disallowed"
+	Environments   []string // Environments kept: prod, staging, dev or test
+}
+
+// riskLevels ranks the risk levels FindingFilter.MinRisk accepts
+var riskLevels = map[string]int{"low": 0, "medium": 1, "high": 2, "critical": 3}
+
+// Validate reports an unsupported MinRisk, NIST category or environment
+func (f FindingFilter) Validate() error {
+	if _, ok := riskLevels[strings.ToLower(f.MinRisk)]; f.MinRisk != "" && !ok {
+		return fmt.Errorf("unsupported minimum risk '%s'. Use: low, medium, high, critical", f.MinRisk)
+	}
+	for _, category := range f.NISTCategories {
+		switch crypto.NISTCategory(strings.ToLower(category)) {
+		case crypto.NISTCategory1, crypto.NISTCategory2, crypto.NISTCategory3, crypto.NISTCategory4, crypto.NISTCategory5,
+			crypto.NISTCategoryDeprecated, crypto.NISTCategoryDisallowed:
+		default:
+			return fmt.Errorf("unsupported NIST category '%s'. Use: 1-5, deprecated, disallowed", category)
+		}
+	}
+	for _, environment := range f.Environments {
+		if err := crypto.CheckEnvironment(environment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keep reports whether finding passes the filter. Findings with an unknown risk
+// level are dropped by any MinRisk.
+func (f FindingFilter) keep(finding Finding) bool {
+	if f.MinRisk != "" {
+		rank, ok := riskLevels[strings.ToLower(finding.Risk)]
+		if !ok || rank < riskLevels[strings.ToLower(f.MinRisk)] {
+			return false
+		}
+	}
+	if len(f.Algorithms) > 0 && !containsFold(f.Algorithms, finding.Algorithm, crypto.CanonicalAlgorithm) {
+		return false
+	}
+	if len(f.NISTCategories) > 0 && !containsFold(f.NISTCategories, finding.NISTCategory, nil) {
+		return false
+	}
+	if len(f.Environments) > 0 && !containsFold(f.Environments, finding.Environment, nil) {
+		return false
+	}
+	return true
+}
+
+// FailingFindings returns the findings at or above risk, which -fail-on fails a
+// scan on. risk is a FindingFilter.MinRisk level. Simulated findings weren't
+// observed, so they never fail a scan.
+func FailingFindings(findings []Finding, risk string) []Finding {
+	filter := FindingFilter{MinRisk: risk}
+	var failing []Finding
+	for _, finding := range findings {
+		if !finding.Simulated && filter.keep(finding) {
+			failing = append(failing, finding)
+		}
+	}
+	return failing
+}
+
+// containsFold reports whether list holds value, ignoring case and comparing both
+// sides through normalize when it is set
+func containsFold(list []string, value string, normalize func(string) string) bool {
+	if normalize != nil {
+		value = normalize(value)
+	}
+	for _, item := range list {
+		if normalize != nil {
+			item = normalize(item)
+		}
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// NetworkOptions configures PCAP analysis and live capture
+type NetworkOptions struct {
+	LiveCapture bool
+	Interface   string // Network interface for live capture (default: eth0)
+	Duration    string // Capture duration (default: 60s)
+	TLSOnly     bool
+}
+
+// ScanResult holds the findings and metadata of a completed scan
+type ScanResult struct {
+	Findings []Finding
+	Metadata Metadata
+}
+
+// DefaultRules returns the built-in detection rule set
+func DefaultRules() []DetectionRule {
+	return crypto.NewScanner(false).Rules
+}
+
+// Capabilities reports which scan features are available: reading capture files,
+// live capture, which needs a cgo build with libpcap, and access to the cluster of k
+func Capabilities(k KubernetesOptions) []Capability {
+	return crypto.CheckCapabilities(k.Kubeconfig, k.Context)
+}
+
+// Scan runs a scan described by opts. If ctx is done before the scan completes,
+// the findings gathered so far are returned with an error wrapping ErrScanCanceled.
+func Scan(ctx context.Context, opts ScanOptions) (ScanResult, error) {
+	return scan(ctx, opts, nil)
+}
+
+// ScanStream runs a scan like Scan but sends each finding to out as it is found and
+// closes out when the scan ends. File scans stream as each file completes; other
+// modes send their findings once the scan finishes. The returned ScanResult holds
+// metadata only.
+func ScanStream(ctx context.Context, opts ScanOptions, out chan<- Finding) (ScanResult, error) {
+	defer close(out)
+	return scan(ctx, opts, out)
+}
+
+// scan runs a scan, collecting findings when out is nil and streaming them otherwise
+func scan(ctx context.Context, opts ScanOptions, out chan<- Finding) (ScanResult, error) {
+	s, err := NewScanner(opts)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	return s.scan(ctx, opts.Targets, out)
+}
+
+// Scanner runs scans sharing one set of options. It builds the detection rules and
+// loads the SBOM, severity map, environments, suppressions and compliance framework
+// once, so scanning many targets doesn't repeat that work.
+type Scanner struct {
+	opts         ScanOptions
+	rules        []DetectionRule
+	bom          *sbom.SBOM
+	severities   *crypto.SeverityMap
+	environments *crypto.Environments
+	suppressions *crypto.Suppressions
+	framework    *compliance.Framework
+
+	configuration *utils.ScanConfiguration // Shared by every scan, see targetConfiguration
+}
+
+// NewScanner validates opts and prepares a Scanner for them
+func NewScanner(opts ScanOptions) (*Scanner, error) {
+	if err := opts.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := utils.CheckCBOMSchema(opts.CBOMSchema); err != nil {
+		return nil, err
+	}
+	if opts.Network.Interface == "" {
+		opts.Network.Interface = "eth0"
+	}
+	if opts.Network.Duration == "" {
+		opts.Network.Duration = "60s"
+	}
+
+	s := &Scanner{opts: opts, rules: opts.Rules}
+	if s.rules == nil {
+		s.rules = DefaultRules()
+	}
+	var err error
+	if opts.SBOM != "" {
+		if s.bom, err = sbom.Load(opts.SBOM); err != nil {
+			return nil, err
+		}
+	}
+	if opts.SeverityMap != "" {
+		if s.severities, err = crypto.LoadSeverityMap(opts.SeverityMap); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Environment != "" || len(opts.EnvironmentPaths) > 0 {
+		if s.environments, err = scanEnvironments(opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Suppressions != "" {
+		if s.suppressions, err = crypto.LoadSuppressions(opts.Suppressions); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Compliance != "" {
+		if s.framework, err = compliance.Load(opts.Compliance); err != nil {
+			return nil, err
+		}
+	}
+	s.configuration = scanConfiguration(s.rules, opts)
+	return s, nil
+}
+
+// Scan runs a scan of targets, or of the Targets of its options when targets is nil,
+// like the package-level Scan
+func (s *Scanner) Scan(ctx context.Context, targets []string) (ScanResult, error) {
+	if targets == nil {
+		targets = s.opts.Targets
+	}
+	return s.scan(ctx, targets, nil)
+}
+
+// scan runs a scan of targets, collecting findings when out is nil and streaming
+// them otherwise
+func (s *Scanner) scan(ctx context.Context, targets []string, out chan<- Finding) (ScanResult, error) {
+	if err := ctx.Err(); err != nil {
+		return ScanResult{}, fmt.Errorf("%w: %w", ErrScanCanceled, err)
+	}
+	opts := s.opts
+	opts.Targets = targets
+
+	scanner := crypto.NewScannerWithRules(opts.Verbose, s.rules)
+	scanner.Workers = opts.Concurrency
+	scanner.MinConfidence = opts.MinConfidence
+	scanner.ContextLines = opts.ContextLines
+	scanner.ShowRemediation = opts.ShowRemediation
+	scanner.SuggestFixes = opts.SuggestFixes
+	scanner.KeystorePassword = opts.KeystorePassword
+	scanner.Strict = opts.Strict
+	scanner.FileTimeout = opts.FileTimeout
+	scanner.ScanArchives = opts.ScanArchives
+	scanner.ScanBinaries = opts.ScanBinaries
+	scanner.ScanConfig = opts.ScanConfig
+	scanner.ScanScripts = opts.ScanScripts
+	scanner.ProgressFunc = opts.Progress
+	if opts.Logger != nil {
+		scanner.Logger = opts.Logger
+	}
+
+	if opts.Repo != "" {
+		if opts.Mode != ModeFile && opts.Mode != "" {
+			return ScanResult{}, fmt.Errorf("a repository can only be scanned in file mode")
+		}
+		if len(opts.Targets) > 0 || len(opts.Files) > 0 {
+			return ScanResult{}, fmt.Errorf("a repository scan cannot be combined with targets or files")
+		}
+		dir, err := os.MkdirTemp("", "cbom-repo-")
+		if err != nil {
+			return ScanResult{}, fmt.Errorf("error creating clone directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		if opts.Verbose {
+			scanner.Logger.Printf("Cloning %s\n", crypto.RedactURLCredentials(opts.Repo))
+		}
+		if err := crypto.CloneRepository(ctx, opts.Repo, opts.Ref, opts.RepoToken, dir); err != nil {
+			return ScanResult{}, err
+		}
+		opts.Targets = []string{dir}
+	}
+
+	scanner.Roots = ScanRoots(opts)
+	sink := &findingSink{out: out, bom: s.bom, roots: scanner.Roots, severities: s.severities, environments: s.environments, suppressions: s.suppressions, projectDate: opts.ProjectDate, filter: opts.Filter}
+	if s.framework != nil {
+		sink.compliance = s.framework.NewAssessment()
+	}
+	if opts.RedactPaths && (opts.Mode == ModeFile || opts.Mode == "" || opts.Mode == ModePCAP) {
+		redactor := utils.PathRedactor{Salt: opts.RedactSalt}
+		if opts.ScanRoot != "" {
+			root, err := filepath.Abs(opts.ScanRoot)
+			if err != nil {
+				return ScanResult{}, fmt.Errorf("error resolving scan root: %w", err)
+			}
+			redactor.Root = root
+		}
+		sink.redactor = &redactor
+	}
+	if opts.Repo != "" {
+		// The clone is gone once the scan returns, so its paths mean nothing outside it
+		sink.redactor = &utils.PathRedactor{Salt: opts.RedactSalt, Root: opts.Targets[0]}
+	}
+
+	start := time.Now()
+	var result ScanResult
+	var err error
+	switch opts.Mode {
+	case ModeFile, "":
+		result, err = scanFiles(ctx, scanner, opts, sink)
+	case ModeKubernetes, ModeClusterScan:
+		result, err = scanKubernetes(ctx, scanner, opts)
+	case ModePCAP:
+		result, err = scanPCAP(ctx, scanner, opts)
+	case ModeNetwork:
+		result, err = scanNetwork(ctx, scanner, opts)
+	default:
+		return ScanResult{}, fmt.Errorf("unsupported mode '%s'. Use: file, k8s, cluster-scan, pcap, network", opts.Mode)
+	}
+
+	if opts.Mode != ModeFile && opts.Mode != "" {
+		sink.add(ctx, result.Findings)
+	}
+	result.Findings = sink.findings
+	result.Metadata.Duration = time.Since(start).Round(time.Microsecond).String()
+	if opts.Timing {
+		result.Metadata.Timing = phaseTiming(scanner.Timing())
+		scanner.Logger.Printf("Scan took %s (walk %s, match %s, Kubernetes API %s)\n", result.Metadata.Duration,
+			durationOrNone(result.Metadata.Timing.Walk), durationOrNone(result.Metadata.Timing.Match), durationOrNone(result.Metadata.Timing.KubernetesAPI))
+	}
+	result.Metadata.FilteredFindings = sink.filtered
+	result.Metadata.SuppressedFindings = sink.suppressed
+	if !opts.ProjectDate.IsZero() {
+		result.Metadata.ProjectDate = opts.ProjectDate.Format("2006-01-02")
+	}
+	result.Metadata.Warnings = scanner.Warnings()
+	result.Metadata.CBOMSchema = opts.CBOMSchema
+	if result.Metadata.CBOMSchema == "" {
+		result.Metadata.CBOMSchema = utils.CBOMSchemaLegacy
+	}
+	result.Metadata.Configuration = s.targetConfiguration(ctx, opts)
+	if sink.compliance != nil {
+		result.Metadata.Compliance = sink.compliance.Summary()
+	}
+
+	if sink.redactor != nil {
+		result.Metadata.Target = sink.redactor.RedactList(result.Metadata.Target)
+		for i, warning := range result.Metadata.Warnings {
+			if warning.File != "" {
+				result.Metadata.Warnings[i].File = sink.redactor.Redact(warning.File)
+			}
+		}
+		for i, target := range result.Metadata.Targets {
+			result.Metadata.Targets[i].Target = sink.redactor.Redact(target.Target)
+		}
+		for i, file := range result.Metadata.TimedOutFiles {
+			result.Metadata.TimedOutFiles[i] = sink.redactor.Redact(file)
+		}
+	}
+	if opts.Repo != "" {
+		result.Metadata.Target = crypto.RedactURLCredentials(opts.Repo)
+		if opts.Ref != "" {
+			result.Metadata.Target += "@" + opts.Ref
+		}
+	}
+	if errors.Is(err, ErrScanCanceled) {
+		result.Metadata.Partial = true
+	}
+	if s.bom != nil && opts.Verbose {
+		scanner.Logger.Printf("Attached SBOM provenance to %d of %d findings\n", sink.enriched, len(sink.metrics))
+	}
+	if err == nil || result.Metadata.Partial {
+		recordMetrics(opts.Mode, time.Since(start), result.Metadata.TotalAssets, sink.metrics)
+	}
+	return result, err
+}
+
+// phaseTiming formats the phases a scan went through as metadata
+func phaseTiming(t crypto.ScanTiming) *utils.PhaseTiming {
+	format := func(d time.Duration) string {
+		if d == 0 {
+			return ""
+		}
+		return d.Round(time.Microsecond).String()
+	}
+	return &utils.PhaseTiming{Walk: format(t.Walk), Match: format(t.Match), KubernetesAPI: format(t.KubernetesAPI)}
+}
+
+// durationOrNone returns d, or "-" for a phase the scan did not go through
+func durationOrNone(d string) string {
+	if d == "" {
+		return "-"
+	}
+	return d
+}
+
+// findingSink receives findings as a scan produces them, tagging default CWEs,
+// attaching SBOM provenance, labeling environments, redacting paths, dropping
+// suppressed findings, applying the severity map, environment risk and filter and
+// assessing compliance, then either collecting them or sending them on out
+type findingSink struct {
+	out          chan<- Finding // nil collects into findings
+	bom          *sbom.SBOM
+	roots        []string
+	redactor     *utils.PathRedactor  // nil keeps paths as scanned
+	severities   *crypto.SeverityMap  // nil keeps the scanner's risks
+	environments *crypto.Environments // nil leaves findings unlabeled
+	suppressions *crypto.Suppressions // nil suppresses nothing
+	projectDate  time.Time            // Zero: no project date status
+	filter       FindingFilter
+	compliance   *compliance.Assessment // nil: no compliance mapping
+
+	findings   []Finding
+	filtered   int // Findings dropped by filter
+	suppressed int // Findings dropped by suppressions
+	metrics    []metrics.Finding
+	enriched   int
+}
+
+// add passes a batch of findings through the sink. Sends stop once ctx is done.
+func (s *findingSink) add(ctx context.Context, batch []Finding) {
+	// Source findings already have fingerprints; the rest are derived before paths are redacted
+	crypto.SetFingerprints(batch, nil, s.roots)
+	crypto.SetDefaultCWE(batch)
+	if s.bom != nil {
+		s.enriched += s.bom.Enrich(batch, s.roots)
+	}
+	if s.environments != nil {
+		s.environments.Label(batch, s.roots)
+	}
+	// Redact after enrichment and labeling, which match on the real paths
+	if s.redactor != nil {
+		s.redactor.RedactResults(batch)
+	}
+	if !s.projectDate.IsZero() {
+		crypto.SetTimelineStatusAt(batch, s.projectDate)
+	}
+	var kept []Finding
+	suppressed := 0
+	for _, f := range batch {
+		if s.suppressions != nil && s.suppressions.Suppresses(f) {
+			suppressed++
+			continue
+		}
+		if s.severities != nil {
+			s.severities.Apply(&f)
+		}
+		crypto.ApplyEnvironmentRisk(&f)
+		s.metrics = append(s.metrics, metrics.Finding{Risk: f.Risk, Algorithm: f.Algorithm})
+		if s.filter.keep(f) {
+			if s.compliance != nil {
+				s.compliance.Add(&f)
+			}
+			kept = append(kept, f)
+		}
+	}
+	s.suppressed += suppressed
+	s.filtered += len(batch) - len(kept) - suppressed
+	batch = kept
+	if s.out == nil {
+		s.findings = append(s.findings, batch...)
+		return
+	}
+	for _, f := range batch {
+		select {
+		case s.out <- f:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// scanEnvironments parses the environment labels of opts
+func scanEnvironments(opts ScanOptions) (*crypto.Environments, error) {
+	if opts.Environment != "" {
+		if err := crypto.CheckEnvironment(opts.Environment); err != nil {
+			return nil, err
+		}
+	}
+	environments := &crypto.Environments{Default: opts.Environment}
+	for _, spec := range opts.EnvironmentPaths {
+		mapping, err := crypto.ParseEnvironmentPath(spec)
+		if err != nil {
+			return nil, err
+		}
+		environments.Paths = append(environments.Paths, mapping)
+	}
+	return environments, nil
+}
+
+// ScanRoots returns the absolute roots findings are fingerprinted and matched
+// against SBOM paths from
+func ScanRoots(opts ScanOptions) []string {
+	if opts.Mode != ModeFile && opts.Mode != "" {
+		return nil
+	}
+	targets := opts.Targets
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+	var roots []string
+	for _, target := range targets {
+		if absPath, err := filepath.Abs(target); err == nil {
+			roots = append(roots, absPath)
+		}
+	}
+	return roots
+}
+
+// recordMetrics updates the scanner's Prometheus metrics for a scan
+func recordMetrics(mode string, duration time.Duration, assets int, findings []metrics.Finding) {
+	if mode == "" {
+		mode = ModeFile
+	}
+	metrics.Default.RecordScan(mode, duration, assets, findings)
+}
+
+// scanFiles processes traditional file/directory scanning
+func scanFiles(ctx context.Context, scanner *crypto.Scanner, opts ScanOptions, sink *findingSink) (ScanResult, error) {
+	if opts.CacheDir != "" {
+		cache, err := crypto.NewScanCache(opts.CacheDir, scanner)
+		if err != nil {
+			return ScanResult{}, err
+		}
+		scanner.Cache = cache
+	}
+
+	targets := opts.Targets
+	// If no directory or file list specified, use current directory
+	if len(targets) == 0 && len(opts.Files) == 0 {
+		currentDir, err := os.Getwd()
+		if err != nil {
+			return ScanResult{}, fmt.Errorf("error getting current directory: %w", err)
+		}
+		targets = []string{currentDir}
+	}
+
+	var absTargets []string
+	var targetAssets []utils.TargetAssets
+	var scanErr error
+	assetCount := 0
+
+	for _, target := range targets {
+		targetStart := assetCount
+		if ctx.Err() != nil {
+			scanErr = fmt.Errorf("%w: %w", ErrScanCanceled, ctx.Err())
+			break
+		}
+
+		absPath, err := filepath.Abs(target)
+		if err != nil {
+			return ScanResult{}, fmt.Errorf("error resolving path: %w", err)
+		}
+
+		if opts.Verbose {
+			scanner.Logger.Printf("Scanning: %s\n", absPath)
+		}
+
+		fileInfo, err := os.Stat(absPath)
+		if err != nil {
+			return ScanResult{}, fmt.Errorf("error reading path: %w", err)
+		}
+
+		if fileInfo.IsDir() {
+			var dirCount int
+			if sink.out != nil {
+				dirCount, err = streamDirectory(ctx, scanner, absPath, sink)
+			} else {
+				var dirResults []crypto.Result
+				dirResults, dirCount, err = scanner.ScanDirectoryContext(ctx, absPath)
+				sink.add(ctx, dirResults)
+			}
+			assetCount += dirCount
+			if err != nil {
+				scanErr = err
+				absTargets = append(absTargets, absPath)
+				targetAssets = append(targetAssets, utils.TargetAssets{Target: absPath, TotalAssets: assetCount - targetStart})
+				break
+			}
+		} else if scanner.ScanArchives && crypto.IsArchive(absPath) {
+			archiveResults, entryCount := scanner.ScanArchive(absPath)
+			sink.add(ctx, archiveResults)
+			assetCount += entryCount
+		} else if scanner.ScanBinaries && crypto.IsBinaryFile(absPath) {
+			sink.add(ctx, scanner.ScanBinary(absPath))
+			assetCount++
+		} else if crypto.IsCertificateFile(absPath) {
+			sink.add(ctx, scanner.ScanCertificateFile(absPath))
+			assetCount++
+		} else if crypto.IsKeystoreFile(absPath) {
+			sink.add(ctx, scanner.ScanKeystore(absPath))
+			assetCount++
+		} else if scanner.ScanConfig && crypto.IsConfigFile(absPath) {
+			sink.add(ctx, scanner.ScanConfigFile(ctx, absPath))
+			assetCount++
+		} else {
+			sink.add(ctx, scanner.ScanFile(absPath))
+			assetCount++
+		}
+		absTargets = append(absTargets, absPath)
+		targetAssets = append(targetAssets, utils.TargetAssets{Target: absPath, TotalAssets: assetCount - targetStart})
+	}
+
+	if len(opts.Files) > 0 && scanErr == nil {
+		var files []string
+		for _, file := range opts.Files {
+			absPath, err := filepath.Abs(file)
+			if err != nil {
+				return ScanResult{}, fmt.Errorf("error resolving path: %w", err)
+			}
+			files = append(files, absPath)
+		}
+		if opts.Verbose {
+			scanner.Logger.Printf("Scanning %d listed files\n", len(files))
+		}
+
+		var fileCount int
+		var err error
+		if sink.out != nil {
+			fileCount, err = streamFiles(ctx, scanner, files, sink)
+		} else {
+			var fileResults []crypto.Result
+			fileResults, fileCount, err = scanner.ScanFilesContext(ctx, files)
+			sink.add(ctx, fileResults)
+		}
+		assetCount += fileCount
+		scanErr = err
+		absTargets = append(absTargets, files...)
+	}
+
+	metadata := utils.ScanMetadata{
+		Mode:        "file",
+		Target:      strings.Join(absTargets, ","),
+		TotalAssets: assetCount,
+		ScanTime:    utils.GetCurrentTimestamp(),
+	}
+	metadata.TimedOutFiles = scanner.TimedOutFiles()
+	if len(targetAssets) > 1 {
+		metadata.Targets = targetAssets
+	}
+	if scanner.Cache != nil {
+		metadata.CacheHits = scanner.Cache.Hits()
+		metadata.CacheMisses = scanner.Cache.Misses()
+		if opts.Verbose {
+			scanner.Logger.Printf("Scan cache: %d hits, %d misses\n", metadata.CacheHits, metadata.CacheMisses)
+		}
+	}
+
+	return ScanResult{Metadata: metadata}, scanErr
+}
+
+// streamDirectory scans dir, passing each finding to sink as soon as its file is done
+func streamDirectory(ctx context.Context, scanner *crypto.Scanner, dir string, sink *findingSink) (int, error) {
+	return streamScan(ctx, sink, func(found chan<- crypto.Result) (int, error) {
+		return scanner.ScanDirectoryStream(ctx, dir, found)
+	})
+}
+
+// streamFiles scans the listed files, passing each finding to sink as soon as its file is done
+func streamFiles(ctx context.Context, scanner *crypto.Scanner, files []string, sink *findingSink) (int, error) {
+	return streamScan(ctx, sink, func(found chan<- crypto.Result) (int, error) {
+		return scanner.ScanFilesStream(ctx, files, found)
+	})
+}
+
+// streamScan runs scan, forwarding the findings it sends to sink
+func streamScan(ctx context.Context, sink *findingSink, scan func(found chan<- crypto.Result) (int, error)) (int, error) {
+	found := make(chan crypto.Result)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for f := range found {
+			sink.add(ctx, []Finding{f})
+		}
+	}()
+
+	count, err := scan(found)
+	close(found)
+	<-done
+	return count, err
+}
+
+// scanKubernetes processes Kubernetes cluster scanning
+func scanKubernetes(ctx context.Context, scanner *crypto.Scanner, opts ScanOptions) (ScanResult, error) {
+	// Trim whitespace from namespaces
+	var targetNamespaces []string
+	for _, ns := range opts.Targets {
+		targetNamespaces = append(targetNamespaces, strings.TrimSpace(ns))
+	}
+
+	if opts.Verbose {
+		scanner.Logger.Printf("Starting Kubernetes cluster scan...\n")
+		if len(targetNamespaces) > 0 {
+			scanner.Logger.Printf("Target namespaces: %s\n", strings.Join(targetNamespaces, ","))
+		} else {
+			scanner.Logger.Printf("Scanning all accessible namespaces\n")
+		}
+	}
+
+	k := opts.Kubernetes
+	scanner.Kubeconfig = k.Kubeconfig
+	scanner.KubeContext = k.Context
+	results, assetCount, err := scanner.ScanKubernetes(ctx, targetNamespaces, k.scanOptions())
+
+	metadata := utils.ScanMetadata{
+		Mode:        "kubernetes",
+		Target:      strings.Join(targetNamespaces, ","),
+		TotalAssets: assetCount,
+		ScanTime:    utils.GetCurrentTimestamp(),
+		Namespaces:  targetNamespaces,
+	}
+
+	return ScanResult{Findings: results, Metadata: metadata}, err
+}
+
+// scanPCAP processes PCAP file analysis
+func scanPCAP(ctx context.Context, scanner *crypto.Scanner, opts ScanOptions) (ScanResult, error) {
+	pcapFile := ""
+	if len(opts.Targets) > 0 {
+		pcapFile = opts.Targets[0]
+	}
+	n := opts.Network
+
+	if opts.Verbose {
+		if n.LiveCapture {
+			scanner.Logger.Printf("Starting live network capture on interface %s for %s...\n", n.Interface, n.Duration)
+		} else {
+			scanner.Logger.Printf("Analyzing PCAP file: %s\n", pcapFile)
+		}
+	}
+
+	results, assetCount, err := scanner.ScanPCAP(ctx, pcapFile, n.LiveCapture, n.Interface, n.Duration, n.TLSOnly)
+
+	target := pcapFile
+	if pcapFile == crypto.PCAPStdin {
+		target = crypto.PCAPStdinSource
+	}
+	if n.LiveCapture {
+		target = fmt.Sprintf("%s (live:%s)", n.Interface, n.Duration)
+	}
+
+	metadata := utils.ScanMetadata{
+		Mode:        "pcap",
+		Target:      target,
+		TotalAssets: assetCount,
+		ScanTime:    utils.GetCurrentTimestamp(),
+	}
+
+	return ScanResult{Findings: results, Metadata: metadata}, err
+}
+
+// scanNetwork processes live network monitoring
+func scanNetwork(ctx context.Context, scanner *crypto.Scanner, opts ScanOptions) (ScanResult, error) {
+	n := opts.Network
+
+	if opts.Verbose {
+		scanner.Logger.Printf("Starting network monitoring on interface %s for %s...\n", n.Interface, n.Duration)
+	}
+
+	results, assetCount, err := scanner.ScanNetwork(ctx, n.Interface, n.Duration, n.TLSOnly)
+
+	metadata := utils.ScanMetadata{
+		Mode:        "network",
+		Target:      fmt.Sprintf("%s (duration:%s)", n.Interface, n.Duration),
+		TotalAssets: assetCount,
+		ScanTime:    utils.GetCurrentTimestamp(),
+	}
+
+	return ScanResult{Findings: results, Metadata: metadata}, err
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"qvs-pro/scanner/internal/utils"
+	"qvs-pro/scanner/internal/webhook"
+)
+
+func TestWebhookPostsCBOM(t *testing.T) {
+	var gotBody []byte
+	var gotAuth, gotContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST, got %s", r.Method)
+		}
+		gotBody, _ = io.ReadAll(r.Body)
+		gotAuth = r.Header.Get("Authorization")
+		gotContentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	var body bytes.Buffer
+	metadata := utils.ScanMetadata{Mode: "file", Target: "/src", TotalAssets: 2, ScanTime: utils.GetCurrentTimestamp()}
+	if err := utils.WriteOutput(&body, "cbom", sampleResults, metadata, "file"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := webhook.Config{URL: server.URL, AuthHeader: "Authorization: Bearer s3cret"}
+	if err := webhook.Post(context.Background(), cfg, utils.ContentType("cbom"), body.Bytes()); err != nil {
+		t.Fatalf("Post returned error: %v", err)
+	}
+
+	var report utils.CBOMReport
+	if err := json.Unmarshal(gotBody, &report); err != nil {
+		t.Fatalf("Posted body is not valid CBOM JSON: %v", err)
+	}
+	if len(report.Components) != len(sampleResults) {
+		t.Errorf("Expected %d components in posted CBOM, got %d", len(sampleResults), len(report.Components))
+	}
+	if gotAuth != "Bearer s3cret" {
+		t.Errorf("Expected auth header to be forwarded, got %q", gotAuth)
+	}
+	if gotContentType != "application/json" {
+		t.Errorf("Expected application/json content type, got %q", gotContentType)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := webhook.Config{URL: server.URL, Retries: 3, Backoff: time.Millisecond}
+	if err := webhook.Post(context.Background(), cfg, "application/json", []byte("[]")); err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	cfg.URL = rejecting.URL
+	if err := webhook.Post(context.Background(), cfg, "application/json", []byte("[]")); err == nil {
+		t.Error("Expected error for 400 response")
+	}
+	if calls != 1 {
+		t.Errorf("Expected client errors not to be retried, got %d attempts", calls)
+	}
+}